@@ -8,6 +8,7 @@ import (
 
 	"github.com/apicat/apicat/backend/app/util"
 	"github.com/apicat/apicat/backend/common/openai"
+	"github.com/apicat/apicat/backend/common/search"
 	"github.com/apicat/apicat/backend/common/spec/plugin/openapi"
 	"github.com/apicat/apicat/backend/common/translator"
 	"github.com/apicat/apicat/backend/config"
@@ -128,6 +129,7 @@ func AICreateCollection(ctx *gin.Context) {
 		DefinitionParameters: models.DefinitionParametersImport(currentProject.(*models.Projects).ID, content.Definitions.Parameters),
 	}
 	records := models.CollectionsImport(currentProject.(*models.Projects).ID, data.ParentID, content.Collections, refContentVirtualIDToId)
+	resetSearchIndex(currentProject.(*models.Projects).ID)
 
 	if len(records) == 0 {
 		slog.DebugCtx(ctx, "CollectionsImport Failed")
@@ -254,6 +256,7 @@ func AICreateSchema(ctx *gin.Context) {
 		})
 		return
 	}
+	updateSearchIndex(definition.ProjectId, search.SchemaDocument(definition.ID, definition.Name, definition.Description, definition.Schema))
 
 	ctx.JSON(http.StatusCreated, gin.H{
 		"id":          definition.ID,
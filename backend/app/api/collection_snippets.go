@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/apicat/apicat/backend/common/spec/plugin/snippet"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+type CollectionSnippetsData struct {
+	Language      string `form:"lang" binding:"omitempty,oneof=curl go python javascript java"`
+	EnvironmentID uint   `form:"environment_id"`
+}
+
+// CollectionsSnippets 生成接口的请求代码 指定环境时使用环境变量替换后的地址和参数
+func CollectionsSnippets(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	project := currentProject.(*models.Projects)
+	currentCollection, _ := ctx.Get("CurrentCollection")
+	collection := currentCollection.(*models.Collections)
+
+	var data CollectionSnippetsData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindQuery(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	apicatData := models.CollectionExport(project, collection)
+	if data.EnvironmentID > 0 {
+		env, ok := projectEnvironment(project.ID, data.EnvironmentID)
+		if !ok {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"code":    enum.Display404ErrorMessage,
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.NotFound"}),
+			})
+			return
+		}
+		apicatData.ReplaceVariables(env.ToSpec(false).Map(false))
+	}
+
+	opt := snippet.Option{
+		Globals:    apicatData.Globals.Parameters,
+		Securities: apicatData.Definitions.Securities,
+	}
+	if len(apicatData.Servers) > 0 {
+		opt.ServerURL = apicatData.Servers[0].URL
+	}
+
+	for path, methods := range apicatData.CollectionsMap(true, 2) {
+		for method, part := range methods {
+			if data.Language == "" {
+				ctx.JSON(http.StatusOK, snippet.GenerateAll(method, path, &part, opt))
+				return
+			}
+			code, _ := snippet.Generate(data.Language, method, path, &part, opt)
+			ctx.JSON(http.StatusOK, []snippet.Snippet{{Language: data.Language, Code: code}})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.NotHttpApi"}),
+	})
+}
@@ -6,10 +6,10 @@ import (
 	"net/http"
 
 	"github.com/apicat/apicat/backend/app/util"
-	"github.com/apicat/apicat/backend/common/spec"
-	"github.com/apicat/apicat/backend/common/spec/plugin/export"
-	"github.com/apicat/apicat/backend/common/spec/plugin/openapi"
+	"github.com/apicat/apicat/backend/common/search"
+	"github.com/apicat/apicat/backend/common/spec/plugin/curl"
 	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
 	"github.com/gin-gonic/gin"
@@ -22,8 +22,9 @@ type CollectionDataGetData struct {
 }
 
 type ExportCollection struct {
-	Type     string `form:"type" binding:"required,oneof=apicat swagger openapi3.0.0 openapi3.0.1 openapi3.0.2 openapi3.1.0 HTML md"`
-	Download string `form:"download" binding:"omitempty,oneof=true false"`
+	Type          string `form:"type" binding:"required,oneof=apicat swagger openapi3.0.0 openapi3.0.1 openapi3.0.2 openapi3.1.0 postman HTML md sdk server asyncapi"`
+	Download      string `form:"download" binding:"omitempty,oneof=true false"`
+	EnvironmentID uint   `form:"environment_id"`
 }
 
 type CollectionList struct {
@@ -36,11 +37,17 @@ type CollectionList struct {
 }
 
 type CollectionCreate struct {
-	ParentID    uint   `json:"parent_id" binding:"gte=0"`                       // 父级id
-	Title       string `json:"title" binding:"required,lte=255"`                // 名称
-	Type        string `json:"type" binding:"required,oneof=category doc http"` // 类型: category,doc,http
-	Content     string `json:"content"`                                         // 内容
-	IterationID string `json:"iteration_id" binding:"omitempty,gte=0"`          // 迭代id
+	ParentID    uint   `json:"parent_id" binding:"gte=0"`                                                             // 父级id
+	Title       string `json:"title" binding:"required,lte=255"`                                                      // 名称
+	Type        string `json:"type" binding:"required,oneof=category doc http webhook callback graphql channel grpc"` // 类型: category,doc,http,webhook,callback,graphql,channel,grpc
+	Content     string `json:"content"`                                                                               // 内容
+	IterationID string `json:"iteration_id" binding:"omitempty,gte=0"`                                                // 迭代id
+}
+
+type CollectionCurlImport struct {
+	ParentID    uint   `json:"parent_id" binding:"gte=0"`
+	Curl        string `json:"curl" binding:"required"`
+	IterationID string `json:"iteration_id" binding:"omitempty,gte=0"`
 }
 
 type CollectionUpdate struct {
@@ -233,6 +240,11 @@ func CollectionsCreate(ctx *gin.Context) {
 		})
 		return
 	}
+	if collection.Type != "category" {
+		updateSearchIndex(project.ID, search.CollectionDocument(collection.ID, collection.Type, collection.Title, collection.Content))
+		after, _ := collectionItemSpec(collection.ID, collection.Type, collection.Title, collection.Content)
+		emitCollectionWebhook(ctx, webhook.EventCollectionCreated, collection, nil, after)
+	}
 
 	if data.IterationID != "" {
 		iteration, err := models.NewIterations(data.IterationID)
@@ -268,6 +280,95 @@ func CollectionsCreate(ctx *gin.Context) {
 	})
 }
 
+// CollectionsCurlImport 将粘贴的curl命令创建为http接口
+func CollectionsCurlImport(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	data := CollectionCurlImport{}
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	item, err := curl.Import(data.Curl)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.CurlParseFailed"}),
+		})
+		return
+	}
+	content, err := json.Marshal(item.Content)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.CurlParseFailed"}),
+		})
+		return
+	}
+
+	var iteration *models.Iterations
+	if data.IterationID != "" {
+		if iteration, err = models.NewIterations(data.IterationID); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.CreateFailed"}),
+			})
+			return
+		}
+	}
+
+	currentProject, _ := ctx.Get("CurrentProject")
+	collection, _ := models.NewCollections()
+	collection.ProjectId = currentProject.(*models.Projects).ID
+	collection.ParentId = data.ParentID
+	collection.Title = item.Title
+	collection.Type = string(item.Type)
+	collection.Content = string(content)
+	collection.CreatedBy = currentProjectMember.(*models.ProjectMembers).UserID
+	collection.UpdatedBy = currentProjectMember.(*models.ProjectMembers).UserID
+	if err := collection.CreateDoc(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.CreateFailed"}),
+		})
+		return
+	}
+	updateSearchIndex(collection.ProjectId, search.CollectionDocument(collection.ID, collection.Type, collection.Title, collection.Content))
+	after, _ := collectionItemSpec(collection.ID, collection.Type, collection.Title, collection.Content)
+	emitCollectionWebhook(ctx, webhook.EventCollectionCreated, collection, nil, after)
+
+	if iteration != nil {
+		ia, _ := models.NewIterationApis()
+		ia.IterationID = iteration.ID
+		ia.CollectionID = collection.ID
+		ia.CollectionType = collection.Type
+		if err := ia.Create(); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.CreateFailed"}),
+			})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"id":         collection.ID,
+		"parent_id":  collection.ParentId,
+		"title":      collection.Title,
+		"type":       collection.Type,
+		"content":    collection.Content,
+		"created_at": collection.CreatedAt.Format("2006-01-02 15:04:05"),
+		"created_by": collection.Creator(),
+		"updated_at": collection.UpdatedAt.Format("2006-01-02 15:04:05"),
+		"updated_by": collection.Updater(),
+	})
+}
+
 func CollectionsUpdate(ctx *gin.Context) {
 	currentCollection, _ := ctx.Get("CurrentCollection")
 	collection := currentCollection.(*models.Collections)
@@ -289,12 +390,19 @@ func CollectionsUpdate(ctx *gin.Context) {
 		return
 	}
 
+	before, _ := collectionItemSpec(collection.ID, collection.Type, collection.Title, collection.Content)
 	if err := collection.UpdateContent(false, data.Title, data.Content, currentProjectMember.(*models.ProjectMembers).UserID); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.UpdateFailed"}),
 		})
 		return
 	}
+	if collection.Type != "category" {
+		updateSearchIndex(collection.ProjectId, search.CollectionDocument(collection.ID, collection.Type, data.Title, data.Content))
+		after, _ := collectionItemSpec(collection.ID, collection.Type, data.Title, data.Content)
+		collection.Title = data.Title
+		emitCollectionWebhook(ctx, webhook.EventCollectionUpdated, collection, before, after)
+	}
 
 	ctx.Status(http.StatusCreated)
 }
@@ -346,6 +454,11 @@ func CollectionsCopy(ctx *gin.Context) {
 		})
 		return
 	}
+	if newCollection.Type != "category" {
+		updateSearchIndex(newCollection.ProjectId, search.CollectionDocument(newCollection.ID, newCollection.Type, newCollection.Title, newCollection.Content))
+		after, _ := collectionItemSpec(newCollection.ID, newCollection.Type, newCollection.Title, newCollection.Content)
+		emitCollectionWebhook(ctx, webhook.EventCollectionCreated, &newCollection, nil, after)
+	}
 
 	if data.IterationID != "" {
 		iteration, err := models.NewIterations(data.IterationID)
@@ -470,12 +583,23 @@ func CollectionsDelete(ctx *gin.Context) {
 		}
 	}
 
+	// 分类下的集合会一起删除
+	deleted, _ := collection.GetSubCollectionsContainsSelf()
 	if err := models.Deletes(collection.ID, models.Conn, currentProjectMember.(*models.ProjectMembers).UserID); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Collections.DeleteFailed"}),
 		})
 		return
 	}
+	ids := make([]uint, 0, len(deleted))
+	for _, v := range deleted {
+		ids = append(ids, v.ID)
+		if v.Type != "category" {
+			before, _ := collectionItemSpec(v.ID, v.Type, v.Title, v.Content)
+			emitCollectionWebhook(ctx, webhook.EventCollectionDeleted, v, before, nil)
+		}
+	}
+	removeFromSearchIndex(collection.ProjectId, search.TypeCollection, ids...)
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -515,29 +639,23 @@ func CollectionDataGet(ctx *gin.Context) {
 	}
 
 	apicatData := models.CollectionExport(project, collection)
+	if data.EnvironmentID > 0 {
+		env, ok := projectEnvironment(project.ID, data.EnvironmentID)
+		if !ok {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"code":    enum.Display404ErrorMessage,
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.NotFound"}),
+			})
+			return
+		}
+		// 文档中不展示私密变量的值
+		apicatData.ReplaceVariables(env.ToSpec(false).Map(false))
+	}
 	if apicatDataContent, err := json.Marshal(apicatData); err == nil {
 		slog.InfoCtx(ctx, "Export", slog.String("apicat", string(apicatDataContent)))
 	}
 
-	var content []byte
-	switch data.Type {
-	case "swagger":
-		content, err = openapi.Encode(apicatData, "2.0")
-	case "openapi3.0.0":
-		content, err = openapi.Encode(apicatData, "3.0.0")
-	case "openapi3.0.1":
-		content, err = openapi.Encode(apicatData, "3.0.1")
-	case "openapi3.0.2":
-		content, err = openapi.Encode(apicatData, "3.0.2")
-	case "openapi3.1.0":
-		content, err = openapi.Encode(apicatData, "3.1.0")
-	case "HTML":
-		content, err = export.HTML(apicatData)
-	case "md":
-		content, err = export.Markdown(apicatData)
-	default:
-		content, err = apicatData.ToJSON(spec.JSONOption{Indent: "  "})
-	}
+	content, err := specEncode(data.Type, apicatData)
 
 	slog.InfoCtx(ctx, "Export", slog.String(data.Type, string(content)))
 
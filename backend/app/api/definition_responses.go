@@ -324,6 +324,8 @@ func DefinitionResponsesDelete(ctx *gin.Context) {
 		})
 		return
 	}
+	// 解引用会修改引用了这个响应的集合
+	resetSearchIndex(definitionResponses.ProjectID)
 
 	ctx.Status(http.StatusNoContent)
 }
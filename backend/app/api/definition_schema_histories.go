@@ -6,6 +6,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/apicat/apicat/backend/common/search"
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/diff"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
 	"github.com/apicat/apicat/backend/common/translator"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
@@ -330,12 +334,87 @@ func DefinitionSchemaHistoryRestore(ctx *gin.Context) {
 		return
 	}
 
-	if err := dsh.Restore(currentDefinitionSchema.(*models.DefinitionSchemas), currentUser.(*models.Users).ID); err != nil {
+	definition := currentDefinitionSchema.(*models.DefinitionSchemas)
+	before, _ := schemaItemSpec(definition.ID, definition.Name, definition.Description, definition.Schema)
+	if err := dsh.Restore(definition, currentUser.(*models.Users).ID); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"message": translator.Trasnlate(ctx, &translator.TT{ID: "History.RestoreFailed"}),
 		})
 		return
 	}
+	updateSearchIndex(definition.ProjectId, search.SchemaDocument(dsh.SchemaID, dsh.Name, dsh.Description, dsh.Schema))
+	after, _ := schemaItemSpec(definition.ID, dsh.Name, dsh.Description, dsh.Schema)
+	definition.Name = dsh.Name
+	emitSchemaWebhook(ctx, diff.ActionChanged, definition, before, after)
 
 	ctx.Status(http.StatusCreated)
 }
+
+// schemaVersionSpec 返回模型指定历史版本的spec historyID为0时为当前版本
+func schemaVersionSpec(ctx *gin.Context, definitionSchema *models.DefinitionSchemas, historyID uint) (*spec.Spec, bool) {
+	name, description, content := definitionSchema.Name, definitionSchema.Description, definitionSchema.Schema
+	if historyID > 0 {
+		dsh, err := models.NewDefinitionSchemaHistories(historyID)
+		if err != nil || dsh.SchemaID != definitionSchema.ID {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"code":    enum.Display404ErrorMessage,
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "History.NotFound"}),
+			})
+			return nil, false
+		}
+		name, description, content = dsh.Name, dsh.Description, dsh.Schema
+	}
+
+	s, err := schemaItemSpec(definitionSchema.ID, name, description, content)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.ContentParsingFailed"}),
+		})
+		return nil, false
+	}
+	return s, true
+}
+
+// schemaItemSpec 只包含一个模型的spec
+func schemaItemSpec(id uint, name, description, content string) (*spec.Spec, error) {
+	schema := &jsonschema.Schema{}
+	if err := json.Unmarshal([]byte(content), schema); err != nil {
+		return nil, err
+	}
+	return &spec.Spec{
+		Definitions: spec.Definitions{
+			Schemas: spec.Schemas{{
+				ID:          int64(id),
+				Name:        name,
+				Description: description,
+				Schema:      schema,
+			}},
+		},
+	}, nil
+}
+
+// DefinitionSchemaHistoryBreaking 比较模型两个历史版本的差异 并标记是否兼容
+// history_id2为0时和当前版本比较
+func DefinitionSchemaHistoryBreaking(ctx *gin.Context) {
+	currentDefinitionSchema, _ := ctx.Get("CurrentDefinitionSchema")
+	definitionSchema := currentDefinitionSchema.(*models.DefinitionSchemas)
+
+	var data SchemaHistoryDiffData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindQuery(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	source, ok := schemaVersionSpec(ctx, definitionSchema, data.HistoryID1)
+	if !ok {
+		return
+	}
+	target, ok := schemaVersionSpec(ctx, definitionSchema, data.HistoryID2)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, diff.Classify(diff.Compare(source, target)))
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/apicat/apicat/backend/common/search"
+	"github.com/apicat/apicat/backend/common/spec/diff"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
 	"github.com/apicat/apicat/backend/common/translator"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
@@ -37,6 +40,10 @@ type DefinitionSchemaID struct {
 	ID uint `uri:"schemas-id" binding:"required,gte=0"`
 }
 
+type DefinitionSchemaInfer struct {
+	Samples []string `json:"samples" binding:"required,min=1,max=20,dive,required"`
+}
+
 type DefinitionSchemaMove struct {
 	Target OrderContent `json:"target" binding:"required"`
 	Origin OrderContent `json:"origin" binding:"required"`
@@ -152,6 +159,11 @@ func DefinitionSchemasCreate(ctx *gin.Context) {
 		})
 		return
 	}
+	if definition.Type == "schema" {
+		updateSearchIndex(definition.ProjectId, search.SchemaDocument(definition.ID, definition.Name, definition.Description, definition.Schema))
+		after, _ := schemaItemSpec(definition.ID, definition.Name, definition.Description, definition.Schema)
+		emitSchemaWebhook(ctx, diff.ActionAdded, definition, nil, after)
+	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
 		"id":          definition.ID,
@@ -206,10 +218,17 @@ func DefinitionSchemasUpdate(ctx *gin.Context) {
 		return
 	}
 
+	before, _ := schemaItemSpec(definition.ID, definition.Name, definition.Description, definition.Schema)
 	if err := definition.UpdateContent(false, data.Name, data.Description, string(schemaJson), currentProjectMember.(*models.ProjectMembers).UserID); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"message": translator.Trasnlate(ctx, &translator.TT{ID: "DefinitionSchemas.UpdateFail"})})
 		return
 	}
+	if definition.Type == "schema" {
+		updateSearchIndex(definition.ProjectId, search.SchemaDocument(definition.ID, data.Name, data.Description, string(schemaJson)))
+		after, _ := schemaItemSpec(definition.ID, data.Name, data.Description, string(schemaJson))
+		definition.Name = data.Name
+		emitSchemaWebhook(ctx, diff.ActionChanged, definition, before, after)
+	}
 
 	ctx.Status(http.StatusCreated)
 }
@@ -261,6 +280,10 @@ func DefinitionSchemasDelete(ctx *gin.Context) {
 		})
 		return
 	}
+	// 解引用会修改引用了这个模型的集合和模型
+	resetSearchIndex(definition.ProjectId)
+	before, _ := schemaItemSpec(definition.ID, definition.Name, definition.Description, definition.Schema)
+	emitSchemaWebhook(ctx, diff.ActionRemoved, definition, before, nil)
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -340,6 +363,11 @@ func DefinitionSchemasCopy(ctx *gin.Context) {
 		})
 		return
 	}
+	if newDefinition.Type == "schema" {
+		updateSearchIndex(newDefinition.ProjectId, search.SchemaDocument(newDefinition.ID, newDefinition.Name, newDefinition.Description, newDefinition.Schema))
+		after, _ := schemaItemSpec(newDefinition.ID, newDefinition.Name, newDefinition.Description, newDefinition.Schema)
+		emitSchemaWebhook(ctx, diff.ActionAdded, newDefinition, nil, after)
+	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
 		"id":          newDefinition.ID,
@@ -394,3 +422,28 @@ func DefinitionSchemasMove(ctx *gin.Context) {
 
 	ctx.Status(http.StatusCreated)
 }
+
+// DefinitionSchemasInfer 根据json示例推断模型的结构 不会保存
+func DefinitionSchemasInfer(ctx *gin.Context) {
+	var data DefinitionSchemaInfer
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	raws := make([][]byte, 0, len(data.Samples))
+	for _, v := range data.Samples {
+		raws = append(raws, []byte(v))
+	}
+	schema, err := jsonschema.InferJSON(raws...)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "DefinitionSchemas.InvalidSample"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schema)
+}
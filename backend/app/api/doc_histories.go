@@ -1,11 +1,16 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 
+	"github.com/apicat/apicat/backend/common/search"
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/diff"
 	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
 	"github.com/gin-gonic/gin"
@@ -290,12 +295,82 @@ func CollectionHistoryRestore(ctx *gin.Context) {
 		return
 	}
 
-	if err := ch.Restore(currentCollection.(*models.Collections), currentUser.(*models.Users).ID); err != nil {
+	collection := currentCollection.(*models.Collections)
+	before, _ := collectionItemSpec(collection.ID, collection.Type, collection.Title, collection.Content)
+	if err := ch.Restore(collection, currentUser.(*models.Users).ID); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"message": translator.Trasnlate(ctx, &translator.TT{ID: "History.RestoreFailed"}),
 		})
 		return
 	}
+	updateSearchIndex(collection.ProjectId, search.CollectionDocument(ch.CollectionId, ch.Type, ch.Title, ch.Content))
+	after, _ := collectionItemSpec(collection.ID, collection.Type, ch.Title, ch.Content)
+	collection.Title = ch.Title
+	emitCollectionWebhook(ctx, webhook.EventCollectionUpdated, collection, before, after)
 
 	ctx.Status(http.StatusCreated)
 }
+
+// collectionVersionSpec 返回集合指定历史版本的spec historyID为0时为当前版本
+func collectionVersionSpec(ctx *gin.Context, collection *models.Collections, historyID uint) (*spec.Spec, bool) {
+	title, content := collection.Title, collection.Content
+	if historyID > 0 {
+		ch, err := models.NewCollectionHistories(historyID)
+		if err != nil || ch.CollectionId != collection.ID {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"code":    enum.Display404ErrorMessage,
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "History.NotFound"}),
+			})
+			return nil, false
+		}
+		title, content = ch.Title, ch.Content
+	}
+
+	s, err := collectionItemSpec(collection.ID, collection.Type, title, content)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.ContentParsingFailed"}),
+		})
+		return nil, false
+	}
+	return s, true
+}
+
+// collectionItemSpec 只包含一个集合的spec
+func collectionItemSpec(id uint, typ, title, content string) (*spec.Spec, error) {
+	item := &spec.CollectItem{
+		ID:    int64(id),
+		Title: title,
+		Type:  spec.ContentType(typ),
+	}
+	if err := json.Unmarshal([]byte(content), &item.Content); err != nil {
+		return nil, err
+	}
+	return &spec.Spec{Collections: []*spec.CollectItem{item}}, nil
+}
+
+// CollectionHistoryBreaking 比较两个历史版本的差异 并标记是否兼容
+// history_id2为0时和当前版本比较
+func CollectionHistoryBreaking(ctx *gin.Context) {
+	currentCollection, _ := ctx.Get("CurrentCollection")
+	collection := currentCollection.(*models.Collections)
+
+	var data CollectionHistoryDiffData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindQuery(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	source, ok := collectionVersionSpec(ctx, collection, data.HistoryID1)
+	if !ok {
+		return
+	}
+	target, ok := collectionVersionSpec(ctx, collection, data.HistoryID2)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, diff.Classify(diff.Compare(source, target)))
+}
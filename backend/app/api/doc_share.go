@@ -8,6 +8,7 @@ import (
 	"github.com/apicat/apicat/backend/common/encrypt"
 	"github.com/apicat/apicat/backend/common/random"
 	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
 	"github.com/gin-gonic/gin"
@@ -139,7 +140,8 @@ func DocShareSwitch(ctx *gin.Context) {
 			collection.PublicId = shortuuid.New()
 		}
 
-		if collection.SharePassword == "" {
+		shared := collection.SharePassword != ""
+		if !shared {
 			collection.SharePassword = random.GenerateRandomString(4)
 		}
 
@@ -149,6 +151,9 @@ func DocShareSwitch(ctx *gin.Context) {
 			})
 			return
 		}
+		if !shared {
+			emitProjectWebhook(ctx, webhook.EventShareToggled, data.Share, &webhook.Entity{Type: "collection", ID: collection.ID, Title: collection.Title}, nil, nil)
+		}
 
 		ctx.JSON(http.StatusCreated, gin.H{
 			"collection_public_id": collection.PublicId,
@@ -164,6 +169,7 @@ func DocShareSwitch(ctx *gin.Context) {
 			return
 		}
 
+		shared := collection.SharePassword != ""
 		collection.SharePassword = ""
 		if err := collection.Update(); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -171,6 +177,9 @@ func DocShareSwitch(ctx *gin.Context) {
 			})
 			return
 		}
+		if shared {
+			emitProjectWebhook(ctx, webhook.EventShareToggled, data.Share, &webhook.Entity{Type: "collection", ID: collection.ID, Title: collection.Title}, nil, nil)
+		}
 
 		ctx.Status(http.StatusCreated)
 	}
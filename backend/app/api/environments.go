@@ -0,0 +1,215 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+// 私密变量返回给前端时的值 更新时传入该值表示不修改
+const secretVariableMask = "******"
+
+type EnvironmentVariable struct {
+	Name        string `json:"name" binding:"required,lte=255"`
+	Value       string `json:"value"`
+	Secret      bool   `json:"secret"`
+	Description string `json:"description" binding:"lte=255"`
+}
+
+type EnvironmentData struct {
+	Name        string                `json:"name" binding:"required,lte=255"`
+	Description string                `json:"description" binding:"lte=255"`
+	Variables   []EnvironmentVariable `json:"variables" binding:"dive"`
+}
+
+type EnvironmentUriData struct {
+	ProjectID     string `uri:"project-id" binding:"required"`
+	EnvironmentID uint   `uri:"environment-id" binding:"required,gt=0"`
+}
+
+func environmentDetails(env *models.Environments) gin.H {
+	variables := env.GetVariables()
+	for _, v := range variables {
+		if v.Secret {
+			v.Value = secretVariableMask
+		}
+	}
+	return gin.H{
+		"id":          env.ID,
+		"name":        env.Name,
+		"description": env.Description,
+		"variables":   variables,
+	}
+}
+
+// environmentVariables 私密变量的值为掩码时使用原来的值
+func environmentVariables(data []EnvironmentVariable, old []*spec.Variable) []*spec.Variable {
+	oldValues := make(map[string]string)
+	for _, v := range old {
+		oldValues[v.Name] = v.Value
+	}
+	variables := make([]*spec.Variable, 0, len(data))
+	for _, v := range data {
+		value := v.Value
+		if v.Secret && value == secretVariableMask {
+			value = oldValues[v.Name]
+		}
+		variables = append(variables, &spec.Variable{
+			Name:        v.Name,
+			Value:       value,
+			Secret:      v.Secret,
+			Description: v.Description,
+		})
+	}
+	return variables
+}
+
+// getEnvironment 获取当前项目下的环境 不存在时直接响应404
+func getEnvironment(ctx *gin.Context) (*models.Environments, bool) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	var uriData EnvironmentUriData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindUri(&uriData)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	env, ok := projectEnvironment(currentProject.(*models.Projects).ID, uriData.EnvironmentID)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"code":    enum.Display404ErrorMessage,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.NotFound"}),
+		})
+		return nil, false
+	}
+	return env, true
+}
+
+// projectEnvironment 获取项目下的环境 不存在或不属于该项目时返回false
+func projectEnvironment(projectID, environmentID uint) (*models.Environments, bool) {
+	env, err := models.NewEnvironments(environmentID)
+	if err != nil || env.ProjectID != projectID {
+		return nil, false
+	}
+	return env, true
+}
+
+func EnvironmentsList(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	env, _ := models.NewEnvironments()
+	environments, err := env.List(currentProject.(*models.Projects).ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.QueryFailed"}),
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(environments))
+	for _, v := range environments {
+		list = append(list, environmentDetails(v))
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+func EnvironmentsCreate(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data EnvironmentData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	env, _ := models.NewEnvironments()
+	env.ProjectID = currentProject.(*models.Projects).ID
+	env.Name = data.Name
+	env.Description = data.Description
+	env.SetVariables(environmentVariables(data.Variables, nil))
+	if err := env.Create(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.CreateFail"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, environmentDetails(env))
+}
+
+func EnvironmentsUpdate(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data EnvironmentData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	env, ok := getEnvironment(ctx)
+	if !ok {
+		return
+	}
+
+	env.Name = data.Name
+	env.Description = data.Description
+	env.SetVariables(environmentVariables(data.Variables, env.GetVariables()))
+	if err := env.Update(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.UpdateFail"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, environmentDetails(env))
+}
+
+func EnvironmentsDelete(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	env, ok := getEnvironment(ctx)
+	if !ok {
+		return
+	}
+
+	if err := env.Delete(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.DeleteFail"}),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
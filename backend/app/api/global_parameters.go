@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/apicat/apicat/backend/common/array_operations"
+	"github.com/apicat/apicat/backend/common/search"
 	"github.com/apicat/apicat/backend/common/spec"
 	"github.com/apicat/apicat/backend/common/spec/jsonschema"
 	"github.com/apicat/apicat/backend/common/translator"
@@ -319,6 +320,7 @@ func GlobalParametersDelete(ctx *gin.Context) {
 				ctx.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 				return
 			}
+			updateSearchIndex(project.ID, search.CollectionDocument(collection.ID, collection.Type, collection.Title, string(newContent)))
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
 	"github.com/gin-gonic/gin"
@@ -407,6 +408,17 @@ func IterationsUpdate(ctx *gin.Context) {
 		return
 	}
 
+	// 迭代的路由中没有当前项目
+	if project, err := models.NewProjects(iteration.ProjectID); err == nil {
+		payload := newWebhookPayload(ctx, project, webhook.EventIterationUpdated, "")
+		payload.Entity = &webhook.Entity{Type: "iteration", ID: iteration.ID, Title: iteration.Title}
+		payload.Data = map[string]any{
+			"public_id":      iteration.PublicID,
+			"collection_ids": data.CollectionIDs,
+		}
+		emitWebhook(project.ID, payload)
+	}
+
 	ctx.Status(http.StatusCreated)
 }
 
@@ -17,11 +17,14 @@ import (
 
 type MockServer struct {
 	cache sync.Map
+	// 有状态mock的数据 项目id => *mockStore
+	stores sync.Map
 }
 
 func NewMockServer() *MockServer {
 	return &MockServer{
-		cache: sync.Map{},
+		cache:  sync.Map{},
+		stores: sync.Map{},
 	}
 }
 
@@ -34,10 +37,24 @@ func (m *MockServer) Handler(c *gin.Context) {
 		c.Writer.WriteHeader(http.StatusNotFound)
 		return
 	}
-	routes := m.getRequestRoutesSchemaOrCache(p.ID)
-	part := m.matchRoute(c, routes)
+	mc := m.getRequestRoutesSchemaOrCache(p.ID)
+	if m.handleChannel(c, mc) {
+		return
+	}
+	route, part := m.matchRoute(c, mc.routes)
 	if part == nil {
-		c.Writer.WriteHeader(http.StatusNotFound)
+		if !m.handleGraphQL(c, mc) && !m.handleGRPC(c, mc) {
+			c.Writer.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+	if isMockAuth(c) && !m.authorizeRequest(c, part, mc.definitions) {
+		return
+	}
+	if isMockValidate(c) && !m.validateRequest(c, route, part, mc.definitions) {
+		return
+	}
+	if p.MockStateful && m.handleStateful(c, p.ID, mc.routes, route) {
 		return
 	}
 	rescode, _ := strconv.Atoi(c.Query("mock_response_code"))
@@ -78,22 +95,39 @@ func (m *MockServer) ClearCache() gin.HandlerFunc {
 	}
 }
 
-func (m *MockServer) getRequestRoutesSchemaOrCache(id uint) map[string]map[string]spec.HTTPPart {
+// mockCache 缓存的项目路由和公共定义
+type mockCache struct {
+	routes      map[string]map[string]spec.HTTPPart
+	graphql     []spec.GraphQLPart
+	channels    []spec.ChannelPart
+	grpc        []spec.GRPCPart
+	definitions *spec.Definitions
+}
+
+func (m *MockServer) getRequestRoutesSchemaOrCache(id uint) *mockCache {
 	cm, ok := m.cache.Load(id)
 	if ok {
-		return cm.(map[string]map[string]spec.HTTPPart)
+		return cm.(*mockCache)
 	}
 	specObj := &spec.Spec{}
 	specObj.Definitions.Schemas = models.DefinitionSchemasExport(id)
 	specObj.Definitions.Parameters = models.DefinitionParametersExport(id)
 	specObj.Definitions.Responses = models.DefinitionResponsesExport(id)
+	specObj.Definitions.Securities = models.SecuritySchemesExport(id)
 	specObj.Collections = models.CollectionsExport(id)
-	newcm := specObj.CollectionsMap(true, 3)
+	newcm := &mockCache{
+		routes:      specObj.CollectionsMap(true, 3),
+		graphql:     specObj.GraphQLOperations(false, 0),
+		channels:    specObj.Channels(true, 3),
+		grpc:        specObj.GRPCMethods(true, 3),
+		definitions: &specObj.Definitions,
+	}
 	m.cache.Store(id, newcm)
 	return newcm
 }
 
-func (m *MockServer) matchRoute(c *gin.Context, routes map[string]map[string]spec.HTTPPart) *spec.HTTPPart {
+// matchRoute 返回匹配到的路由定义和接口
+func (m *MockServer) matchRoute(c *gin.Context, routes map[string]map[string]spec.HTTPPart) (string, *spec.HTTPPart) {
 	p := strings.Split(c.Param("path"), "/")
 	matched := map[string]struct {
 		vars int
@@ -130,21 +164,21 @@ func (m *MockServer) matchRoute(c *gin.Context, routes map[string]map[string]spe
 				}{hasVar, h}
 			} else {
 				slog.InfoCtx(c, "find route", slog.String("path", path), slog.String("mockpath", c.Param("path")))
-				return &h
+				return path, &h
 			}
 		}
 	}
 	for path, v := range matched {
 		slog.InfoCtx(c, "find route", slog.String("path", path), slog.String("mockpath", c.Param("path")))
-		return &v.data
+		return path, &v.data
 	}
-	return nil
+	return "", nil
 }
 
 func (m *MockServer) renderMockResponse(c *gin.Context, res spec.HTTPResponse) {
 	// find first contentType
 	for k, v := range res.Content {
-		b, _ := json.Marshal(v.Schema)
+		b, _ := json.Marshal(v.Schema.Flatten())
 		responsedata, err := datagen.JSONSchemaGen(b, &datagen.GenOption{
 			DatagenKey: "x-apicat-mock",
 		})
@@ -161,7 +195,7 @@ func (m *MockServer) renderMockResponse(c *gin.Context, res spec.HTTPResponse) {
 						continue
 					}
 				}
-				hb, _ := json.Marshal(h.Schema)
+				hb, _ := json.Marshal(h.Schema.Flatten())
 				headerdata, err := datagen.JSONSchemaGen(hb, &datagen.GenOption{
 					DatagenKey: "x-apicat-mock",
 				})
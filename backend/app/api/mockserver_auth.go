@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// isMockAuth 是否检查请求的认证凭证 通过query参数mock_auth=true开启
+func isMockAuth(c *gin.Context) bool {
+	v, _ := strconv.ParseBool(c.Query("mock_auth"))
+	return v
+}
+
+// authorizeRequest 检查请求中是否带有接口要求的认证凭证 只检查是否存在 不校验凭证的值
+// 缺少凭证时直接响应401并返回false
+func (m *MockServer) authorizeRequest(c *gin.Context, part *spec.HTTPPart, definitions *spec.Definitions) bool {
+	if definitions.Securities.Satisfied(c.Request, part.Security) {
+		return true
+	}
+	slog.InfoCtx(c, "mock request unauthorized", slog.Int("requirements", len(part.Security)))
+	if challenge := mockAuthChallenge(definitions.Securities, part.Security); challenge != "" {
+		c.Header("WWW-Authenticate", challenge)
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"message": translator.Trasnlate(c, &translator.TT{ID: "Mock.Unauthorized"}),
+	})
+	return false
+}
+
+// mockAuthChallenge 使用第一个需要Authorization头的认证方式生成WWW-Authenticate
+func mockAuthChallenge(schemes spec.SecuritySchemes, requirements []spec.SecurityRequirement) string {
+	for _, req := range requirements {
+		for name := range req {
+			scheme := schemes.Lookup(name)
+			if scheme == nil {
+				continue
+			}
+			switch scheme.Type {
+			case spec.SecurityTypeHTTP:
+				if scheme.Scheme == "" {
+					return "Bearer"
+				}
+				return strings.ToUpper(scheme.Scheme[:1]) + scheme.Scheme[1:]
+			case spec.SecurityTypeOAuth2, spec.SecurityTypeOpenIDConnect:
+				return "Bearer"
+			}
+		}
+	}
+	return ""
+}
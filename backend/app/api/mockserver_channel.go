@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/datagen"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"golang.org/x/exp/slog"
+)
+
+// 推送消息的默认间隔和最小间隔
+const (
+	mockChannelInterval    = time.Second
+	mockChannelMinInterval = 100 * time.Millisecond
+)
+
+var mockUpgrader = websocket.Upgrader{
+	// mock服务允许任意来源的页面连接
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// channelEnvelope 订阅多个通道时 客户端发送的订阅请求和服务端推送的消息
+type channelEnvelope struct {
+	// Action 客户端发送subscribe或unsubscribe 服务端回复subscribed unsubscribed或error
+	Action  string `json:"action,omitempty"`
+	Channel string `json:"channel"`
+	// Message 推送的消息名称或者错误信息
+	Message string `json:"message,omitempty"`
+	Payload any    `json:"payload,omitempty"`
+}
+
+// handleChannel websocket连接
+// 路径为通道名称时定时推送该通道subscribe操作的消息
+// 路径为/时 客户端发送{"action":"subscribe","channel":"名称"}订阅多个通道 推送的消息带有通道名称
+// 返回false表示不是websocket请求
+func (m *MockServer) handleChannel(c *gin.Context, mc *mockCache) bool {
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		return false
+	}
+	var direct *spec.ChannelPart
+	if path := c.Param("path"); path != "/" {
+		if direct = matchChannel(mc.channels, path); direct == nil {
+			return false
+		}
+	}
+
+	interval := mockChannelInterval
+	if ms, err := strconv.Atoi(c.Query("mock_interval")); err == nil {
+		interval = time.Duration(ms) * time.Millisecond
+		if interval < mockChannelMinInterval {
+			interval = mockChannelMinInterval
+		}
+	}
+
+	conn, err := mockUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade已经返回了错误响应
+		slog.ErrorCtx(c, "mock websocket upgrade faild", slog.String("err", err.Error()))
+		return true
+	}
+	defer conn.Close()
+
+	// 连接只能有一个写入者 客户端的请求交给当前协程处理
+	requests := make(chan channelEnvelope)
+	done, quit := make(chan struct{}), make(chan struct{})
+	defer close(quit)
+	go func() {
+		defer close(done)
+		for {
+			_, raw, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			// 直接连接通道时忽略客户端发送的消息
+			if direct != nil {
+				continue
+			}
+			var req channelEnvelope
+			if err := json.Unmarshal(raw, &req); err != nil {
+				req = channelEnvelope{Action: "error", Message: err.Error()}
+			}
+			select {
+			case requests <- req:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	subscribed := make(map[string]*spec.ChannelPart)
+	if direct != nil {
+		subscribed[direct.Channel.Channel] = direct
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return true
+		case req := <-requests:
+			if err := conn.WriteJSON(subscribeChannel(mc.channels, subscribed, req)); err != nil {
+				return true
+			}
+		case <-ticker.C:
+			for name, part := range subscribed {
+				msg, payload, ok := mockChannelMessage(part)
+				if !ok {
+					continue
+				}
+				var out any = payload
+				if direct == nil {
+					out = channelEnvelope{Channel: name, Message: msg, Payload: payload}
+				}
+				if err := conn.WriteJSON(out); err != nil {
+					return true
+				}
+			}
+		}
+	}
+}
+
+// subscribeChannel 处理订阅请求 返回回复给客户端的消息
+func subscribeChannel(channels []spec.ChannelPart, subscribed map[string]*spec.ChannelPart, req channelEnvelope) channelEnvelope {
+	switch req.Action {
+	case "subscribe":
+		part := matchChannel(channels, req.Channel)
+		if part == nil {
+			return channelEnvelope{Action: "error", Channel: req.Channel, Message: "channel not found"}
+		}
+		subscribed[req.Channel] = part
+		return channelEnvelope{Action: "subscribed", Channel: req.Channel}
+	case "unsubscribe":
+		delete(subscribed, req.Channel)
+		return channelEnvelope{Action: "unsubscribed", Channel: req.Channel}
+	case "error":
+		return req
+	}
+	return channelEnvelope{Action: "error", Channel: req.Channel, Message: "unsupported action " + req.Action}
+}
+
+// matchChannel 通道名称中的{name}参数匹配任意值 开头的/可以省略
+func matchChannel(channels []spec.ChannelPart, name string) *spec.ChannelPart {
+	p := strings.Split(strings.Trim(name, "/"), "/")
+	var matched *spec.ChannelPart
+	for i := range channels {
+		rp := strings.Split(strings.Trim(channels[i].Channel.Channel, "/"), "/")
+		if len(rp) != len(p) {
+			continue
+		}
+		vars, ok := 0, true
+		for k, v := range rp {
+			if v == p[k] {
+				continue
+			}
+			if len(v) > 0 && v[0] == '{' {
+				vars++
+				continue
+			}
+			ok = false
+			break
+		}
+		if !ok {
+			continue
+		}
+		if vars == 0 {
+			return &channels[i]
+		}
+		if matched == nil {
+			matched = &channels[i]
+		}
+	}
+	return matched
+}
+
+// mockChannelMessage 随机选择subscribe操作中的一个消息生成数据
+func mockChannelMessage(part *spec.ChannelPart) (string, any, bool) {
+	if part.Subscribe == nil || len(part.Subscribe.Messages) == 0 {
+		return "", nil, false
+	}
+	msg := part.Subscribe.Messages[rand.Intn(len(part.Subscribe.Messages))]
+	if msg.Payload == nil {
+		return msg.Name, nil, true
+	}
+	b, _ := json.Marshal(msg.Payload.Flatten())
+	payload, err := datagen.JSONSchemaGen(b, &datagen.GenOption{
+		DatagenKey: "x-apicat-mock",
+	})
+	if err != nil {
+		return "", nil, false
+	}
+	return msg.Name, payload, true
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/plugin/graphql"
+	"github.com/apicat/datagen"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// graphqlRequest GET请求时从query中读取
+type graphqlRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// handleGraphQL 请求路径为graphql操作的地址时 按照查询文档的选择集生成数据
+// 返回false表示不是graphql请求
+func (m *MockServer) handleGraphQL(c *gin.Context, mc *mockCache) bool {
+	ops := make([]spec.GraphQLPart, 0)
+	for _, v := range mc.graphql {
+		if v.Operation.Path == c.Param("path") {
+			ops = append(ops, v)
+		}
+	}
+	if len(ops) == 0 {
+		return false
+	}
+
+	var req graphqlRequest
+	switch c.Request.Method {
+	case http.MethodGet:
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+	case http.MethodPost:
+		if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+			renderGraphQLError(c, http.StatusBadRequest, err.Error())
+			return true
+		}
+	default:
+		return false
+	}
+	if req.Query == "" {
+		renderGraphQLError(c, http.StatusBadRequest, "must provide query string")
+		return true
+	}
+
+	s, err := graphql.ResponseSchema(req.Query, req.OperationName, ops, mc.definitions.SchemaResolver())
+	if err != nil {
+		renderGraphQLError(c, http.StatusOK, err.Error())
+		return true
+	}
+	b, _ := json.Marshal(s.Flatten())
+	data, err := datagen.JSONSchemaGen(b, &datagen.GenOption{
+		DatagenKey: "x-apicat-mock",
+	})
+	if err != nil {
+		slog.ErrorCtx(c, "datagen jsonschema gen faild", slog.String("err", err.Error()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return true
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+	return true
+}
+
+func renderGraphQLError(c *gin.Context, code int, message string) {
+	c.JSON(code, gin.H{
+		"errors": []gin.H{{"message": message}},
+	})
+}
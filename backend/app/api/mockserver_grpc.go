@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/datagen"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// 服务端流式方法返回的消息数量
+const mockGRPCStreamCount = 3
+
+// handleGRPC 按照gRPC-JSON转码的方式模拟gRPC方法
+// 有google.api.http注解时使用注解的方法和路径 否则为POST /package.Service/Method
+// 服务端流式方法按行返回{"result": 消息} 与grpc-gateway相同
+// 返回false表示没有匹配的方法
+func (m *MockServer) handleGRPC(c *gin.Context, mc *mockCache) bool {
+	var part *spec.GRPCPart
+	for i := range mc.grpc {
+		method, path := mc.grpc[i].Method.Transcoding()
+		if method == c.Request.Method && matchHTTPRule(path, c.Param("path")) {
+			part = &mc.grpc[i]
+			break
+		}
+	}
+	if part == nil {
+		return false
+	}
+
+	count := 1
+	if part.Method.ServerStreaming {
+		count = mockGRPCStreamCount
+	}
+	messages := make([]any, 0, count)
+	for i := 0; i < count; i++ {
+		var data any = map[string]any{}
+		if part.Response != nil {
+			b, _ := json.Marshal(part.Response.Flatten())
+			v, err := datagen.JSONSchemaGen(b, &datagen.GenOption{
+				DatagenKey: "x-apicat-mock",
+			})
+			if err != nil {
+				slog.ErrorCtx(c, "datagen jsonschema gen faild", slog.String("err", err.Error()))
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return true
+			}
+			data = v
+		}
+		messages = append(messages, data)
+	}
+
+	if !part.Method.ServerStreaming {
+		c.JSON(http.StatusOK, messages[0])
+		return true
+	}
+	c.Header("Content-Type", "application/json")
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	for _, v := range messages {
+		if err := enc.Encode(gin.H{"result": v}); err != nil {
+			return true
+		}
+		c.Writer.Flush()
+	}
+	return true
+}
+
+// matchHTTPRule 匹配google.api.http注解中的路径模板
+// {name}和*匹配一段路径 **匹配剩余的路径 {name=shelves/*}按照等号后的模板匹配
+func matchHTTPRule(rule, path string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for len(rule) > 0 {
+		switch {
+		case rule[0] == '{':
+			end := strings.IndexByte(rule, '}')
+			if end < 0 {
+				return false
+			}
+			tpl := "*"
+			if i := strings.IndexByte(rule[:end], '='); i >= 0 {
+				tpl = rule[i+1 : end]
+			}
+			b.WriteString(httpRuleSegments(tpl))
+			rule = rule[end+1:]
+		case strings.HasPrefix(rule, "**"):
+			b.WriteString(".+")
+			rule = rule[2:]
+		case rule[0] == '*':
+			b.WriteString("[^/]+")
+			rule = rule[1:]
+		default:
+			end := strings.IndexAny(rule, "{*")
+			if end < 0 {
+				end = len(rule)
+			}
+			b.WriteString(regexp.QuoteMeta(rule[:end]))
+			rule = rule[end:]
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(path)
+}
+
+// httpRuleSegments 变量中的模板转为正则
+func httpRuleSegments(tpl string) string {
+	segs := strings.Split(tpl, "/")
+	for i, v := range segs {
+		switch v {
+		case "**":
+			segs[i] = ".+"
+		case "*":
+			segs[i] = "[^/]+"
+		default:
+			segs[i] = regexp.QuoteMeta(v)
+		}
+	}
+	return strings.Join(segs, "/")
+}
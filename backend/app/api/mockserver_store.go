@@ -0,0 +1,325 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/apicat/datagen"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// 初始化集合时生成的数据条数
+const mockStoreSeedCount = 5
+
+type MockStatefulSwitchData struct {
+	Status string `json:"status" binding:"required,oneof=open close"`
+}
+
+// mockStore 有状态mock的内存存储 每个项目一个
+// key为集合的路径 如 /users
+type mockStore struct {
+	mu        sync.Mutex
+	resources map[string]*mockResource
+}
+
+// mockResource REST风格的资源集合
+type mockResource struct {
+	idKey   string
+	integer bool
+	nextID  int64
+	items   []map[string]any
+}
+
+// mockResourceRoute 路由对应的资源
+// collection为集合路径 item为单个资源的路径 id为请求中的资源id 集合请求时为空
+type mockResourceRoute struct {
+	collection string
+	item       string
+	param      string
+	id         string
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{resources: make(map[string]*mockResource)}
+}
+
+func (m *MockServer) getStore(projectID uint) *mockStore {
+	v, _ := m.stores.LoadOrStore(projectID, newMockStore())
+	return v.(*mockStore)
+}
+
+// resetStore 清空项目的mock数据 下次请求时重新生成
+func (m *MockServer) resetStore(projectID uint) {
+	m.stores.Delete(projectID)
+}
+
+// findMockResource 根据路由找到对应的资源集合
+// /users 和 /users/{id} 同时存在时认为是一个资源集合
+func findMockResource(routes map[string]map[string]spec.HTTPPart, route, path string) *mockResourceRoute {
+	if param := lastPathParam(route); param != "" {
+		collection := route[:strings.LastIndex(route, "/")]
+		if _, ok := routes[collection]; !ok || collection == "" {
+			return nil
+		}
+		return &mockResourceRoute{
+			collection: collection,
+			item:       route,
+			param:      param,
+			id:         path[strings.LastIndex(path, "/")+1:],
+		}
+	}
+	for k := range routes {
+		if i := strings.LastIndex(k, "/"); i > 0 && k[:i] == route {
+			if param := lastPathParam(k); param != "" {
+				return &mockResourceRoute{
+					collection: route,
+					item:       k,
+					param:      param,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lastPathParam 返回路径最后一段的参数名 /users/{id} => id
+func lastPathParam(route string) string {
+	v := route[strings.LastIndex(route, "/")+1:]
+	if len(v) > 2 && v[0] == '{' && v[len(v)-1] == '}' {
+		return v[1 : len(v)-1]
+	}
+	return ""
+}
+
+// mockItemSchema 查找单个资源的schema
+// 依次使用 GET单个资源的响应 POST集合的响应 GET集合响应中的数组元素
+func mockItemSchema(routes map[string]map[string]spec.HTTPPart, res *mockResourceRoute) *jsonschema.Schema {
+	schemas := []*jsonschema.Schema{
+		mockSuccessSchema(routes[res.item]["get"]),
+		mockSuccessSchema(routes[res.collection]["post"]),
+	}
+	if s := mockSuccessSchema(routes[res.collection]["get"]); s != nil &&
+		schemaHasType(s, "array") && s.Items != nil && !s.Items.IsBool() {
+		schemas = append(schemas, s.Items.Value())
+	}
+	for _, s := range schemas {
+		if s != nil && schemaHasType(s, "object") {
+			return s
+		}
+	}
+	return nil
+}
+
+// mockSuccessSchema 返回2xx响应中的json schema
+func mockSuccessSchema(part spec.HTTPPart) *jsonschema.Schema {
+	for _, v := range part.Responses {
+		if v.Code < 200 || v.Code >= 300 {
+			continue
+		}
+		for k, c := range v.Content {
+			if strings.Contains(k, "json") && c != nil && c.Schema != nil {
+				return c.Schema.Flatten()
+			}
+		}
+	}
+	return nil
+}
+
+// handleStateful 使用内存数据处理REST风格的请求
+// 没有找到对应的资源集合时返回false 使用默认的随机数据响应
+func (m *MockServer) handleStateful(c *gin.Context, projectID uint, routes map[string]map[string]spec.HTTPPart, route string) bool {
+	res := findMockResource(routes, route, c.Param("path"))
+	if res == nil {
+		return false
+	}
+	schema := mockItemSchema(routes, res)
+	if schema == nil {
+		return false
+	}
+
+	store := m.getStore(projectID)
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	r, ok := store.resources[res.collection]
+	if !ok {
+		r = newMockResource(res.param, schema)
+		store.resources[res.collection] = r
+	}
+	slog.InfoCtx(c, "stateful mock", slog.String("collection", res.collection), slog.String("id", res.id))
+
+	method := c.Request.Method
+	if res.id == "" {
+		switch method {
+		case http.MethodGet:
+			c.JSON(http.StatusOK, r.items)
+		case http.MethodPost:
+			body, ok := bindMockItem(c)
+			if !ok {
+				return true
+			}
+			item := r.create(schema, body)
+			c.JSON(http.StatusCreated, item)
+		default:
+			c.Status(http.StatusMethodNotAllowed)
+		}
+		return true
+	}
+
+	i := r.index(res.id)
+	if i < 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"message": translator.Trasnlate(c, &translator.TT{ID: "Mock.ResourceNotFound"}),
+		})
+		return true
+	}
+	switch method {
+	case http.MethodGet:
+		c.JSON(http.StatusOK, r.items[i])
+	case http.MethodPut, http.MethodPatch:
+		body, ok := bindMockItem(c)
+		if !ok {
+			return true
+		}
+		if method == http.MethodPut {
+			r.items[i] = body
+		} else {
+			for k, v := range body {
+				r.items[i][k] = v
+			}
+		}
+		// id不允许修改
+		r.items[i][r.idKey] = r.idValue(res.id)
+		c.JSON(http.StatusOK, r.items[i])
+	case http.MethodDelete:
+		r.items = append(r.items[:i], r.items[i+1:]...)
+		c.Status(http.StatusNoContent)
+	default:
+		c.Status(http.StatusMethodNotAllowed)
+	}
+	return true
+}
+
+func bindMockItem(c *gin.Context) (map[string]any, bool) {
+	body := make(map[string]any)
+	if err := json.NewDecoder(c.Request.Body).Decode(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(c, &translator.TT{ID: "Mock.InvalidBody"}),
+		})
+		return nil, false
+	}
+	return body, true
+}
+
+// newMockResource 创建资源集合并使用datagen生成初始数据
+// id字段使用路径参数名 schema中不存在时使用id
+func newMockResource(param string, schema *jsonschema.Schema) *mockResource {
+	r := &mockResource{idKey: "id"}
+	if _, ok := schema.Properties[param]; ok {
+		r.idKey = param
+	}
+	if p, ok := schema.Properties[r.idKey]; !ok || schemaHasType(p, "integer") || schemaHasType(p, "number") {
+		r.integer = true
+	}
+	for i := 0; i < mockStoreSeedCount; i++ {
+		r.create(schema, nil)
+	}
+	return r
+}
+
+// create 使用datagen生成数据 body中的字段会覆盖生成的值
+func (r *mockResource) create(schema *jsonschema.Schema, body map[string]any) map[string]any {
+	item := make(map[string]any)
+	b, _ := json.Marshal(schema)
+	if v, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"}); err == nil {
+		if x, ok := v.(map[string]any); ok {
+			item = x
+		}
+	}
+	for k, v := range body {
+		item[k] = v
+	}
+	r.nextID++
+	item[r.idKey] = r.idValue(strconv.FormatInt(r.nextID, 10))
+	r.items = append(r.items, item)
+	return item
+}
+
+func (r *mockResource) idValue(id string) any {
+	if r.integer {
+		if n, err := strconv.ParseInt(id, 10, 64); err == nil {
+			return n
+		}
+	}
+	return id
+}
+
+func (r *mockResource) index(id string) int {
+	for i, v := range r.items {
+		if fmt.Sprint(v[r.idKey]) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// StatefulSwitch 开启或关闭项目的有状态mock
+func (m *MockServer) StatefulSwitch(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data MockStatefulSwitchData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	project := currentProject.(*models.Projects)
+	project.MockStateful = data.Status == "open"
+	if err := project.Save(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Mock.ModifyStatefulStatusFail"}),
+		})
+		return
+	}
+	m.resetStore(project.ID)
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"mock_stateful": project.MockStateful,
+	})
+}
+
+// StatefulReset 清空项目的有状态mock数据
+func (m *MockServer) StatefulReset(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	m.resetStore(currentProject.(*models.Projects).ID)
+	ctx.Status(http.StatusCreated)
+}
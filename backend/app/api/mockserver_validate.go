@@ -0,0 +1,200 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slog"
+)
+
+// mockValidateError 请求校验失败的字段
+type mockValidateError struct {
+	In      string `json:"in"`
+	Name    string `json:"name,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// isMockValidate 是否开启请求校验 通过query参数mock_validate=true开启
+func isMockValidate(c *gin.Context) bool {
+	v, _ := strconv.ParseBool(c.Query("mock_validate"))
+	return v
+}
+
+// validateRequest 使用文档中定义的请求参数和body校验请求
+// 校验失败时直接响应错误并返回false
+// 参数错误响应400 只有body错误时响应422
+func (m *MockServer) validateRequest(c *gin.Context, route string, part *spec.HTTPPart, definitions *spec.Definitions) bool {
+	opt := jsonschema.ValidateOption{
+		Mode:     jsonschema.ValidateRequest,
+		Resolver: definitions.SchemaResolver(),
+	}
+	paramErrs := validateMockParameters(c, route, part.Parameters, opt)
+	bodyErrs := validateMockBody(c, part.Content, opt)
+	if len(paramErrs) == 0 && len(bodyErrs) == 0 {
+		return true
+	}
+	slog.InfoCtx(c, "mock request validation failed",
+		slog.Int("parameters", len(paramErrs)),
+		slog.Int("body", len(bodyErrs)),
+	)
+	code := http.StatusBadRequest
+	if len(paramErrs) == 0 {
+		code = http.StatusUnprocessableEntity
+	}
+	c.AbortWithStatusJSON(code, gin.H{
+		"message": translator.Trasnlate(c, &translator.TT{ID: "Mock.RequestValidationFailed"}),
+		"errors":  append(paramErrs, bodyErrs...),
+	})
+	return false
+}
+
+func validateMockParameters(c *gin.Context, route string, params spec.HTTPParameters, opt jsonschema.ValidateOption) []*mockValidateError {
+	pathValues := mockPathValues(route, c.Param("path"))
+	errs := make([]*mockValidateError, 0)
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		for _, p := range params.Map()[in] {
+			if p == nil || p.Name == "" {
+				continue
+			}
+			var values []string
+			switch in {
+			case "path":
+				if v, ok := pathValues[p.Name]; ok {
+					values = []string{v}
+				}
+			case "query":
+				values = c.QueryArray(p.Name)
+			case "header":
+				values = c.Request.Header.Values(p.Name)
+			case "cookie":
+				if v, err := c.Cookie(p.Name); err == nil {
+					values = []string{v}
+				}
+			}
+			if len(values) == 0 {
+				if p.Required {
+					errs = append(errs, &mockValidateError{
+						In:      in,
+						Name:    p.Name,
+						Keyword: "required",
+						Message: "is required",
+					})
+				}
+				continue
+			}
+			if p.Schema == nil {
+				continue
+			}
+			if err := p.Schema.ValidateWithOption(jsonschema.CoerceStrings(values, p.Schema), opt); err != nil {
+				errs = append(errs, toMockValidateErrors(in, p.Name, err)...)
+			}
+		}
+	}
+	return errs
+}
+
+func validateMockBody(c *gin.Context, content spec.HTTPBody, opt jsonschema.ValidateOption) []*mockValidateError {
+	if len(content) == 0 {
+		return nil
+	}
+	contentType := c.ContentType()
+	var (
+		schema *jsonschema.Schema
+		isJSON = strings.Contains(contentType, "json")
+	)
+	for k, v := range content {
+		if k == contentType || (isJSON && strings.Contains(k, "json")) {
+			if v != nil {
+				schema = v.Schema
+			}
+			break
+		}
+	}
+
+	raw, _ := c.GetRawData()
+	// 重新写回body 后续处理可能还需要读取
+	c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+	// 请求体不是必须的时候允许为空
+	if len(bytes.TrimSpace(raw)) == 0 {
+		if content.Required() {
+			return []*mockValidateError{{
+				In:      "body",
+				Keyword: "required",
+				Message: "request body is required",
+			}}
+		}
+		return nil
+	}
+	if schema == nil {
+		if _, ok := content[contentType]; ok {
+			return nil
+		}
+		return []*mockValidateError{{
+			In:      "body",
+			Keyword: "contentType",
+			Message: "content type " + contentType + " is not documented",
+		}}
+	}
+	// 只校验json
+	if !isJSON {
+		return nil
+	}
+	if err := schema.ValidationWithOption(raw, opt); err != nil {
+		return toMockValidateErrors("body", "", err)
+	}
+	return nil
+}
+
+func schemaHasType(s *jsonschema.Schema, typ string) bool {
+	if s == nil || s.Type == nil {
+		return false
+	}
+	for _, v := range s.Type.Value() {
+		if v == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// mockPathValues 根据路由定义取出路径参数
+// route /users/{id} path /users/1 => {"id":"1"}
+func mockPathValues(route, path string) map[string]string {
+	values := make(map[string]string)
+	rp := strings.Split(route, "/")
+	p := strings.Split(path, "/")
+	if len(rp) != len(p) {
+		return values
+	}
+	for k, v := range rp {
+		if len(v) > 2 && v[0] == '{' && v[len(v)-1] == '}' {
+			values[v[1:len(v)-1]] = p[k]
+		}
+	}
+	return values
+}
+
+func toMockValidateErrors(in, name string, err error) []*mockValidateError {
+	verrs := jsonschema.AsValidationErrors(err)
+	list := make([]*mockValidateError, len(verrs))
+	for i, v := range verrs {
+		list[i] = &mockValidateError{
+			In:      in,
+			Name:    name,
+			Path:    v.Path,
+			Keyword: v.Keyword,
+			Message: v.Message,
+		}
+	}
+	return list
+}
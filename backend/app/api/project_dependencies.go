@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/apicat/apicat/backend/app/util"
+	"github.com/apicat/apicat/backend/common/spec/refs"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectDependenciesData struct {
+	Type     string `form:"type" binding:"omitempty,oneof=json dot"`
+	Download string `form:"download" binding:"omitempty,oneof=true false"`
+}
+
+// definitionUsages 返回定义本身和直接或间接引用了它的集合和公共定义
+func definitionUsages(ctx *gin.Context, kind string, id uint) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	g := refs.Build(models.ProjectExport(currentProject.(*models.Projects)))
+
+	usages := g.Usages(kind, int64(id))
+	if usages == nil {
+		usages = make([]*refs.Usage, 0)
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"definition": g.Lookup(kind, int64(id)),
+		"usages":     usages,
+	})
+}
+
+// DefinitionSchemasUsages 删除模型前查看引用了它的集合 公共响应和其它模型
+func DefinitionSchemasUsages(ctx *gin.Context) {
+	currentDefinitionSchema, _ := ctx.Get("CurrentDefinitionSchema")
+	definitionUsages(ctx, refs.KindSchema, currentDefinitionSchema.(*models.DefinitionSchemas).ID)
+}
+
+func DefinitionResponsesUsages(ctx *gin.Context) {
+	cr := DefinitionResponsesID{}
+	definitionResponses, err := cr.CheckDefinitionResponses(ctx)
+	if err != nil {
+		return
+	}
+	definitionUsages(ctx, refs.KindResponse, definitionResponses.ID)
+}
+
+// ProjectDependencies 导出项目中集合和公共定义的引用关系 type为json或dot 默认为json
+func ProjectDependencies(ctx *gin.Context) {
+	data := ProjectDependenciesData{}
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindQuery(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if data.Type == "" {
+		data.Type = "json"
+	}
+
+	currentProject, _ := ctx.Get("CurrentProject")
+	project := currentProject.(*models.Projects)
+	g := refs.Build(models.ProjectExport(project))
+
+	var content []byte
+	if data.Type == "dot" {
+		content = []byte(g.DOT(project.Title))
+	} else {
+		var err error
+		if content, err = json.Marshal(g); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Projects.ExportFail"}),
+			})
+			return
+		}
+	}
+	util.ExportResponse(data.Type, data.Download, project.Title+"-dependencies", content, ctx)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/apicat/apicat/backend/common/spec/diff"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectDiffData struct {
+	Data     string `json:"data" binding:"required"`
+	DataType string `json:"data_type" binding:"required,oneof=apicat swagger openapi postman"`
+}
+
+// ProjectDiff 比较上传的导出文件和项目当前的差异 上传的文件作为旧版本
+func ProjectDiff(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	var data ProjectDiffData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	source, err := specFileParse(data.DataType, data.Data)
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.ContentParsingFailed"}),
+		})
+		return
+	}
+
+	target := models.ProjectExport(currentProject.(*models.Projects))
+	ctx.JSON(http.StatusOK, diff.Compare(source, target))
+}
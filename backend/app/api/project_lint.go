@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/apicat/apicat/backend/common/spec/lint"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectLintRulesData struct {
+	Rules  map[string]string  `json:"rules"`
+	Custom []*lint.CustomRule `json:"custom"`
+}
+
+// lintRulesDetails 内置规则使用项目配置的严重程度
+func lintRulesDetails(cfg *lint.Config) gin.H {
+	rules := make([]gin.H, 0, len(lint.Rules()))
+	for _, v := range lint.Rules() {
+		rules = append(rules, gin.H{
+			"name":             v.Name,
+			"description":      v.Description,
+			"default_severity": v.Severity,
+			"severity":         cfg.Severity(v),
+		})
+	}
+	return gin.H{
+		"rules":  rules,
+		"custom": cfg.Custom,
+	}
+}
+
+// ProjectLint 按照项目的配置检查接口和公共模型
+func ProjectLint(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	project := currentProject.(*models.Projects)
+
+	ctx.JSON(http.StatusOK, lint.Lint(models.ProjectExport(project), project.GetLintConfig()))
+}
+
+func ProjectLintRules(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	ctx.JSON(http.StatusOK, lintRulesDetails(currentProject.(*models.Projects).GetLintConfig()))
+}
+
+// ProjectLintRulesUpdate 覆盖项目的规则配置 rules中没有的内置规则使用默认的严重程度
+func ProjectLintRulesUpdate(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data ProjectLintRulesData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	cfg := &lint.Config{Rules: data.Rules, Custom: data.Custom}
+	if cfg.Rules == nil {
+		cfg.Rules = make(map[string]string)
+	}
+	if cfg.Custom == nil {
+		cfg.Custom = make([]*lint.CustomRule, 0)
+	}
+	if err := cfg.Valid(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Projects.LintRulesInvalid"}) + ": " + err.Error(),
+		})
+		return
+	}
+
+	project := currentProject.(*models.Projects)
+	project.SetLintConfig(cfg)
+	if err := project.Save(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Projects.UpdateFail"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, lintRulesDetails(cfg))
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
 	"github.com/gin-gonic/gin"
@@ -191,6 +192,10 @@ func ProjectMembersCreate(ctx *gin.Context) {
 			continue
 		}
 
+		emitProjectWebhook(ctx, webhook.EventMemberAdded, "", &webhook.Entity{Type: "member", ID: user.ID, Title: user.Username}, nil, map[string]any{
+			"authority": pm.Authority,
+		})
+
 		result = append(result, gin.H{
 			"id":         pm.ID,
 			"user_id":    user.ID,
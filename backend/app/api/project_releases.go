@@ -0,0 +1,216 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/apicat/apicat/backend/app/util"
+	"github.com/apicat/apicat/backend/common/spec/diff"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectReleaseData struct {
+	Version string `json:"version" binding:"required,lte=255"`
+	Notes   string `json:"notes" binding:"lte=10000"`
+}
+
+type ProjectReleaseUriData struct {
+	ProjectID string `uri:"project-id" binding:"required"`
+	ReleaseID uint   `uri:"release-id" binding:"required,gt=0"`
+}
+
+type ExportProjectRelease struct {
+	Type     string `form:"type" binding:"required,oneof=apicat swagger openapi3.0.0 openapi3.0.1 openapi3.0.2 openapi3.1.0 postman HTML md sdk server asyncapi"`
+	Download string `form:"download" binding:"omitempty,oneof=true false"`
+}
+
+func projectReleaseDetails(release *models.ProjectReleases) gin.H {
+	return gin.H{
+		"id":         release.ID,
+		"version":    release.Version,
+		"notes":      release.Notes,
+		"created_by": release.Creator(),
+		"created_at": release.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// getProjectRelease 获取当前项目下的版本 不存在时直接响应404
+func getProjectRelease(ctx *gin.Context) (*models.ProjectReleases, bool) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	var uriData ProjectReleaseUriData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindUri(&uriData)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	release, err := models.NewProjectReleases(uriData.ReleaseID)
+	if err != nil || release.ProjectID != currentProject.(*models.Projects).ID {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"code":    enum.Display404ErrorMessage,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "ProjectReleases.NotFound"}),
+		})
+		return nil, false
+	}
+	return release, true
+}
+
+func ProjectReleasesList(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	release, _ := models.NewProjectReleases()
+	releases, err := release.List(currentProject.(*models.Projects).ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "ProjectReleases.QueryFailed"}),
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(releases))
+	for _, v := range releases {
+		list = append(list, projectReleaseDetails(v))
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// ProjectReleasesCreate 将项目当前的状态发布为一个版本
+func ProjectReleasesCreate(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	currentUser, _ := ctx.Get("CurrentUser")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data ProjectReleaseData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	project := currentProject.(*models.Projects)
+	release, _ := models.NewProjectReleases()
+	release.ProjectID = project.ID
+	release.Version = data.Version
+	release.Notes = data.Notes
+	release.CreatedBy = currentUser.(*models.Users).ID
+
+	if count, err := release.GetCountByVersion(); err != nil || count > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "ProjectReleases.VersionExists"}),
+		})
+		return
+	}
+
+	if err := release.Create(project); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "ProjectReleases.CreateFail"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, projectReleaseDetails(release))
+}
+
+// ProjectReleasesDataGet 按照导出类型下载版本快照
+func ProjectReleasesDataGet(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	var data ExportProjectRelease
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindQuery(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	release, ok := getProjectRelease(ctx)
+	if !ok {
+		return
+	}
+
+	apicatData, err := release.Spec()
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.ContentParsingFailed"}),
+		})
+		return
+	}
+
+	content, err := specEncode(data.Type, apicatData)
+	if err != nil {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Projects.ExportFail"}),
+		})
+		return
+	}
+
+	filename := currentProject.(*models.Projects).Title + "-" + release.Version + "-" + data.Type
+	util.ExportResponse(data.Type, data.Download, filename, content, ctx)
+}
+
+// ProjectReleasesDiff 比较版本快照和项目当前的差异 快照作为旧版本
+func ProjectReleasesDiff(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	release, ok := getProjectRelease(ctx)
+	if !ok {
+		return
+	}
+
+	source, err := release.Spec()
+	if err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.ContentParsingFailed"}),
+		})
+		return
+	}
+
+	target := models.ProjectExport(currentProject.(*models.Projects))
+	ctx.JSON(http.StatusOK, diff.Classify(diff.Compare(source, target)))
+}
+
+// ProjectReleasesRollback 将项目回滚到指定版本 快照中没有的接口和模型会移入回收站
+// 回滚后按差异通知变化的接口和模型
+func ProjectReleasesRollback(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	currentUser, _ := ctx.Get("CurrentUser")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	release, ok := getProjectRelease(ctx)
+	if !ok {
+		return
+	}
+
+	project := currentProject.(*models.Projects)
+	before := models.ProjectExport(project)
+	if err := release.Rollback(currentUser.(*models.Users).ID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "ProjectReleases.RollbackFail"}),
+		})
+		return
+	}
+
+	resetSearchIndex(project.ID)
+	emitReportWebhooks(ctx, webhookDiff(before, models.ProjectExport(project)), map[string]any{"release": release.Version})
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/apicat/apicat/backend/common/search"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectSearchData struct {
+	Q     string `form:"q" binding:"required,lte=255"`
+	Type  string `form:"type" binding:"omitempty,oneof=collection schema"`
+	Limit int    `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+// searchIndexes 项目id => *searchIndex 第一次检索时从数据库创建
+// 集合和公共模型修改后更新已经创建的索引 批量修改时删除索引 下次检索时重新创建
+var searchIndexes sync.Map
+
+// searchIndex 先放入searchIndexes再加锁创建 创建期间的修改等待创建完成后再写入索引
+type searchIndex struct {
+	mu  sync.Mutex
+	idx *search.Index
+}
+
+func projectSearchIndex(projectID uint) *search.Index {
+	v, _ := searchIndexes.LoadOrStore(projectID, &searchIndex{})
+	si := v.(*searchIndex)
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	if si.idx != nil {
+		return si.idx
+	}
+	idx := search.NewIndex()
+
+	c, _ := models.NewCollections()
+	c.ProjectId = projectID
+	if collections, err := c.List(); err == nil {
+		for _, v := range collections {
+			if v.Type != "category" {
+				idx.Put(search.CollectionDocument(v.ID, v.Type, v.Title, v.Content))
+			}
+		}
+	}
+
+	d, _ := models.NewDefinitionSchemas()
+	d.ProjectId = projectID
+	d.Type = "schema"
+	if definitions, err := d.List(); err == nil {
+		for _, v := range definitions {
+			idx.Put(search.SchemaDocument(v.ID, v.Name, v.Description, v.Schema))
+		}
+	}
+
+	si.idx = idx
+	return idx
+}
+
+// loadedSearchIndex 返回已经创建的索引 正在创建时等待创建完成
+func loadedSearchIndex(projectID uint) *search.Index {
+	v, ok := searchIndexes.Load(projectID)
+	if !ok {
+		return nil
+	}
+	si := v.(*searchIndex)
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	return si.idx
+}
+
+// updateSearchIndex 索引还没有创建时忽略
+func updateSearchIndex(projectID uint, docs ...*search.Document) {
+	if idx := loadedSearchIndex(projectID); idx != nil {
+		idx.Put(docs...)
+	}
+}
+
+func removeFromSearchIndex(projectID uint, typ string, ids ...uint) {
+	if idx := loadedSearchIndex(projectID); idx != nil {
+		idx.Remove(typ, ids...)
+	}
+}
+
+func resetSearchIndex(projectID uint) {
+	searchIndexes.Delete(projectID)
+}
+
+// ProjectSearch 检索集合的标题 路径 参数 文档内容和公共模型的属性
+func ProjectSearch(ctx *gin.Context) {
+	data := ProjectSearchData{}
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindQuery(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if data.Limit == 0 {
+		data.Limit = 20
+	}
+	var types []string
+	if data.Type != "" {
+		types = append(types, data.Type)
+	}
+
+	currentProject, _ := ctx.Get("CurrentProject")
+	ctx.JSON(http.StatusOK, projectSearchIndex(currentProject.(*models.Projects).ID).Search(data.Q, types, data.Limit))
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/apicat/apicat/backend/common/encrypt"
 	"github.com/apicat/apicat/backend/common/random"
 	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
 	"github.com/gin-gonic/gin"
@@ -141,6 +142,7 @@ func ProjectSharingSwitch(ctx *gin.Context) {
 				})
 				return
 			}
+			emitProjectWebhook(ctx, webhook.EventShareToggled, data.Share, &webhook.Entity{Type: "project", ID: project.ID, Title: project.Title}, nil, nil)
 		}
 
 		ctx.JSON(http.StatusCreated, gin.H{
@@ -157,6 +159,7 @@ func ProjectSharingSwitch(ctx *gin.Context) {
 			return
 		}
 
+		shared := project.SharePassword != ""
 		project.SharePassword = ""
 		if err := project.Save(); err != nil {
 			ctx.JSON(http.StatusBadRequest, gin.H{
@@ -164,6 +167,9 @@ func ProjectSharingSwitch(ctx *gin.Context) {
 			})
 			return
 		}
+		if shared {
+			emitProjectWebhook(ctx, webhook.EventShareToggled, data.Share, &webhook.Entity{Type: "project", ID: project.ID, Title: project.Title}, nil, nil)
+		}
 
 		ctx.Status(http.StatusCreated)
 	}
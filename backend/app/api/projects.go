@@ -3,15 +3,21 @@ package api
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/apicat/apicat/backend/app/util"
 	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/plugin/asyncapi"
+	"github.com/apicat/apicat/backend/common/spec/plugin/codegen"
 	"github.com/apicat/apicat/backend/common/spec/plugin/export"
+	"github.com/apicat/apicat/backend/common/spec/plugin/graphql"
+	"github.com/apicat/apicat/backend/common/spec/plugin/har"
 	"github.com/apicat/apicat/backend/common/spec/plugin/openapi"
 	"github.com/apicat/apicat/backend/common/spec/plugin/postman"
+	"github.com/apicat/apicat/backend/common/spec/plugin/protobuf"
 	"github.com/apicat/apicat/backend/common/translator"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
@@ -26,7 +32,7 @@ type CreateProject struct {
 	Data       string `json:"data"`
 	Cover      string `json:"cover" binding:"lte=255"`
 	Visibility string `json:"visibility" binding:"required,oneof=private public"`
-	DataType   string `json:"data_type" binding:"omitempty,oneof=apicat swagger openapi postman"`
+	DataType   string `json:"data_type" binding:"omitempty,oneof=apicat swagger openapi postman har graphql asyncapi protobuf"`
 	GroupID    uint   `json:"group_id" binding:"omitempty"`
 }
 
@@ -42,8 +48,9 @@ type ProjectID struct {
 }
 
 type ExportProject struct {
-	Type     string `form:"type" binding:"required,oneof=apicat swagger openapi3.0.0 openapi3.0.1 openapi3.0.2 openapi3.1.0 HTML md"`
-	Download string `form:"download" binding:"omitempty,oneof=true false"`
+	Type          string `form:"type" binding:"required,oneof=apicat swagger openapi3.0.0 openapi3.0.1 openapi3.0.2 openapi3.1.0 postman HTML md sdk server asyncapi"`
+	Download      string `form:"download" binding:"omitempty,oneof=true false"`
+	EnvironmentID uint   `form:"environment_id"`
 }
 
 type TranslateProject struct {
@@ -183,15 +190,16 @@ func ProjectsGet(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"id":          project.PublicId,
-		"title":       project.Title,
-		"description": project.Description,
-		"cover":       project.Cover,
-		"authority":   authority,
-		"visibility":  visibility,
-		"secret_key":  project.SharePassword,
-		"created_at":  project.CreatedAt.Format("2006-01-02 15:04:05"),
-		"updated_at":  project.UpdatedAt.Format("2006-01-02 15:04:05"),
+		"id":            project.PublicId,
+		"title":         project.Title,
+		"description":   project.Description,
+		"cover":         project.Cover,
+		"authority":     authority,
+		"visibility":    visibility,
+		"secret_key":    project.SharePassword,
+		"mock_stateful": project.MockStateful,
+		"created_at":    project.CreatedAt.Format("2006-01-02 15:04:05"),
+		"updated_at":    project.UpdatedAt.Format("2006-01-02 15:04:05"),
 	})
 }
 
@@ -238,22 +246,7 @@ func ProjectsCreate(ctx *gin.Context) {
 	project, _ := models.NewProjects()
 
 	if data.DataType != "" {
-		switch data.DataType {
-		case "apicat":
-			content, err = apicatFileParse(data.Data)
-		case "swagger":
-			content, err = openapiAndSwaggerFileParse(data.Data)
-		case "openapi":
-			content, err = openapiAndSwaggerFileParse(data.Data)
-		case "postman":
-			content, err = postmanFileParse(data.Data)
-		default:
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Projects.ImportFail"}),
-			})
-			return
-		}
-
+		content, err = specFileParse(data.DataType, data.Data)
 		if err != nil {
 			ctx.JSON(http.StatusUnprocessableEntity, gin.H{
 				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Projects.ImportFail"}),
@@ -295,6 +288,8 @@ func ProjectsCreate(ctx *gin.Context) {
 	// 进行数据导入工作
 	if data.Data != "" {
 		models.ServersImport(project.ID, content.Servers)
+		models.EnvironmentsImport(project.ID, content.Environments)
+		models.SecuritySchemesImport(project.ID, content.Definitions.Securities)
 
 		refContentVirtualIDToId := &models.RefContentVirtualIDToId{
 			DefinitionSchemas:    models.DefinitionSchemasImport(project.ID, content.Definitions.Schemas, user.ID),
@@ -316,6 +311,27 @@ func ProjectsCreate(ctx *gin.Context) {
 	})
 }
 
+// specFileParse 按照文件类型解析导入的文件
+func specFileParse(dataType, fileContent string) (*spec.Spec, error) {
+	switch dataType {
+	case "apicat":
+		return apicatFileParse(fileContent)
+	case "swagger", "openapi":
+		return openapiAndSwaggerFileParse(fileContent)
+	case "postman":
+		return postmanFileParse(fileContent)
+	case "har":
+		return harFileParse(fileContent)
+	case "graphql":
+		return graphqlFileParse(fileContent)
+	case "asyncapi":
+		return asyncapiFileParse(fileContent)
+	case "protobuf":
+		return protobufFileParse(fileContent)
+	}
+	return nil, fmt.Errorf("unsupported data type %s", dataType)
+}
+
 // openapi & swagger 导出文件解析
 func openapiAndSwaggerFileParse(fileContent string) (*spec.Spec, error) {
 	var (
@@ -378,6 +394,20 @@ func postmanFileParse(fileContent string) (*spec.Spec, error) {
 	return postman.Import(rawContent)
 }
 
+// har 文件解析 浏览器读取.har文件时的类型不固定
+func harFileParse(fileContent string) (*spec.Spec, error) {
+	if i := strings.Index(fileContent, ";base64,"); i >= 0 {
+		fileContent = fileContent[i+len(";base64,"):]
+	}
+
+	rawContent, err := base64.StdEncoding.DecodeString(fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	return har.Import(rawContent)
+}
+
 func ProjectsUpdate(ctx *gin.Context) {
 	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
 	if !currentProjectMember.(*models.ProjectMembers).MemberIsManage() {
@@ -521,46 +551,25 @@ func ProjectDataGet(ctx *gin.Context) {
 		return
 	}
 
-	apicatData := &spec.Spec{}
-	apicatData.ApiCat = "apicat"
-	apicatData.Info = &spec.Info{
-		ID:          project.PublicId,
-		Title:       project.Title,
-		Description: project.Description,
-		Version:     "1.0.0",
+	apicatData := models.ProjectExport(project)
+	if data.EnvironmentID > 0 {
+		env, ok := projectEnvironment(project.ID, data.EnvironmentID)
+		if !ok {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"code":    enum.Display404ErrorMessage,
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.NotFound"}),
+			})
+			return
+		}
+		// 文档中不展示私密变量的值
+		apicatData.ReplaceVariables(env.ToSpec(false).Map(false))
 	}
 
-	apicatData.Servers = models.ServersExport(project.ID)
-	apicatData.Globals.Parameters = models.GlobalParametersExport(project.ID)
-	apicatData.Definitions.Schemas = models.DefinitionSchemasExport(project.ID)
-	apicatData.Definitions.Parameters = models.DefinitionParametersExport(project.ID)
-	apicatData.Definitions.Responses = models.DefinitionResponsesExport(project.ID)
-	apicatData.Collections = models.CollectionsExport(project.ID)
-
 	if apicatDataContent, err := json.Marshal(apicatData); err == nil {
 		slog.InfoCtx(ctx, "Export", slog.String("apicat", string(apicatDataContent)))
 	}
 
-	switch data.Type {
-	case "swagger":
-		content, err = openapi.Encode(apicatData, "2.0")
-	case "openapi3.0.0":
-		content, err = openapi.Encode(apicatData, "3.0.0")
-	case "openapi3.0.1":
-		content, err = openapi.Encode(apicatData, "3.0.1")
-	case "openapi3.0.2":
-		content, err = openapi.Encode(apicatData, "3.0.2")
-	case "openapi3.1.0":
-		content, err = openapi.Encode(apicatData, "3.1.0")
-	case "HTML":
-		content, err = export.HTML(apicatData)
-	case "md":
-		content, err = export.Markdown(apicatData)
-	case "apicat":
-		content, err = apicatData.ToJSON(spec.JSONOption{Indent: "  "})
-	default:
-		content, err = apicatData.ToJSON(spec.JSONOption{Indent: "  "})
-	}
+	content, err = specEncode(data.Type, apicatData)
 
 	slog.InfoCtx(ctx, "Export", slog.String(data.Type, string(content)))
 
@@ -574,6 +583,35 @@ func ProjectDataGet(ctx *gin.Context) {
 	util.ExportResponse(data.Type, data.Download, project.Title+"-"+data.Type, content, ctx)
 }
 
+// specEncode 按照导出类型编码 apicat 结构
+func specEncode(dataType string, apicatData *spec.Spec) ([]byte, error) {
+	switch dataType {
+	case "swagger":
+		return openapi.Encode(apicatData, "2.0")
+	case "openapi3.0.0":
+		return openapi.Encode(apicatData, "3.0.0")
+	case "openapi3.0.1":
+		return openapi.Encode(apicatData, "3.0.1")
+	case "openapi3.0.2":
+		return openapi.Encode(apicatData, "3.0.2")
+	case "openapi3.1.0":
+		return openapi.Encode(apicatData, "3.1.0")
+	case "postman":
+		return postman.Encode(apicatData)
+	case "HTML":
+		return export.HTML(apicatData)
+	case "md":
+		return export.Markdown(apicatData)
+	case "sdk":
+		return codegen.ClientSDK(apicatData)
+	case "server":
+		return codegen.ServerStub(apicatData)
+	case "asyncapi":
+		return asyncapi.Encode(apicatData)
+	}
+	return apicatData.ToJSON(spec.JSONOption{Indent: "  "})
+}
+
 // ProjectExit handles the exit of a project member.
 func ProjectExit(ctx *gin.Context) {
 	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
@@ -707,3 +745,45 @@ func ProjectChangeGroup(ctx *gin.Context) {
 
 	ctx.Status(http.StatusCreated)
 }
+
+// graphql SDL或introspection结果的解析 .graphql文件的类型不固定
+func graphqlFileParse(fileContent string) (*spec.Spec, error) {
+	if i := strings.Index(fileContent, ";base64,"); i >= 0 {
+		fileContent = fileContent[i+len(";base64,"):]
+	}
+
+	rawContent, err := base64.StdEncoding.DecodeString(fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	return graphql.Import(rawContent)
+}
+
+// asyncapi 2.x的json或yaml文档
+func asyncapiFileParse(fileContent string) (*spec.Spec, error) {
+	if i := strings.Index(fileContent, ";base64,"); i >= 0 {
+		fileContent = fileContent[i+len(";base64,"):]
+	}
+
+	rawContent, err := base64.StdEncoding.DecodeString(fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	return asyncapi.Decode(rawContent)
+}
+
+// proto文件 import的其它文件不会被解析
+func protobufFileParse(fileContent string) (*spec.Spec, error) {
+	if i := strings.Index(fileContent, ";base64,"); i >= 0 {
+		fileContent = fileContent[i+len(";base64,"):]
+	}
+
+	rawContent, err := base64.StdEncoding.DecodeString(fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	return protobuf.Import(rawContent)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+)
+
+type SecuritySchemeData struct {
+	Name             string           `json:"name" binding:"required,lte=255"`
+	Type             string           `json:"type" binding:"required,oneof=apiKey http oauth2 openIdConnect"`
+	Description      string           `json:"description" binding:"lte=255"`
+	In               string           `json:"in" binding:"required_if=Type apiKey,omitempty,oneof=header query cookie"`
+	Key              string           `json:"key" binding:"required_if=Type apiKey,lte=255"`
+	Scheme           string           `json:"scheme" binding:"required_if=Type http,lte=255"`
+	BearerFormat     string           `json:"bearer_format" binding:"lte=255"`
+	Flows            *spec.OAuthFlows `json:"flows" binding:"required_if=Type oauth2"`
+	OpenIDConnectURL string           `json:"openid_connect_url" binding:"required_if=Type openIdConnect,omitempty,url"`
+}
+
+type SecuritySchemeUriData struct {
+	ProjectID        string `uri:"project-id" binding:"required"`
+	SecuritySchemeID uint   `uri:"security-scheme-id" binding:"required,gt=0"`
+}
+
+func (d *SecuritySchemeData) toSpec() *spec.SecurityScheme {
+	return &spec.SecurityScheme{
+		Name:             d.Name,
+		Type:             d.Type,
+		Description:      d.Description,
+		In:               d.In,
+		Key:              d.Key,
+		Scheme:           d.Scheme,
+		BearerFormat:     d.BearerFormat,
+		Flows:            d.Flows,
+		OpenIDConnectURL: d.OpenIDConnectURL,
+	}
+}
+
+func securitySchemeDetails(scheme *models.SecuritySchemes) gin.H {
+	s := scheme.ToSpec()
+	return gin.H{
+		"id":                 scheme.ID,
+		"name":               s.Name,
+		"type":               s.Type,
+		"description":        s.Description,
+		"in":                 s.In,
+		"key":                s.Key,
+		"scheme":             s.Scheme,
+		"bearer_format":      s.BearerFormat,
+		"flows":              s.Flows,
+		"openid_connect_url": s.OpenIDConnectURL,
+	}
+}
+
+// getSecurityScheme 获取当前项目下的认证方式 不存在时直接响应404
+func getSecurityScheme(ctx *gin.Context) (*models.SecuritySchemes, bool) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	var uriData SecuritySchemeUriData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindUri(&uriData)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	scheme, err := models.NewSecuritySchemes(uriData.SecuritySchemeID)
+	if err != nil || scheme.ProjectID != currentProject.(*models.Projects).ID {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"code":    enum.Display404ErrorMessage,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.NotFound"}),
+		})
+		return nil, false
+	}
+	return scheme, true
+}
+
+func SecuritySchemesList(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	scheme, _ := models.NewSecuritySchemes()
+	schemes, err := scheme.List(currentProject.(*models.Projects).ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.QueryFailed"}),
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(schemes))
+	for _, v := range schemes {
+		list = append(list, securitySchemeDetails(v))
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+func SecuritySchemesCreate(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data SecuritySchemeData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	scheme, _ := models.NewSecuritySchemes()
+	scheme.ProjectID = currentProject.(*models.Projects).ID
+	scheme.SetSpec(data.toSpec())
+	if count, err := scheme.GetCountByName(); err != nil || count > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.NameExists"}),
+		})
+		return
+	}
+
+	if err := scheme.Create(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.CreateFail"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, securitySchemeDetails(scheme))
+}
+
+// SecuritySchemesUpdate 修改名称时同步修改接口认证要求中引用的名称
+func SecuritySchemesUpdate(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data SecuritySchemeData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	scheme, ok := getSecurityScheme(ctx)
+	if !ok {
+		return
+	}
+
+	oldName := scheme.Name
+	scheme.SetSpec(data.toSpec())
+	if count, err := scheme.GetCountByName(); err != nil || count > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.NameExists"}),
+		})
+		return
+	}
+
+	var err error
+	if scheme.Name != oldName {
+		err = scheme.Rename(oldName)
+	} else {
+		err = scheme.Update()
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.UpdateFail"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, securitySchemeDetails(scheme))
+}
+
+func SecuritySchemesDelete(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	scheme, ok := getSecurityScheme(ctx)
+	if !ok {
+		return
+	}
+
+	// 删除后接口中的认证要求无法满足 需要先在接口中移除
+	if count, err := scheme.ReferencedCount(); err != nil || count > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.InUse"}),
+		})
+		return
+	}
+
+	if err := scheme.Delete(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "SecuritySchemes.DeleteFail"}),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/apicat/apicat/backend/common/runner"
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
+	"github.com/apicat/apicat/backend/config"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/exp/slices"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	testRunRunning = "running"
+	testRunPassed  = "passed"
+	testRunFailed  = "failed"
+)
+
+// 一次测试的最长执行时间 超时后剩余的接口直接失败
+const testRunTimeout = 10 * time.Minute
+
+type TestRunsCreateData struct {
+	ServerUrl     string `json:"server_url" binding:"required,lte=255"`
+	EnvironmentID uint   `json:"environment_id"`
+	CollectionIDs []uint `json:"collection_ids"`
+}
+
+type TestRunUriData struct {
+	ProjectID string `uri:"project-id" binding:"required"`
+	TestRunID uint   `uri:"run-id" binding:"required,gt=0"`
+}
+
+func testRunDetails(tr *models.TestRuns) gin.H {
+	return gin.H{
+		"id":         tr.ID,
+		"server_url": tr.ServerUrl,
+		"status":     tr.Status,
+		"total":      tr.Total,
+		"passed":     tr.Passed,
+		"failed":     tr.Failed,
+		"duration":   tr.Duration,
+		"created_at": tr.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func testRunResultList(results []*models.TestRunResults) []gin.H {
+	list := make([]gin.H, 0, len(results))
+	for _, v := range results {
+		failures := make([]*runner.Failure, 0)
+		if v.Failures != "" {
+			json.Unmarshal([]byte(v.Failures), &failures)
+		}
+		tests := make([]*runner.TestResult, 0)
+		if v.Tests != "" {
+			json.Unmarshal([]byte(v.Tests), &tests)
+		}
+		logs := make([]string, 0)
+		if v.Logs != "" {
+			json.Unmarshal([]byte(v.Logs), &logs)
+		}
+		list = append(list, gin.H{
+			"collection_id": v.CollectionID,
+			"title":         v.Title,
+			"path":          v.Path,
+			"method":        v.Method,
+			"url":           v.Url,
+			"status_code":   v.StatusCode,
+			"duration":      v.Duration,
+			"passed":        v.Passed,
+			"failures":      failures,
+			"tests":         tests,
+			"logs":          logs,
+		})
+	}
+	return list
+}
+
+// TestRunsCreate 按照文档请求指定的服务器 校验响应并保存测试结果
+// 测试在后台执行 先返回running状态的记录 通过查询接口获取结果
+func TestRunsCreate(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	currentUser, _ := ctx.Get("CurrentUser")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data TestRunsCreateData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	project := currentProject.(*models.Projects)
+	variables := map[string]string{}
+	if data.EnvironmentID > 0 {
+		env, ok := projectEnvironment(project.ID, data.EnvironmentID)
+		if !ok {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"code":    enum.Display404ErrorMessage,
+				"message": translator.Trasnlate(ctx, &translator.TT{ID: "Environments.NotFound"}),
+			})
+			return
+		}
+		variables = env.ToSpec(true).Map(true)
+	}
+	// 服务器地址可以使用环境变量 如 {{baseUrl}}
+	serverUrl := spec.ReplaceVariables(data.ServerUrl, variables)
+	if u, err := url.ParseRequestURI(serverUrl); err != nil || u.Host == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "TestRuns.InvalidServerUrl"}),
+		})
+		return
+	}
+
+	apicatData := models.ProjectExport(project)
+	paths := apicatData.CollectionsMap(true, 3)
+	if len(data.CollectionIDs) > 0 {
+		for path, methods := range paths {
+			for method, part := range methods {
+				if !slices.Contains(data.CollectionIDs, uint(part.ID)) {
+					delete(methods, method)
+				}
+			}
+			if len(methods) == 0 {
+				delete(paths, path)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "TestRuns.NoApis"}),
+		})
+		return
+	}
+
+	tr, _ := models.NewTestRuns()
+	tr.ProjectID = project.ID
+	tr.ServerUrl = serverUrl
+	tr.Status = testRunRunning
+	for _, methods := range paths {
+		tr.Total += len(methods)
+	}
+	tr.CreatedBy = currentUser.(*models.Users).ID
+	if err := tr.Create(); err != nil {
+		slog.ErrorCtx(ctx, "save test run failed", slog.String("err", err.Error()))
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "TestRuns.CreateFail"}),
+		})
+		return
+	}
+
+	r := runner.NewRunner(serverUrl, apicatData.Globals.Parameters, &apicatData.Definitions)
+	r.SetClient(testRunClient())
+	r.SetVariables(variables)
+	res := testRunDetails(tr)
+	res["results"] = []gin.H{}
+	go runTest(tr, r, paths)
+
+	ctx.JSON(http.StatusAccepted, res)
+}
+
+// runTest 在后台执行测试 结束后保存结果 可以通过查询接口获取进度
+func runTest(tr *models.TestRuns, r *runner.Runner, paths map[string]map[string]spec.HTTPPart) {
+	start := time.Now()
+	c, cancel := context.WithTimeout(context.Background(), testRunTimeout)
+	defer cancel()
+	report := r.Run(c, paths)
+
+	tr.Status = testRunStatus(report)
+	tr.Total = report.Total
+	tr.Passed = report.Passed
+	tr.Failed = report.Failed
+	tr.Duration = time.Since(start).Milliseconds()
+
+	results := make([]*models.TestRunResults, 0, len(report.Results))
+	for _, v := range report.Results {
+		var failures, tests, logs []byte
+		if len(v.Failures) > 0 {
+			failures, _ = json.Marshal(v.Failures)
+		}
+		if len(v.Tests) > 0 {
+			tests, _ = json.Marshal(v.Tests)
+		}
+		if len(v.Logs) > 0 {
+			logs, _ = json.Marshal(v.Logs)
+		}
+		results = append(results, &models.TestRunResults{
+			CollectionID: uint(v.CollectionID),
+			Title:        v.Title,
+			Path:         v.Path,
+			Method:       v.Method,
+			Url:          v.URL,
+			StatusCode:   v.StatusCode,
+			Duration:     v.Duration,
+			Passed:       v.Passed,
+			Failures:     string(failures),
+			Tests:        string(tests),
+			Logs:         string(logs),
+		})
+	}
+	if err := tr.Finish(results); err != nil {
+		slog.Error("save test run results failed", slog.Uint64("id", uint64(tr.ID)), slog.String("err", err.Error()))
+	}
+}
+
+// testRunClient 配置中test_run.allow_local不为true时拒绝请求内网 本机和链路本地地址
+// 在建立连接时检查 重定向和DNS重绑定同样会被拒绝 不使用环境变量中的代理
+func testRunClient() *http.Client {
+	allowLocal, _ := strconv.ParseBool(config.GetSysConfig().TestRun.AllowLocal.Value)
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         webhook.NewDialer(allowLocal).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+	}
+}
+
+func testRunStatus(report *runner.Report) string {
+	if report.Failed > 0 {
+		return testRunFailed
+	}
+	return testRunPassed
+}
+
+// failStaleTestRuns 服务重启时中断的测试不会再有结果 超过最长执行时间后标记为失败
+func failStaleTestRuns(projectID uint) {
+	tr, _ := models.NewTestRuns()
+	if err := tr.FailStale(projectID, time.Now().Add(-testRunTimeout-time.Minute), testRunRunning, testRunFailed); err != nil {
+		slog.Error("fail stale test runs failed", slog.String("err", err.Error()))
+	}
+}
+
+func TestRunsList(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	failStaleTestRuns(currentProject.(*models.Projects).ID)
+	tr, _ := models.NewTestRuns()
+	testRuns, err := tr.List(currentProject.(*models.Projects).ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "TestRuns.QueryFailed"}),
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(testRuns))
+	for _, v := range testRuns {
+		list = append(list, testRunDetails(v))
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// getTestRun 获取当前项目下的测试记录 不存在时直接响应404
+func getTestRun(ctx *gin.Context) (*models.TestRuns, bool) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	var uriData TestRunUriData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindUri(&uriData)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	failStaleTestRuns(currentProject.(*models.Projects).ID)
+	tr, err := models.NewTestRuns(uriData.TestRunID)
+	if err != nil || tr.ProjectID != currentProject.(*models.Projects).ID {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"code":    enum.Display404ErrorMessage,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "TestRuns.NotFound"}),
+		})
+		return nil, false
+	}
+	return tr, true
+}
+
+func TestRunsGet(ctx *gin.Context) {
+	tr, ok := getTestRun(ctx)
+	if !ok {
+		return
+	}
+
+	results, err := tr.Results()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "TestRuns.QueryFailed"}),
+		})
+		return
+	}
+
+	res := testRunDetails(tr)
+	res["results"] = testRunResultList(results)
+	ctx.JSON(http.StatusOK, res)
+}
+
+func TestRunsDelete(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	tr, ok := getTestRun(ctx)
+	if !ok {
+		return
+	}
+
+	if err := tr.Delete(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "TestRuns.DeleteFail"}),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
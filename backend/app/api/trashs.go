@@ -3,7 +3,9 @@ package api
 import (
 	"net/http"
 
+	"github.com/apicat/apicat/backend/common/search"
 	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
 	"github.com/apicat/apicat/backend/enum"
 	"github.com/apicat/apicat/backend/models"
 	"github.com/gin-gonic/gin"
@@ -84,7 +86,11 @@ func TrashsRecover(ctx *gin.Context) {
 			continue
 		}
 		collection.ParentId = trashsRecoverBody.Category
-		collection.Restore()
+		if err := collection.Restore(); err == nil && collection.Type != "category" {
+			updateSearchIndex(project.ID, search.CollectionDocument(collection.ID, collection.Type, collection.Title, collection.Content))
+			after, _ := collectionItemSpec(collection.ID, collection.Type, collection.Title, collection.Content)
+			emitCollectionWebhook(ctx, webhook.EventCollectionCreated, collection, nil, after)
+		}
 	}
 
 	if !allOK {
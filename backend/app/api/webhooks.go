@@ -0,0 +1,584 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apicat/apicat/backend/common/random"
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/diff"
+	"github.com/apicat/apicat/backend/common/translator"
+	"github.com/apicat/apicat/backend/common/webhook"
+	"github.com/apicat/apicat/backend/config"
+	"github.com/apicat/apicat/backend/enum"
+	"github.com/apicat/apicat/backend/models"
+	"github.com/gin-gonic/gin"
+	"github.com/lithammer/shortuuid/v4"
+)
+
+// 投递状态
+const (
+	webhookDeliveryPending = "pending"
+	webhookDeliverySuccess = "success"
+	webhookDeliveryFailed  = "failed"
+)
+
+const (
+	// webhookWorkers 同时发送的投递数量
+	webhookWorkers = 4
+	// webhookPollInterval 检查到期投递的间隔
+	webhookPollInterval = 5 * time.Second
+	// webhookPollLimit 每次最多领取的投递数量
+	webhookPollLimit = 100
+)
+
+var (
+	// webhookSender 默认拒绝内网和本机地址 启动时由InitWebhooks按照配置重新创建
+	webhookSender = webhook.NewSender(false)
+	// webhookJobs 已经领取的投递 由固定数量的worker发送
+	webhookJobs = make(chan *models.WebhookDeliveries)
+	// webhookWake 有新的投递时立即检查 不等待下一次轮询
+	webhookWake       = make(chan struct{}, 1)
+	webhookWorkerOnce sync.Once
+)
+
+// InitWebhooks 配置中webhook.allow_local为true时允许向内网和本机地址发送 仅用于测试
+// 启动发送投递的后台任务 上次退出时没有发送完成的投递会继续发送
+func InitWebhooks() {
+	allowLocal, _ := strconv.ParseBool(config.GetSysConfig().Webhook.AllowLocal.Value)
+	webhookSender = webhook.NewSender(allowLocal)
+	startWebhookWorkers()
+}
+
+type WebhookData struct {
+	Url string `json:"url" binding:"required,url,lte=1024"`
+	// Secret 为空时创建会自动生成 修改时为空或传入掩码表示不修改
+	Secret string `json:"secret" binding:"lte=255"`
+	// Unsigned 为true时不签名 清空Secret
+	Unsigned bool     `json:"unsigned"`
+	Events   []string `json:"events" binding:"required,min=1,dive,oneof=collection.created collection.updated collection.deleted schema.changed iteration.updated member.added share.toggled"`
+	Enabled  *bool    `json:"enabled"`
+}
+
+type WebhookUriData struct {
+	ProjectID string `uri:"project-id" binding:"required"`
+	WebhookID uint   `uri:"webhook-id" binding:"required,gt=0"`
+}
+
+type WebhookDeliveryUriData struct {
+	ProjectID  string `uri:"project-id" binding:"required"`
+	WebhookID  uint   `uri:"webhook-id" binding:"required,gt=0"`
+	DeliveryID uint   `uri:"delivery-id" binding:"required,gt=0"`
+}
+
+type WebhookDeliveriesData struct {
+	Limit int `form:"limit" binding:"omitempty,gte=1,lte=100"`
+}
+
+func webhookDetails(w *models.Webhooks) gin.H {
+	secret := ""
+	if w.Secret != "" {
+		secret = secretVariableMask
+	}
+	return gin.H{
+		"id":         w.ID,
+		"url":        w.Url,
+		"secret":     secret,
+		"events":     w.GetEvents(),
+		"enabled":    w.IsEnabled == 1,
+		"created_at": w.CreatedAt.Format("2006-01-02 15:04:05"),
+		"updated_at": w.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func webhookDeliveryDetails(wd *models.WebhookDeliveries, withPayload bool) gin.H {
+	details := gin.H{
+		"id":          wd.ID,
+		"delivery_id": wd.DeliveryID,
+		"event":       wd.Event,
+		"status":      wd.Status,
+		"status_code": wd.StatusCode,
+		"error":       wd.Error,
+		"attempts":    wd.Attempts,
+		"duration":    wd.Duration,
+		"created_at":  wd.CreatedAt.Format("2006-01-02 15:04:05"),
+		"updated_at":  wd.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if wd.NextAttemptAt != nil {
+		details["next_attempt_at"] = wd.NextAttemptAt.Format("2006-01-02 15:04:05")
+	}
+	if withPayload {
+		details["payload"] = wd.Payload
+		details["response"] = wd.Response
+	}
+	return details
+}
+
+// getWebhook 获取当前项目下的webhook 不存在时直接响应404
+func getWebhook(ctx *gin.Context) (*models.Webhooks, bool) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	var uriData WebhookUriData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindUri(&uriData)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return nil, false
+	}
+
+	w, err := models.NewWebhooks(uriData.WebhookID)
+	if err != nil || w.ProjectID != currentProject.(*models.Projects).ID {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"code":    enum.Display404ErrorMessage,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.NotFound"}),
+		})
+		return nil, false
+	}
+	return w, true
+}
+
+func WebhooksList(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+
+	w, _ := models.NewWebhooks()
+	webhooks, err := w.List(currentProject.(*models.Projects).ID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.QueryFailed"}),
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(webhooks))
+	for _, v := range webhooks {
+		list = append(list, webhookDetails(v))
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// WebhooksCreate 创建webhook 只在创建时返回签名密钥
+func WebhooksCreate(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data WebhookData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	w, _ := models.NewWebhooks()
+	w.ProjectID = currentProject.(*models.Projects).ID
+	w.Url = data.Url
+	w.Secret = data.Secret
+	if data.Unsigned {
+		w.Secret = ""
+	} else if w.Secret == "" {
+		w.Secret = random.GenerateRandomString(32)
+	}
+	w.SetEvents(data.Events)
+	w.IsEnabled = 1
+	if data.Enabled != nil && !*data.Enabled {
+		w.IsEnabled = 0
+	}
+	w.CreatedBy = currentProjectMember.(*models.ProjectMembers).UserID
+	if err := w.Create(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.CreateFail"}),
+		})
+		return
+	}
+
+	details := webhookDetails(w)
+	details["secret"] = w.Secret
+	ctx.JSON(http.StatusCreated, details)
+}
+
+func WebhooksUpdate(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	var data WebhookData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindJSON(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	w, ok := getWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	w.Url = data.Url
+	if data.Unsigned {
+		w.Secret = ""
+	} else if data.Secret != "" && data.Secret != secretVariableMask {
+		w.Secret = data.Secret
+	}
+	w.SetEvents(data.Events)
+	if data.Enabled != nil {
+		w.IsEnabled = 0
+		if *data.Enabled {
+			w.IsEnabled = 1
+		}
+	}
+	if err := w.Update(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.UpdateFail"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, webhookDetails(w))
+}
+
+func WebhooksDelete(ctx *gin.Context) {
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	w, ok := getWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	if err := w.Delete(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.DeleteFail"}),
+		})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// WebhooksPing 发送ping事件测试webhook是否可用 停用的webhook也会发送
+func WebhooksPing(ctx *gin.Context) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	currentProjectMember, _ := ctx.Get("CurrentProjectMember")
+	if !currentProjectMember.(*models.ProjectMembers).MemberHasWritePermission() {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"code":    enum.ProjectMemberInsufficientPermissionsCode,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Common.InsufficientPermissions"}),
+		})
+		return
+	}
+
+	w, ok := getWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	payload := newWebhookPayload(ctx, currentProject.(*models.Projects), webhook.EventPing, "")
+	wd, err := createWebhookDelivery(w, payload)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.DeliveryFail"}),
+		})
+		return
+	}
+	wakeWebhookWorkers()
+
+	ctx.JSON(http.StatusCreated, webhookDeliveryDetails(wd, true))
+}
+
+func WebhookDeliveriesList(ctx *gin.Context) {
+	var data WebhookDeliveriesData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindQuery(&data)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if data.Limit == 0 {
+		data.Limit = 20
+	}
+
+	w, ok := getWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	wd, _ := models.NewWebhookDeliveries()
+	deliveries, err := wd.List(w.ID, data.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.QueryFailed"}),
+		})
+		return
+	}
+
+	list := make([]gin.H, 0, len(deliveries))
+	for _, v := range deliveries {
+		list = append(list, webhookDeliveryDetails(v, false))
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// WebhookDeliveriesGet 投递记录详情 包含发送的内容和最后一次的响应
+func WebhookDeliveriesGet(ctx *gin.Context) {
+	w, ok := getWebhook(ctx)
+	if !ok {
+		return
+	}
+
+	var uriData WebhookDeliveryUriData
+	if err := translator.ValiadteTransErr(ctx, ctx.ShouldBindUri(&uriData)); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+
+	wd, err := models.NewWebhookDeliveries(uriData.DeliveryID)
+	if err != nil || wd.WebhookID != w.ID {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"code":    enum.Display404ErrorMessage,
+			"message": translator.Trasnlate(ctx, &translator.TT{ID: "Webhooks.DeliveryNotFound"}),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhookDeliveryDetails(wd, true))
+}
+
+func newWebhookPayload(ctx *gin.Context, project *models.Projects, event, action string) *webhook.Payload {
+	payload := &webhook.Payload{
+		Event:     event,
+		Action:    action,
+		Timestamp: time.Now().Unix(),
+		Project:   &webhook.Project{ID: project.PublicId, Title: project.Title},
+	}
+	if currentUser, ok := ctx.Get("CurrentUser"); ok {
+		if user, ok := currentUser.(*models.Users); ok {
+			payload.Actor = &webhook.Actor{ID: user.ID, Username: user.Username}
+		}
+	}
+	return payload
+}
+
+// createWebhookDelivery 保存待发送的投递记录 每次投递使用新的id 由后台任务发送
+func createWebhookDelivery(w *models.Webhooks, payload *webhook.Payload) (*models.WebhookDeliveries, error) {
+	p := *payload
+	p.ID = shortuuid.New()
+	body, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	wd, _ := models.NewWebhookDeliveries()
+	wd.WebhookID = w.ID
+	wd.DeliveryID = p.ID
+	wd.Event = p.Event
+	wd.Payload = string(body)
+	wd.Status = webhookDeliveryPending
+	wd.NextAttemptAt = &now
+	return wd, wd.Create()
+}
+
+// startWebhookWorkers 启动轮询到期投递的任务和发送投递的worker 只会启动一次
+func startWebhookWorkers() {
+	webhookWorkerOnce.Do(func() {
+		for i := 0; i < webhookWorkers; i++ {
+			go func() {
+				for wd := range webhookJobs {
+					sendWebhookDelivery(wd)
+				}
+			}()
+		}
+		go pollWebhookDeliveries()
+	})
+}
+
+func wakeWebhookWorkers() {
+	select {
+	case webhookWake <- struct{}{}:
+	default:
+	}
+}
+
+// pollWebhookDeliveries 领取到期的投递交给worker 数据库未连接时跳过
+func pollWebhookDeliveries() {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	resumed := false
+	for {
+		if status, _ := models.DBConnStatus(); status == 1 && models.Conn != nil {
+			wd, _ := models.NewWebhookDeliveries()
+			if !resumed {
+				resumed = wd.Resume(webhookDeliveryPending, time.Now()) == nil
+			}
+			if deliveries, err := wd.ListDue(webhookDeliveryPending, time.Now(), webhookPollLimit); err == nil {
+				for _, v := range deliveries {
+					if ok, err := v.Claim(); err == nil && ok {
+						webhookJobs <- v
+					}
+				}
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-webhookWake:
+		}
+	}
+}
+
+// sendWebhookDelivery 发送一次并更新投递记录 失败后需要重试时记录下次发送时间
+func sendWebhookDelivery(wd *models.WebhookDeliveries) {
+	w, err := models.NewWebhooks(wd.WebhookID)
+	if err != nil {
+		wd.Status = webhookDeliveryFailed
+		wd.Error = "webhook not found"
+		wd.Update()
+		return
+	}
+
+	a := webhookSender.Deliver(w.Url, w.Secret, wd.Event, wd.DeliveryID, []byte(wd.Payload), wd.Attempts+1)
+	wd.Attempts = a.Number
+	wd.StatusCode = a.StatusCode
+	wd.Response = a.Response
+	wd.Error = a.Error
+	wd.Duration = a.Duration.Milliseconds()
+	switch {
+	case a.Success:
+		wd.Status = webhookDeliverySuccess
+	case a.Retry:
+		next := time.Now().Add(webhookSender.Delay(a.Number))
+		wd.NextAttemptAt = &next
+	default:
+		wd.Status = webhookDeliveryFailed
+	}
+	wd.Update()
+}
+
+// emitWebhook 保存项目中启用并订阅了该事件的webhook的投递 由后台任务发送
+func emitWebhook(projectID uint, payload *webhook.Payload) {
+	go func() {
+		w, _ := models.NewWebhooks()
+		webhooks, err := w.ListByEvent(projectID, payload.Event)
+		if err != nil {
+			return
+		}
+		for _, v := range webhooks {
+			createWebhookDelivery(v, payload)
+		}
+		if len(webhooks) > 0 {
+			wakeWebhookWorkers()
+		}
+	}()
+}
+
+// webhookDiff 变化前后的差异 before或after为nil时表示创建或删除
+func webhookDiff(before, after *spec.Spec) *diff.Report {
+	if before == nil {
+		before = &spec.Spec{}
+	}
+	if after == nil {
+		after = &spec.Spec{}
+	}
+	return diff.Classify(diff.Compare(before, after))
+}
+
+// emitProjectWebhook 当前项目的事件
+func emitProjectWebhook(ctx *gin.Context, event, action string, entity *webhook.Entity, report *diff.Report, data map[string]any) {
+	currentProject, _ := ctx.Get("CurrentProject")
+	project := currentProject.(*models.Projects)
+
+	payload := newWebhookPayload(ctx, project, event, action)
+	payload.Entity = entity
+	payload.Diff = report
+	payload.Data = data
+	emitWebhook(project.ID, payload)
+}
+
+// emitCollectionWebhook 集合的创建 修改和删除 分类不会通知
+func emitCollectionWebhook(ctx *gin.Context, event string, collection *models.Collections, before, after *spec.Spec) {
+	if collection.Type == "category" {
+		return
+	}
+	entity := &webhook.Entity{Type: "collection", ID: collection.ID, Title: collection.Title}
+	emitProjectWebhook(ctx, event, "", entity, webhookDiff(before, after), nil)
+}
+
+// emitSchemaWebhook 模型的变化 action和差异中的added changed removed相同
+func emitSchemaWebhook(ctx *gin.Context, action string, definition *models.DefinitionSchemas, before, after *spec.Spec) {
+	if definition.Type == "category" {
+		return
+	}
+	entity := &webhook.Entity{Type: "schema", ID: definition.ID, Title: definition.Name}
+	emitProjectWebhook(ctx, webhook.EventSchemaChanged, action, entity, webhookDiff(before, after), nil)
+}
+
+// emitReportWebhooks 批量修改后按差异通知每个变化的接口和模型 例如回滚版本
+func emitReportWebhooks(ctx *gin.Context, report *diff.Report, data map[string]any) {
+	for _, v := range report.Collections {
+		event := webhook.EventCollectionUpdated
+		switch v.Action {
+		case diff.ActionAdded:
+			event = webhook.EventCollectionCreated
+		case diff.ActionRemoved:
+			event = webhook.EventCollectionDeleted
+		}
+		entity := &webhook.Entity{Type: "collection", ID: uint(v.ID), Title: v.Title}
+		emitProjectWebhook(ctx, event, "", entity, webhookItemReport(v, true), data)
+	}
+	for _, v := range report.Schemas {
+		entity := &webhook.Entity{Type: "schema", ID: uint(v.ID), Title: v.Title}
+		emitProjectWebhook(ctx, webhook.EventSchemaChanged, v.Action, entity, webhookItemReport(v, false), data)
+	}
+}
+
+// webhookItemReport 只包含一个接口或模型的差异
+func webhookItemReport(item *diff.ItemDiff, collection bool) *diff.Report {
+	r := &diff.Report{
+		Collections: []*diff.ItemDiff{},
+		Schemas:     []*diff.ItemDiff{},
+		Responses:   []*diff.ItemDiff{},
+		Parameters:  []*diff.ItemDiff{},
+	}
+	if collection {
+		r.Collections = append(r.Collections, item)
+	} else {
+		r.Schemas = append(r.Schemas, item)
+	}
+	switch item.Action {
+	case diff.ActionAdded:
+		r.Summary.Added = 1
+	case diff.ActionRemoved:
+		r.Summary.Removed = 1
+	default:
+		r.Summary.Changed = 1
+	}
+	if item.Level == diff.LevelBreaking {
+		r.Summary.Breaking = 1
+	}
+	return r
+}
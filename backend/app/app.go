@@ -1,6 +1,7 @@
 package app
 
 import (
+	"github.com/apicat/apicat/backend/app/api"
 	"github.com/apicat/apicat/backend/app/router"
 	"github.com/apicat/apicat/backend/config"
 	"github.com/apicat/apicat/frontend"
@@ -17,6 +18,7 @@ func Run() {
 	t, _ := template.ParseFS(frontend.FrontDist, "dist/templates/*.tmpl")
 	r.SetHTMLTemplate(t)
 
+	api.InitWebhooks()
 	router.InitApiRouter(r)
 	r.Run(config.GetSysConfig().App.Host.Value + ":" + config.GetSysConfig().App.Port.Value)
 }
@@ -107,6 +107,7 @@ func InitApiRouter(r *gin.Engine) {
 			{
 				collections.GET("", api.CollectionsList)
 				collections.GET("/:collection-id", middleware.CheckCollection(), api.CollectionsGet)
+				collections.GET("/:collection-id/snippets", middleware.CheckCollection(), api.CollectionsSnippets)
 			}
 
 			globalParameters := halfLogin.Group("/projects/:project-id/global/parameters")
@@ -181,6 +182,12 @@ func InitApiRouter(r *gin.Engine) {
 				projects.POST("/follow", api.ProjectFollow)
 				projects.DELETE("/follow", api.ProjectUnFollow)
 				projects.PUT("/change_group", api.ProjectChangeGroup)
+				projects.POST("/diff", api.ProjectDiff)
+				projects.GET("/lint", api.ProjectLint)
+				projects.GET("/lint/rules", api.ProjectLintRules)
+				projects.PUT("/lint/rules", api.ProjectLintRulesUpdate)
+				projects.GET("/search", api.ProjectSearch)
+				projects.GET("/dependencies", api.ProjectDependencies)
 			}
 
 			definitionSchemas := project.Group("/definition/schemas")
@@ -189,7 +196,9 @@ func InitApiRouter(r *gin.Engine) {
 				definitionSchemas.PUT("/:schemas-id", middleware.CheckDefinitionSchema(), api.DefinitionSchemasUpdate)
 				definitionSchemas.DELETE("/:schemas-id", middleware.CheckDefinitionSchema(), api.DefinitionSchemasDelete)
 				definitionSchemas.POST("/:schemas-id", middleware.CheckDefinitionSchema(), api.DefinitionSchemasCopy)
+				definitionSchemas.GET("/:schemas-id/usages", middleware.CheckDefinitionSchema(), api.DefinitionSchemasUsages)
 				definitionSchemas.PUT("/movement", api.DefinitionSchemasMove)
+				definitionSchemas.POST("/infer", api.DefinitionSchemasInfer)
 			}
 
 			servers := project.Group("/servers")
@@ -197,6 +206,31 @@ func InitApiRouter(r *gin.Engine) {
 				servers.PUT("", api.UrlSettings)
 			}
 
+			environments := project.Group("/environments")
+			{
+				environments.GET("", api.EnvironmentsList)
+				environments.POST("", api.EnvironmentsCreate)
+				environments.PUT("/:environment-id", api.EnvironmentsUpdate)
+				environments.DELETE("/:environment-id", api.EnvironmentsDelete)
+			}
+
+			securitySchemes := project.Group("/security_schemes")
+			{
+				securitySchemes.GET("", api.SecuritySchemesList)
+				securitySchemes.POST("", api.SecuritySchemesCreate)
+				securitySchemes.PUT("/:security-scheme-id", api.SecuritySchemesUpdate)
+				securitySchemes.DELETE("/:security-scheme-id", api.SecuritySchemesDelete)
+			}
+
+			releases := project.Group("/releases")
+			{
+				releases.GET("", api.ProjectReleasesList)
+				releases.POST("", api.ProjectReleasesCreate)
+				releases.GET("/:release-id/data", api.ProjectReleasesDataGet)
+				releases.GET("/:release-id/diff", api.ProjectReleasesDiff)
+				releases.PUT("/:release-id/rollback", api.ProjectReleasesRollback)
+			}
+
 			globalParameters := project.Group("/global/parameters")
 			{
 				globalParameters.POST("", api.GlobalParametersCreate)
@@ -209,11 +243,13 @@ func InitApiRouter(r *gin.Engine) {
 				definitionResponses.POST("", api.DefinitionResponsesCreate)
 				definitionResponses.PUT("/:response-id", api.DefinitionResponsesUpdate)
 				definitionResponses.DELETE("/:response-id", api.DefinitionResponsesDelete)
+				definitionResponses.GET("/:response-id/usages", api.DefinitionResponsesUsages)
 			}
 
 			collections := project.Group("/collections")
 			{
 				collections.POST("", api.CollectionsCreate)
+				collections.POST("/curl", api.CollectionsCurlImport)
 				collections.PUT("/:collection-id", middleware.CheckCollection(), api.CollectionsUpdate)
 				collections.POST("/:collection-id", middleware.CheckCollection(), api.CollectionsCopy)
 				collections.PUT("/movement", api.CollectionsMovement)
@@ -223,6 +259,31 @@ func InitApiRouter(r *gin.Engine) {
 				collections.PUT("/:collection-id/share/reset", middleware.CheckCollection(), api.DocShareReset)
 			}
 
+			mock := project.Group("/mock")
+			{
+				mock.PUT("/stateful/switch", mocksrv.StatefulSwitch)
+				mock.PUT("/stateful/reset", mocksrv.StatefulReset)
+			}
+
+			testRuns := project.Group("/test_runs")
+			{
+				testRuns.GET("", api.TestRunsList)
+				testRuns.POST("", api.TestRunsCreate)
+				testRuns.GET("/:run-id", api.TestRunsGet)
+				testRuns.DELETE("/:run-id", api.TestRunsDelete)
+			}
+
+			webhooks := project.Group("/webhooks")
+			{
+				webhooks.GET("", api.WebhooksList)
+				webhooks.POST("", api.WebhooksCreate)
+				webhooks.PUT("/:webhook-id", api.WebhooksUpdate)
+				webhooks.DELETE("/:webhook-id", api.WebhooksDelete)
+				webhooks.POST("/:webhook-id/ping", api.WebhooksPing)
+				webhooks.GET("/:webhook-id/deliveries", api.WebhookDeliveriesList)
+				webhooks.GET("/:webhook-id/deliveries/:delivery-id", api.WebhookDeliveriesGet)
+			}
+
 			trashs := project.Group("/trashs")
 			{
 				trashs.GET("", api.TrashsList)
@@ -251,6 +312,7 @@ func InitApiRouter(r *gin.Engine) {
 				collectionHistories.GET("", api.CollectionHistoryList)
 				collectionHistories.GET("/:history-id", api.CollectionHistoryDetails)
 				collectionHistories.GET("/diff", api.CollectionHistoryDiff)
+				collectionHistories.GET("/breaking", api.CollectionHistoryBreaking)
 				collectionHistories.PUT("/:history-id/restore", api.CollectionHistoryRestore)
 
 			}
@@ -261,6 +323,7 @@ func InitApiRouter(r *gin.Engine) {
 				definitionSchemaHistories.GET("", api.DefinitionSchemaHistoryList)
 				definitionSchemaHistories.GET("/:history-id", api.DefinitionSchemaHistoryDetails)
 				definitionSchemaHistories.GET("/diff", api.DefinitionSchemaHistoryDiff)
+				definitionSchemaHistories.GET("/breaking", api.DefinitionSchemaHistoryBreaking)
 				definitionSchemaHistories.PUT("/:history-id/restore", api.DefinitionSchemaHistoryRestore)
 			}
 		}
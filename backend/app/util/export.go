@@ -14,6 +14,10 @@ func ExportResponse(exportType, download, filename string, content []byte, ctx *
 			ctx.Header("Content-Disposition", "attachment; filename="+filename+".html")
 		case "md":
 			ctx.Header("Content-Disposition", "attachment; filename="+filename+".md")
+		case "sdk", "server":
+			ctx.Header("Content-Disposition", "attachment; filename="+filename+".zip")
+		case "dot":
+			ctx.Header("Content-Disposition", "attachment; filename="+filename+".dot")
 		default:
 			ctx.Header("Content-Disposition", "attachment; filename="+filename+".json")
 		}
@@ -24,6 +28,12 @@ func ExportResponse(exportType, download, filename string, content []byte, ctx *
 			ctx.Data(http.StatusOK, "text/html; charset=utf-8", content)
 		case "md":
 			ctx.Data(http.StatusOK, "text/markdown; charset=utf-8", content)
+		case "sdk", "server":
+			// zip文件无法直接预览 始终作为附件下载
+			ctx.Header("Content-Disposition", "attachment; filename="+filename+".zip")
+			ctx.Data(http.StatusOK, "application/zip", content)
+		case "dot":
+			ctx.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", content)
 		default:
 			ctx.Data(http.StatusOK, "application/json", content)
 		}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// checkResponse 校验状态码 响应头和body是否符合文档
+func (r *Runner) checkResponse(resp *http.Response, body []byte, responses spec.HTTPResponses) []*Failure {
+	var def *spec.HTTPResponse
+	for i := range responses {
+		if responses[i].Code == resp.StatusCode {
+			def = &responses[i]
+			break
+		}
+	}
+	if def == nil {
+		codes := make([]string, len(responses))
+		for i, v := range responses {
+			codes[i] = strconv.Itoa(v.Code)
+		}
+		return []*Failure{{
+			In:      "status",
+			Message: fmt.Sprintf("status code %d is not documented, expected one of [%s]", resp.StatusCode, strings.Join(codes, ",")),
+		}}
+	}
+
+	opt := jsonschema.ValidateOption{
+		Mode:     jsonschema.ValidateResponse,
+		Resolver: r.resolver,
+	}
+	failures := make([]*Failure, 0)
+	for _, h := range def.Header {
+		if h == nil || h.Name == "" {
+			continue
+		}
+		v := resp.Header.Get(h.Name)
+		if v == "" {
+			if h.Required {
+				failures = append(failures, &Failure{In: "header", Name: h.Name, Message: "is required"})
+			}
+			continue
+		}
+		if h.Schema == nil {
+			continue
+		}
+		if err := h.Schema.ValidateWithOption(jsonschema.CoerceString(v, h.Schema), opt); err != nil {
+			failures = append(failures, toFailures("header", h.Name, err)...)
+		}
+	}
+
+	if len(def.Content) == 0 {
+		return failures
+	}
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var schema *jsonschema.Schema
+	matched := false
+	for k, v := range def.Content {
+		if k == contentType || (strings.Contains(k, "json") && strings.Contains(contentType, "json")) {
+			matched = true
+			if v != nil {
+				schema = v.Schema
+			}
+			break
+		}
+	}
+	if !matched {
+		return append(failures, &Failure{
+			In:      "body",
+			Message: fmt.Sprintf("content type %q is not documented", contentType),
+		})
+	}
+	if schema != nil && strings.Contains(contentType, "json") {
+		if err := schema.ValidationWithOption(body, opt); err != nil {
+			failures = append(failures, toFailures("body", "", err)...)
+		}
+	}
+	return failures
+}
+
+func toFailures(in, name string, err error) []*Failure {
+	errs := jsonschema.AsValidationErrors(err)
+	list := make([]*Failure, len(errs))
+	for i, v := range errs {
+		list[i] = &Failure{In: in, Name: name, Path: v.Path, Message: v.Message}
+	}
+	return list
+}
@@ -0,0 +1,364 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/datagen"
+)
+
+// 读取响应内容的最大长度 超过时不再校验body
+const maxResponseSize = 10 << 20
+
+// Runner 按照接口文档构造请求发送到指定的服务器 并校验响应是否符合文档
+type Runner struct {
+	baseURL   string
+	client    *http.Client
+	globals   spec.HTTPParameters
+	resolver  func(string) *jsonschema.Schema
+	variables Variables
+}
+
+// Failure 单个不符合文档的地方
+type Failure struct {
+	In      string `json:"in"`
+	Name    string `json:"name,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result 单个接口的测试结果
+type Result struct {
+	CollectionID int64         `json:"collection_id"`
+	Title        string        `json:"title"`
+	Path         string        `json:"path"`
+	Method       string        `json:"method"`
+	URL          string        `json:"url"`
+	StatusCode   int           `json:"status_code"`
+	Duration     int64         `json:"duration"`
+	Passed       bool          `json:"passed"`
+	Failures     []*Failure    `json:"failures,omitempty"`
+	Tests        []*TestResult `json:"tests,omitempty"`
+	Logs         []string      `json:"logs,omitempty"`
+}
+
+// Report 一次测试的结果汇总
+type Report struct {
+	Total   int       `json:"total"`
+	Passed  int       `json:"passed"`
+	Failed  int       `json:"failed"`
+	Results []*Result `json:"results"`
+}
+
+// NewRunner 创建测试执行器
+// baseURL 为被测试服务器的地址 definitions 用于解析未展开的引用
+func NewRunner(baseURL string, globals spec.HTTPParameters, definitions *spec.Definitions) *Runner {
+	return &Runner{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		client:    &http.Client{Timeout: 30 * time.Second},
+		globals:   globals,
+		resolver:  definitions.SchemaResolver(),
+		variables: make(Variables),
+	}
+}
+
+// SetClient 设置发送请求的http client
+func (r *Runner) SetClient(c *http.Client) {
+	r.client = c
+}
+
+// SetVariables 设置初始变量 脚本中修改的变量在整个测试过程中共享
+func (r *Runner) SetVariables(vars map[string]string) {
+	for k, v := range vars {
+		r.variables[k] = v
+	}
+}
+
+// Variables 返回当前的变量
+func (r *Runner) Variables() Variables {
+	return r.variables
+}
+
+// Run 依次测试所有接口 按照路径和方法排序
+// paths 为 spec.CollectionsMap 的返回值 引用需要先展开
+func (r *Runner) Run(ctx context.Context, paths map[string]map[string]spec.HTTPPart) *Report {
+	type route struct{ path, method string }
+	list := make([]route, 0)
+	for path, methods := range paths {
+		for method := range methods {
+			list = append(list, route{path, method})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].path == list[j].path {
+			return list[i].method < list[j].method
+		}
+		return list[i].path < list[j].path
+	})
+
+	report := &Report{Results: make([]*Result, 0, len(list))}
+	for _, v := range list {
+		res := r.RunPart(ctx, v.path, v.method, paths[v.path][v.method])
+		report.Total++
+		if res.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+// RunPart 测试单个接口
+// 请求前脚本可以修改请求 响应后脚本的断言失败会作为失败原因
+func (r *Runner) RunPart(ctx context.Context, path, method string, part spec.HTTPPart) *Result {
+	res := &Result{
+		CollectionID: part.ID,
+		Title:        part.Title,
+		Path:         path,
+		Method:       strings.ToUpper(method),
+	}
+	fail := func(in string, err error) *Result {
+		res.Failures = append(res.Failures, &Failure{In: in, Message: err.Error()})
+		return res
+	}
+
+	sreq, err := r.buildRequest(path, method, part)
+	if err != nil {
+		return fail("request", err)
+	}
+	if part.Script.PreRequest != "" {
+		sr, err := runScript(part.Script.PreRequest, r.variables, sreq, nil)
+		res.addScriptResult(sr)
+		if err != nil {
+			return fail("preRequest", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, sreq.Method, sreq.URL, bytes.NewReader(sreq.Body))
+	if err != nil {
+		return fail("request", err)
+	}
+	req.Header = sreq.Header
+	res.URL = req.URL.String()
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	res.Duration = time.Since(start).Milliseconds()
+	if err != nil {
+		return fail("request", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return fail("body", err)
+	}
+	if len(body) > maxResponseSize {
+		res.StatusCode = resp.StatusCode
+		return fail("body", fmt.Errorf("response body is larger than %d bytes", maxResponseSize))
+	}
+
+	res.StatusCode = resp.StatusCode
+	res.Failures = append(res.Failures, r.checkResponse(resp, body, part.Responses)...)
+	if part.Script.PostResponse != "" {
+		sr, err := runScript(part.Script.PostResponse, r.variables, sreq, &scriptResponse{
+			Status: resp.StatusCode,
+			Header: resp.Header,
+			Body:   body,
+		})
+		res.addScriptResult(sr)
+		if err != nil {
+			fail("postResponse", err)
+		}
+	}
+	res.Passed = len(res.Failures) == 0
+	return res
+}
+
+func (res *Result) addScriptResult(sr *ScriptResult) {
+	if sr == nil {
+		return
+	}
+	res.Logs = append(res.Logs, sr.Logs...)
+	res.Tests = append(res.Tests, sr.Tests...)
+	for _, t := range sr.Tests {
+		if !t.Passed {
+			res.Failures = append(res.Failures, &Failure{In: "test", Name: t.Name, Message: t.Message})
+		}
+	}
+}
+
+// buildRequest 使用参数的示例值或者mock数据构造请求 并替换其中的变量
+func (r *Runner) buildRequest(path, method string, part spec.HTTPPart) (*scriptRequest, error) {
+	params := r.parameters(part)
+
+	for _, p := range params.Path {
+		path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(r.paramString(exampleValue(p))))
+	}
+	query := url.Values{}
+	for _, p := range params.Query {
+		if v, ok := paramValue(p); ok {
+			query.Set(p.Name, r.paramString(v))
+		}
+	}
+	u := r.variables.Replace(r.baseURL) + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req := &scriptRequest{
+		Method: strings.ToUpper(method),
+		URL:    u,
+		Header: make(http.Header),
+	}
+	contentType, body, err := requestBody(part.Content)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+		req.Body = []byte(r.variables.Replace(string(body)))
+	}
+	for _, p := range params.Header {
+		if v, ok := paramValue(p); ok {
+			req.Header.Set(p.Name, r.paramString(v))
+		}
+	}
+	for _, p := range params.Cookie {
+		if v, ok := paramValue(p); ok {
+			c := &http.Cookie{Name: p.Name, Value: r.paramString(v)}
+			req.Header.Add("Cookie", c.String())
+		}
+	}
+	return req, nil
+}
+
+// parameters 合并全局参数和接口参数
+func (r *Runner) parameters(part spec.HTTPPart) spec.HTTPParameters {
+	skips := make(map[string]bool)
+	for k, v := range part.GlobalExcepts {
+		for _, x := range v {
+			skips[fmt.Sprintf("%s|_%d", k, x)] = true
+		}
+	}
+	params := spec.HTTPParameters{}
+	params.Fill()
+	for in, ps := range part.Parameters.Map() {
+		for _, v := range ps {
+			params.Add(in, v)
+		}
+	}
+	for in, ps := range r.globals.Map() {
+		for _, v := range ps {
+			if !skips[fmt.Sprintf("%s|_%d", in, v.ID)] {
+				params.Add(in, v)
+			}
+		}
+	}
+	return params
+}
+
+func requestBody(content spec.HTTPBody) (string, []byte, error) {
+	for _, k := range sortedKeys(content) {
+		v := content[k]
+		switch {
+		case strings.Contains(k, "json"):
+			b, err := json.Marshal(exampleValue(v))
+			return k, b, err
+		case k == "application/x-www-form-urlencoded":
+			form := url.Values{}
+			if m, ok := exampleValue(v).(map[string]any); ok {
+				for name, x := range m {
+					form.Set(name, paramString(x))
+				}
+			}
+			return k, []byte(form.Encode()), nil
+		}
+	}
+	return "", nil, nil
+}
+
+// paramValue 必填或者有示例值的参数才会发送
+func paramValue(p *spec.Schema) (any, bool) {
+	if p == nil || p.Name == "" {
+		return nil, false
+	}
+	if !p.Required && p.Example == nil && (p.Schema == nil || (p.Schema.Example == nil && p.Schema.Default == nil)) {
+		return nil, false
+	}
+	return exampleValue(p), true
+}
+
+// exampleValue 依次使用示例 默认值 枚举的第一个值 都没有时使用datagen生成
+func exampleValue(p *spec.Schema) any {
+	if p == nil {
+		return nil
+	}
+	if p.Example != nil {
+		return p.Example
+	}
+	s := p.Schema
+	if s == nil {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	}
+	b, _ := json.Marshal(s.Flatten())
+	v, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"})
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// paramString 转为字符串并替换变量
+func (r *Runner) paramString(v any) string {
+	return r.variables.Replace(paramString(v))
+}
+
+func paramString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []any:
+		list := make([]string, len(x))
+		for i, item := range x {
+			list[i] = paramString(item)
+		}
+		return strings.Join(list, ",")
+	case map[string]any:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+func TestRun(t *testing.T) {
+	raw, err := os.ReadFile("./testdata/spec.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := spec.ParseJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/1", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Rate-Limit", "10")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"name":"tom"}`))
+	})
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			// 缺少id
+			json.NewEncoder(w).Encode(map[string]any{"name": body["name"]})
+			return
+		}
+		// 文档中没有500
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := NewRunner(srv.URL, s.Globals.Parameters, &s.Definitions)
+	report := r.Run(context.Background(), s.CollectionsMap(true, 2))
+	if report.Total != 3 || report.Passed != 1 || report.Failed != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	results := map[string]*Result{}
+	for _, v := range report.Results {
+		results[v.Method+" "+v.Path] = v
+	}
+	if v := results["GET /users/{id}"]; !v.Passed || v.URL != srv.URL+"/users/1" {
+		t.Errorf("get user: %+v", v)
+	}
+	if v := results["POST /users"]; v.Passed || len(v.Failures) != 1 || v.Failures[0].Path != "/id" {
+		t.Errorf("create user: %+v", v)
+	}
+	if v := results["GET /users"]; v.Passed || v.StatusCode != 500 || v.Failures[0].In != "status" {
+		t.Errorf("list users: %+v", v)
+	}
+}
+
+func TestRunLargeBody(t *testing.T) {
+	raw, err := os.ReadFile("./testdata/spec.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := spec.ParseJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(make([]byte, maxResponseSize+1))
+	}))
+	defer srv.Close()
+
+	r := NewRunner(srv.URL, s.Globals.Parameters, &s.Definitions)
+	paths := s.CollectionsMap(true, 2)
+	res := r.RunPart(context.Background(), "/users", "get", paths["/users"]["get"])
+	if res.Passed || len(res.Failures) != 1 || res.Failures[0].In != "body" || res.StatusCode != 200 {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
@@ -0,0 +1,252 @@
+package runner
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// 脚本最长执行时间
+const scriptTimeout = 5 * time.Second
+
+// TestResult 脚本中 apicat.test 的执行结果
+type TestResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// ScriptResult 脚本执行的结果
+type ScriptResult struct {
+	Tests []*TestResult `json:"tests,omitempty"`
+	Logs  []string      `json:"logs,omitempty"`
+}
+
+// scriptRequest 脚本中可以修改的请求
+type scriptRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// scriptResponse 脚本中只读的响应
+type scriptResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// runScript 在沙箱中执行脚本
+// 脚本中可以使用apicat对象读写变量 修改请求 读取响应和断言
+// req 为发送前的请求 脚本对请求的修改会写回 res 为nil时表示请求前脚本
+func runScript(src string, vars Variables, req *scriptRequest, res *scriptResponse) (*ScriptResult, error) {
+	result := &ScriptResult{}
+	vm := goja.New()
+
+	timer := time.AfterFunc(scriptTimeout, func() {
+		vm.Interrupt("script execution timeout")
+	})
+	defer timer.Stop()
+
+	apicat := vm.NewObject()
+	console := vm.NewObject()
+	console.Set("log", func(call goja.FunctionCall) goja.Value {
+		args := make([]string, len(call.Arguments))
+		for i, v := range call.Arguments {
+			args[i] = scriptString(v)
+		}
+		result.Logs = append(result.Logs, strings.Join(args, " "))
+		return goja.Undefined()
+	})
+	vm.Set("console", console)
+
+	variables := vm.NewObject()
+	variables.Set("get", func(name string) goja.Value {
+		if v, ok := vars[name]; ok {
+			return vm.ToValue(v)
+		}
+		return goja.Undefined()
+	})
+	variables.Set("set", func(name string, v goja.Value) {
+		vars[name] = scriptString(v)
+	})
+	variables.Set("unset", func(name string) {
+		delete(vars, name)
+	})
+	variables.Set("has", func(name string) bool {
+		_, ok := vars[name]
+		return ok
+	})
+	apicat.Set("variables", variables)
+
+	request := map[string]any{
+		"method":  req.Method,
+		"url":     req.URL,
+		"headers": scriptHeaders(req.Header),
+		"body":    string(req.Body),
+	}
+	apicat.Set("request", request)
+
+	if res != nil {
+		response := vm.NewObject()
+		response.Set("status", res.Status)
+		response.Set("headers", scriptHeaders(res.Header))
+		response.Set("body", string(res.Body))
+		response.Set("json", func() goja.Value {
+			var v any
+			if err := json.Unmarshal(res.Body, &v); err != nil {
+				panic(vm.NewGoError(err))
+			}
+			return vm.ToValue(v)
+		})
+		apicat.Set("response", response)
+	}
+
+	apicat.Set("test", func(name string, fn goja.Callable) {
+		t := &TestResult{Name: name, Passed: true}
+		if _, err := fn(goja.Undefined()); err != nil {
+			t.Passed = false
+			t.Message = scriptErrorMessage(err)
+		}
+		result.Tests = append(result.Tests, t)
+	})
+	apicat.Set("assert", func(v goja.Value, msg string) {
+		if !v.ToBoolean() {
+			if msg == "" {
+				msg = "assertion failed"
+			}
+			panic(vm.NewGoError(errors.New(msg)))
+		}
+	})
+	apicat.Set("crypto", scriptCrypto(vm))
+	vm.Set("apicat", apicat)
+
+	if _, err := vm.RunString(src); err != nil {
+		return result, errors.New(scriptErrorMessage(err))
+	}
+
+	// 写回请求的修改
+	req.Method = strings.ToUpper(fmt.Sprint(request["method"]))
+	req.URL = fmt.Sprint(request["url"])
+	req.Body = scriptBody(request["body"])
+	req.Header = make(http.Header)
+	if h, ok := request["headers"].(map[string]any); ok {
+		for k, v := range h {
+			if list, ok := v.([]any); ok {
+				for _, item := range list {
+					req.Header.Add(k, fmt.Sprint(item))
+				}
+				continue
+			}
+			req.Header.Set(k, fmt.Sprint(v))
+		}
+	}
+	return result, nil
+}
+
+// scriptHeaders 只有一个值的头为字符串 多个值时为数组 例如Set-Cookie
+func scriptHeaders(h http.Header) map[string]any {
+	headers := make(map[string]any, len(h))
+	for k, v := range h {
+		if len(v) == 1 {
+			headers[k] = v[0]
+			continue
+		}
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i] = item
+		}
+		headers[k] = list
+	}
+	return headers
+}
+
+// scriptBody 脚本中设置的对象和数组转为json
+func scriptBody(v any) []byte {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []byte(x)
+	case []byte:
+		return x
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprint(v))
+	}
+	return b
+}
+
+// scriptCrypto 常用的签名方法 结果为hex字符串
+func scriptCrypto(vm *goja.Runtime) *goja.Object {
+	hashes := map[string]func() hash.Hash{
+		"md5":    md5.New,
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+	}
+	c := vm.NewObject()
+	for name, h := range hashes {
+		h := h
+		c.Set(name, func(data string) string {
+			x := h()
+			x.Write([]byte(data))
+			return hex.EncodeToString(x.Sum(nil))
+		})
+		c.Set("hmac"+strings.ToUpper(name), func(key, data string) string {
+			x := hmac.New(h, []byte(key))
+			x.Write([]byte(data))
+			return hex.EncodeToString(x.Sum(nil))
+		})
+	}
+	c.Set("base64Encode", func(data string) string {
+		return base64.StdEncoding.EncodeToString([]byte(data))
+	})
+	c.Set("base64Decode", func(data string) string {
+		b, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			panic(vm.NewGoError(err))
+		}
+		return string(b)
+	})
+	return c
+}
+
+// scriptString 对象和数组转为json 其他转为字符串
+func scriptString(v goja.Value) string {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return ""
+	}
+	if obj, ok := v.(*goja.Object); ok {
+		if b, err := obj.MarshalJSON(); err == nil {
+			return string(b)
+		}
+	}
+	return v.String()
+}
+
+func scriptErrorMessage(err error) string {
+	var ex *goja.Exception
+	if errors.As(err, &ex) {
+		if obj, ok := ex.Value().(*goja.Object); ok {
+			if msg := obj.Get("message"); msg != nil && !goja.IsUndefined(msg) {
+				return msg.String()
+			}
+		}
+		return ex.Value().String()
+	}
+	return err.Error()
+}
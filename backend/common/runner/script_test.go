@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+func TestRunScript(t *testing.T) {
+	vars := Variables{"name": "tom"}
+	req := &scriptRequest{
+		Method: "GET",
+		URL:    "http://localhost/users",
+		Header: http.Header{"Accept": {"application/json"}},
+	}
+	src := `
+		apicat.request.headers['X-Name'] = apicat.variables.get('name')
+		apicat.request.url += '?page=2'
+		apicat.variables.set('count', 1)
+		apicat.variables.unset('name')
+		console.log('sign', apicat.crypto.md5('abc'))
+	`
+	res, err := runScript(src, vars, req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.Get("X-Name") != "tom" || req.Header.Get("Accept") != "application/json" {
+		t.Errorf("unexpected header %v", req.Header)
+	}
+	if req.URL != "http://localhost/users?page=2" {
+		t.Errorf("unexpected url %s", req.URL)
+	}
+	if _, ok := vars["name"]; ok || vars["count"] != "1" {
+		t.Errorf("unexpected variables %v", vars)
+	}
+	if len(res.Logs) != 1 || res.Logs[0] != "sign 900150983cd24fb0d6963f7d28e17f72" {
+		t.Errorf("unexpected logs %v", res.Logs)
+	}
+
+	res, err = runScript(`
+		apicat.test('status', function () { apicat.assert(apicat.response.status === 201, 'expected 201') })
+		apicat.test('body', function () { apicat.assert(apicat.response.json().id === 1) })
+	`, vars, req, &scriptResponse{Status: 200, Body: []byte(`{"id":1}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tests) != 2 || res.Tests[0].Passed || res.Tests[0].Message != "expected 201" || !res.Tests[1].Passed {
+		t.Errorf("unexpected tests %+v %+v", res.Tests[0], res.Tests[1])
+	}
+
+	req.Header.Add("Cookie", "a=1")
+	req.Header.Add("Cookie", "b=2")
+	if _, err := runScript(`
+		apicat.request.body = {name: apicat.request.headers['Cookie'].join(';')}
+		apicat.request.headers['X-Tags'] = ['a', 'b']
+		apicat.variables.set('cookie', apicat.response.headers['Set-Cookie'].length)
+	`, vars, req, &scriptResponse{Status: 200, Header: http.Header{"Set-Cookie": {"a=1", "b=2"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if string(req.Body) != `{"name":"a=1;b=2"}` || len(req.Header.Values("X-Tags")) != 2 || len(req.Header.Values("Cookie")) != 2 {
+		t.Errorf("unexpected request %s %v", req.Body, req.Header)
+	}
+	if vars["cookie"] != "2" {
+		t.Errorf("unexpected response headers %v", vars)
+	}
+
+	if _, err := runScript(`apicat.unknown()`, vars, req, nil); err == nil {
+		t.Error("expected script error")
+	}
+}
+
+func TestRunScriptTimeout(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	_, err := runScript(`while (true) {}`, Variables{}, &scriptRequest{Header: http.Header{}}, nil)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+}
+
+func TestRunWithScript(t *testing.T) {
+	raw, err := os.ReadFile("./testdata/script.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := spec.ParseJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"token": "t-" + body["user"]})
+	})
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		mac := hmac.New(sha256.New, []byte("key"))
+		mac.Write([]byte("GET " + srv.URL + "/users"))
+		if r.Header.Get("Authorization") != "Bearer t-admin" || r.Header.Get("X-Sign") != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":1}]`))
+	})
+	srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := NewRunner(srv.URL, s.Globals.Parameters, &s.Definitions)
+	r.SetVariables(map[string]string{"user": "admin"})
+	report := r.Run(context.Background(), s.CollectionsMap(true, 2))
+	if report.Total != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	login, users := report.Results[0], report.Results[1]
+	if !login.Passed || len(login.Tests) != 1 || r.Variables()["token"] != "t-admin" {
+		t.Errorf("login: %+v", login)
+	}
+	if users.Passed || users.StatusCode != 200 || len(users.Failures) != 1 || users.Failures[0].In != "test" {
+		t.Errorf("list users: %+v", users)
+	}
+	if len(users.Logs) != 1 || users.Logs[0] != "total 1" {
+		t.Errorf("unexpected logs %v", users.Logs)
+	}
+}
@@ -0,0 +1,11 @@
+package runner
+
+import "github.com/apicat/apicat/backend/common/spec"
+
+// Variables 运行时的变量 在请求中使用 {{name}} 引用
+type Variables map[string]string
+
+// Replace 替换字符串中的变量 不存在的变量保持原样
+func (v Variables) Replace(s string) string {
+	return spec.ReplaceVariables(s, v)
+}
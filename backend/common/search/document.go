@@ -0,0 +1,130 @@
+package search
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// CollectionDocument 从集合的内容中提取检索的字段
+// content为集合中节点的json数组 无法解析时只检索标题
+func CollectionDocument(id uint, kind, title, content string) *Document {
+	d := &Document{Type: TypeCollection, ID: id, Kind: kind, Title: title}
+	var nodes []map[string]any
+	if json.Unmarshal([]byte(content), &nodes) != nil {
+		return d
+	}
+	for _, node := range nodes {
+		typ, _ := node["type"].(string)
+		attrs, _ := node["attrs"].(map[string]any)
+		if !strings.HasPrefix(typ, "apicat-") {
+			// 文档节点 每个顶层节点中的文本作为一个字段
+			if text := strings.TrimSpace(nodeText(node)); text != "" {
+				d.add(FieldDoc, text)
+			}
+			continue
+		}
+		switch typ {
+		case "apicat-http-url":
+			d.add(FieldPath, attrs["path"])
+			d.add(FieldMethod, attrs["method"])
+		case "apicat-http-webhook", "apicat-http-callback":
+			d.add(FieldPath, attrs["event"])
+			d.add(FieldPath, attrs["expression"])
+			d.add(FieldMethod, attrs["method"])
+		case "apicat-graphql-operation":
+			d.add(FieldPath, attrs["field"])
+			d.add(FieldMethod, attrs["operation"])
+		case "apicat-channel":
+			d.add(FieldPath, attrs["channel"])
+			d.add(FieldMethod, attrs["protocol"])
+		case "apicat-grpc-method":
+			d.add(FieldPath, attrs["service"])
+			d.add(FieldPath, attrs["method"])
+			d.add(FieldPath, attrs["httpPath"])
+			d.add(FieldMethod, attrs["httpMethod"])
+		}
+		d.walk(attrs)
+	}
+	return d
+}
+
+// SchemaDocument 从公共模型中提取检索的字段 schema为jsonschema的json
+func SchemaDocument(id uint, name, description, schema string) *Document {
+	d := &Document{Type: TypeSchema, ID: id, Title: name}
+	d.add(FieldDescription, description)
+	var v any
+	if json.Unmarshal([]byte(schema), &v) == nil {
+		d.walk(v)
+	}
+	return d
+}
+
+// add 忽略空的内容和不是字符串的值
+func (d *Document) add(name string, v any) {
+	if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+		d.Fields = append(d.Fields, Field{Name: name, Text: s})
+	}
+}
+
+// walk 查找节点属性中的参数和jsonschema的属性
+// 有name和schema的对象作为参数 properties中的键作为属性名称
+func (d *Document) walk(v any) {
+	switch x := v.(type) {
+	case map[string]any:
+		if _, ok := x["schema"]; ok {
+			if name, ok := x["name"].(string); ok {
+				d.add(FieldParameter, name)
+				d.add(FieldDescription, x["description"])
+			}
+		}
+		if props, ok := x["properties"].(map[string]any); ok {
+			for _, k := range sortedKeys(props) {
+				d.add(FieldProperty, k)
+				if p, ok := props[k].(map[string]any); ok {
+					d.add(FieldDescription, p["description"])
+				}
+			}
+		}
+		for _, k := range sortedKeys(x) {
+			d.walk(x[k])
+		}
+	case []any:
+		for _, item := range x {
+			d.walk(item)
+		}
+	}
+}
+
+// nodeText prosemirror节点中的所有文本 块级节点之间使用空格分隔
+func nodeText(node map[string]any) string {
+	if text, ok := node["text"].(string); ok {
+		return text
+	}
+	content, _ := node["content"].([]any)
+	list := make([]string, 0, len(content))
+	inline := true
+	for _, v := range content {
+		child, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := child["text"]; !ok {
+			inline = false
+		}
+		list = append(list, nodeText(child))
+	}
+	if inline {
+		return strings.Join(list, "")
+	}
+	return strings.Join(list, " ")
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,346 @@
+package search
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// 检索对象的类型
+const (
+	TypeCollection = "collection"
+	TypeSchema     = "schema"
+)
+
+// 文档中的字段 权重越高排序越靠前
+const (
+	FieldTitle       = "title"
+	FieldPath        = "path"
+	FieldMethod      = "method"
+	FieldParameter   = "parameter"
+	FieldProperty    = "property"
+	FieldDescription = "description"
+	FieldDoc         = "doc"
+)
+
+var fieldWeights = map[string]float64{
+	FieldTitle:       3,
+	FieldPath:        2.5,
+	FieldMethod:      1,
+	FieldParameter:   2,
+	FieldProperty:    2,
+	FieldDescription: 1,
+	FieldDoc:         1,
+}
+
+// 前缀匹配的词得分的比例
+const prefixFactor = 0.5
+
+// 每个结果最多返回的高亮片段
+const maxHighlights = 3
+
+// 高亮片段的最大长度 超过时截取第一个匹配附近的内容
+const fragmentSize = 120
+
+// Document 一个集合或公共模型
+type Document struct {
+	Type string
+	ID   uint
+	// Kind 集合的类型 http doc graphql等
+	Kind   string
+	Title  string
+	Fields []Field
+}
+
+// Field 同一个字段可以出现多次 例如每个参数名称都是一个parameter字段
+type Field struct {
+	Name string
+	Text string
+}
+
+func (d *Document) key() string {
+	return d.Type + ":" + strconv.FormatUint(uint64(d.ID), 10)
+}
+
+type Result struct {
+	Type       string      `json:"type"`
+	ID         uint        `json:"id"`
+	Kind       string      `json:"kind,omitempty"`
+	Title      string      `json:"title"`
+	Score      float64     `json:"score"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Highlight 匹配的内容 匹配的词使用<mark>包裹 其它内容已经转义
+type Highlight struct {
+	Field    string `json:"field"`
+	Fragment string `json:"fragment"`
+}
+
+// Index 内存中的倒排索引 可以并发使用
+type Index struct {
+	mu   sync.RWMutex
+	docs map[string]*Document
+	// 词 => 文档 => 加权的词频
+	postings map[string]map[string]float64
+	// 文档 => 包含的词 删除文档时使用
+	terms map[string][]string
+}
+
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*Document),
+		postings: make(map[string]map[string]float64),
+		terms:    make(map[string][]string),
+	}
+}
+
+// Len 文档的数量
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return len(i.docs)
+}
+
+// Put 添加文档 相同类型和id的文档会被替换
+func (i *Index) Put(docs ...*Document) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, d := range docs {
+		key := d.key()
+		i.remove(key)
+		freqs := make(map[string]float64)
+		add := func(name, text string) {
+			for _, t := range tokenize(text, true) {
+				freqs[t.term] += fieldWeights[name]
+			}
+		}
+		add(FieldTitle, d.Title)
+		for _, f := range d.Fields {
+			add(f.Name, f.Text)
+		}
+		terms := make([]string, 0, len(freqs))
+		for term, freq := range freqs {
+			if i.postings[term] == nil {
+				i.postings[term] = make(map[string]float64)
+			}
+			i.postings[term][key] = freq
+			terms = append(terms, term)
+		}
+		i.docs[key] = d
+		i.terms[key] = terms
+	}
+}
+
+// Remove 删除文档 文档不存在时忽略
+func (i *Index) Remove(typ string, ids ...uint) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, id := range ids {
+		i.remove((&Document{Type: typ, ID: id}).key())
+	}
+}
+
+func (i *Index) remove(key string) {
+	for _, term := range i.terms[key] {
+		delete(i.postings[term], key)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	delete(i.docs, key)
+	delete(i.terms, key)
+}
+
+// Search 返回包含所有关键词的文档 按照得分排序
+// 关键词可以是词的前缀 types为空时检索所有类型 limit小于等于0时不限制数量
+func (i *Index) Search(query string, types []string, limit int) []*Result {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	queryTerms := uniqueTerms(tokenize(query, false))
+	results := make([]*Result, 0)
+	if len(queryTerms) == 0 {
+		return results
+	}
+
+	scores := make(map[string]float64)
+	matched := make(map[string]map[string]bool)
+	for n, qt := range queryTerms {
+		termScores := make(map[string]float64)
+		for term, postings := range i.postings {
+			factor := 1.0
+			if term != qt {
+				if !strings.HasPrefix(term, qt) {
+					continue
+				}
+				factor = prefixFactor
+			}
+			idf := math.Log(1 + float64(len(i.docs))/float64(len(postings)))
+			for key, freq := range postings {
+				if n > 0 {
+					if _, ok := scores[key]; !ok {
+						continue
+					}
+				}
+				if matched[key] == nil {
+					matched[key] = make(map[string]bool)
+				}
+				matched[key][term] = true
+				if s := factor * (1 + math.Log(freq)) * idf; s > termScores[key] {
+					termScores[key] = s
+				}
+			}
+		}
+		// 只保留包含所有关键词的文档
+		next := make(map[string]float64, len(termScores))
+		for key, s := range termScores {
+			next[key] = scores[key] + s
+		}
+		scores = next
+		if len(scores) == 0 {
+			return results
+		}
+	}
+
+	for key, score := range scores {
+		d := i.docs[key]
+		if len(types) > 0 && !contains(types, d.Type) {
+			continue
+		}
+		results = append(results, &Result{
+			Type:       d.Type,
+			ID:         d.ID,
+			Kind:       d.Kind,
+			Title:      d.Title,
+			Score:      math.Round(score*1000) / 1000,
+			Highlights: highlights(d, matched[key]),
+		})
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		if results[a].Type != results[b].Type {
+			return results[a].Type < results[b].Type
+		}
+		return results[a].ID < results[b].ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func uniqueTerms(tokens []token) []string {
+	list := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if !contains(list, t.term) {
+			list = append(list, t.term)
+		}
+	}
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// highlights 按照字段的权重返回匹配的片段
+func highlights(d *Document, terms map[string]bool) []Highlight {
+	type fragment struct {
+		Highlight
+		weight float64
+	}
+	list := make([]fragment, 0)
+	for _, f := range append([]Field{{Name: FieldTitle, Text: d.Title}}, d.Fields...) {
+		if s, ok := highlight(f.Text, terms); ok {
+			list = append(list, fragment{Highlight{Field: f.Name, Fragment: s}, fieldWeights[f.Name]})
+		}
+	}
+	sort.SliceStable(list, func(a, b int) bool { return list[a].weight > list[b].weight })
+	res := make([]Highlight, 0, maxHighlights)
+	for _, v := range list {
+		if len(res) == maxHighlights {
+			break
+		}
+		res = append(res, v.Highlight)
+	}
+	return res
+}
+
+// highlight 使用<mark>包裹文本中匹配的词 重叠或相邻的词合并为一个
+func highlight(text string, terms map[string]bool) (string, bool) {
+	ranges := make([][2]int, 0)
+	for _, t := range tokenize(text, true) {
+		if !terms[t.term] {
+			continue
+		}
+		if n := len(ranges); n > 0 && t.start <= ranges[n-1][1] {
+			if t.end > ranges[n-1][1] {
+				ranges[n-1][1] = t.end
+			}
+			continue
+		}
+		ranges = append(ranges, [2]int{t.start, t.end})
+	}
+	if len(ranges) == 0 {
+		return "", false
+	}
+
+	start, end := 0, len(text)
+	if utf8.RuneCountInString(text) > fragmentSize {
+		start = backward(text, ranges[0][0], fragmentSize/3)
+		end = forward(text, start, fragmentSize)
+	}
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, r := range ranges {
+		if r[0] >= end {
+			break
+		}
+		if r[1] > end {
+			r[1] = end
+		}
+		b.WriteString(html.EscapeString(text[pos:r[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[r[0]:r[1]]))
+		b.WriteString("</mark>")
+		pos = r[1]
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
+
+// backward 从pos向前移动n个字符
+func backward(text string, pos, n int) int {
+	for ; n > 0 && pos > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= size
+	}
+	return pos
+}
+
+// forward 从pos向后移动n个字符
+func forward(text string, pos, n int) int {
+	for ; n > 0 && pos < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
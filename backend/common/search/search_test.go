@@ -0,0 +1,137 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	terms := func(text string, parts bool) []string {
+		list := make([]string, 0)
+		for _, v := range tokenize(text, parts) {
+			list = append(list, v.term)
+		}
+		return list
+	}
+	for _, c := range []struct {
+		text  string
+		parts bool
+		want  []string
+	}{
+		{"merchant_id", false, []string{"merchantid"}},
+		{"merchantId", true, []string{"merchantid", "merchant", "id"}},
+		{"/orders/{order-id}", true, []string{"orders", "orderid", "order", "id"}},
+		{"HTTPServer v2", true, []string{"httpserver", "http", "server", "v2"}},
+		{"_private 订单", true, []string{"private", "订", "单"}},
+	} {
+		if got := terms(c.text, c.parts); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("tokenize(%q) = %v, want %v", c.text, got, c.want)
+		}
+	}
+}
+
+const orderContent = `[
+	{"type":"paragraph","content":[{"type":"text","text":"Create an order for the "},{"type":"text","text":"merchant"}]},
+	{"type":"apicat-http-url","attrs":{"path":"/orders","method":"post"}},
+	{"type":"apicat-http-request","attrs":{"parameters":{"header":[{"name":"X-Merchant-Id","schema":{"type":"string"}}]},
+		"content":{"application/json":{"schema":{"type":"object","properties":{"merchant_id":{"type":"integer","description":"<b>owner</b> of the order"}}}}}}}
+]`
+
+func testIndex() *Index {
+	idx := NewIndex()
+	idx.Put(
+		CollectionDocument(1, "http", "Create order", orderContent),
+		CollectionDocument(2, "http", "List users", `[{"type":"apicat-http-url","attrs":{"path":"/users","method":"get"}}]`),
+		CollectionDocument(3, "doc", "Guide", `[{"type":"heading","content":[{"type":"text","text":"商户接入"}]}]`),
+		CollectionDocument(4, "doc", "Export report", `[{"type":"paragraph","content":[{"type":"text","text":"Exports all users"}]}]`),
+		SchemaDocument(1, "Merchant", "商户信息", `{"type":"object","properties":{"merchantId":{"type":"integer"},"name":{"type":"string"}}}`),
+	)
+	return idx
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+
+	// 路径和标题中的匹配权重更高
+	res := idx.Search("users", nil, 0)
+	if len(res) != 2 || res[0].ID != 2 || res[1].ID != 4 {
+		t.Fatalf("users: %+v", res)
+	}
+
+	// 不同的命名方式都可以匹配
+	res = idx.Search("merchant_id", nil, 0)
+	if len(res) != 2 {
+		t.Fatalf("merchant_id: %d results", len(res))
+	}
+	want := []Highlight{{Field: FieldProperty, Fragment: "<mark>merchantId</mark>"}}
+	if res[1].Type != TypeSchema || !reflect.DeepEqual(res[1].Highlights, want) {
+		t.Errorf("merchant_id: %+v", res[1])
+	}
+
+	// 前缀匹配和多个关键词
+	res = idx.Search("ord own", nil, 0)
+	if len(res) != 1 || res[0].ID != 1 {
+		t.Fatalf("ord own: %+v", res)
+	}
+	want = []Highlight{
+		{Field: FieldTitle, Fragment: "Create <mark>order</mark>"},
+		{Field: FieldPath, Fragment: "/<mark>orders</mark>"},
+		{Field: FieldDoc, Fragment: "Create an <mark>order</mark> for the merchant"},
+	}
+	if !reflect.DeepEqual(res[0].Highlights, want) {
+		t.Errorf("ord own: highlights %+v", res[0].Highlights)
+	}
+	// 高亮的内容会被转义
+	if s, _ := highlight("<b>owner</b>", map[string]bool{"owner": true}); s != "&lt;b&gt;<mark>owner</mark>&lt;/b&gt;" {
+		t.Errorf("escape: %q", s)
+	}
+
+	res = idx.Search("商户", []string{TypeCollection}, 0)
+	if len(res) != 1 || res[0].ID != 3 || res[0].Highlights[0].Fragment != "<mark>商户</mark>接入" {
+		t.Errorf("商户: %+v", res)
+	}
+
+	if res = idx.Search("merchant", nil, 1); len(res) != 1 {
+		t.Errorf("limit: %d results", len(res))
+	}
+	if res = idx.Search("missing order", nil, 0); len(res) != 0 {
+		t.Errorf("missing order: %+v", res)
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	idx := testIndex()
+	idx.Put(CollectionDocument(2, "http", "List customers", `[{"type":"apicat-http-url","attrs":{"path":"/customers","method":"get"}}]`))
+	if res := idx.Search("users", nil, 0); len(res) != 1 || res[0].ID != 4 {
+		t.Errorf("users after update: %+v", res)
+	}
+	if res := idx.Search("customers", nil, 0); len(res) != 1 || res[0].ID != 2 {
+		t.Errorf("customers after update: %+v", res)
+	}
+
+	idx.Remove(TypeSchema, 1)
+	if res := idx.Search("merchant", []string{TypeSchema}, 0); len(res) != 0 {
+		t.Errorf("merchant after remove: %+v", res)
+	}
+	if idx.Len() != 4 {
+		t.Errorf("len = %d", idx.Len())
+	}
+	for term, postings := range idx.postings {
+		if postings["schema:1"] != 0 {
+			t.Errorf("term %q still references the removed schema", term)
+		}
+	}
+}
+
+func TestHighlightFragment(t *testing.T) {
+	text := "The first sentence is long enough to push the keyword out of the fragment window. " +
+		"Somewhere after that the merchant appears and the text goes on for a while longer than the fragment needs, so the end is cut off too."
+	s, ok := highlight(text, map[string]bool{"merchant": true})
+	if !ok {
+		t.Fatal("no highlight")
+	}
+	want := "…agment window. Somewhere after that the <mark>merchant</mark> appears and the text goes on for a while longer than the fragment needs…"
+	if s != want {
+		t.Errorf("fragment = %q", s)
+	}
+}
@@ -0,0 +1,108 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token 文本中的一个词 start和end为在原文中的字节位置
+type token struct {
+	term  string
+	start int
+	end   int
+}
+
+// tokenize 拆分文本中的词
+// 字母和数字组成的单词 使用_和-连接的部分作为一个单词 汉字每个字作为一个词
+// 单词去掉_和-后转为小写 merchant_id merchant-id merchantId都得到merchantid
+// parts为true时 单词中按照_ -和驼峰拆分的每个部分也作为词 建立索引时使用
+func tokenize(text string, parts bool) []token {
+	list := make([]token, 0)
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := strings.Trim(text[start:end], "_-")
+		if word != "" {
+			offset := start + strings.Index(text[start:end], word)
+			list = append(list, wordTokens(word, offset, parts)...)
+		}
+		start = -1
+	}
+	for i, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush(i)
+			list = append(list, token{term: string(r), start: i, end: i + utf8.RuneLen(r)})
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-':
+			if start < 0 {
+				start = i
+			}
+		default:
+			flush(i)
+		}
+	}
+	flush(len(text))
+	return list
+}
+
+func wordTokens(word string, offset int, parts bool) []token {
+	compound := token{
+		term:  strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(word)),
+		start: offset,
+		end:   offset + len(word),
+	}
+	list := []token{compound}
+	if !parts {
+		return list
+	}
+	sub := splitWord(word)
+	if len(sub) < 2 {
+		return list
+	}
+	for _, v := range sub {
+		list = append(list, token{term: strings.ToLower(v.term), start: offset + v.start, end: offset + v.end})
+	}
+	return list
+}
+
+// splitWord 按照_ -和驼峰拆分单词 HTTPServer拆分为HTTP和Server
+func splitWord(word string) []token {
+	list := make([]token, 0)
+	runes := []rune(word)
+	start, pos := 0, 0
+	offsets := make([]int, len(runes)+1)
+	for i, r := range runes {
+		offsets[i] = pos
+		pos += utf8.RuneLen(r)
+	}
+	offsets[len(runes)] = pos
+	add := func(end int) {
+		if end > start {
+			list = append(list, token{term: string(runes[start:end]), start: offsets[start], end: offsets[end]})
+		}
+	}
+	for i, r := range runes {
+		if r == '_' || r == '-' {
+			add(i)
+			start = i + 1
+			continue
+		}
+		if i == start {
+			continue
+		}
+		prev := runes[i-1]
+		switch {
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			add(i)
+			start = i
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			add(i)
+			start = i
+		}
+	}
+	add(len(runes))
+	return list
+}
@@ -0,0 +1,120 @@
+package spec
+
+import (
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// 通道上操作的类型 与asyncapi 2.x的含义相同
+const (
+	// ChannelPublish 客户端向通道发送消息
+	ChannelPublish = "publish"
+	// ChannelSubscribe 客户端订阅通道上服务端发出的消息
+	ChannelSubscribe = "subscribe"
+)
+
+// ChannelNode 事件通道 例如websocket的地址或者消息队列的topic
+type ChannelNode struct {
+	// Channel 通道名称 可以包含{name}形式的参数
+	Channel string `json:"channel"`
+	// Protocol ws kafka amqp mqtt等
+	Protocol   string  `json:"protocol,omitempty"`
+	Parameters Schemas `json:"parameters,omitempty"`
+}
+
+func (ChannelNode) Name() string {
+	return "apicat-channel"
+}
+
+// ChannelMessage 通道中的消息 payload可以引用公共模型
+type ChannelMessage struct {
+	Name        string             `json:"name,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	ContentType string             `json:"contentType,omitempty"`
+	Headers     *jsonschema.Schema `json:"headers,omitempty"`
+	Payload     *jsonschema.Schema `json:"payload,omitempty"`
+}
+
+// ChannelOperationNode 通道上的publish或subscribe操作 有多个消息时表示其中之一
+type ChannelOperationNode struct {
+	Action      string           `json:"action"`
+	OperationID string           `json:"operationId,omitempty"`
+	Summary     string           `json:"summary,omitempty"`
+	Messages    []ChannelMessage `json:"messages"`
+}
+
+func (ChannelOperationNode) Name() string {
+	return "apicat-channel-operation"
+}
+
+// ChannelPart channel集合的定义
+type ChannelPart struct {
+	Title     string
+	ID        int64
+	Dir       string
+	Tags      []string
+	Doc       Document
+	Channel   ChannelNode
+	Publish   *ChannelOperationNode
+	Subscribe *ChannelOperationNode
+}
+
+// Operations 按照publish subscribe的顺序返回通道上的操作
+func (c *ChannelPart) Operations() []*ChannelOperationNode {
+	list := make([]*ChannelOperationNode, 0, 2)
+	for _, v := range []*ChannelOperationNode{c.Publish, c.Subscribe} {
+		if v != nil {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// Channels 按照集合的顺序返回所有的通道
+// expend 是否展开参数和消息中的模型引用
+func (s *Spec) Channels(expend bool, refexpendMaxCount int) []ChannelPart {
+	list := make([]ChannelPart, 0)
+	s.WalkCollections(func(v *CollectItem, p []string) bool {
+		if v.Type != ContentItemTypeChannel {
+			return true
+		}
+		part := ChannelPart{
+			Title: v.Title,
+			ID:    v.ID,
+			Dir:   strings.Join(p, "/"),
+			Tags:  v.Tags,
+		}
+		for _, item := range v.Content {
+			switch nx := item.Node.(type) {
+			case *DocNode:
+				part.Doc.Items = append(part.Doc.Items, nx)
+			case *HTTPNode[ChannelNode]:
+				part.Channel = nx.Attrs
+			case *HTTPNode[ChannelOperationNode]:
+				op := nx.Attrs
+				switch op.Action {
+				case ChannelPublish:
+					part.Publish = &op
+				case ChannelSubscribe:
+					part.Subscribe = &op
+				}
+			}
+		}
+		if expend {
+			for _, v := range part.Channel.Parameters {
+				s.expendRef(v, refexpendMaxCount)
+			}
+			for _, op := range part.Operations() {
+				for _, m := range op.Messages {
+					s.expendRef(m.Headers, refexpendMaxCount)
+					s.expendRef(m.Payload, refexpendMaxCount)
+				}
+			}
+		}
+		list = append(list, part)
+		return true
+	})
+	return list
+}
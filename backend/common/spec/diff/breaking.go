@@ -0,0 +1,264 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	LevelBreaking    = "breaking"
+	LevelNonBreaking = "non-breaking"
+	LevelInfo        = "info"
+)
+
+// 差异所属的类型
+const (
+	KindCollection = "collection"
+	KindSchema     = "schema"
+	KindResponse   = "response"
+	KindParameter  = "parameter"
+)
+
+// Rule 差异分类规则 不匹配时返回false
+// change为nil时表示整个接口 模型等的新增或删除
+type Rule func(kind string, item *ItemDiff, change *Change) (level, reason string, ok bool)
+
+// DefaultRules 默认的分类规则 按顺序匹配 第一个匹配的规则生效
+var DefaultRules = []Rule{
+	itemRule,
+	urlRule,
+	pathParameterRule,
+	fieldPresenceRule,
+	requiredRule,
+	typeRule,
+	enumRule,
+	constraintRule,
+	nullableRule,
+	informationalRule,
+}
+
+// Classify 使用规则对报告中的所有差异分类 没有传入规则时使用 DefaultRules
+// 接口等的级别为其中最严重的差异的级别
+func Classify(r *Report, rules ...Rule) *Report {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	r.Summary.Breaking = 0
+	for kind, list := range map[string][]*ItemDiff{
+		KindCollection: r.Collections,
+		KindSchema:     r.Schemas,
+		KindResponse:   r.Responses,
+		KindParameter:  r.Parameters,
+	} {
+		for _, item := range list {
+			item.Level, item.Reason = classify(rules, kind, item, nil)
+			for _, c := range item.Changes {
+				c.Level, c.Reason = classify(rules, kind, item, c)
+				if levelWeight(c.Level) > levelWeight(item.Level) {
+					item.Level, item.Reason = c.Level, c.Reason
+				}
+			}
+			if item.Level == LevelBreaking {
+				r.Summary.Breaking++
+			}
+		}
+	}
+	return r
+}
+
+func classify(rules []Rule, kind string, item *ItemDiff, c *Change) (string, string) {
+	for _, rule := range rules {
+		if level, reason, ok := rule(kind, item, c); ok {
+			return level, reason
+		}
+	}
+	if c == nil {
+		return LevelInfo, ""
+	}
+	return LevelNonBreaking, fmt.Sprintf("%s %s", c.Path, c.Action)
+}
+
+func levelWeight(level string) int {
+	switch level {
+	case LevelBreaking:
+		return 2
+	case LevelNonBreaking:
+		return 1
+	}
+	return 0
+}
+
+// itemRule 整个接口 模型 公共响应或全局参数的新增和删除
+func itemRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c != nil || item.Action == ActionChanged {
+		return "", "", false
+	}
+	name := item.Title
+	if kind == KindCollection {
+		name = item.Method + " " + item.Path
+	}
+	if item.Action == ActionRemoved {
+		if kind == KindParameter {
+			return LevelNonBreaking, fmt.Sprintf("global parameter %s removed", name), true
+		}
+		return LevelBreaking, fmt.Sprintf("%s %s removed", kind, name), true
+	}
+	return LevelNonBreaking, fmt.Sprintf("%s %s added", kind, name), true
+}
+
+func urlRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil || c.Path != "url" {
+		return "", "", false
+	}
+	return LevelBreaking, fmt.Sprintf("endpoint %s changed from %v to %v", c.Field, c.Old, c.New), true
+}
+
+// pathParameterRule 路径参数的新增删除都会导致原来的请求地址不可用
+func pathParameterRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil || c.Field != "" || !strings.HasPrefix(c.Path, "request.path.") {
+		return "", "", false
+	}
+	name := strings.TrimPrefix(c.Path, "request.path.")
+	for _, x := range item.Changes {
+		if x != c && x.Field == "" && strings.HasPrefix(x.Path, "request.path.") && x.Action != c.Action {
+			return LevelBreaking, fmt.Sprintf("path parameter %s renamed", name), true
+		}
+	}
+	return LevelBreaking, fmt.Sprintf("path parameter %s %s", name, c.Action), true
+}
+
+// fieldPresenceRule 参数 字段和响应的新增删除
+// 请求中新增必须字段 响应中删除字段或状态码会影响客户端
+func fieldPresenceRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil || c.Field != "" || (c.Action != ActionAdded && c.Action != ActionRemoved) {
+		return "", "", false
+	}
+	switch {
+	case c.In == "response" && c.Action == ActionRemoved:
+		return LevelBreaking, fmt.Sprintf("response %s removed", c.Path), true
+	case c.In == "response":
+		return LevelNonBreaking, fmt.Sprintf("response %s added", c.Path), true
+	case c.Action == ActionAdded && c.Required:
+		return LevelBreaking, fmt.Sprintf("new required field %s", c.Path), true
+	case c.In == "request" && c.Action == ActionRemoved:
+		return LevelNonBreaking, fmt.Sprintf("request %s removed", c.Path), true
+	case c.Action == ActionRemoved:
+		// 模型可能用在响应中
+		return LevelBreaking, fmt.Sprintf("field %s removed", c.Path), true
+	}
+	return LevelNonBreaking, fmt.Sprintf("optional field %s added", c.Path), true
+}
+
+func requiredRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil || c.Field != "required" {
+		return "", "", false
+	}
+	required, _ := c.New.(bool)
+	switch {
+	case required && c.In != "response":
+		return LevelBreaking, fmt.Sprintf("%s became required", c.Path), true
+	case !required && c.In != "request":
+		return LevelBreaking, fmt.Sprintf("%s is no longer guaranteed in response", c.Path), true
+	}
+	return LevelNonBreaking, fmt.Sprintf("required of %s changed", c.Path), true
+}
+
+func typeRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil || (c.Field != "type" && c.Field != "$ref") {
+		return "", "", false
+	}
+	if c.Field == "$ref" {
+		return LevelBreaking, fmt.Sprintf("reference of %s changed from %v to %v", c.Path, c.Old, c.New), true
+	}
+	return LevelBreaking, fmt.Sprintf("type of %s changed from %v to %v", c.Path, c.Old, c.New), true
+}
+
+// enumRule 请求的枚举缩小或响应的枚举扩大会导致不兼容
+func enumRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil || c.Field != "enum" {
+		return "", "", false
+	}
+	before, _ := c.Old.([]any)
+	after, _ := c.New.([]any)
+	narrowed := len(after) > 0 && (len(before) == 0 || !subset(before, after))
+	widened := len(before) > 0 && (len(after) == 0 || !subset(after, before))
+	switch {
+	case narrowed && c.In != "response":
+		return LevelBreaking, fmt.Sprintf("enum of %s narrowed", c.Path), true
+	case widened && c.In != "request":
+		return LevelBreaking, fmt.Sprintf("enum of %s widened", c.Path), true
+	}
+	return LevelNonBreaking, fmt.Sprintf("enum of %s changed", c.Path), true
+}
+
+// constraintRule 请求中限制更严格时不兼容
+func constraintRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil {
+		return "", "", false
+	}
+	var stricter bool
+	before, after := int64Value(c.Old), int64Value(c.New)
+	switch c.Field {
+	case "minimum", "minLength", "minItems":
+		stricter = after != nil && (before == nil || *after > *before)
+	case "maximum", "maxLength", "maxItems":
+		stricter = after != nil && (before == nil || *after < *before)
+	case "pattern", "format", "multipleOf", "exclusiveMinimum", "exclusiveMaximum", "uniqueItems", "additionalProperties":
+		stricter = true
+	default:
+		return "", "", false
+	}
+	if stricter && c.In != "response" {
+		return LevelBreaking, fmt.Sprintf("%s of %s is stricter", c.Field, c.Path), true
+	}
+	return LevelNonBreaking, fmt.Sprintf("%s of %s changed", c.Field, c.Path), true
+}
+
+func nullableRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil || c.Field != "nullable" {
+		return "", "", false
+	}
+	if reflect.DeepEqual(c.New, boolPtr(true)) && c.In != "request" {
+		return LevelBreaking, fmt.Sprintf("%s may be null in response", c.Path), true
+	}
+	return LevelNonBreaking, fmt.Sprintf("nullable of %s changed", c.Path), true
+}
+
+// informationalRule 只影响文档展示的修改
+func informationalRule(kind string, item *ItemDiff, c *Change) (string, string, bool) {
+	if c == nil {
+		return "", "", false
+	}
+	switch c.Field {
+	case "title", "name", "description", "example", "x-apicat-mock", "deprecated", "preRequest", "postResponse":
+		return LevelInfo, fmt.Sprintf("%s of %s changed", c.Field, c.Path), true
+	}
+	return "", "", false
+}
+
+// subset a中的值是否都在b中
+func subset(a, b []any) bool {
+	for _, x := range a {
+		found := false
+		for _, y := range b {
+			if reflect.DeepEqual(x, y) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func int64Value(v any) *int64 {
+	if x, ok := v.(*int64); ok {
+		return x
+	}
+	return nil
+}
+
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,591 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	ActionAdded   = "added"
+	ActionRemoved = "removed"
+	ActionChanged = "changed"
+)
+
+// Change 字段级别的差异
+// Path 为差异所在的位置 如 request.query.page responses.200.body.application/json.user.name
+// In 为差异在请求还是响应中 模型和全局参数为空
+// Field 为修改的属性 如 type enum required
+// Required 表示新增或删除的参数或字段是否必须
+// Level 和 Reason 由 Classify 填充
+type Change struct {
+	Action   string `json:"action"`
+	In       string `json:"in,omitempty"`
+	Path     string `json:"path"`
+	Field    string `json:"field,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Old      any    `json:"old,omitempty"`
+	New      any    `json:"new,omitempty"`
+	Level    string `json:"level,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ItemDiff 接口 模型 公共响应或全局参数的差异
+type ItemDiff struct {
+	Action  string    `json:"action"`
+	ID      int64     `json:"id,omitempty"`
+	Title   string    `json:"title"`
+	Method  string    `json:"method,omitempty"`
+	Path    string    `json:"path,omitempty"`
+	In      string    `json:"in,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	Changes []*Change `json:"changes,omitempty"`
+}
+
+// Summary 差异数量 Breaking 为不兼容的数量 由 Classify 填充
+type Summary struct {
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Changed  int `json:"changed"`
+	Breaking int `json:"breaking"`
+}
+
+// Report 两个项目的差异
+type Report struct {
+	Summary     Summary     `json:"summary"`
+	Collections []*ItemDiff `json:"collections"`
+	Schemas     []*ItemDiff `json:"schemas"`
+	Responses   []*ItemDiff `json:"responses"`
+	Parameters  []*ItemDiff `json:"parameters"`
+}
+
+// Compare 比较整个项目的差异 source为旧版本 target为新版本
+// 接口使用方法和路径匹配 找不到时使用id匹配 模型和公共响应使用id匹配 找不到时使用名称匹配
+// 引用不会展开 模型的修改只在模型中体现 引用使用定义的名称比较
+// 导入的文件和项目中定义的id不同 按名称比较时不会出现差异
+func Compare(source, target *spec.Spec) *Report {
+	refs := &refs{source: newRefNames(source), target: newRefNames(target)}
+	r := &Report{
+		Collections: compareCollections(refs, source, target),
+		Schemas:     compareDefinitionSchemas(refs, source.Definitions.Schemas, target.Definitions.Schemas),
+		Responses:   compareDefinitionResponses(refs, source.Definitions.Responses, target.Definitions.Responses),
+		Parameters:  compareGlobalParameters(refs, source.Globals.Parameters, target.Globals.Parameters),
+	}
+	for _, list := range [][]*ItemDiff{r.Collections, r.Schemas, r.Responses, r.Parameters} {
+		for _, v := range list {
+			switch v.Action {
+			case ActionAdded:
+				r.Summary.Added++
+			case ActionRemoved:
+				r.Summary.Removed++
+			default:
+				r.Summary.Changed++
+			}
+		}
+	}
+	return r
+}
+
+// Empty 是否没有任何差异
+func (r *Report) Empty() bool {
+	return r.Summary.Added+r.Summary.Removed+r.Summary.Changed == 0
+}
+
+type endpoint struct {
+	method string
+	path   string
+	part   spec.HTTPPart
+}
+
+func (e *endpoint) key() string { return e.method + " " + e.path }
+
+func endpoints(s *spec.Spec) []*endpoint {
+	list := make([]*endpoint, 0)
+	for path, methods := range s.CollectionsMap(false, 0) {
+		for method, part := range methods {
+			list = append(list, &endpoint{method: strings.ToUpper(method), path: path, part: part})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].path == list[j].path {
+			return list[i].method < list[j].method
+		}
+		return list[i].path < list[j].path
+	})
+	return list
+}
+
+func compareCollections(refs *refs, source, target *spec.Spec) []*ItemDiff {
+	olds := endpoints(source)
+	byKey := make(map[string]*endpoint)
+	byID := make(map[int64]*endpoint)
+	for _, v := range olds {
+		byKey[v.key()] = v
+		byID[v.part.ID] = v
+	}
+
+	news := endpoints(target)
+	newKeys := make(map[string]bool)
+	for _, v := range news {
+		newKeys[v.key()] = true
+	}
+
+	matched := make(map[*endpoint]bool)
+	list := make([]*ItemDiff, 0)
+	for _, b := range news {
+		a, ok := byKey[b.key()]
+		// 路径或方法修改过的接口
+		if !ok {
+			if x, has := byID[b.part.ID]; has && b.part.ID != 0 && !matched[x] && !newKeys[x.key()] {
+				a, ok = x, true
+			}
+		}
+		item := &ItemDiff{ID: b.part.ID, Title: b.part.Title, Method: b.method, Path: b.path}
+		if !ok {
+			item.Action = ActionAdded
+			list = append(list, item)
+			continue
+		}
+		matched[a] = true
+		if item.Changes = compareEndpoint(refs, a, b); len(item.Changes) > 0 {
+			item.Action = ActionChanged
+			list = append(list, item)
+		}
+	}
+	for _, a := range olds {
+		if !matched[a] {
+			list = append(list, &ItemDiff{
+				Action: ActionRemoved,
+				ID:     a.part.ID,
+				Title:  a.part.Title,
+				Method: a.method,
+				Path:   a.path,
+			})
+		}
+	}
+	return list
+}
+
+// refNames 公共定义的引用 => 类型和名称 例如schemas/User
+type refNames map[string]string
+
+func newRefNames(s *spec.Spec) refNames {
+	names := make(refNames)
+	for _, v := range s.Definitions.Schemas {
+		names[fmt.Sprintf("#/definitions/schemas/%d", v.ID)] = "schemas/" + v.Name
+	}
+	for _, v := range s.Definitions.Parameters {
+		names[fmt.Sprintf("#/definitions/parameters/%d", v.ID)] = "parameters/" + v.Name
+	}
+	for _, v := range s.Definitions.Responses {
+		names[fmt.Sprintf("#/definitions/responses/%d", v.ID)] = "responses/" + v.Name
+	}
+	return names
+}
+
+// refs 两个版本中引用的定义名称
+type refs struct {
+	source, target refNames
+}
+
+func (r *refs) changes() *changes {
+	return &changes{refs: r}
+}
+
+// changes 收集差异
+type changes struct {
+	list []*Change
+	refs *refs
+}
+
+func (c *changes) add(action, in, path string, required bool, old, new any) {
+	c.list = append(c.list, &Change{Action: action, In: in, Path: path, Required: required, Old: old, New: new})
+}
+
+func (c *changes) field(in, path, field string, old, new any) {
+	c.list = append(c.list, &Change{Action: ActionChanged, In: in, Path: path, Field: field, Old: old, New: new})
+}
+
+// refEqual 引用的定义名称相同 定义不存在时比较引用本身
+func (c *changes) refEqual(a, b *string) bool {
+	x, y := refString(a), refString(b)
+	if name, ok := c.refs.source[x]; ok {
+		x = name
+	}
+	if name, ok := c.refs.target[y]; ok {
+		y = name
+	}
+	return x == y
+}
+
+func compareEndpoint(refs *refs, a, b *endpoint) []*Change {
+	c := refs.changes()
+	if a.part.Title != b.part.Title {
+		c.field("", "title", "title", a.part.Title, b.part.Title)
+	}
+	if a.method != b.method {
+		c.field("", "url", "method", a.method, b.method)
+	}
+	if a.path != b.path {
+		c.field("", "url", "path", a.path, b.path)
+	}
+
+	ap, bp := a.part.Parameters.Map(), b.part.Parameters.Map()
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		c.parameters("request", "request."+in, ap[in], bp[in])
+	}
+	c.body("request", "request.body", a.part.Content, b.part.Content)
+	c.responses(a.part.Responses, b.part.Responses)
+
+	if a.part.Script.PreRequest != b.part.Script.PreRequest {
+		c.field("", "script", "preRequest", a.part.Script.PreRequest, b.part.Script.PreRequest)
+	}
+	if a.part.Script.PostResponse != b.part.Script.PostResponse {
+		c.field("", "script", "postResponse", a.part.Script.PostResponse, b.part.Script.PostResponse)
+	}
+	return c.list
+}
+
+func (c *changes) parameters(in, path string, a, b spec.Schemas) {
+	for _, v := range b {
+		x := a.Lookup(v.Name)
+		p := path + "." + v.Name
+		if x == nil {
+			c.add(ActionAdded, in, p, v.Required, nil, v)
+			continue
+		}
+		c.parameter(in, p, x, v)
+	}
+	for _, v := range a {
+		if b.Lookup(v.Name) == nil {
+			c.add(ActionRemoved, in, path+"."+v.Name, v.Required, v, nil)
+		}
+	}
+}
+
+func (c *changes) parameter(in, path string, a, b *spec.Schema) {
+	if !c.refEqual(a.Reference, b.Reference) {
+		c.field(in, path, "$ref", refString(a.Reference), refString(b.Reference))
+		return
+	}
+	if a.Required != b.Required {
+		c.field(in, path, "required", a.Required, b.Required)
+	}
+	if a.Description != b.Description {
+		c.field(in, path, "description", a.Description, b.Description)
+	}
+	if !reflect.DeepEqual(a.Example, b.Example) {
+		c.field(in, path, "example", a.Example, b.Example)
+	}
+	c.jsonschema(in, path, a.Schema, b.Schema)
+}
+
+func (c *changes) body(in, path string, a, b spec.HTTPBody) {
+	for _, k := range sortedKeys(b) {
+		p := path + "." + k
+		x, ok := a[k]
+		if !ok {
+			c.add(ActionAdded, in, p, false, nil, b[k])
+			continue
+		}
+		if x == nil || b[k] == nil {
+			continue
+		}
+		if !c.refEqual(x.Reference, b[k].Reference) {
+			c.field(in, p, "$ref", refString(x.Reference), refString(b[k].Reference))
+			continue
+		}
+		c.jsonschema(in, p, x.Schema, b[k].Schema)
+	}
+	for _, k := range sortedKeys(a) {
+		if _, ok := b[k]; !ok {
+			c.add(ActionRemoved, in, path+"."+k, false, a[k], nil)
+		}
+	}
+}
+
+func (c *changes) responses(a, b spec.HTTPResponses) {
+	am := make(map[int]spec.HTTPResponse)
+	for _, v := range a {
+		am[v.Code] = v
+	}
+	bm := make(map[int]bool)
+	for _, v := range b {
+		bm[v.Code] = true
+		p := fmt.Sprintf("responses.%d", v.Code)
+		x, ok := am[v.Code]
+		if !ok {
+			c.add(ActionAdded, "response", p, false, nil, v)
+			continue
+		}
+		c.response("response", p, &x.HTTPResponseDefine, &v.HTTPResponseDefine)
+	}
+	for _, v := range a {
+		if !bm[v.Code] {
+			c.add(ActionRemoved, "response", fmt.Sprintf("responses.%d", v.Code), false, v, nil)
+		}
+	}
+}
+
+func (c *changes) response(in, path string, a, b *spec.HTTPResponseDefine) {
+	if !c.refEqual(a.Reference, b.Reference) {
+		c.field(in, path, "$ref", refString(a.Reference), refString(b.Reference))
+		return
+	}
+	if a.Name != b.Name {
+		c.field(in, path, "name", a.Name, b.Name)
+	}
+	if a.Description != b.Description {
+		c.field(in, path, "description", a.Description, b.Description)
+	}
+	c.parameters(in, path+".header", a.Header, b.Header)
+	c.body(in, path+".body", a.Content, b.Content)
+}
+
+// schemaKeywords 直接比较值的schema属性
+var schemaKeywords = []struct {
+	name  string
+	value func(*jsonschema.Schema) any
+}{
+	{"format", func(s *jsonschema.Schema) any { return s.Format }},
+	{"pattern", func(s *jsonschema.Schema) any { return s.Pattern }},
+	{"enum", func(s *jsonschema.Schema) any { return s.Enum }},
+	{"default", func(s *jsonschema.Schema) any { return s.Default }},
+	{"example", func(s *jsonschema.Schema) any { return s.Example }},
+	{"description", func(s *jsonschema.Schema) any { return s.Description }},
+	{"x-apicat-mock", func(s *jsonschema.Schema) any { return s.XMock }},
+	{"minimum", func(s *jsonschema.Schema) any { return s.Minimum }},
+	{"maximum", func(s *jsonschema.Schema) any { return s.Maximum }},
+	{"exclusiveMinimum", func(s *jsonschema.Schema) any { return s.ExclusiveMinimum }},
+	{"exclusiveMaximum", func(s *jsonschema.Schema) any { return s.ExclusiveMaximum }},
+	{"multipleOf", func(s *jsonschema.Schema) any { return s.MultipleOf }},
+	{"minLength", func(s *jsonschema.Schema) any { return s.MinLength }},
+	{"maxLength", func(s *jsonschema.Schema) any { return s.MaxLength }},
+	{"minItems", func(s *jsonschema.Schema) any { return s.MinItems }},
+	{"maxItems", func(s *jsonschema.Schema) any { return s.MaxItems }},
+	{"uniqueItems", func(s *jsonschema.Schema) any { return s.UniqueItems }},
+	{"additionalProperties", func(s *jsonschema.Schema) any { return s.AdditionalProperties }},
+	{"nullable", func(s *jsonschema.Schema) any { return s.Nullable }},
+	{"readOnly", func(s *jsonschema.Schema) any { return s.ReadOnly }},
+	{"writeOnly", func(s *jsonschema.Schema) any { return s.WriteOnly }},
+	{"deprecated", func(s *jsonschema.Schema) any { return s.Deprecated }},
+}
+
+func (c *changes) jsonschema(in, path string, a, b *jsonschema.Schema) {
+	switch {
+	case a == nil && b == nil:
+		return
+	case a == nil:
+		c.add(ActionAdded, in, path, false, nil, b)
+		return
+	case b == nil:
+		c.add(ActionRemoved, in, path, false, a, nil)
+		return
+	}
+	if a.Ref() || b.Ref() {
+		if !c.refEqual(a.Reference, b.Reference) {
+			c.field(in, path, "$ref", refString(a.Reference), refString(b.Reference))
+		}
+		return
+	}
+	if at, bt := a.Type.Value(), b.Type.Value(); !slices.Equal(at, bt) {
+		// 类型修改后其他属性没有比较的意义
+		c.field(in, path, "type", at, bt)
+		return
+	}
+	for _, k := range schemaKeywords {
+		if x, y := k.value(a), k.value(b); !reflect.DeepEqual(x, y) {
+			c.field(in, path, k.name, x, y)
+		}
+	}
+
+	for _, k := range sortedKeys(b.Properties) {
+		p := path + "." + k
+		required := slices.Contains(b.Required, k)
+		x, ok := a.Properties[k]
+		if !ok {
+			c.add(ActionAdded, in, p, required, nil, b.Properties[k])
+			continue
+		}
+		if old := slices.Contains(a.Required, k); old != required {
+			c.field(in, p, "required", old, required)
+		}
+		c.jsonschema(in, p, x, b.Properties[k])
+	}
+	for _, k := range sortedKeys(a.Properties) {
+		if _, ok := b.Properties[k]; !ok {
+			c.add(ActionRemoved, in, path+"."+k, slices.Contains(a.Required, k), a.Properties[k], nil)
+		}
+	}
+
+	c.jsonschema(in, path+"[]", itemsSchema(a), itemsSchema(b))
+	c.compositions(in, path, "allOf", a.AllOf, b.AllOf)
+	c.compositions(in, path, "anyOf", a.AnyOf, b.AnyOf)
+	c.compositions(in, path, "oneOf", a.OneOf, b.OneOf)
+}
+
+func (c *changes) compositions(in, path, keyword string, a, b []*jsonschema.Schema) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y *jsonschema.Schema
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		c.jsonschema(in, fmt.Sprintf("%s.%s[%d]", path, keyword, i), x, y)
+	}
+}
+
+func compareDefinitionSchemas(refs *refs, a, b spec.Schemas) []*ItemDiff {
+	list := make([]*ItemDiff, 0)
+	pairs := matchDefinitions(len(a), len(b),
+		func(i, j int) bool { return a[i].ID != 0 && a[i].ID == b[j].ID },
+		func(i, j int) bool { return a[i].Name == b[j].Name },
+	)
+	matched := make(map[int]bool)
+	for j, v := range b {
+		i, ok := pairs[j]
+		item := &ItemDiff{ID: v.ID, Title: v.Name}
+		if !ok {
+			item.Action = ActionAdded
+			list = append(list, item)
+			continue
+		}
+		matched[i] = true
+		x := a[i]
+		c := refs.changes()
+		if x.Name != v.Name {
+			c.field("", "name", "name", x.Name, v.Name)
+		}
+		if x.Description != v.Description {
+			c.field("", "description", "description", x.Description, v.Description)
+		}
+		c.jsonschema("", "schema", x.Schema, v.Schema)
+		if len(c.list) > 0 {
+			item.Action = ActionChanged
+			item.Changes = c.list
+			list = append(list, item)
+		}
+	}
+	for i, v := range a {
+		if !matched[i] {
+			list = append(list, &ItemDiff{Action: ActionRemoved, ID: v.ID, Title: v.Name})
+		}
+	}
+	return list
+}
+
+// matchDefinitions 先使用id匹配 剩下的使用名称匹配 返回新版本下标 => 旧版本下标
+// 每个定义只匹配一次 导入的文件中定义的id和项目中不同 只能按名称匹配
+func matchDefinitions(na, nb int, sameID, sameName func(i, j int) bool) map[int]int {
+	pairs := make(map[int]int)
+	used := make(map[int]bool)
+	for _, same := range []func(i, j int) bool{sameID, sameName} {
+		for j := 0; j < nb; j++ {
+			if _, ok := pairs[j]; ok {
+				continue
+			}
+			for i := 0; i < na; i++ {
+				if !used[i] && same(i, j) {
+					pairs[j] = i
+					used[i] = true
+					break
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+func compareDefinitionResponses(refs *refs, a, b spec.HTTPResponseDefines) []*ItemDiff {
+	list := make([]*ItemDiff, 0)
+	pairs := matchDefinitions(len(a), len(b),
+		func(i, j int) bool { return a[i].ID != 0 && a[i].ID == b[j].ID },
+		func(i, j int) bool { return a[i].Name == b[j].Name },
+	)
+	matched := make(map[int]bool)
+	for j := range b {
+		v := &b[j]
+		i, ok := pairs[j]
+		item := &ItemDiff{ID: v.ID, Title: v.Name}
+		if !ok {
+			item.Action = ActionAdded
+			list = append(list, item)
+			continue
+		}
+		matched[i] = true
+		c := refs.changes()
+		c.response("response", "response", &a[i], v)
+		if len(c.list) > 0 {
+			item.Action = ActionChanged
+			item.Changes = c.list
+			list = append(list, item)
+		}
+	}
+	for i := range a {
+		if !matched[i] {
+			list = append(list, &ItemDiff{Action: ActionRemoved, ID: a[i].ID, Title: a[i].Name})
+		}
+	}
+	return list
+}
+
+func compareGlobalParameters(refs *refs, a, b spec.HTTPParameters) []*ItemDiff {
+	list := make([]*ItemDiff, 0)
+	am, bm := a.Map(), b.Map()
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		olds, news := am[in], bm[in]
+		for _, v := range news {
+			item := &ItemDiff{ID: v.ID, Title: v.Name, In: in}
+			x := olds.Lookup(v.Name)
+			if x == nil {
+				item.Action = ActionAdded
+				list = append(list, item)
+				continue
+			}
+			c := refs.changes()
+			c.parameter("request", "parameter", x, v)
+			if len(c.list) > 0 {
+				item.Action = ActionChanged
+				item.Changes = c.list
+				list = append(list, item)
+			}
+		}
+		for _, v := range olds {
+			if news.Lookup(v.Name) == nil {
+				list = append(list, &ItemDiff{Action: ActionRemoved, ID: v.ID, Title: v.Name, In: in})
+			}
+		}
+	}
+	return list
+}
+
+func itemsSchema(s *jsonschema.Schema) *jsonschema.Schema {
+	if s.Items == nil || s.Items.IsBool() {
+		return nil
+	}
+	return s.Items.Value()
+}
+
+func refString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
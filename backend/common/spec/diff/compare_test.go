@@ -0,0 +1,155 @@
+package diff
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+func compareSpec(t *testing.T, globals, schemas, collections string) *spec.Spec {
+	s, err := spec.ParseJSON([]byte(`{
+		"apicat": "2.0",
+		"info": {"title": "t", "version": "1.0.0"},
+		"globals": {"parameters": ` + globals + `},
+		"definitions": {"schemas": ` + schemas + `, "parameters": [], "responses": []},
+		"collections": ` + collections + `
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func httpCollection(id, path, method, request, responses string) string {
+	return `{"id": ` + id + `, "title": "api` + id + `", "type": "http", "content": [
+		{"type": "apicat-http-url", "attrs": {"path": "` + path + `", "method": "` + method + `"}},
+		{"type": "apicat-http-request", "attrs": ` + request + `},
+		{"type": "apicat-http-response", "attrs": {"list": ` + responses + `}}
+	]}`
+}
+
+func TestCompare(t *testing.T) {
+	user := `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}, "role": {"type": "string", "enum": ["admin", "user"]}}}`
+	a := compareSpec(t,
+		`{"header": [{"id": 1, "name": "X-Token", "required": true, "schema": {"type": "string"}}]}`,
+		`[{"id": 1, "name": "User", "schema": `+user+`}]`,
+		`[`+httpCollection("1", "/users/{id}", "get",
+			`{"parameters": {"path": [{"name": "id", "required": true, "schema": {"type": "integer"}}]}}`,
+			`[{"code": 200, "content": {"application/json": {"schema": {"$ref": "#/definitions/schemas/1"}}}}, {"code": 404}]`)+`,`+
+			httpCollection("2", "/old", "get", `{}`, `[]`)+`]`,
+	)
+	b := compareSpec(t,
+		`{"header": [{"id": 1, "name": "X-Token", "required": false, "schema": {"type": "string"}}]}`,
+		`[{"id": 1, "name": "User", "schema": {"type": "object", "required": ["id", "name"], "properties": {"id": {"type": "string"}, "name": {"type": "string"}, "role": {"type": "string", "enum": ["admin"]}}}}]`,
+		`[`+httpCollection("1", "/v2/users/{userId}", "get",
+			`{"parameters": {"path": [{"name": "userId", "required": true, "schema": {"type": "integer"}}]}}`,
+			`[{"code": 200, "content": {"application/json": {"schema": {"$ref": "#/definitions/schemas/1"}}}}]`)+`,`+
+			httpCollection("3", "/new", "post", `{}`, `[]`)+`]`,
+	)
+
+	r := Compare(a, b)
+	if r.Summary != (Summary{Added: 1, Removed: 1, Changed: 3}) {
+		t.Fatalf("unexpected summary %+v", r.Summary)
+	}
+
+	changes := map[string]*Change{}
+	for _, list := range [][]*ItemDiff{r.Collections, r.Schemas, r.Parameters} {
+		for _, item := range list {
+			for _, c := range item.Changes {
+				changes[c.Action+" "+c.Path+" "+c.Field] = c
+			}
+		}
+	}
+	for _, k := range []string{
+		"changed url path",
+		"removed request.path.id ",
+		"added request.path.userId ",
+		"removed responses.404 ",
+		"changed schema.id type",
+		"added schema.name ",
+		"changed schema.role enum",
+		"changed parameter required",
+	} {
+		if _, ok := changes[k]; !ok {
+			t.Errorf("missing change %q", k)
+		}
+	}
+	if c := changes["added schema.name "]; c != nil && !c.Required {
+		t.Error("added required property should be marked required")
+	}
+	if c := changes["changed url path"]; c != nil && (c.Old != "/users/{id}" || c.New != "/v2/users/{userId}") {
+		t.Errorf("unexpected path change %+v", c)
+	}
+
+	if r := Compare(a, a); !r.Empty() {
+		t.Errorf("expected no difference %+v", r.Summary)
+	}
+}
+
+// TestCompareByName 导入的文件中定义的id和项目中不同 使用名称匹配
+func TestCompareByName(t *testing.T) {
+	res := `[{"code": 200, "content": {"application/json": {"schema": {"$ref": "#/definitions/schemas/%s"}}}}]`
+	a := compareSpec(t, `{}`,
+		`[{"id": 1, "name": "User", "schema": {"type": "object", "properties": {"pet": {"$ref": "#/definitions/schemas/2"}}}}, {"id": 2, "name": "Pet", "schema": {"type": "object"}}]`,
+		`[`+httpCollection("1", "/users", "get", `{}`, fmt.Sprintf(res, "1"))+`]`)
+	b := compareSpec(t, `{}`,
+		`[{"id": 9002, "name": "Pet", "schema": {"type": "object"}}, {"id": 9001, "name": "User", "schema": {"type": "object", "properties": {"pet": {"$ref": "#/definitions/schemas/9002"}}}}]`,
+		`[`+httpCollection("1", "/users", "get", `{}`, fmt.Sprintf(res, "9001"))+`]`)
+	if r := Compare(a, b); !r.Empty() {
+		t.Fatalf("expected no difference %+v %+v", r.Collections, r.Schemas)
+	}
+
+	// 引用了另一个定义
+	b = compareSpec(t, `{}`,
+		`[{"id": 9002, "name": "Pet", "schema": {"type": "object"}}, {"id": 9001, "name": "User", "schema": {"type": "object", "properties": {"pet": {"$ref": "#/definitions/schemas/9002"}}}}]`,
+		`[`+httpCollection("1", "/users", "get", `{}`, fmt.Sprintf(res, "9002"))+`]`)
+	r := Compare(a, b)
+	if len(r.Schemas) != 0 || len(r.Collections) != 1 || len(r.Collections[0].Changes) != 1 || r.Collections[0].Changes[0].Field != "$ref" {
+		t.Fatalf("expected changed $ref %+v", r.Collections)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	a := compareSpec(t, `{}`, `[]`, `[`+httpCollection("1", "/users/{id}", "post",
+		`{"parameters": {"path": [{"name": "id", "required": true, "schema": {"type": "integer"}}], "query": [{"name": "q", "schema": {"type": "string", "maxLength": 20}}]},
+		  "content": {"application/json": {"schema": {"type": "object", "properties": {"role": {"type": "string", "enum": ["admin", "user"]}}}}}}`,
+		`[{"code": 200, "content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "integer"}, "name": {"type": "string", "description": "a"}}}}}}]`)+`,`+
+		httpCollection("2", "/old", "get", `{}`, `[]`)+`]`)
+	b := compareSpec(t, `{}`, `[]`, `[`+httpCollection("1", "/users/{userId}", "post",
+		`{"parameters": {"path": [{"name": "userId", "required": true, "schema": {"type": "integer"}}], "query": [{"name": "q", "schema": {"type": "string", "maxLength": 10}}, {"name": "page", "schema": {"type": "integer"}}]},
+		  "content": {"application/json": {"schema": {"type": "object", "required": ["age"], "properties": {"role": {"type": "string", "enum": ["admin"]}, "age": {"type": "integer"}}}}}}`,
+		`[{"code": 200, "content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string", "description": "b"}, "tags": {"type": "array"}}}}}}]`)+`]`)
+
+	r := Classify(Compare(a, b))
+	levels := map[string]string{}
+	for _, item := range r.Collections {
+		levels[item.Action+" "+item.Path] = item.Level
+		for _, c := range item.Changes {
+			if c.Reason == "" {
+				t.Errorf("missing reason for %+v", c)
+			}
+			levels[c.Action+" "+c.Path+" "+c.Field] = c.Level
+		}
+	}
+	for k, v := range map[string]string{
+		"removed /old":                                                 LevelBreaking,
+		"changed url path":                                             LevelBreaking,
+		"removed request.path.id ":                                     LevelBreaking,
+		"added request.path.userId ":                                   LevelBreaking,
+		"changed request.query.q maxLength":                            LevelBreaking,
+		"added request.query.page ":                                    LevelNonBreaking,
+		"added request.body.application/json.age ":                     LevelBreaking,
+		"changed request.body.application/json.role enum":              LevelBreaking,
+		"removed responses.200.body.application/json.id ":              LevelBreaking,
+		"added responses.200.body.application/json.tags ":              LevelNonBreaking,
+		"changed responses.200.body.application/json.name description": LevelInfo,
+	} {
+		if levels[k] != v {
+			t.Errorf("%s: expected %s, got %q", k, v, levels[k])
+		}
+	}
+	if r.Summary.Breaking != 2 {
+		t.Errorf("unexpected breaking count %d", r.Summary.Breaking)
+	}
+}
@@ -1,7 +1,7 @@
 package diff
 
 import (
-	"fmt"
+	"reflect"
 
 	"github.com/apicat/apicat/backend/common/spec"
 	"github.com/apicat/apicat/backend/common/spec/jsonschema"
@@ -22,16 +22,19 @@ var (
 
 // Diff 比较两个接口的差异
 // source,target 是完整的spec对象 因为需要解析schema等依赖
-// spec.Collections 里面只能有一个接口
+// spec.Collections 里面只能有一个接口 否则返回nil 比较整个项目使用 Compare
 // 返回对比后的两个接口 其中只有最新的那个 也就是target里边会通过x-apicat-diff标记是否有差异
 // 差异并不包含排序
 func Diff(source, target *spec.Spec, del bool) (*spec.CollectItem, *spec.CollectItem) {
 	if len(source.Collections) != 1 || len(target.Collections) != 1 {
-		panic("source,target Collections length error")
+		return nil, nil
 	}
 	a, au := getMapOne(source.CollectionsMap(true, 1))
 	b, bu := getMapOne(target.CollectionsMap(true, 1))
-	if au.Method != bu.Method || au.Path != bu.Method {
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	if au.Method != bu.Method || au.Path != bu.Path {
 		bu.XDiff = &diffUpdate
 	}
 	equalRequest(&a.HTTPRequestNode, &b.HTTPRequestNode, del)
@@ -166,7 +169,6 @@ func equalResponse(a, b spec.HTTPResponses, del bool) spec.HTTPResponses {
 func equalSchema(a, b *spec.Schema, del bool) {
 	switch {
 	case a.Name != b.Name:
-		fmt.Println("----->>>>>")
 	case a.Description != b.Description:
 	case a.Required != b.Required:
 	default:
@@ -177,17 +179,21 @@ func equalSchema(a, b *spec.Schema, del bool) {
 }
 
 func equalJsonSchema(a, b *jsonschema.Schema, del bool) {
-	if !slices.Equal(a.Type.Value(), b.Type.Value()) {
-		b.XDiff = &diffUpdate
+	if a == nil || b == nil {
+		if a != b && b != nil {
+			b.XDiff = &diffUpdate
+		}
 		return
 	}
-	at := a.Type.Value()[0]
-	bt := b.Type.Value()[0]
-	if at != bt {
+	if !slices.Equal(a.Type.Value(), b.Type.Value()) {
 		b.XDiff = &diffUpdate
 		return
 	}
 	equalJsonSchemaNormal(a, b)
+	if len(b.Type.Value()) == 0 {
+		return
+	}
+	bt := b.Type.Value()[0]
 	switch bt {
 	case "object":
 		for k, v := range b.Properties {
@@ -211,14 +217,14 @@ func equalJsonSchema(a, b *jsonschema.Schema, del bool) {
 			}
 		}
 	case "array":
-		equalJsonSchema(a.Items.Value(), b.Items.Value(), del)
+		equalJsonSchema(itemsSchema(a), itemsSchema(b), del)
 	}
 
 }
 
 func equalJsonSchemaNormal(a, b *jsonschema.Schema) bool {
 	switch {
-	case a.Default != b.Default:
+	case !reflect.DeepEqual(a.Default, b.Default):
 	case a.Description != b.Description:
 	case a.XMock != b.XMock:
 	// case a.Format != b.Format:
@@ -0,0 +1,104 @@
+package spec
+
+import (
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// GraphQL操作的类型
+const (
+	GraphQLQuery        = "query"
+	GraphQLMutation     = "mutation"
+	GraphQLSubscription = "subscription"
+)
+
+// GraphQLOperationNode graphql的操作 对应根类型上的一个字段
+type GraphQLOperationNode struct {
+	// Operation query mutation或subscription
+	Operation string `json:"operation"`
+	// Field 根类型上的字段名
+	Field string `json:"field"`
+	// Path graphql服务的地址 默认为/graphql
+	Path string `json:"path"`
+}
+
+func (GraphQLOperationNode) Name() string {
+	return "apicat-graphql-operation"
+}
+
+// GraphQLQueryNode 请求时使用的查询文档
+type GraphQLQueryNode struct {
+	Query string `json:"query"`
+}
+
+func (GraphQLQueryNode) Name() string {
+	return "apicat-graphql-query"
+}
+
+// GraphQLVariablesNode 查询文档中变量的结构
+type GraphQLVariablesNode struct {
+	Schema *jsonschema.Schema `json:"schema"`
+}
+
+func (GraphQLVariablesNode) Name() string {
+	return "apicat-graphql-variables"
+}
+
+// GraphQLResponseNode 响应中data的结构 属性名为操作的字段名
+type GraphQLResponseNode struct {
+	Schema *jsonschema.Schema `json:"schema"`
+}
+
+func (GraphQLResponseNode) Name() string {
+	return "apicat-graphql-response"
+}
+
+// GraphQLPart graphql集合的定义
+type GraphQLPart struct {
+	Title     string
+	ID        int64
+	Dir       string
+	Operation GraphQLOperationNode
+	Query     string
+	Variables *jsonschema.Schema
+	Response  *jsonschema.Schema
+}
+
+// GraphQLOperations 按照集合的顺序返回所有的graphql操作
+// expend 是否展开变量和响应中的模型引用
+func (s *Spec) GraphQLOperations(expend bool, refexpendMaxCount int) []GraphQLPart {
+	list := make([]GraphQLPart, 0)
+	s.WalkCollections(func(v *CollectItem, p []string) bool {
+		if v.Type != ContentItemTypeGraphQL {
+			return true
+		}
+		part := GraphQLPart{
+			Title: v.Title,
+			ID:    v.ID,
+			Dir:   strings.Join(p, "/"),
+		}
+		for _, item := range v.Content {
+			switch nx := item.Node.(type) {
+			case *HTTPNode[GraphQLOperationNode]:
+				part.Operation = nx.Attrs
+			case *HTTPNode[GraphQLQueryNode]:
+				part.Query = nx.Attrs.Query
+			case *HTTPNode[GraphQLVariablesNode]:
+				part.Variables = nx.Attrs.Schema
+			case *HTTPNode[GraphQLResponseNode]:
+				part.Response = nx.Attrs.Schema
+			}
+		}
+		if part.Operation.Path == "" {
+			part.Operation.Path = "/graphql"
+		}
+		if expend {
+			s.expendRef(part.Variables, refexpendMaxCount)
+			s.expendRef(part.Response, refexpendMaxCount)
+		}
+		list = append(list, part)
+		return true
+	})
+	return list
+}
@@ -0,0 +1,135 @@
+package spec
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// gRPC方法的流模式
+const (
+	GRPCUnary           = "unary"
+	GRPCClientStreaming = "client streaming"
+	GRPCServerStreaming = "server streaming"
+	GRPCBidiStreaming   = "bidirectional streaming"
+)
+
+// GRPCMethodNode proto文件中service的一个rpc
+type GRPCMethodNode struct {
+	Package string `json:"package,omitempty"`
+	Service string `json:"service"`
+	Method  string `json:"method"`
+	// RequestType ResponseType proto中的消息名称
+	RequestType     string `json:"requestType"`
+	ResponseType    string `json:"responseType"`
+	ClientStreaming bool   `json:"clientStreaming,omitempty"`
+	ServerStreaming bool   `json:"serverStreaming,omitempty"`
+	// HTTPMethod HTTPPath google.api.http注解中的转码规则 没有注解时为空
+	HTTPMethod string `json:"httpMethod,omitempty"`
+	HTTPPath   string `json:"httpPath,omitempty"`
+	// HTTPBody 注解中的body 为*时整个请求消息作为请求体
+	HTTPBody string `json:"httpBody,omitempty"`
+}
+
+func (GRPCMethodNode) Name() string {
+	return "apicat-grpc-method"
+}
+
+// FullService 包含包名的服务名称
+func (g GRPCMethodNode) FullService() string {
+	if g.Package == "" {
+		return g.Service
+	}
+	return g.Package + "." + g.Service
+}
+
+// FullMethod gRPC请求的路径 例如/helloworld.Greeter/SayHello
+func (g GRPCMethodNode) FullMethod() string {
+	return "/" + g.FullService() + "/" + g.Method
+}
+
+// StreamingMode unary client streaming server streaming或bidirectional streaming
+func (g GRPCMethodNode) StreamingMode() string {
+	switch {
+	case g.ClientStreaming && g.ServerStreaming:
+		return GRPCBidiStreaming
+	case g.ClientStreaming:
+		return GRPCClientStreaming
+	case g.ServerStreaming:
+		return GRPCServerStreaming
+	}
+	return GRPCUnary
+}
+
+// Transcoding 转码为http时的方法和路径 没有注解时使用POST FullMethod
+func (g GRPCMethodNode) Transcoding() (string, string) {
+	if g.HTTPMethod != "" && g.HTTPPath != "" {
+		return strings.ToUpper(g.HTTPMethod), g.HTTPPath
+	}
+	return http.MethodPost, g.FullMethod()
+}
+
+// GRPCRequestNode 请求消息的结构
+type GRPCRequestNode struct {
+	Schema *jsonschema.Schema `json:"schema"`
+}
+
+func (GRPCRequestNode) Name() string {
+	return "apicat-grpc-request"
+}
+
+// GRPCResponseNode 响应消息的结构
+type GRPCResponseNode struct {
+	Schema *jsonschema.Schema `json:"schema"`
+}
+
+func (GRPCResponseNode) Name() string {
+	return "apicat-grpc-response"
+}
+
+// GRPCPart grpc集合的定义
+type GRPCPart struct {
+	Title    string
+	ID       int64
+	Dir      string
+	Doc      Document
+	Method   GRPCMethodNode
+	Request  *jsonschema.Schema
+	Response *jsonschema.Schema
+}
+
+// GRPCMethods 按照集合的顺序返回所有的gRPC方法
+// expend 是否展开请求和响应中的模型引用
+func (s *Spec) GRPCMethods(expend bool, refexpendMaxCount int) []GRPCPart {
+	list := make([]GRPCPart, 0)
+	s.WalkCollections(func(v *CollectItem, p []string) bool {
+		if v.Type != ContentItemTypeGRPC {
+			return true
+		}
+		part := GRPCPart{
+			Title: v.Title,
+			ID:    v.ID,
+			Dir:   strings.Join(p, "/"),
+		}
+		for _, item := range v.Content {
+			switch nx := item.Node.(type) {
+			case *DocNode:
+				part.Doc.Items = append(part.Doc.Items, nx)
+			case *HTTPNode[GRPCMethodNode]:
+				part.Method = nx.Attrs
+			case *HTTPNode[GRPCRequestNode]:
+				part.Request = nx.Attrs.Schema
+			case *HTTPNode[GRPCResponseNode]:
+				part.Response = nx.Attrs.Schema
+			}
+		}
+		if expend {
+			s.expendRef(part.Request, refexpendMaxCount)
+			s.expendRef(part.Response, refexpendMaxCount)
+		}
+		list = append(list, part)
+		return true
+	})
+	return list
+}
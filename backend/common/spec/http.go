@@ -84,10 +84,21 @@ func (HTTPURLNode) Name() string {
 
 type HTTPBody map[string]*Schema
 
+// Required 任意一种类型的请求体标记为必须时 请求体为必须
+func (b HTTPBody) Required() bool {
+	for _, v := range b {
+		if v != nil && v.Required {
+			return true
+		}
+	}
+	return false
+}
+
 type HTTPRequestNode struct {
-	GlobalExcepts map[string][]int64 `json:"globalExcepts,omitempty"`
-	Parameters    HTTPParameters     `json:"parameters,omitempty"`
-	Content       HTTPBody           `json:"content,omitempty"`
+	GlobalExcepts map[string][]int64    `json:"globalExcepts,omitempty"`
+	Parameters    HTTPParameters        `json:"parameters,omitempty"`
+	Content       HTTPBody              `json:"content,omitempty"`
+	Security      []SecurityRequirement `json:"security,omitempty"`
 }
 
 func (HTTPRequestNode) Name() string {
@@ -102,6 +113,43 @@ func (HTTPResponsesNode) Name() string {
 	return "apicat-http-response"
 }
 
+// HTTPScriptNode 接口请求前后执行的javascript脚本
+type HTTPScriptNode struct {
+	PreRequest   string `json:"preRequest,omitempty"`
+	PostResponse string `json:"postResponse,omitempty"`
+}
+
+func (HTTPScriptNode) Name() string {
+	return "apicat-http-script"
+}
+
+func (h HTTPScriptNode) Empty() bool {
+	return h.PreRequest == "" && h.PostResponse == ""
+}
+
+// HTTPWebhookNode openapi 3.1 的webhook 由服务端主动发起的请求
+type HTTPWebhookNode struct {
+	Event  string `json:"event"`
+	Method string `json:"method"`
+}
+
+func (HTTPWebhookNode) Name() string {
+	return "apicat-http-webhook"
+}
+
+// HTTPCallbackNode openapi 的callback 调用接口后服务端向表达式对应的地址发起的请求
+type HTTPCallbackNode struct {
+	Event      string `json:"event"`
+	Expression string `json:"expression"`
+	Method     string `json:"method"`
+	// 回调所属的接口
+	Operation HTTPURLNode `json:"operation"`
+}
+
+func (HTTPCallbackNode) Name() string {
+	return "apicat-http-callback"
+}
+
 type HTTPResponse struct {
 	Code  int     `json:"code"`
 	XDiff *string `json:"x-apicat-diff,omitempty"`
@@ -119,15 +167,27 @@ func (h HTTPResponses) Map() map[int]HTTPResponseDefine {
 }
 
 type HTTPResponseDefine struct {
-	ID          int64    `json:"id,omitempty"`
-	Name        string   `json:"name,omitempty"`
-	Description string   `json:"description,omitempty"`
-	Content     HTTPBody `json:"content,omitempty"`
-	Header      Schemas  `json:"header,omitempty"`
-	Reference   *string  `json:"$ref,omitempty"`
-	XDiff       *string  `json:"x-apicat-diff,omitempty"`
+	ID          int64     `json:"id,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Content     HTTPBody  `json:"content,omitempty"`
+	Header      Schemas   `json:"header,omitempty"`
+	Links       HTTPLinks `json:"links,omitempty"`
+	Reference   *string   `json:"$ref,omitempty"`
+	XDiff       *string   `json:"x-apicat-diff,omitempty"`
+}
+
+// HTTPLink 描述如何使用响应中的值调用其他接口
+type HTTPLink struct {
+	OperationID  string            `json:"operationId,omitempty"`
+	OperationRef string            `json:"operationRef,omitempty"`
+	Parameters   map[string]string `json:"parameters,omitempty"`
+	RequestBody  string            `json:"requestBody,omitempty"`
+	Description  string            `json:"description,omitempty"`
 }
 
+type HTTPLinks map[string]*HTTPLink
+
 func (h *HTTPResponseDefine) Ref() bool { return h.Reference != nil }
 
 type HTTPResponseDefines []HTTPResponseDefine
@@ -155,7 +215,8 @@ type HTTPPart struct {
 	ID    int64
 	Dir   string
 	HTTPRequestNode
-	Responses HTTPResponses `json:"responses,omitempty"`
+	Responses HTTPResponses  `json:"responses,omitempty"`
+	Script    HTTPScriptNode `json:"script,omitempty"`
 }
 
 func (h *HTTPPart) ToCollectItem(urlnode HTTPURLNode) *CollectItem {
@@ -167,6 +228,9 @@ func (h *HTTPPart) ToCollectItem(urlnode HTTPURLNode) *CollectItem {
 	content = append(content, MuseCreateNodeProxy(WarpHTTPNode(urlnode)))
 	content = append(content, MuseCreateNodeProxy(WarpHTTPNode(h.HTTPRequestNode)))
 	content = append(content, MuseCreateNodeProxy(WarpHTTPNode(&HTTPResponsesNode{List: h.Responses})))
+	if !h.Script.Empty() {
+		content = append(content, MuseCreateNodeProxy(WarpHTTPNode(h.Script)))
+	}
 	item.Content = content
 	return item
 }
@@ -0,0 +1,68 @@
+package jsonschema
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// CoerceString 将字符串按照schema类型转换 用于校验参数和响应头
+// 转换失败时保留原字符串 由校验给出类型错误
+func CoerceString(v string, s *Schema) any {
+	if s == nil || s.Type == nil {
+		return v
+	}
+	for _, t := range s.Type.Value() {
+		switch t {
+		case "integer":
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n
+			}
+		case "number":
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				return n
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		}
+	}
+	return v
+}
+
+// CoerceStrings 转换多值参数 数组类型的每个值还可以用逗号分隔
+// 非数组类型只取第一个值
+func CoerceStrings(values []string, s *Schema) any {
+	if s == nil || s.Type == nil || !slices.Contains(s.Type.Value(), "array") {
+		if len(values) == 0 {
+			return nil
+		}
+		return CoerceString(values[0], s)
+	}
+	var items *Schema
+	if s.Items != nil && !s.Items.IsBool() {
+		items = s.Items.Value()
+	}
+	list := make([]any, 0, len(values))
+	for _, v := range values {
+		for _, x := range strings.Split(v, ",") {
+			list = append(list, CoerceString(x, items))
+		}
+	}
+	return list
+}
+
+// AsValidationErrors 取出校验错误 其他错误作为一个没有路径的校验错误返回
+func AsValidationErrors(err error) ValidationErrors {
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return ValidationErrors{{Message: err.Error()}}
+}
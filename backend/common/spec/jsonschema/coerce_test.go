@@ -0,0 +1,56 @@
+package jsonschema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCoerceString(t *testing.T) {
+	cases := []struct {
+		schema string
+		value  string
+		expect any
+	}{
+		{`{"type":"integer"}`, "12", int64(12)},
+		{`{"type":"integer"}`, "1.5", "1.5"},
+		{`{"type":"number"}`, "1.5", 1.5},
+		{`{"type":"boolean"}`, "true", true},
+		{`{"type":"string"}`, "12", "12"},
+		{`{}`, "12", "12"},
+	}
+	for _, c := range cases {
+		got := CoerceString(c.value, mustSchema(t, c.schema))
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("%s %q: expected %#v, got %#v", c.schema, c.value, c.expect, got)
+		}
+	}
+	if got := CoerceString("12", nil); got != "12" {
+		t.Errorf("nil schema: expected string, got %#v", got)
+	}
+}
+
+func TestCoerceStrings(t *testing.T) {
+	s := mustSchema(t, `{"type":"array","items":{"type":"integer"}}`)
+	got := CoerceStrings([]string{"1,2", "3"}, s)
+	if expect := []any{int64(1), int64(2), int64(3)}; !reflect.DeepEqual(got, expect) {
+		t.Errorf("array: expected %#v, got %#v", expect, got)
+	}
+	if got := CoerceStrings([]string{"1", "2"}, mustSchema(t, `{"type":"integer"}`)); got != int64(1) {
+		t.Errorf("single: expected first value, got %#v", got)
+	}
+}
+
+func TestAsValidationErrors(t *testing.T) {
+	if AsValidationErrors(nil) != nil {
+		t.Error("nil error should return nil")
+	}
+	err := mustSchema(t, `{"type":"integer"}`).Validate("x")
+	if errs := AsValidationErrors(err); len(errs) != 1 || errs[0].Keyword != "type" {
+		t.Errorf("expected type error, got %v", errs)
+	}
+	errs := AsValidationErrors(errors.New("broken"))
+	if len(errs) != 1 || errs[0].Message != "broken" || errs[0].Path != "" {
+		t.Errorf("expected wrapped error, got %v", errs)
+	}
+}
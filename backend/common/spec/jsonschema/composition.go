@@ -0,0 +1,127 @@
+package jsonschema
+
+import (
+	"encoding/json"
+
+	"golang.org/x/exp/slices"
+)
+
+// Flatten 合并allOf 并且oneOf/anyOf只取第一个 返回新的schema
+// 用于datagen等不支持组合的场景 引用需要先展开
+func (s *Schema) Flatten() *Schema {
+	if s == nil {
+		return nil
+	}
+	out := s.clone()
+	out.flatten()
+	return out
+}
+
+// MergeAllOf 只合并allOf 返回新的schema
+func (s *Schema) MergeAllOf() *Schema {
+	if s == nil || len(s.AllOf) == 0 {
+		return s
+	}
+	out := s.clone()
+	list := out.AllOf
+	out.AllOf = nil
+	for _, v := range list {
+		out.merge(v.MergeAllOf())
+	}
+	return out
+}
+
+func (s *Schema) clone() *Schema {
+	b, _ := json.Marshal(s)
+	var out Schema
+	json.Unmarshal(b, &out)
+	return &out
+}
+
+func (s *Schema) flatten() {
+	if list := s.AllOf; len(list) > 0 {
+		s.AllOf = nil
+		for _, v := range list {
+			v.flatten()
+			s.merge(v)
+		}
+	}
+	for _, list := range [][]*Schema{s.OneOf, s.AnyOf} {
+		if len(list) > 0 && list[0] != nil {
+			list[0].flatten()
+			s.merge(list[0])
+		}
+	}
+	s.OneOf, s.AnyOf, s.Discriminator = nil, nil, nil
+
+	for _, v := range s.Properties {
+		if v != nil {
+			v.flatten()
+		}
+	}
+	if s.Items != nil && !s.Items.IsBool() && s.Items.Value() != nil {
+		s.Items.Value().flatten()
+	}
+	if s.AdditionalProperties != nil && !s.AdditionalProperties.IsBool() && s.AdditionalProperties.Value() != nil {
+		s.AdditionalProperties.Value().flatten()
+	}
+}
+
+// merge 将x合并到s 已有的值不覆盖 属性和必填字段取并集
+func (s *Schema) merge(x *Schema) {
+	if x == nil || x.Reference != nil {
+		return
+	}
+	if (s.Type == nil || len(s.Type.Value()) == 0) && x.Type != nil && len(x.Type.Value()) > 0 {
+		s.Type = CreateSliceOrOne(x.Type.Value()...)
+	}
+	if len(x.Properties) > 0 {
+		// 原来有属性但是没有排序时不再追加排序 避免排序和属性不一致
+		keepOrder := len(s.Properties) == 0 || len(s.XOrder) > 0
+		if s.Properties == nil {
+			s.Properties = make(map[string]*Schema)
+		}
+		names := x.XOrder
+		if len(names) == 0 {
+			for k := range x.Properties {
+				names = append(names, k)
+			}
+		}
+		for _, k := range names {
+			v, ok := x.Properties[k]
+			if _, exists := s.Properties[k]; !ok || exists {
+				continue
+			}
+			s.Properties[k] = v
+			if keepOrder {
+				s.XOrder = append(s.XOrder, k)
+			}
+		}
+	}
+	for _, k := range x.Required {
+		if !slices.Contains(s.Required, k) {
+			s.Required = append(s.Required, k)
+		}
+	}
+	if s.Items == nil {
+		s.Items = x.Items
+	}
+	if s.AdditionalProperties == nil {
+		s.AdditionalProperties = x.AdditionalProperties
+	}
+	if len(s.Enum) == 0 {
+		s.Enum = x.Enum
+	}
+	if s.Format == "" {
+		s.Format = x.Format
+	}
+	if s.XMock == "" {
+		s.XMock = x.XMock
+	}
+	if s.Description == "" {
+		s.Description = x.Description
+	}
+	if s.Example == nil {
+		s.Example = x.Example
+	}
+}
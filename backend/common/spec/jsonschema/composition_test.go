@@ -0,0 +1,38 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestFlatten(t *testing.T) {
+	s := mustSchema(t, `{
+		"type": "object",
+		"properties": {
+			"pet": {
+				"allOf": [
+					{"type": "object", "required": ["kind"], "properties": {"kind": {"type": "string"}}, "x-apicat-orders": ["kind"]},
+					{"type": "object", "required": ["lives"], "properties": {"lives": {"type": "integer"}}, "x-apicat-orders": ["lives"]}
+				]
+			},
+			"id": {"oneOf": [{"type": "integer"}, {"type": "string"}]}
+		}
+	}`)
+	out := s.Flatten()
+
+	pet := out.Properties["pet"]
+	if len(pet.AllOf) != 0 || pet.Type.Value()[0] != "object" {
+		t.Fatalf("allOf not merged: %+v", pet)
+	}
+	if !slices.Equal(pet.XOrder, []string{"kind", "lives"}) || !slices.Equal(pet.Required, []string{"kind", "lives"}) {
+		t.Fatalf("unexpected orders %v required %v", pet.XOrder, pet.Required)
+	}
+	if id := out.Properties["id"]; len(id.OneOf) != 0 || id.Type.Value()[0] != "integer" {
+		t.Fatalf("oneOf not flattened: %+v", id)
+	}
+	// 原schema不变
+	if len(s.Properties["pet"].AllOf) != 2 {
+		t.Fatal("source schema modified")
+	}
+}
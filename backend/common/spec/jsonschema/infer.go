@@ -0,0 +1,339 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// 字符串的不同值不超过enumMaxValues个 并且每个值平均出现两次以上时推断为枚举
+const (
+	enumMaxValues  = 5
+	enumMinSamples = 3
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Infer 根据一个或多个json示例推断schema 示例为json.Unmarshal或ParseSample得到的值
+// 对象的属性在所有示例中都出现时为必须 integer和number同时出现时为number
+// 出现null时设置nullable 其它类型冲突时type为多个类型
+// 字符串会推断常见的format 重复出现的少量取值推断为enum
+func Infer(samples ...any) *Schema {
+	n := &inferNode{}
+	for _, v := range samples {
+		n.add(v)
+	}
+	return n.schema()
+}
+
+// InferJSON 解析json示例后推断schema 属性的顺序和示例中出现的顺序一致
+func InferJSON(raws ...[]byte) (*Schema, error) {
+	samples := make([]any, 0, len(raws))
+	for _, raw := range raws {
+		v, err := ParseSample(raw)
+		if err != nil {
+			return nil, err
+		}
+		samples = append(samples, v)
+	}
+	return Infer(samples...), nil
+}
+
+// orderedObject 保留属性顺序的json对象
+type orderedObject struct {
+	keys   []string
+	values map[string]any
+}
+
+// ParseSample 解析json示例 和json.Unmarshal不同的是对象会保留属性的顺序 数字为json.Number
+// 返回值只用于Infer
+func ParseSample(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	v, err := parseValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid json: unexpected data after top-level value")
+	}
+	return v, nil
+}
+
+func parseValue(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch x := tok.(type) {
+	case json.Delim:
+		switch x {
+		case '{':
+			obj := &orderedObject{values: make(map[string]any)}
+			for dec.More() {
+				kt, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, _ := kt.(string)
+				v, err := parseValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				if _, ok := obj.values[key]; !ok {
+					obj.keys = append(obj.keys, key)
+				}
+				obj.values[key] = v
+			}
+			_, err := dec.Token()
+			return obj, err
+		case '[':
+			list := make([]any, 0)
+			for dec.More() {
+				v, err := parseValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			_, err := dec.Token()
+			return list, err
+		}
+		return nil, errors.New("invalid json")
+	}
+	return tok, nil
+}
+
+// inferNode 合并同一位置的所有示例值
+type inferNode struct {
+	types    []string
+	nullable bool
+	example  any
+	// 字符串的取值和出现次数 用于推断format和enum
+	strings    []string
+	stringSeen map[string]bool
+	stringNum  int
+	// 作为对象出现的次数 和每个属性出现的次数用于判断是否必须
+	objects    int
+	order      []string
+	properties map[string]*inferNode
+	propCount  map[string]int
+	items      *inferNode
+}
+
+func (n *inferNode) addType(t string) {
+	for i, v := range n.types {
+		switch {
+		case v == t:
+			return
+		case v == "integer" && t == "number":
+			n.types[i] = "number"
+			return
+		case v == "number" && t == "integer":
+			return
+		}
+	}
+	n.types = append(n.types, t)
+}
+
+func (n *inferNode) add(v any) {
+	switch x := v.(type) {
+	case nil:
+		n.nullable = true
+	case bool:
+		n.addType("boolean")
+		n.setExample(x)
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<53 {
+			n.addType("integer")
+		} else {
+			n.addType("number")
+		}
+		n.setExample(x)
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			n.addType("integer")
+			n.setExample(i)
+		} else {
+			n.addType("number")
+			f, _ := x.Float64()
+			n.setExample(f)
+		}
+	case string:
+		n.addType("string")
+		n.setExample(x)
+		n.addString(x)
+	case []any:
+		n.addType("array")
+		if n.items == nil {
+			n.items = &inferNode{}
+		}
+		for _, item := range x {
+			n.items.add(item)
+		}
+	case *orderedObject:
+		n.addObject(x.keys, x.values)
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		n.addObject(keys, x)
+	}
+}
+
+func (n *inferNode) addString(v string) {
+	if n.stringSeen == nil {
+		n.stringSeen = make(map[string]bool)
+	}
+	n.stringNum++
+	if !n.stringSeen[v] {
+		n.stringSeen[v] = true
+		n.strings = append(n.strings, v)
+	}
+}
+
+func (n *inferNode) addObject(keys []string, values map[string]any) {
+	n.addType("object")
+	if n.properties == nil {
+		n.properties = make(map[string]*inferNode)
+		n.propCount = make(map[string]int)
+	}
+	n.objects++
+	for _, k := range keys {
+		p, ok := n.properties[k]
+		if !ok {
+			p = &inferNode{}
+			n.properties[k] = p
+			n.order = append(n.order, k)
+		}
+		n.propCount[k]++
+		p.add(values[k])
+	}
+}
+
+func (n *inferNode) setExample(v any) {
+	if n.example == nil {
+		n.example = v
+	}
+}
+
+func (n *inferNode) schema() *Schema {
+	s := &Schema{}
+	switch len(n.types) {
+	case 0:
+		// 只出现过null
+		s.Type = CreateSliceOrOne("null")
+		return s
+	case 1:
+		s.Type = CreateSliceOrOne(n.types[0])
+	default:
+		s.Type = CreateSliceOrOne(n.types...)
+	}
+	if n.nullable {
+		nullable := true
+		s.Nullable = &nullable
+	}
+	if len(n.types) == 1 {
+		s.Example = n.example
+		if n.types[0] == "string" {
+			s.Format = n.format()
+			if s.Format == "" {
+				s.Enum = n.enum()
+			}
+		}
+	}
+
+	if n.properties != nil {
+		s.Properties = make(map[string]*Schema, len(n.properties))
+		s.XOrder = n.order
+		for _, k := range n.order {
+			s.Properties[k] = n.properties[k].schema()
+			if n.propCount[k] == n.objects {
+				s.Required = append(s.Required, k)
+			}
+		}
+	}
+	if n.items != nil {
+		// 空数组时items为空schema
+		items := &Schema{}
+		if len(n.items.types) > 0 || n.items.nullable {
+			items = n.items.schema()
+		}
+		s.Items = &ValueOrBoolean[*Schema]{}
+		s.Items.SetValue(items)
+	}
+	return s
+}
+
+// format 所有非空的值都符合同一个format时返回该format
+func (n *inferNode) format() string {
+	var format string
+	for _, v := range n.strings {
+		if v == "" {
+			continue
+		}
+		f := stringFormat(v)
+		if f == "" || (format != "" && f != format) {
+			return ""
+		}
+		format = f
+	}
+	return format
+}
+
+func stringFormat(v string) string {
+	switch {
+	case uuidPattern.MatchString(v):
+		return "uuid"
+	case isDateTime(v):
+		return "date-time"
+	case isDate(v):
+		return "date"
+	case isEmail(v):
+		return "email"
+	case isURI(v):
+		return "uri"
+	}
+	return ""
+}
+
+func isDateTime(v string) bool {
+	_, err := time.Parse(time.RFC3339, v)
+	return err == nil
+}
+
+func isDate(v string) bool {
+	_, err := time.Parse("2006-01-02", v)
+	return err == nil
+}
+
+func isEmail(v string) bool {
+	addr, err := mail.ParseAddress(v)
+	return err == nil && addr.Address == v
+}
+
+func isURI(v string) bool {
+	u, err := url.Parse(v)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func (n *inferNode) enum() []any {
+	distinct := len(n.strings)
+	if n.stringNum < enumMinSamples || distinct > enumMaxValues || distinct*2 > n.stringNum {
+		return nil
+	}
+	enum := make([]any, 0, distinct)
+	for _, v := range n.strings {
+		enum = append(enum, v)
+	}
+	return enum
+}
@@ -0,0 +1,76 @@
+package jsonschema
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestInfer(t *testing.T) {
+	s, err := InferJSON(
+		[]byte(`{"id": 1, "name": "cat", "tags": ["a"], "price": 1, "owner": {"id": 1}}`),
+		[]byte(`{"id": 2, "name": "dog", "tags": [], "price": 2.5, "owner": null, "extra": true}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Type.Value()[0] != "object" {
+		t.Fatalf("unexpected type %v", s.Type.Value())
+	}
+	if !slices.Equal(s.Required, []string{"id", "name", "tags", "price", "owner"}) {
+		t.Errorf("unexpected required %v", s.Required)
+	}
+	for name, typ := range map[string]string{"id": "integer", "name": "string", "tags": "array", "price": "number", "owner": "object", "extra": "boolean"} {
+		p := s.Properties[name]
+		if p == nil || p.Type.Value()[0] != typ {
+			t.Errorf("%s: expected %s got %+v", name, typ, p)
+		}
+	}
+	if tags := s.Properties["tags"]; tags.Items.Value().Type.Value()[0] != "string" {
+		t.Errorf("unexpected items %+v", tags.Items.Value())
+	}
+	if owner := s.Properties["owner"]; owner.Nullable == nil || !*owner.Nullable {
+		t.Error("owner should be nullable")
+	}
+	if s.Properties["name"].Example != "cat" {
+		t.Errorf("unexpected example %v", s.Properties["name"].Example)
+	}
+
+	mixed := Infer("a", float64(1))
+	if !slices.Equal(mixed.Type.Value(), []string{"string", "integer"}) {
+		t.Errorf("unexpected mixed type %v", mixed.Type.Value())
+	}
+	if err := s.Valid(); err != nil {
+		t.Error(err)
+	}
+	if !slices.Equal(s.XOrder, []string{"id", "name", "tags", "price", "owner", "extra"}) {
+		t.Errorf("unexpected orders %v", s.XOrder)
+	}
+}
+
+func TestInferFormatAndEnum(t *testing.T) {
+	s, err := InferJSON([]byte(`[
+		{"id": "6f1c2f0e-7b8a-4f5e-9d3c-2a1b0c9d8e7f", "email": "a@example.com", "created": "2023-09-01T10:00:00Z", "day": "2023-09-01", "site": "https://example.com/a", "status": "active", "name": "a"},
+		{"id": "0b6c2f0e-7b8a-4f5e-9d3c-2a1b0c9d8e7f", "email": "b@example.com", "created": "2023-09-02T10:00:00+08:00", "day": "2023-09-02", "site": "http://example.org", "status": "disabled", "name": "b"},
+		{"id": "1a6c2f0e-7b8a-4f5e-9d3c-2a1b0c9d8e7f", "email": "", "created": "2023-09-03T10:00:00Z", "day": "2023-09-03", "site": "https://example.net", "status": "active", "name": "c"},
+		{"id": "2a6c2f0e-7b8a-4f5e-9d3c-2a1b0c9d8e7f", "email": "d@example.com", "created": "2023-09-04T10:00:00Z", "day": "2023-09-04", "site": "https://example.com", "status": "active", "name": "d"}
+	]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := s.Items.Value()
+	for name, format := range map[string]string{"id": "uuid", "email": "email", "created": "date-time", "day": "date", "site": "uri", "status": "", "name": ""} {
+		if got := item.Properties[name].Format; got != format {
+			t.Errorf("%s: expected format %q got %q", name, format, got)
+		}
+	}
+	if enum := item.Properties["status"].Enum; !slices.Equal(enum, []any{"active", "disabled"}) {
+		t.Errorf("unexpected enum %v", enum)
+	}
+	if item.Properties["name"].Enum != nil {
+		t.Errorf("name should not be enum %v", item.Properties["name"].Enum)
+	}
+	if _, err := InferJSON([]byte(`{"a": 1} x`)); err == nil {
+		t.Error("expected error for trailing data")
+	}
+}
@@ -17,10 +17,11 @@ type Schema struct {
 	// 3.1 schema or bool
 	Items *ValueOrBoolean[*Schema] `json:"items,omitempty"`
 
-	// 先不支持 后期再说
-	// AllOf []*Schema `json:"AllOf,omitempty"`
-	// AnyOf []*Schema `json:"anyOf,omitempty"`
-	// OneOf []*Schema `json:"oneOf,omitempty"`
+	AllOf []*Schema `json:"allOf,omitempty"`
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+	OneOf []*Schema `json:"oneOf,omitempty"`
+	// 3.0+ 多态时用于区分oneOf/anyOf的具体类型
+	Discriminator *Discriminator `json:"discriminator,omitempty"`
 
 	// 3.0 bool 3.1 int
 	ExclusiveMaximum *ValueOrBoolean[int64] `json:"exclusiveMaximum,omitempty"`
@@ -56,8 +57,20 @@ type Schema struct {
 	Reference *string `json:"$ref,omitempty"`
 }
 
+// Discriminator 多态类型标识
+// Mapping 为属性值和对应schema引用
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
+}
+
 func (s *Schema) Ref() bool { return s != nil && s.Reference != nil }
 
+// Composition 是否使用了allOf/anyOf/oneOf组合
+func (s *Schema) Composition() bool {
+	return s != nil && (len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0)
+}
+
 var coreTypes = []string{
 	"string",
 	"integer",
@@ -68,14 +81,20 @@ var coreTypes = []string{
 	"null",
 }
 
-func (s *Schema) Validation(raw []byte) error {
-	return nil
-}
-
 func (s *Schema) Valid() error {
 	if s.Reference != nil {
 		return nil
 	}
+	for _, list := range [][]*Schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, v := range list {
+			if err := v.Valid(); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Type == nil {
+		return nil
+	}
 	for _, v := range s.Type.Value() {
 		if !slices.Contains(coreTypes, v) {
 			return fmt.Errorf("unkowan type %s", v)
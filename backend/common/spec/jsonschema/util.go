@@ -65,6 +65,9 @@ type SliceOrOneValue[T any] struct {
 }
 
 func (s *SliceOrOneValue[T]) Value() []T {
+	if s == nil {
+		return nil
+	}
 	return s.value
 }
 
@@ -0,0 +1,494 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+)
+
+// ValidationError 单个字段的校验错误
+// Path 为json pointer格式的字段路径 根节点为空字符串
+type ValidationError struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors 校验失败的所有字段
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, v := range e {
+		msgs[i] = v.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ValidateMode 校验数据的方向 用于处理readOnly和writeOnly
+type ValidateMode int
+
+const (
+	// ValidateAny 不区分方向 忽略readOnly和writeOnly
+	ValidateAny ValidateMode = iota
+	// ValidateRequest 校验请求数据 不允许出现readOnly字段
+	ValidateRequest
+	// ValidateResponse 校验响应数据 不允许出现writeOnly字段
+	ValidateResponse
+)
+
+// ValidateOption 校验选项
+type ValidateOption struct {
+	Mode ValidateMode
+	// Resolver 用于解析$ref 为nil或者解析不到时跳过引用的校验
+	Resolver func(ref string) *Schema
+}
+
+// 连续解析引用的最大次数 防止自身引用死循环
+const maxRefDepth = 32
+
+// Validation 校验json数据是否符合schema
+func (s *Schema) Validation(raw []byte) error {
+	return s.ValidationWithOption(raw, ValidateOption{})
+}
+
+// ValidationWithOption 使用指定选项校验json数据
+func (s *Schema) ValidationWithOption(raw []byte, opt ValidateOption) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return ValidationErrors{{Keyword: "json", Message: err.Error()}}
+	}
+	return s.ValidateWithOption(v, opt)
+}
+
+// Validate 校验已经解码的数据
+// v 可以是json.Unmarshal解出来的数据 数字类型可以是任意go数字类型或json.Number
+// 校验失败返回 ValidationErrors
+func (s *Schema) Validate(v any) error {
+	return s.ValidateWithOption(v, ValidateOption{})
+}
+
+// ValidateWithOption 使用指定选项校验已经解码的数据
+func (s *Schema) ValidateWithOption(v any, opt ValidateOption) error {
+	vd := &validator{opt: opt}
+	vd.validate(s, v, "", 0)
+	if len(vd.errs) == 0 {
+		return nil
+	}
+	return vd.errs
+}
+
+type validator struct {
+	opt  ValidateOption
+	errs ValidationErrors
+}
+
+func (vd *validator) addErr(path, keyword, format string, args ...any) {
+	vd.errs = append(vd.errs, &ValidationError{
+		Path:    path,
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve 解开引用 refDepth为当前节点已经连续解开的次数
+func (vd *validator) resolve(s *Schema, refDepth int) (*Schema, int) {
+	for s != nil && s.Reference != nil {
+		if vd.opt.Resolver == nil || refDepth >= maxRefDepth {
+			return nil, refDepth
+		}
+		s = vd.opt.Resolver(*s.Reference)
+		refDepth++
+	}
+	return s, refDepth
+}
+
+func (vd *validator) validate(s *Schema, v any, path string, refDepth int) {
+	s, refDepth = vd.resolve(s, refDepth)
+	if s == nil {
+		return
+	}
+
+	if v == nil && s.Nullable != nil && *s.Nullable {
+		return
+	}
+
+	if s.Type != nil && len(s.Type.Value()) > 0 {
+		types := s.Type.Value()
+		if !slices.ContainsFunc(types, func(t string) bool { return typeMatch(t, v) }) {
+			vd.addErr(path, "type", "expected %s, got %s", strings.Join(types, " or "), valueType(v))
+			return
+		}
+	}
+
+	if len(s.Enum) > 0 {
+		if !slices.ContainsFunc(s.Enum, func(e any) bool { return valueEqual(e, v) }) {
+			vd.addErr(path, "enum", "value must be one of %s", enumString(s.Enum))
+		}
+	}
+
+	if s.Not != nil && vd.valid(s.Not, v, path, refDepth) {
+		vd.addErr(path, "not", "must not be valid against the schema in not")
+	}
+
+	vd.validateComposition(s, v, path, refDepth)
+
+	switch x := v.(type) {
+	case string:
+		vd.validateString(s, x, path)
+	case map[string]any:
+		vd.validateObject(s, x, path)
+	case []any:
+		vd.validateArray(s, x, path)
+	default:
+		if n, ok := toFloat(v); ok {
+			vd.validateNumber(s, n, path)
+		}
+	}
+}
+
+// valid 使用独立的validator校验 只返回是否通过
+func (vd *validator) valid(s *Schema, v any, path string, refDepth int) bool {
+	sub := &validator{opt: vd.opt}
+	sub.validate(s, v, path, refDepth)
+	return len(sub.errs) == 0
+}
+
+func (vd *validator) validateComposition(s *Schema, v any, path string, refDepth int) {
+	for _, sub := range s.AllOf {
+		vd.validate(sub, v, path, refDepth)
+	}
+
+	// 有discriminator时直接使用映射的schema校验
+	if d := s.Discriminator; d != nil && len(d.Mapping) > 0 {
+		if obj, ok := v.(map[string]any); ok {
+			if name, ok := obj[d.PropertyName].(string); ok {
+				if ref, ok := d.Mapping[name]; ok {
+					vd.validate(&Schema{Reference: &ref}, v, path, refDepth)
+					return
+				}
+			}
+		}
+	}
+
+	if len(s.AnyOf) > 0 &&
+		!slices.ContainsFunc(s.AnyOf, func(sub *Schema) bool { return vd.valid(sub, v, path, refDepth) }) {
+		vd.addErr(path, "anyOf", "must match at least one schema in anyOf")
+	}
+
+	if len(s.OneOf) > 0 {
+		var n int
+		for _, sub := range s.OneOf {
+			if vd.valid(sub, v, path, refDepth) {
+				n++
+			}
+		}
+		switch {
+		case n == 0:
+			vd.addErr(path, "oneOf", "must match exactly one schema in oneOf")
+		case n > 1:
+			vd.addErr(path, "oneOf", "matches %d schemas in oneOf, expected exactly one", n)
+		}
+	}
+}
+
+func (vd *validator) validateString(s *Schema, x string, path string) {
+	n := int64(utf8.RuneCountInString(x))
+	if s.MinLength != nil && n < *s.MinLength {
+		vd.addErr(path, "minLength", "length must be >= %d", *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		vd.addErr(path, "maxLength", "length must be <= %d", *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(x) {
+			vd.addErr(path, "pattern", "does not match pattern %s", s.Pattern)
+		}
+	}
+	if s.Format != "" && !formatMatch(s.Format, x) {
+		vd.addErr(path, "format", "is not a valid %s", s.Format)
+	}
+}
+
+func (vd *validator) validateObject(s *Schema, x map[string]any, path string) {
+	for _, name := range s.Required {
+		if _, ok := x[name]; ok {
+			continue
+		}
+		// 请求中不需要传只读字段 响应中不会返回只写字段
+		if prop, _ := vd.resolve(s.Properties[name], 0); prop != nil {
+			if (vd.opt.Mode == ValidateRequest && prop.ReadOnly) ||
+				(vd.opt.Mode == ValidateResponse && prop.WriteOnly) {
+				continue
+			}
+		}
+		vd.addErr(path+"/"+escapePointer(name), "required", "is required")
+	}
+	for name, pv := range x {
+		p := path + "/" + escapePointer(name)
+		prop, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if s.AdditionalProperties.IsBool() {
+				if !s.AdditionalProperties.Bool() {
+					vd.addErr(p, "additionalProperties", "additional property is not allowed")
+				}
+				continue
+			}
+			vd.validate(s.AdditionalProperties.Value(), pv, p, 0)
+			continue
+		}
+		if rp, _ := vd.resolve(prop, 0); rp != nil {
+			if vd.opt.Mode == ValidateRequest && rp.ReadOnly {
+				vd.addErr(p, "readOnly", "is read only")
+				continue
+			}
+			if vd.opt.Mode == ValidateResponse && rp.WriteOnly {
+				vd.addErr(p, "writeOnly", "is write only")
+				continue
+			}
+		}
+		vd.validate(prop, pv, p, 0)
+	}
+}
+
+func (vd *validator) validateArray(s *Schema, x []any, path string) {
+	n := int64(len(x))
+	if s.MinItems != nil && n < *s.MinItems {
+		vd.addErr(path, "minItems", "must have at least %d items", *s.MinItems)
+	}
+	if s.MaxItems != nil && n > *s.MaxItems {
+		vd.addErr(path, "maxItems", "must have at most %d items", *s.MaxItems)
+	}
+	if s.Items == nil {
+		return
+	}
+	if s.Items.IsBool() {
+		if !s.Items.Bool() && len(x) > 0 {
+			vd.addErr(path, "items", "items are not allowed")
+		}
+		return
+	}
+	for i, item := range x {
+		vd.validate(s.Items.Value(), item, path+"/"+strconv.Itoa(i), 0)
+	}
+}
+
+func (vd *validator) validateNumber(s *Schema, n float64, path string) {
+	if s.MultipleOf != nil && *s.MultipleOf != 0 {
+		if math.Mod(n, float64(*s.MultipleOf)) != 0 {
+			vd.addErr(path, "multipleOf", "must be a multiple of %d", *s.MultipleOf)
+		}
+	}
+	// 3.0 exclusiveMinimum为bool 修饰minimum 3.1为数值
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum != nil && s.ExclusiveMinimum.IsBool() && s.ExclusiveMinimum.Bool() {
+			if n <= float64(*s.Minimum) {
+				vd.addErr(path, "exclusiveMinimum", "must be > %d", *s.Minimum)
+			}
+		} else if n < float64(*s.Minimum) {
+			vd.addErr(path, "minimum", "must be >= %d", *s.Minimum)
+		}
+	}
+	if s.ExclusiveMinimum != nil && !s.ExclusiveMinimum.IsBool() {
+		if n <= float64(s.ExclusiveMinimum.Value()) {
+			vd.addErr(path, "exclusiveMinimum", "must be > %d", s.ExclusiveMinimum.Value())
+		}
+	}
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum != nil && s.ExclusiveMaximum.IsBool() && s.ExclusiveMaximum.Bool() {
+			if n >= float64(*s.Maximum) {
+				vd.addErr(path, "exclusiveMaximum", "must be < %d", *s.Maximum)
+			}
+		} else if n > float64(*s.Maximum) {
+			vd.addErr(path, "maximum", "must be <= %d", *s.Maximum)
+		}
+	}
+	if s.ExclusiveMaximum != nil && !s.ExclusiveMaximum.IsBool() {
+		if n >= float64(s.ExclusiveMaximum.Value()) {
+			vd.addErr(path, "exclusiveMaximum", "must be < %d", s.ExclusiveMaximum.Value())
+		}
+	}
+}
+
+var (
+	uuidRegexp     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+)
+
+// formatMatch 校验常用的format 未知的format不做校验
+func formatMatch(format, v string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", v)
+		if err != nil {
+			_, err = time.Parse("15:04:05", v)
+		}
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(v)
+		return err == nil && addr.Address == v
+	case "uri", "url":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuidRegexp.MatchString(v)
+	case "ipv4":
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	case "ipv6":
+		ip := net.ParseIP(v)
+		return ip != nil && strings.Contains(v, ":")
+	case "hostname":
+		return len(v) <= 253 && hostnameRegexp.MatchString(v)
+	}
+	return true
+}
+
+func typeMatch(t string, v any) bool {
+	switch t {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		_, ok := toFloat(v)
+		return ok
+	case "integer":
+		n, ok := toFloat(v)
+		return ok && n == math.Trunc(n)
+	default:
+		// 非标准类型 如file 不做校验
+		return true
+	}
+}
+
+func valueType(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	}
+	if n, ok := toFloat(v); ok {
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	}
+	return reflect.TypeOf(v).String()
+}
+
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	case float64:
+		return x, true
+	case float32:
+		return float64(x), true
+	case int:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case uint:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	}
+	return 0, false
+}
+
+func valueEqual(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !valueEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !valueEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func enumString(list []any) string {
+	b, _ := json.Marshal(list)
+	return string(b)
+}
+
+// escapePointer 按照rfc6901转义json pointer中的字段名
+func escapePointer(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
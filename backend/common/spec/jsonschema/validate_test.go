@@ -0,0 +1,228 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func mustSchema(t *testing.T, raw string) *Schema {
+	t.Helper()
+	var s Schema
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		t.Fatal(err)
+	}
+	return &s
+}
+
+// checkErrorPaths 校验错误路径和关键字 expected为 path => keyword
+func checkErrorPaths(t *testing.T, name string, err error, expected map[string]string) {
+	t.Helper()
+	if len(expected) == 0 {
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+		return
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Errorf("%s: expected validation errors, got %v", name, err)
+		return
+	}
+	got := map[string]string{}
+	for _, v := range errs {
+		got[v.Path] = v.Keyword
+	}
+	for p, k := range expected {
+		if got[p] != k {
+			t.Errorf("%s: expected %s error at %q, got %v", name, k, p, err)
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("%s: expected %d errors, got %v", name, len(expected), err)
+	}
+}
+
+func TestValidation(t *testing.T) {
+	raw := `{
+		"type": "object",
+		"required": ["name", "age"],
+		"properties": {
+			"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+			"age": {"type": "integer", "minimum": 0, "maximum": 150},
+			"role": {"type": "string", "enum": ["admin", "user"]},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`
+	var s Schema
+	if err := json.Unmarshal([]byte(raw), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]string{
+		`{"name":"tom","age":18,"role":"admin","tags":["a"]}`: nil,
+		`{"name":"t","age":18}`:                               {"/name"},
+		`{"name":"Tom1","age":18.5}`:                          {"/name", "/age"},
+		`{"age":200,"role":"guest"}`:                          {"/name", "/age", "/role"},
+		`{"name":"tom","age":1,"tags":["a",1]}`:               {"/tags/1"},
+		`[]`:                                                  {""},
+	}
+	for body, paths := range cases {
+		err := s.Validation([]byte(body))
+		if len(paths) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", body, err)
+			}
+			continue
+		}
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Errorf("%s: expected validation errors, got %v", body, err)
+			continue
+		}
+		got := map[string]bool{}
+		for _, v := range errs {
+			got[v.Path] = true
+		}
+		for _, p := range paths {
+			if !got[p] {
+				t.Errorf("%s: missing error at %q, got %v", body, p, err)
+			}
+		}
+	}
+}
+
+func TestValidationKeywords(t *testing.T) {
+	s := mustSchema(t, `{
+		"type": "object",
+		"additionalProperties": false,
+		"properties": {
+			"email": {"type": "string", "format": "email"},
+			"created": {"type": "string", "format": "date-time"},
+			"id": {"type": "string", "format": "uuid"},
+			"step": {"type": "integer", "multipleOf": 5},
+			"score": {"type": "number", "minimum": 0, "maximum": 10, "exclusiveMinimum": true, "exclusiveMaximum": true},
+			"level": {"type": "integer", "exclusiveMinimum": 1, "exclusiveMaximum": 3},
+			"tags": {"type": "array", "minItems": 1, "maxItems": 2, "items": {"type": "string"}},
+			"nick": {"type": "string", "nullable": true},
+			"meta": {"type": "object", "additionalProperties": {"type": "integer"}}
+		}
+	}`)
+
+	cases := map[string]map[string]string{
+		`{"email":"a@b.com","created":"2023-01-02T15:04:05Z","id":"7f1d3c1e-8a4b-4c6e-9a51-3b2f6d5e4c3a"}`: nil,
+		`{"email":"abc","created":"2023-01-02","id":"1"}`: {
+			"/email": "format", "/created": "format", "/id": "format",
+		},
+		`{"step":10,"score":5,"level":2,"tags":["a"],"nick":null,"meta":{"a":1}}`: nil,
+		`{"step":7,"score":0,"level":3}`: {
+			"/step": "multipleOf", "/score": "exclusiveMinimum", "/level": "exclusiveMaximum",
+		},
+		`{"score":10,"level":1}`: {
+			"/score": "exclusiveMaximum", "/level": "exclusiveMinimum",
+		},
+		`{"tags":[],"meta":{"a":"x"}}`: {"/tags": "minItems", "/meta/a": "type"},
+		`{"tags":["a","b","c"],"x":1}`: {"/tags": "maxItems", "/x": "additionalProperties"},
+	}
+	for body, expected := range cases {
+		checkErrorPaths(t, body, s.Validation([]byte(body)), expected)
+	}
+}
+
+func TestValidationReadWriteOnly(t *testing.T) {
+	s := mustSchema(t, `{
+		"type": "object",
+		"required": ["id", "name", "password"],
+		"properties": {
+			"id": {"type": "integer", "readOnly": true},
+			"name": {"type": "string"},
+			"password": {"type": "string", "writeOnly": true}
+		}
+	}`)
+
+	cases := []struct {
+		mode     ValidateMode
+		body     string
+		expected map[string]string
+	}{
+		{ValidateRequest, `{"name":"a","password":"p"}`, nil},
+		{ValidateRequest, `{"id":1,"name":"a","password":"p"}`, map[string]string{"/id": "readOnly"}},
+		{ValidateResponse, `{"id":1,"name":"a"}`, nil},
+		{ValidateResponse, `{"id":1,"name":"a","password":"p"}`, map[string]string{"/password": "writeOnly"}},
+		{ValidateAny, `{"name":"a"}`, map[string]string{"/id": "required", "/password": "required"}},
+	}
+	for _, c := range cases {
+		err := s.ValidationWithOption([]byte(c.body), ValidateOption{Mode: c.mode})
+		checkErrorPaths(t, c.body, err, c.expected)
+	}
+}
+
+func TestValidationRef(t *testing.T) {
+	defs := map[string]*Schema{
+		"#/definitions/schemas/1": mustSchema(t, `{
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"children": {"type": "array", "items": {"$ref": "#/definitions/schemas/1"}}
+			}
+		}`),
+		// 自身引用
+		"#/definitions/schemas/2": mustSchema(t, `{"$ref": "#/definitions/schemas/2"}`),
+	}
+	opt := ValidateOption{Resolver: func(ref string) *Schema { return defs[ref] }}
+
+	s := mustSchema(t, `{"$ref": "#/definitions/schemas/1"}`)
+	checkErrorPaths(t, "tree", s.ValidationWithOption(
+		[]byte(`{"name":"a","children":[{"name":"b","children":[{"children":[]}]}]}`), opt),
+		map[string]string{"/children/0/children/0/name": "required"},
+	)
+	// 没有resolver时跳过引用
+	checkErrorPaths(t, "no resolver", s.Validation([]byte(`{}`)), nil)
+
+	loop := mustSchema(t, `{"$ref": "#/definitions/schemas/2"}`)
+	checkErrorPaths(t, "loop", loop.ValidationWithOption([]byte(`{}`), opt), nil)
+}
+
+func TestValidationComposition(t *testing.T) {
+	defs := map[string]*Schema{
+		"#/definitions/schemas/1": mustSchema(t, `{
+			"allOf": [
+				{"type": "object", "required": ["kind"], "properties": {"kind": {"type": "string"}}},
+				{"type": "object", "required": ["lives"], "properties": {"lives": {"type": "integer"}}}
+			]
+		}`),
+		"#/definitions/schemas/2": mustSchema(t, `{
+			"type": "object",
+			"required": ["kind", "bark"],
+			"properties": {"kind": {"type": "string"}, "bark": {"type": "boolean"}}
+		}`),
+	}
+	opt := ValidateOption{Resolver: func(ref string) *Schema { return defs[ref] }}
+
+	pet := mustSchema(t, `{
+		"oneOf": [{"$ref": "#/definitions/schemas/1"}, {"$ref": "#/definitions/schemas/2"}],
+		"discriminator": {
+			"propertyName": "kind",
+			"mapping": {"cat": "#/definitions/schemas/1", "dog": "#/definitions/schemas/2"}
+		}
+	}`)
+	cases := map[string]map[string]string{
+		`{"kind":"cat","lives":9}`:   nil,
+		`{"kind":"dog","bark":true}`: nil,
+		`{"kind":"cat","bark":true}`: {"/lives": "required"},
+		`{"kind":"bird","lives":1}`:  nil,
+		`{"kind":"bird","fly":true}`: {"": "oneOf"},
+	}
+	for body, expected := range cases {
+		checkErrorPaths(t, body, pet.ValidationWithOption([]byte(body), opt), expected)
+	}
+
+	anyOf := mustSchema(t, `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`)
+	checkErrorPaths(t, "anyOf string", anyOf.Validation([]byte(`"a"`)), nil)
+	checkErrorPaths(t, "anyOf bool", anyOf.Validation([]byte(`true`)), map[string]string{"": "anyOf"})
+
+	oneOf := mustSchema(t, `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`)
+	checkErrorPaths(t, "oneOf both", oneOf.Validation([]byte(`1`)), map[string]string{"": "oneOf"})
+	checkErrorPaths(t, "oneOf number", oneOf.Validation([]byte(`1.5`)), nil)
+}
@@ -0,0 +1,316 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 自定义规则的条件
+const (
+	ConditionTruthy     = "truthy"
+	ConditionFalsy      = "falsy"
+	ConditionPattern    = "pattern"
+	ConditionNotPattern = "not_pattern"
+	ConditionEnum       = "enum"
+	ConditionMaxLength  = "max_length"
+	ConditionMinLength  = "min_length"
+)
+
+// CustomRule 使用JSONPath选择节点 每个节点都要满足条件
+// 例如 {"path":"$.parameters.query[*].name","condition":"pattern","value":"^[a-z]+$"}
+// truthy条件的path没有匹配到节点时也会报告
+type CustomRule struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Severity 默认为warn
+	Severity string `json:"severity,omitempty"`
+	// Given api或schema 默认为api
+	Given     string `json:"given,omitempty"`
+	Path      string `json:"path"`
+	Condition string `json:"condition"`
+	// Value pattern的正则 enum使用逗号分隔的值 长度条件的数字
+	Value string `json:"value,omitempty"`
+	// Message 报告的信息 为空时使用描述
+	Message string `json:"message,omitempty"`
+
+	selectors []selector
+	pattern   *regexp.Regexp
+	length    int
+}
+
+func (r *CustomRule) severity() string {
+	if r.Severity == "" {
+		return SeverityWarn
+	}
+	return r.Severity
+}
+
+func (r *CustomRule) given() string {
+	if r.Given == "" {
+		return GivenAPI
+	}
+	return r.Given
+}
+
+// compile 解析path和条件的值 规则修改后需要重新调用
+func (r *CustomRule) compile() error {
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if !validSeverity(r.severity()) {
+		return fmt.Errorf("invalid severity %q", r.Severity)
+	}
+	if g := r.given(); g != GivenAPI && g != GivenSchema {
+		return fmt.Errorf("invalid given %q", r.Given)
+	}
+	selectors, err := parsePath(r.Path)
+	if err != nil {
+		return err
+	}
+	r.selectors = selectors
+	switch r.Condition {
+	case ConditionTruthy, ConditionFalsy, ConditionEnum:
+	case ConditionPattern, ConditionNotPattern:
+		if r.pattern, err = regexp.Compile(r.Value); err != nil {
+			return err
+		}
+	case ConditionMaxLength, ConditionMinLength:
+		if r.length, err = strconv.Atoi(r.Value); err != nil {
+			return fmt.Errorf("invalid length %q", r.Value)
+		}
+	default:
+		return fmt.Errorf("invalid condition %q", r.Condition)
+	}
+	return nil
+}
+
+func (r *CustomRule) check(docs []*document, report func(d *document, path, message string)) {
+	message := r.Message
+	if message == "" {
+		message = r.Description
+	}
+	if message == "" {
+		message = strings.TrimSpace(fmt.Sprintf("%s does not satisfy %s %s", r.Path, r.Condition, r.Value))
+	}
+	for _, d := range docs {
+		if d.given != r.given() {
+			continue
+		}
+		matches := evaluate(r.selectors, d.root)
+		if len(matches) == 0 && r.Condition == ConditionTruthy {
+			report(d, r.Path, message)
+			continue
+		}
+		for _, m := range matches {
+			if !r.match(m.value) {
+				report(d, m.path, message)
+			}
+		}
+	}
+}
+
+func (r *CustomRule) match(v any) bool {
+	switch r.Condition {
+	case ConditionTruthy:
+		return truthy(v)
+	case ConditionFalsy:
+		return !truthy(v)
+	case ConditionPattern, ConditionNotPattern:
+		s, ok := v.(string)
+		if !ok {
+			s = fmt.Sprint(v)
+		}
+		return r.pattern.MatchString(s) == (r.Condition == ConditionPattern)
+	case ConditionEnum:
+		for _, e := range strings.Split(r.Value, ",") {
+			if strings.TrimSpace(e) == fmt.Sprint(v) {
+				return true
+			}
+		}
+		return false
+	case ConditionMaxLength:
+		return length(v) <= r.length
+	case ConditionMinLength:
+		return length(v) >= r.length
+	}
+	return true
+}
+
+func truthy(v any) bool {
+	switch x := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return x
+	case string:
+		return strings.TrimSpace(x) != ""
+	case float64:
+		return x != 0
+	case []any:
+		return len(x) > 0
+	case map[string]any:
+		return len(x) > 0
+	}
+	return true
+}
+
+func length(v any) int {
+	switch x := v.(type) {
+	case string:
+		return utf8.RuneCountInString(x)
+	case []any:
+		return len(x)
+	case map[string]any:
+		return len(x)
+	}
+	return 0
+}
+
+// selector JSONPath中的一段
+// key为空并且index小于0时表示*
+type selector struct {
+	recursive bool
+	key       string
+	index     int
+}
+
+func (s selector) wildcard() bool {
+	return s.key == "" && s.index < 0
+}
+
+// parsePath 支持$ .key ['key'] [0] [*] .* 和 ..key
+func parsePath(path string) ([]selector, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+	list := make([]selector, 0)
+	rest := path[1:]
+	recursive := false
+	for len(rest) > 0 {
+		sel := selector{index: -1}
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				continue
+			}
+		case rest[0] == '.':
+			rest = rest[1:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: missing ]", path)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				sel.key = inner[1 : len(inner)-1]
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("path %q: invalid index %q", path, inner)
+				}
+				sel.index = i
+			}
+			sel.recursive, recursive = recursive, false
+			list = append(list, sel)
+			continue
+		default:
+			return nil, fmt.Errorf("path %q: unexpected %q", path, rest[:1])
+		}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		name := rest[:end]
+		rest = rest[end:]
+		if name == "" {
+			return nil, fmt.Errorf("path %q: empty name", path)
+		}
+		if name != "*" {
+			sel.key = name
+		}
+		sel.recursive, recursive = recursive, false
+		list = append(list, sel)
+	}
+	if recursive {
+		return nil, fmt.Errorf("path %q: missing name after ..", path)
+	}
+	return list, nil
+}
+
+type match struct {
+	path  string
+	value any
+}
+
+// evaluate 返回所有匹配的节点和它们的路径
+func evaluate(selectors []selector, root any) []match {
+	list := []match{{path: "$", value: root}}
+	for _, sel := range selectors {
+		next := make([]match, 0)
+		for _, m := range list {
+			if !sel.recursive {
+				next = append(next, sel.apply(m)...)
+				continue
+			}
+			descendants(m, func(d match) {
+				next = append(next, sel.apply(d)...)
+			})
+		}
+		list = next
+	}
+	return list
+}
+
+// apply 节点的子节点中匹配的部分 对象的属性按照名称排序
+func (s selector) apply(m match) []match {
+	switch x := m.value.(type) {
+	case map[string]any:
+		if s.index >= 0 {
+			return nil
+		}
+		if !s.wildcard() {
+			if v, ok := x[s.key]; ok {
+				return []match{{path: child(m.path, s.key), value: v}}
+			}
+			return nil
+		}
+		list := make([]match, 0, len(x))
+		for _, k := range sortedKeys(x) {
+			list = append(list, match{path: child(m.path, k), value: x[k]})
+		}
+		return list
+	case []any:
+		if s.key != "" {
+			return nil
+		}
+		if !s.wildcard() {
+			if s.index < len(x) {
+				return []match{{path: index(m.path, s.index), value: x[s.index]}}
+			}
+			return nil
+		}
+		list := make([]match, 0, len(x))
+		for i, v := range x {
+			list = append(list, match{path: index(m.path, i), value: v})
+		}
+		return list
+	}
+	return nil
+}
+
+// descendants 先序遍历节点和它的所有子节点
+func descendants(m match, fn func(match)) {
+	fn(m)
+	for _, c := range (selector{index: -1}).apply(m) {
+		descendants(c, fn)
+	}
+}
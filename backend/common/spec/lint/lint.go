@@ -0,0 +1,215 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+// 规则的严重程度 off表示不检查
+const (
+	SeverityError = "error"
+	SeverityWarn  = "warn"
+	SeverityInfo  = "info"
+	SeverityOff   = "off"
+)
+
+// 检查的对象 custom rule的given使用
+const (
+	GivenAPI    = "api"
+	GivenSchema = "schema"
+)
+
+// Finding 一条检查结果
+// 接口的问题CollectionID不为空 公共模型的问题SchemaID不为空
+// Path 为问题在文档中的位置 接口文档的结构见apiDocument 公共模型为spec.Schema
+type Finding struct {
+	Rule         string `json:"rule"`
+	Severity     string `json:"severity"`
+	Message      string `json:"message"`
+	CollectionID int64  `json:"collection_id,omitempty"`
+	SchemaID     int64  `json:"schema_id,omitempty"`
+	Title        string `json:"title"`
+	Path         string `json:"path"`
+}
+
+// Summary 每种严重程度的数量
+type Summary struct {
+	Error int `json:"error"`
+	Warn  int `json:"warn"`
+	Info  int `json:"info"`
+}
+
+type Report struct {
+	Summary  Summary    `json:"summary"`
+	Findings []*Finding `json:"findings"`
+}
+
+// Config 项目的检查配置
+// Rules 内置规则的名称 => 严重程度 没有配置的规则使用默认的严重程度
+type Config struct {
+	Rules  map[string]string `json:"rules"`
+	Custom []*CustomRule     `json:"custom"`
+}
+
+// Valid 检查规则名称 严重程度和自定义规则是否正确
+func (c *Config) Valid() error {
+	for name, severity := range c.Rules {
+		if lookupRule(name) == nil {
+			return fmt.Errorf("rule %q not found", name)
+		}
+		if !validSeverity(severity) {
+			return fmt.Errorf("rule %q: invalid severity %q", name, severity)
+		}
+	}
+	names := make(map[string]bool)
+	for _, v := range c.Custom {
+		if lookupRule(v.Name) != nil || names[v.Name] {
+			return fmt.Errorf("custom rule %q already exists", v.Name)
+		}
+		names[v.Name] = true
+		if err := v.compile(); err != nil {
+			return fmt.Errorf("custom rule %q: %s", v.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+// Severity 规则配置的严重程度
+func (c *Config) Severity(r *Rule) string {
+	if c != nil {
+		if v, ok := c.Rules[r.Name]; ok {
+			return v
+		}
+	}
+	return r.Severity
+}
+
+func validSeverity(s string) bool {
+	switch s {
+	case SeverityError, SeverityWarn, SeverityInfo, SeverityOff:
+		return true
+	}
+	return false
+}
+
+// apiDocument 检查时接口的结构 自定义规则的path也基于这个结构
+type apiDocument struct {
+	Title      string              `json:"title"`
+	Path       string              `json:"path"`
+	Method     string              `json:"method"`
+	Parameters spec.HTTPParameters `json:"parameters"`
+	Content    spec.HTTPBody       `json:"content"`
+	Responses  spec.HTTPResponses  `json:"responses"`
+}
+
+// document 一个接口或公共模型转为json后的结构
+type document struct {
+	given        string
+	collectionID int64
+	schemaID     int64
+	title        string
+	root         map[string]any
+}
+
+// documents 接口按照集合id排序 引用不展开
+func documents(s *spec.Spec) []*document {
+	list := make([]*document, 0)
+	for path, methods := range s.CollectionsMap(false, 0) {
+		for method, part := range methods {
+			part.Parameters.Fill()
+			list = append(list, &document{
+				given:        GivenAPI,
+				collectionID: part.ID,
+				title:        part.Title,
+				root: toMap(apiDocument{
+					Title:      part.Title,
+					Path:       path,
+					Method:     method,
+					Parameters: part.Parameters,
+					Content:    part.Content,
+					Responses:  part.Responses,
+				}),
+			})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].collectionID < list[j].collectionID })
+	for _, v := range s.Definitions.Schemas {
+		list = append(list, &document{
+			given:    GivenSchema,
+			schemaID: v.ID,
+			title:    v.Name,
+			root:     toMap(v),
+		})
+	}
+	return list
+}
+
+func toMap(v any) map[string]any {
+	b, _ := json.Marshal(v)
+	m := make(map[string]any)
+	json.Unmarshal(b, &m)
+	return m
+}
+
+// Lint 使用内置规则和自定义规则检查项目 cfg为nil时使用默认配置
+func Lint(s *spec.Spec, cfg *Config) *Report {
+	docs := documents(s)
+	r := &Report{Findings: make([]*Finding, 0)}
+	add := func(rule, severity string) func(d *document, path, message string) {
+		return func(d *document, path, message string) {
+			r.Findings = append(r.Findings, &Finding{
+				Rule:         rule,
+				Severity:     severity,
+				Message:      message,
+				CollectionID: d.collectionID,
+				SchemaID:     d.schemaID,
+				Title:        d.title,
+				Path:         path,
+			})
+			switch severity {
+			case SeverityError:
+				r.Summary.Error++
+			case SeverityWarn:
+				r.Summary.Warn++
+			case SeverityInfo:
+				r.Summary.Info++
+			}
+		}
+	}
+	for _, rule := range builtinRules {
+		severity := cfg.Severity(rule)
+		if severity == SeverityOff {
+			continue
+		}
+		rule.check(docs, add(rule.Name, severity))
+	}
+	if cfg != nil {
+		for _, rule := range cfg.Custom {
+			severity := rule.severity()
+			if severity == SeverityOff || rule.compile() != nil {
+				continue
+			}
+			rule.check(docs, add(rule.Name, severity))
+		}
+	}
+	return r
+}
+
+var identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// child 对象属性的路径 属性名不是标识符时使用['name']
+func child(path, key string) string {
+	if identRegexp.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "['" + key + "']"
+}
+
+func index(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
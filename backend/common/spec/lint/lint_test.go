@@ -0,0 +1,156 @@
+package lint
+
+import (
+	"os"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+func loadSpec(t *testing.T) *spec.Spec {
+	raw, err := os.ReadFile("../testdata/lint.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := spec.ParseJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+type key struct {
+	rule string
+	id   int64
+	path string
+}
+
+func findings(r *Report) map[key]*Finding {
+	m := make(map[key]*Finding)
+	for _, v := range r.Findings {
+		id := v.CollectionID
+		if id == 0 {
+			id = v.SchemaID
+		}
+		m[key{v.Rule, id, v.Path}] = v
+	}
+	return m
+}
+
+func TestBuiltinRules(t *testing.T) {
+	r := Lint(loadSpec(t), nil)
+	got := findings(r)
+	for _, want := range []key{
+		{"path-kebab-case", 11, "$.path"},
+		{"resource-plural", 10, "$.path"},
+		{"operation-4xx-response", 10, "$.responses"},
+		{"property-camel-case", 1, "$.schema.properties.nick_name"},
+		{"no-empty-description", 2, "$.description"},
+		{"no-empty-description", 1, "$.schema.properties.groupId.description"},
+		{"no-empty-description", 10, "$.parameters.query[0].description"},
+		{"no-empty-description", 11, "$.responses[1].description"},
+		{"id-type-consistent", 1, "$.schema.properties.groupId"},
+	} {
+		if got[want] == nil {
+			t.Errorf("finding %+v not found", want)
+		}
+	}
+	if len(r.Findings) != 9 {
+		for _, v := range r.Findings {
+			t.Logf("%+v", v)
+		}
+		t.Errorf("unexpected findings count %d", len(r.Findings))
+	}
+	if r.Summary.Warn != 4 || r.Summary.Info != 4 || r.Summary.Error != 1 {
+		t.Errorf("unexpected summary %+v", r.Summary)
+	}
+	if f := got[key{"resource-plural", 10, "$.path"}]; f.Title != "Get user" || f.Severity != SeverityWarn || f.Message != `resource "user" should be plural` {
+		t.Errorf("unexpected finding %+v", f)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	cfg := &Config{
+		Rules: map[string]string{
+			"no-empty-description": SeverityOff,
+			"path-kebab-case":      SeverityError,
+		},
+		Custom: []*CustomRule{
+			{Name: "query-lower", Path: "$.parameters.query[*].name", Condition: ConditionPattern, Value: "^[a-z]+$"},
+			{Name: "summary", Severity: SeverityInfo, Path: "$.summary", Condition: ConditionTruthy, Message: "summary required"},
+			{Name: "no-nick", Given: GivenSchema, Path: "$..properties.nick_name", Condition: ConditionFalsy},
+			{Name: "short-props", Given: GivenSchema, Path: "$..properties", Condition: ConditionMaxLength, Value: "2"},
+			{Name: "methods", Path: "$.method", Condition: ConditionEnum, Value: "get, put"},
+		},
+	}
+	if err := cfg.Valid(); err != nil {
+		t.Fatal(err)
+	}
+	got := findings(Lint(loadSpec(t), cfg))
+	for _, want := range []key{
+		{"path-kebab-case", 11, "$.path"},
+		{"summary", 10, "$.summary"},
+		{"summary", 11, "$.summary"},
+		{"short-props", 1, "$.schema.properties"},
+		{"methods", 11, "$.method"},
+		{"no-nick", 1, "$.schema.properties.nick_name"},
+	} {
+		if got[want] == nil {
+			t.Errorf("finding %+v not found", want)
+		}
+	}
+	for k := range got {
+		if k.rule == "no-empty-description" || k.rule == "query-lower" || (k.rule == "methods" && k.id == 10) {
+			t.Errorf("unexpected finding %+v", k)
+		}
+	}
+	if got[key{"path-kebab-case", 11, "$.path"}].Severity != SeverityError || got[key{"summary", 10, "$.summary"}].Message != "summary required" {
+		t.Error("unexpected severity or message")
+	}
+
+	for _, bad := range []*Config{
+		{Rules: map[string]string{"unknown": SeverityWarn}},
+		{Rules: map[string]string{"path-kebab-case": "fatal"}},
+		{Custom: []*CustomRule{{Name: "path-kebab-case", Path: "$", Condition: ConditionTruthy}}},
+		{Custom: []*CustomRule{{Name: "a", Path: "x", Condition: ConditionTruthy}}},
+		{Custom: []*CustomRule{{Name: "a", Path: "$.a[x]", Condition: ConditionTruthy}}},
+		{Custom: []*CustomRule{{Name: "a", Path: "$.a", Condition: "exists"}}},
+		{Custom: []*CustomRule{{Name: "a", Path: "$.a", Condition: ConditionPattern, Value: "("}}},
+		{Custom: []*CustomRule{{Name: "a", Path: "$.a", Condition: ConditionTruthy}, {Name: "a", Path: "$.b", Condition: ConditionTruthy}}},
+	} {
+		if err := bad.Valid(); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	root := toMap(map[string]any{
+		"a": []any{map[string]any{"name": "x"}, map[string]any{"name": "y", "b": map[string]any{"name": "z"}}},
+		"c": map[string]any{"application/json": 1},
+	})
+	for path, want := range map[string][]string{
+		"$.a[*].name":             {"$.a[0].name", "$.a[1].name"},
+		"$.a[1]['name']":          {"$.a[1].name"},
+		"$..name":                 {"$.a[0].name", "$.a[1].name", "$.a[1].b.name"},
+		"$.c.*":                   {"$.c['application/json']"},
+		"$.c['application/json']": {"$.c['application/json']"},
+		"$.a[5]":                  {},
+		"$..[0]":                  {"$.a[0]"},
+	} {
+		sel, err := parsePath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := evaluate(sel, root)
+		if len(got) != len(want) {
+			t.Errorf("%s: unexpected matches %+v", path, got)
+			continue
+		}
+		for i := range got {
+			if got[i].path != want[i] {
+				t.Errorf("%s: unexpected path %s", path, got[i].path)
+			}
+		}
+	}
+}
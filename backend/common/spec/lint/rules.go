@@ -0,0 +1,346 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Rule 内置规则
+type Rule struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Severity 默认的严重程度
+	Severity string `json:"severity"`
+	check    func(docs []*document, report func(d *document, path, message string))
+}
+
+var builtinRules = []*Rule{
+	{
+		Name:        "path-kebab-case",
+		Description: "Path segments should be kebab-case",
+		Severity:    SeverityWarn,
+		check:       checkPathKebabCase,
+	},
+	{
+		Name:        "property-camel-case",
+		Description: "Schema properties should be camelCase",
+		Severity:    SeverityWarn,
+		check:       checkPropertyCamelCase,
+	},
+	{
+		Name:        "operation-4xx-response",
+		Description: "Every operation should document at least one 4xx response",
+		Severity:    SeverityWarn,
+		check:       checkOperation4xx,
+	},
+	{
+		Name:        "no-empty-description",
+		Description: "Parameters, responses, schemas and properties should have a description",
+		Severity:    SeverityInfo,
+		check:       checkEmptyDescription,
+	},
+	{
+		Name:        "resource-plural",
+		Description: "Resource names followed by a path parameter should be plural",
+		Severity:    SeverityWarn,
+		check:       checkResourcePlural,
+	},
+	{
+		Name:        "id-type-consistent",
+		Description: "Fields named id or ending with Id should use the same type",
+		Severity:    SeverityError,
+		check:       checkIDType,
+	},
+}
+
+// Rules 所有的内置规则
+func Rules() []*Rule {
+	return builtinRules
+}
+
+func lookupRule(name string) *Rule {
+	for _, v := range builtinRules {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+var (
+	kebabCaseRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	camelCaseRegexp = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+)
+
+func apiPath(d *document) string {
+	s, _ := d.root["path"].(string)
+	return s
+}
+
+// pathSegments 按照/拆分路径 开头和结尾的/会被忽略
+func pathSegments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParamSegment(s string) bool {
+	return strings.Contains(s, "{")
+}
+
+func checkPathKebabCase(docs []*document, report func(d *document, path, message string)) {
+	for _, d := range docs {
+		if d.given != GivenAPI {
+			continue
+		}
+		for _, seg := range pathSegments(apiPath(d)) {
+			if seg == "" || isParamSegment(seg) || kebabCaseRegexp.MatchString(seg) {
+				continue
+			}
+			report(d, "$.path", fmt.Sprintf("path segment %q should be kebab-case", seg))
+		}
+	}
+}
+
+// 单复数相同或者不以s结尾的复数
+var irregularPlurals = map[string]bool{
+	"people": true, "children": true, "men": true, "women": true, "data": true,
+	"media": true, "criteria": true, "feet": true, "teeth": true, "mice": true,
+	"geese": true, "series": true, "species": true, "news": true, "info": true,
+}
+
+// isPlural 只根据单词的结尾判断 kebab-case时使用最后一个单词
+func isPlural(s string) bool {
+	s = strings.ToLower(s)
+	if i := strings.LastIndexAny(s, "-_"); i >= 0 {
+		s = s[i+1:]
+	}
+	if irregularPlurals[s] {
+		return true
+	}
+	return strings.HasSuffix(s, "s") && !strings.HasSuffix(s, "ss")
+}
+
+func checkResourcePlural(docs []*document, report func(d *document, path, message string)) {
+	for _, d := range docs {
+		if d.given != GivenAPI {
+			continue
+		}
+		segs := pathSegments(apiPath(d))
+		for i := 0; i+1 < len(segs); i++ {
+			if segs[i] == "" || isParamSegment(segs[i]) || !isParamSegment(segs[i+1]) || isPlural(segs[i]) {
+				continue
+			}
+			report(d, "$.path", fmt.Sprintf("resource %q should be plural", segs[i]))
+		}
+	}
+}
+
+func checkOperation4xx(docs []*document, report func(d *document, path, message string)) {
+	for _, d := range docs {
+		if d.given != GivenAPI {
+			continue
+		}
+		found := false
+		responses, _ := d.root["responses"].([]any)
+		for _, v := range responses {
+			res, _ := v.(map[string]any)
+			if code, ok := res["code"].(float64); ok && code >= 400 && code < 500 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			report(d, "$.responses", "operation has no 4xx response")
+		}
+	}
+}
+
+// schemaVisitor 遍历jsonschema时的回调 name为属性名 不是属性时为空
+type schemaVisitor func(path, name string, schema map[string]any)
+
+// walkSchema 遍历jsonschema和它的属性 数组元素和组合中的模型
+func walkSchema(path, name string, v any, fn schemaVisitor) {
+	schema, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	fn(path, name, schema)
+	if props, ok := schema["properties"].(map[string]any); ok {
+		for _, k := range sortedKeys(props) {
+			walkSchema(child(child(path, "properties"), k), k, props[k], fn)
+		}
+	}
+	for _, k := range []string{"items", "additionalProperties", "not"} {
+		walkSchema(child(path, k), "", schema[k], fn)
+	}
+	for _, k := range []string{"allOf", "anyOf", "oneOf"} {
+		list, _ := schema[k].([]any)
+		for i, item := range list {
+			walkSchema(index(child(path, k), i), "", item, fn)
+		}
+	}
+}
+
+// walkDocumentSchemas 遍历文档中所有的jsonschema 包括参数 请求体 响应体 响应头和公共模型
+func walkDocumentSchemas(d *document, fn schemaVisitor) {
+	if d.given == GivenSchema {
+		walkSchema("$.schema", "", d.root["schema"], fn)
+		return
+	}
+	eachParameter(d, func(path string, param map[string]any) {
+		walkSchema(child(path, "schema"), "", param["schema"], fn)
+	})
+	walkContent := func(path string, v any) {
+		content, _ := v.(map[string]any)
+		for _, mime := range sortedKeys(content) {
+			body, _ := content[mime].(map[string]any)
+			walkSchema(child(child(path, mime), "schema"), "", body["schema"], fn)
+		}
+	}
+	walkContent("$.content", d.root["content"])
+	responses, _ := d.root["responses"].([]any)
+	for i, v := range responses {
+		res, _ := v.(map[string]any)
+		path := index("$.responses", i)
+		walkContent(child(path, "content"), res["content"])
+		headers, _ := res["header"].([]any)
+		for j, h := range headers {
+			header, _ := h.(map[string]any)
+			walkSchema(child(index(child(path, "header"), j), "schema"), "", header["schema"], fn)
+		}
+	}
+}
+
+// eachParameter 按照path query header cookie的顺序遍历请求参数
+func eachParameter(d *document, fn func(path string, param map[string]any)) {
+	params, _ := d.root["parameters"].(map[string]any)
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		list, _ := params[in].([]any)
+		for i, v := range list {
+			if p, ok := v.(map[string]any); ok {
+				fn(index(child("$.parameters", in), i), p)
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func checkPropertyCamelCase(docs []*document, report func(d *document, path, message string)) {
+	for _, d := range docs {
+		walkDocumentSchemas(d, func(path, name string, schema map[string]any) {
+			if name != "" && !camelCaseRegexp.MatchString(name) {
+				report(d, path, fmt.Sprintf("property %q should be camelCase", name))
+			}
+		})
+	}
+}
+
+func emptyString(v any) bool {
+	s, _ := v.(string)
+	return strings.TrimSpace(s) == ""
+}
+
+// checkEmptyDescription 引用的描述在被引用的定义中检查
+func checkEmptyDescription(docs []*document, report func(d *document, path, message string)) {
+	for _, d := range docs {
+		if d.given == GivenSchema && emptyString(d.root["description"]) {
+			report(d, "$.description", fmt.Sprintf("schema %q has no description", d.title))
+		}
+		if d.given == GivenAPI {
+			eachParameter(d, func(path string, param map[string]any) {
+				if param["$ref"] == nil && emptyString(param["description"]) {
+					report(d, child(path, "description"), fmt.Sprintf("parameter %q has no description", param["name"]))
+				}
+			})
+			responses, _ := d.root["responses"].([]any)
+			for i, v := range responses {
+				res, _ := v.(map[string]any)
+				if res["$ref"] == nil && emptyString(res["description"]) {
+					report(d, child(index("$.responses", i), "description"), fmt.Sprintf("response %v has no description", res["code"]))
+				}
+			}
+		}
+		walkDocumentSchemas(d, func(path, name string, schema map[string]any) {
+			if name != "" && schema["$ref"] == nil && emptyString(schema["description"]) {
+				report(d, child(path, "description"), fmt.Sprintf("property %q has no description", name))
+			}
+		})
+	}
+}
+
+// isIDField id user_id userId userID都作为id字段
+func isIDField(name string) bool {
+	return name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "ID")
+}
+
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		if len(t) > 0 {
+			s, _ := t[0].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// checkIDType 以数量最多的类型为准 数量相同时使用先出现的类型
+func checkIDType(docs []*document, report func(d *document, path, message string)) {
+	type field struct {
+		d    *document
+		path string
+		name string
+		typ  string
+	}
+	fields := make([]field, 0)
+	for _, d := range docs {
+		if d.given == GivenAPI {
+			eachParameter(d, func(path string, param map[string]any) {
+				name, _ := param["name"].(string)
+				schema, _ := param["schema"].(map[string]any)
+				if isIDField(name) && schemaType(schema) != "" {
+					fields = append(fields, field{d, child(path, "schema"), name, schemaType(schema)})
+				}
+			})
+		}
+		walkDocumentSchemas(d, func(path, name string, schema map[string]any) {
+			if isIDField(name) && schemaType(schema) != "" {
+				fields = append(fields, field{d, path, name, schemaType(schema)})
+			}
+		})
+	}
+
+	counts := make(map[string]int)
+	order := make([]string, 0)
+	for _, v := range fields {
+		if counts[v.typ] == 0 {
+			order = append(order, v.typ)
+		}
+		counts[v.typ]++
+	}
+	if len(order) < 2 {
+		return
+	}
+	expected := order[0]
+	for _, t := range order[1:] {
+		if counts[t] > counts[expected] {
+			expected = t
+		}
+	}
+	for _, v := range fields {
+		if v.typ != expected {
+			report(v.d, v.path, fmt.Sprintf("%q is %s, but most id fields are %s", v.name, v.typ, expected))
+		}
+	}
+}
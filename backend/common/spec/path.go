@@ -0,0 +1,75 @@
+package spec
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+var (
+	numericSegment = regexp.MustCompile(`^\d+$`)
+	uuidSegment    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// PathVariable 从实际请求路径中识别出的路径参数
+type PathVariable struct {
+	Name  string
+	Value string
+	UUID  bool
+}
+
+// Parameter 转为必填的路径参数 uuid为带格式的字符串 超出int64范围的数字作为字符串保留原值
+func (v PathVariable) Parameter() *Schema {
+	sh := jsonschema.Create("string")
+	sh.Example = v.Value
+	if v.UUID {
+		sh.Format = "uuid"
+	} else if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+		sh = jsonschema.Create("integer")
+		sh.Example = n
+	}
+	return &Schema{Name: v.Name, Required: true, Schema: sh}
+}
+
+// PathTemplate 将请求路径中的数字和uuid段替换为路径参数 参数名使用前一段的单数形式加Id
+func PathTemplate(p string) (string, []PathVariable) {
+	var (
+		segs   []string
+		params []PathVariable
+	)
+	for _, seg := range strings.Split(strings.Trim(p, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		isUUID := uuidSegment.MatchString(seg)
+		if !isUUID && !numericSegment.MatchString(seg) {
+			segs = append(segs, seg)
+			continue
+		}
+		name := "id"
+		if len(segs) > 0 {
+			prev := strings.TrimSuffix(segs[len(segs)-1], "s")
+			if !strings.HasPrefix(prev, "{") && prev != "" {
+				name = prev + "Id"
+			}
+		}
+		for i := 2; pathVariableExists(params, name); i++ {
+			name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+		}
+		params = append(params, PathVariable{Name: name, Value: seg, UUID: isUUID})
+		segs = append(segs, "{"+name+"}")
+	}
+	return "/" + strings.Join(segs, "/"), params
+}
+
+func pathVariableExists(params []PathVariable, name string) bool {
+	for _, v := range params {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"golang.org/x/exp/slices"
+)
+
+func decodeFile(t *testing.T) *spec.Spec {
+	raw, err := os.ReadFile("../../testdata/asyncapi2.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestDecode(t *testing.T) {
+	x := decodeFile(t)
+	if x.Info.Title != "Chat Service" || len(x.Servers) != 1 || x.Servers[0].URL != "wss://chat.example.com/ws" {
+		t.Fatalf("unexpected info %+v %+v", x.Info, x.Servers)
+	}
+	if len(x.Definitions.Schemas) != 2 || x.Definitions.Schemas[0].Name != "ChatMessage" {
+		t.Fatalf("unexpected definitions %+v", x.Definitions.Schemas)
+	}
+	if ref := x.Definitions.Schemas[0].Schema.Properties["from"].Reference; ref == nil || *ref != "#/definitions/schemas/1000002" {
+		t.Errorf("unexpected ref %v", ref)
+	}
+	if len(x.Collections) != 2 || x.Collections[0].Title != "presence" || x.Collections[1].Title != "chat" {
+		t.Fatalf("unexpected collections %+v", x.Collections)
+	}
+
+	channels := x.Channels(true, 1)
+	if len(channels) != 2 {
+		t.Fatalf("unexpected channels %+v", channels)
+	}
+	room := channels[1]
+	if room.Channel.Channel != "rooms/{roomId}" || room.Channel.Protocol != "wss" || room.Dir != "chat" {
+		t.Errorf("unexpected channel %+v", room)
+	}
+	if len(room.Channel.Parameters) != 1 || room.Channel.Parameters[0].Description != "Id of the room" {
+		t.Errorf("unexpected parameters %+v", room.Channel.Parameters)
+	}
+	if room.Publish == nil || len(room.Publish.Messages) != 1 || room.Publish.Messages[0].Name != "chatMessage" {
+		t.Fatalf("unexpected publish %+v", room.Publish)
+	}
+	if room.Publish.Messages[0].Payload.Properties["from"].Properties["id"] == nil {
+		t.Error("payload refs not expanded")
+	}
+	names := make([]string, 0)
+	for _, v := range room.Subscribe.Messages {
+		names = append(names, v.Name)
+	}
+	if !slices.Equal(names, []string{"chatMessage", "userJoined"}) {
+		t.Errorf("unexpected subscribe messages %v", names)
+	}
+	if len(room.Doc.Items) == 0 {
+		t.Error("description not imported")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	x := decodeFile(t)
+	b, err := Encode(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["asyncapi"] != "2.6.0" {
+		t.Errorf("unexpected version %v", doc["asyncapi"])
+	}
+	servers := doc["servers"].(map[string]any)
+	if servers["Production"].(map[string]any)["protocol"] != "wss" {
+		t.Errorf("unexpected servers %v", servers)
+	}
+
+	// 再次导入后结构不变
+	y, err := Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	channels := y.Channels(true, 1)
+	if len(channels) != 2 {
+		t.Fatalf("unexpected channels %+v", channels)
+	}
+	room := channels[1]
+	if len(room.Subscribe.Messages) != 2 || room.Subscribe.Messages[1].Payload.Properties["user"].Properties["id"] == nil {
+		t.Errorf("unexpected subscribe %+v", room.Subscribe)
+	}
+	if room.Publish.OperationID != "sendMessage" || room.Channel.Parameters[0].Name != "roomId" {
+		t.Errorf("unexpected channel %+v", room)
+	}
+}
+
+func TestDecodeEmptySchema(t *testing.T) {
+	raw := []byte(`asyncapi: 2.6.0
+info:
+  title: Empty
+  version: 1.0.0
+channels: {}
+components:
+  schemas:
+    User:
+    Pet:
+      type: object
+      properties:
+        owner:
+          $ref: '#/components/schemas/User'
+`)
+	x, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(x.Definitions.Schemas) != 2 || x.Definitions.Schemas[0].Name != "Pet" || x.Definitions.Schemas[1].Schema == nil {
+		t.Fatalf("unexpected definitions %+v", x.Definitions.Schemas)
+	}
+	if ref := x.Definitions.Schemas[0].Schema.Properties["owner"].Reference; ref == nil || *ref != "#/definitions/schemas/1000002" {
+		t.Errorf("unexpected ref %v", ref)
+	}
+}
+
+func TestEncodeNilProperty(t *testing.T) {
+	x := decodeFile(t)
+	x.Definitions.Schemas[0].Schema.Properties["x"] = nil
+	b, err := Encode(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	schema := doc["components"].(map[string]any)["schemas"].(map[string]any)[x.Definitions.Schemas[0].Name].(map[string]any)
+	if props := schema["properties"].(map[string]any); props["from"] == nil {
+		t.Errorf("unexpected properties %v", props)
+	}
+}
@@ -0,0 +1,352 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/spec/markdown"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// 模型的虚拟id 位数相同 导入时替换为数据库中的id不会相互影响
+const virtualIDBase = 1000000
+
+type document struct {
+	AsyncAPI string `json:"asyncapi"`
+	Info     struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	} `json:"info"`
+	Servers    map[string]server  `json:"servers,omitempty"`
+	Channels   map[string]channel `json:"channels"`
+	Components struct {
+		Schemas    map[string]*jsonschema.Schema `json:"schemas,omitempty"`
+		Messages   map[string]json.RawMessage    `json:"messages,omitempty"`
+		Parameters map[string]parameter          `json:"parameters,omitempty"`
+	} `json:"components"`
+}
+
+type server struct {
+	URL         string `json:"url"`
+	Protocol    string `json:"protocol"`
+	Description string `json:"description,omitempty"`
+}
+
+type channel struct {
+	Description string               `json:"description,omitempty"`
+	Parameters  map[string]parameter `json:"parameters,omitempty"`
+	Publish     *operation           `json:"publish,omitempty"`
+	Subscribe   *operation           `json:"subscribe,omitempty"`
+}
+
+type parameter struct {
+	Reference   string             `json:"$ref,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Schema      *jsonschema.Schema `json:"schema,omitempty"`
+}
+
+type tag struct {
+	Name string `json:"name"`
+}
+
+type operation struct {
+	OperationID string          `json:"operationId,omitempty"`
+	Summary     string          `json:"summary,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Tags        []tag           `json:"tags,omitempty"`
+	Message     json.RawMessage `json:"message,omitempty"`
+}
+
+type message struct {
+	Reference   string             `json:"$ref,omitempty"`
+	Name        string             `json:"name,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Summary     string             `json:"summary,omitempty"`
+	Description string             `json:"description,omitempty"`
+	ContentType string             `json:"contentType,omitempty"`
+	Headers     *jsonschema.Schema `json:"headers,omitempty"`
+	Payload     *jsonschema.Schema `json:"payload,omitempty"`
+	OneOf       []json.RawMessage  `json:"oneOf,omitempty"`
+}
+
+// Decode 解析asyncapi 2.x的json或yaml文档
+// 每个channel生成一个集合 按照操作的第一个标签分组
+func Decode(data []byte) (*spec.Spec, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var doc document
+	refs := make(map[string]string)
+	if m, ok := raw.(map[string]any); ok {
+		if comp, ok := m["components"].(map[string]any); ok {
+			if schemas, ok := comp["schemas"].(map[string]any); ok {
+				names := make([]string, 0, len(schemas))
+				for k := range schemas {
+					names = append(names, k)
+				}
+				sort.Strings(names)
+				for i, k := range names {
+					refs["#/components/schemas/"+k] = fmt.Sprintf("#/definitions/schemas/%d", virtualIDBase+i+1)
+				}
+			}
+		}
+	}
+	b, err := json.Marshal(replaceRefs(raw, refs))
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.AsyncAPI, "2.") {
+		return nil, fmt.Errorf("asyncapi %q not support", doc.AsyncAPI)
+	}
+
+	d := &decoder{doc: &doc}
+	out := &spec.Spec{
+		ApiCat: "2.0.1",
+		Info: &spec.Info{
+			Title:       doc.Info.Title,
+			Version:     doc.Info.Version,
+			Description: doc.Info.Description,
+		},
+		Servers:     d.servers(),
+		Definitions: spec.Definitions{Schemas: d.definitions(refs)},
+		Collections: d.collections(),
+	}
+	return out, nil
+}
+
+// replaceRefs 模型的引用替换为公共模型的虚拟id yaml解码后的值都是基础类型
+func replaceRefs(v any, refs map[string]string) any {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			if ref, ok := item.(string); ok && k == "$ref" {
+				if to, ok := refs[ref]; ok {
+					x[k] = to
+				}
+				continue
+			}
+			x[k] = replaceRefs(item, refs)
+		}
+	case []any:
+		for i, item := range x {
+			x[i] = replaceRefs(item, refs)
+		}
+	}
+	return v
+}
+
+type decoder struct {
+	doc *document
+}
+
+func (d *decoder) servers() []*spec.Server {
+	names := make([]string, 0, len(d.doc.Servers))
+	for k := range d.doc.Servers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	list := make([]*spec.Server, 0, len(names))
+	for _, k := range names {
+		v := d.doc.Servers[k]
+		u := v.URL
+		// asyncapi的地址可以不包含协议
+		if !strings.Contains(u, "://") && v.Protocol != "" {
+			u = v.Protocol + "://" + u
+		}
+		desc := v.Description
+		if desc == "" {
+			desc = k
+		}
+		list = append(list, &spec.Server{URL: u, Description: desc})
+	}
+	return list
+}
+
+func (d *decoder) definitions(refs map[string]string) spec.Schemas {
+	list := make(spec.Schemas, 0, len(d.doc.Components.Schemas))
+	for k, v := range d.doc.Components.Schemas {
+		// 内容为空的模型 如 User: 没有值 作为任意类型导入 其它地方对它的引用仍然有效
+		if v == nil {
+			v = &jsonschema.Schema{}
+		}
+		var id int64
+		fmt.Sscanf(strings.TrimPrefix(refs["#/components/schemas/"+k], "#/definitions/schemas/"), "%d", &id)
+		list = append(list, &spec.Schema{
+			ID:          id,
+			Name:        k,
+			Description: v.Description,
+			Schema:      v,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// messages 操作的消息可以是引用 或者oneOf多个消息
+func (d *decoder) messages(raw json.RawMessage) []spec.ChannelMessage {
+	if len(raw) == 0 {
+		return []spec.ChannelMessage{}
+	}
+	var m message
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return []spec.ChannelMessage{}
+	}
+	if m.Reference != "" {
+		name := strings.TrimPrefix(m.Reference, "#/components/messages/")
+		ref, ok := d.doc.Components.Messages[name]
+		if !ok || name == m.Reference {
+			return []spec.ChannelMessage{}
+		}
+		list := d.messages(ref)
+		for i := range list {
+			if list[i].Name == "" {
+				list[i].Name = name
+			}
+		}
+		return list
+	}
+	if len(m.OneOf) > 0 {
+		list := make([]spec.ChannelMessage, 0, len(m.OneOf))
+		for _, v := range m.OneOf {
+			list = append(list, d.messages(v)...)
+		}
+		return list
+	}
+	desc := m.Description
+	if desc == "" {
+		desc = m.Summary
+	}
+	return []spec.ChannelMessage{{
+		Name:        m.Name,
+		Title:       m.Title,
+		Description: desc,
+		ContentType: m.ContentType,
+		Headers:     m.Headers,
+		Payload:     m.Payload,
+	}}
+}
+
+func (d *decoder) operation(action string, op *operation) *spec.NodeProxy {
+	return spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.ChannelOperationNode{
+		Action:      action,
+		OperationID: op.OperationID,
+		Summary:     op.Summary,
+		Messages:    d.messages(op.Message),
+	}))
+}
+
+// protocol 通道的协议 使用第一个服务的协议
+func (d *decoder) protocol() string {
+	names := make([]string, 0, len(d.doc.Servers))
+	for k := range d.doc.Servers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return ""
+	}
+	return d.doc.Servers[names[0]].Protocol
+}
+
+func (d *decoder) collections() []*spec.CollectItem {
+	names := make([]string, 0, len(d.doc.Channels))
+	for k := range d.doc.Channels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	root := make([]*spec.CollectItem, 0)
+	dirs := make(map[string]*spec.CollectItem)
+	protocol := d.protocol()
+	for _, name := range names {
+		ch := d.doc.Channels[name]
+		content := make([]*spec.NodeProxy, 0)
+		desc := ch.Description
+		var tags []string
+		for _, op := range []*operation{ch.Publish, ch.Subscribe} {
+			if op == nil {
+				continue
+			}
+			if desc == "" {
+				desc = op.Description
+			}
+			for _, t := range op.Tags {
+				if !slices.Contains(tags, t.Name) {
+					tags = append(tags, t.Name)
+				}
+			}
+		}
+		for _, v := range markdown.ToDocment([]byte(desc)).Items {
+			content = append(content, spec.MuseCreateNodeProxy(v))
+		}
+
+		params := make(spec.Schemas, 0, len(ch.Parameters))
+		for _, k := range paramNames(name, ch.Parameters) {
+			p := ch.Parameters[k]
+			if ref, ok := d.doc.Components.Parameters[strings.TrimPrefix(p.Reference, "#/components/parameters/")]; ok && p.Reference != "" {
+				p = ref
+			}
+			schema := p.Schema
+			if schema == nil {
+				schema = jsonschema.Create("string")
+			}
+			params = append(params, &spec.Schema{Name: k, Description: p.Description, Required: true, Schema: schema})
+		}
+		content = append(content, spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.ChannelNode{
+			Channel:    name,
+			Protocol:   protocol,
+			Parameters: params,
+		})))
+		if ch.Publish != nil {
+			content = append(content, d.operation(spec.ChannelPublish, ch.Publish))
+		}
+		if ch.Subscribe != nil {
+			content = append(content, d.operation(spec.ChannelSubscribe, ch.Subscribe))
+		}
+
+		item := &spec.CollectItem{
+			Type:    spec.ContentItemTypeChannel,
+			Title:   name,
+			Tags:    tags,
+			Content: content,
+		}
+		if len(tags) == 0 {
+			root = append(root, item)
+			continue
+		}
+		dir, ok := dirs[tags[0]]
+		if !ok {
+			dir = &spec.CollectItem{Type: spec.ContentItemTypeDir, Title: tags[0]}
+			dirs[tags[0]] = dir
+			root = append(root, dir)
+		}
+		dir.Items = append(dir.Items, item)
+	}
+	return root
+}
+
+// paramNames 按照在通道名称中出现的顺序排列参数
+func paramNames(channel string, params map[string]parameter) []string {
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		a, b := strings.Index(channel, "{"+names[i]+"}"), strings.Index(channel, "{"+names[j]+"}")
+		if a != b {
+			return a < b
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
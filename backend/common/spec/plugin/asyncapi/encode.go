@@ -0,0 +1,177 @@
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/spec/markdown"
+)
+
+// 导出的asyncapi版本
+const version = "2.6.0"
+
+// 服务地址没有协议时使用的默认协议
+const defaultProtocol = "ws"
+
+// Encode 将spec中的channel集合编码为asyncapi 2.6.0
+// 公共模型导出到components.schemas 其它类型的集合会被忽略
+func Encode(in *spec.Spec) ([]byte, error) {
+	e := &encoder{mapping: make(map[int64]string)}
+	for _, v := range in.Definitions.Schemas {
+		e.mapping[v.ID] = v.Name
+	}
+
+	doc := document{AsyncAPI: version}
+	if in.Info != nil {
+		doc.Info.Title = in.Info.Title
+		doc.Info.Version = in.Info.Version
+		doc.Info.Description = in.Info.Description
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = "1.0.0"
+	}
+	doc.Servers = e.servers(in.Servers)
+	doc.Channels = make(map[string]channel)
+	for _, v := range in.Channels(false, 0) {
+		doc.Channels[v.Channel.Channel] = e.channel(v)
+	}
+	doc.Components.Schemas = make(map[string]*jsonschema.Schema)
+	for _, v := range in.Definitions.Schemas {
+		if v.Schema != nil {
+			doc.Components.Schemas[v.Name] = e.convert(v.Schema)
+		}
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+type encoder struct {
+	// 公共模型的id => 名称
+	mapping map[int64]string
+}
+
+// servers 服务的名称使用描述 重名或者为空时使用序号
+func (e *encoder) servers(list []*spec.Server) map[string]server {
+	out := make(map[string]server, len(list))
+	for i, v := range list {
+		protocol := defaultProtocol
+		if u, err := url.Parse(v.URL); err == nil && u.Scheme != "" {
+			protocol = u.Scheme
+		}
+		name := v.Description
+		if _, ok := out[name]; ok || name == "" || strings.ContainsAny(name, " /") {
+			name = fmt.Sprintf("server%d", i+1)
+		}
+		out[name] = server{URL: v.URL, Protocol: protocol, Description: v.Description}
+	}
+	return out
+}
+
+func (e *encoder) channel(part spec.ChannelPart) channel {
+	ch := channel{}
+	if len(part.Doc.Items) > 0 {
+		if raw, err := markdown.ToMarkdown(&part.Doc); err == nil {
+			ch.Description = string(raw)
+		}
+	}
+	if len(part.Channel.Parameters) > 0 {
+		ch.Parameters = make(map[string]parameter)
+		for _, v := range part.Channel.Parameters {
+			p := parameter{Description: v.Description}
+			if v.Schema != nil {
+				p.Schema = e.convert(v.Schema)
+			}
+			ch.Parameters[v.Name] = p
+		}
+	}
+	ch.Publish = e.operation(part.Publish, part.Tags)
+	ch.Subscribe = e.operation(part.Subscribe, part.Tags)
+	return ch
+}
+
+func (e *encoder) operation(op *spec.ChannelOperationNode, tags []string) *operation {
+	if op == nil {
+		return nil
+	}
+	out := &operation{OperationID: op.OperationID, Summary: op.Summary}
+	for _, v := range tags {
+		out.Tags = append(out.Tags, tag{Name: v})
+	}
+	list := make([]message, 0, len(op.Messages))
+	for _, v := range op.Messages {
+		m := message{
+			Name:        v.Name,
+			Title:       v.Title,
+			Description: v.Description,
+			ContentType: v.ContentType,
+		}
+		if v.Headers != nil {
+			m.Headers = e.convert(v.Headers)
+		}
+		if v.Payload != nil {
+			m.Payload = e.convert(v.Payload)
+		}
+		list = append(list, m)
+	}
+	var msg any
+	switch len(list) {
+	case 0:
+		return out
+	case 1:
+		msg = list[0]
+	default:
+		msg = map[string]any{"oneOf": list}
+	}
+	out.Message, _ = json.Marshal(msg)
+	return out
+}
+
+// convert 公共模型的引用转为components中的引用
+func (e *encoder) convert(v *jsonschema.Schema) *jsonschema.Schema {
+	// 如 properties: {x: null}
+	if v == nil {
+		return nil
+	}
+	sh := *v
+	if sh.Reference != nil {
+		ref := *sh.Reference
+		if i := strings.LastIndex(ref, "/"); i >= 0 && strings.HasPrefix(ref, "#/definitions/schemas/") {
+			if id, err := strconv.ParseInt(ref[i+1:], 10, 64); err == nil {
+				if name, ok := e.mapping[id]; ok {
+					ref = "#/components/schemas/" + name
+				}
+			}
+		}
+		return &jsonschema.Schema{Reference: &ref}
+	}
+	if sh.Properties != nil {
+		props := make(map[string]*jsonschema.Schema, len(sh.Properties))
+		for k, v := range sh.Properties {
+			props[k] = e.convert(v)
+		}
+		sh.Properties = props
+	}
+	if sh.Items != nil && !sh.Items.IsBool() {
+		sh.Items = &jsonschema.ValueOrBoolean[*jsonschema.Schema]{}
+		sh.Items.SetValue(e.convert(v.Items.Value()))
+	}
+	if sh.AdditionalProperties != nil && !sh.AdditionalProperties.IsBool() {
+		sh.AdditionalProperties = &jsonschema.ValueOrBoolean[*jsonschema.Schema]{}
+		sh.AdditionalProperties.SetValue(e.convert(v.AdditionalProperties.Value()))
+	}
+	for _, list := range []*[]*jsonschema.Schema{&sh.AllOf, &sh.AnyOf, &sh.OneOf} {
+		if len(*list) == 0 {
+			continue
+		}
+		items := make([]*jsonschema.Schema, len(*list))
+		for i, v := range *list {
+			items[i] = e.convert(v)
+		}
+		*list = items
+	}
+	return &sh
+}
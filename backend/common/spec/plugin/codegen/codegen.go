@@ -0,0 +1,339 @@
+package codegen
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// document 生成代码使用的项目结构 模型来自公共模型 接口按照所在的目录分组
+type document struct {
+	name   string
+	models []*model
+	refs   map[int64]*model
+	groups []*group
+}
+
+type model struct {
+	name   string
+	schema *jsonschema.Schema
+}
+
+// group 一个目录下的接口 根目录的接口name为空
+type group struct {
+	title string
+	name  string
+	pkg   string
+	ops   []*operation
+}
+
+type operation struct {
+	name     string
+	title    string
+	method   string
+	path     string
+	params   []*param
+	body     *jsonschema.Schema
+	bodyType string
+	response *jsonschema.Schema
+	// status 成功时的状态码
+	status int
+}
+
+type param struct {
+	name     string
+	in       string
+	required bool
+	schema   *jsonschema.Schema
+}
+
+// ClientSDK 生成go和typescript的客户端代码 打包为zip
+func ClientSDK(in *spec.Spec) ([]byte, error) {
+	doc := newDocument(in)
+	pkg := goPackage(doc.name)
+	if pkg == "" {
+		pkg = "client"
+	}
+
+	files := []struct {
+		name string
+		gen  func() ([]byte, error)
+	}{
+		{"go/go.mod", func() ([]byte, error) { return []byte(fmt.Sprintf("module %s\n\ngo 1.20\n", pkg)), nil }},
+		{"go/client.go", func() ([]byte, error) { return goClient(doc, pkg, pkg) }},
+		{"go/core/core.go", func() ([]byte, error) { return []byte(goCore), nil }},
+		{"go/models/models.go", func() ([]byte, error) { return goModels(doc, pkg, false) }},
+	}
+	for _, g := range doc.groups {
+		if g.name == "" {
+			continue
+		}
+		g := g
+		files = append(files, struct {
+			name string
+			gen  func() ([]byte, error)
+		}{fmt.Sprintf("go/%s/%s.go", g.pkg, g.pkg), func() ([]byte, error) { return goGroup(doc, pkg, g) }})
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	write := func(name string, content []byte) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(content)
+		return err
+	}
+	for _, f := range files {
+		content, err := f.gen()
+		if err != nil {
+			return nil, err
+		}
+		if err := write(f.name, content); err != nil {
+			return nil, err
+		}
+	}
+	if err := write("typescript/models.ts", tsModels(doc)); err != nil {
+		return nil, err
+	}
+	if err := write("typescript/client.ts", tsClient(doc)); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newDocument 整理项目的接口和模型 参数和响应的引用会被替换为定义 schema中的模型引用会保留
+func newDocument(in *spec.Spec) *document {
+	d := &document{refs: make(map[int64]*model)}
+	if in.Info != nil {
+		d.name = in.Info.Title
+	}
+
+	names := namer{}
+	for _, v := range in.Definitions.Schemas {
+		if v.Schema == nil {
+			continue
+		}
+		name := goName(v.Name)
+		if name == "" {
+			name = fmt.Sprintf("Model%d", v.ID)
+		}
+		m := &model{name: names.unique(name), schema: v.Schema}
+		d.models = append(d.models, m)
+		d.refs[v.ID] = m
+	}
+
+	paths := in.CollectionsMap(false, 0)
+	keys := make([][2]string, 0)
+	for path, methods := range paths {
+		for method := range methods {
+			keys = append(keys, [2]string{path, method})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := paths[keys[i][0]][keys[i][1]], paths[keys[j][0]][keys[j][1]]
+		if a.Dir != b.Dir {
+			return a.Dir < b.Dir
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		// 导入的接口可能没有id
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+
+	groups := make(map[string]*group)
+	groupNames := namer{"Client": true}
+	groupPkgs := namer{}
+	opNames := make(map[string]namer)
+	for _, k := range keys {
+		part := paths[k[0]][k[1]]
+		g, ok := groups[part.Dir]
+		if !ok {
+			g = &group{title: part.Dir}
+			if part.Dir != "" {
+				name := goName(part.Dir)
+				if name == "" {
+					name = fmt.Sprintf("Group%d", len(groups)+1)
+				}
+				g.name = groupNames.unique(name)
+				g.pkg = groupPkgs.unique(goPackage(g.name))
+			}
+			groups[part.Dir] = g
+			opNames[part.Dir] = namer{}
+			d.groups = append(d.groups, g)
+		}
+		g.ops = append(g.ops, newOperation(in, k[1], k[0], part, opNames[part.Dir]))
+	}
+	// 根目录的接口是客户端的方法 不能和目录的字段重名
+	if g, ok := groups[""]; ok {
+		reserved := namer{"Client": true, "Do": true, "New": true, "Request": true}
+		for _, v := range d.groups {
+			reserved[v.name] = true
+		}
+		for _, op := range g.ops {
+			if reserved[op.name] {
+				op.name = reserved.unique(op.name + "API")
+			}
+			reserved[op.name] = true
+		}
+	}
+	// 根目录的接口放在最前面
+	sort.SliceStable(d.groups, func(i, j int) bool {
+		return d.groups[i].title == "" && d.groups[j].title != ""
+	})
+	return d
+}
+
+func newOperation(in *spec.Spec, method, path string, part spec.HTTPPart, names namer) *operation {
+	op := &operation{
+		title:  part.Title,
+		method: strings.ToUpper(method),
+		path:   path,
+	}
+	name := goName(part.Title)
+	if name == "" {
+		// 标题中没有可用的字符时使用请求方法和路径
+		name = goName(method + " " + strings.NewReplacer("{", " by ", "}", " ").Replace(path))
+	}
+	op.name = names.unique(name)
+
+	for _, position := range []string{"path", "query", "header", "cookie"} {
+		for _, p := range part.Parameters.Map()[position] {
+			p = resolveParameter(in, p)
+			if p == nil || p.Schema == nil {
+				continue
+			}
+			op.params = append(op.params, &param{name: p.Name, in: position, required: p.Required || position == "path", schema: p.Schema})
+		}
+	}
+
+	if len(part.Content) > 0 {
+		op.bodyType = preferJSON(part.Content)
+		if c := part.Content[op.bodyType]; c != nil {
+			op.body = c.Schema
+		}
+	}
+
+	codes := make([]int, 0, len(part.Responses))
+	responses := make(map[int]spec.HTTPResponseDefine)
+	for _, r := range part.Responses {
+		def := r.HTTPResponseDefine
+		if def.Ref() {
+			ref := in.Definitions.Responses.LookupID(refID(*def.Reference))
+			if ref == nil {
+				continue
+			}
+			def = *ref
+		}
+		codes = append(codes, r.Code)
+		responses[r.Code] = def
+	}
+	sort.Ints(codes)
+	op.status = 200
+	for _, code := range codes {
+		if code < 200 || code >= 300 {
+			continue
+		}
+		op.status = code
+		if content := responses[code].Content; len(content) > 0 {
+			if k := preferJSON(content); strings.Contains(k, "json") && content[k] != nil {
+				op.response = content[k].Schema
+			}
+		}
+		break
+	}
+	return op
+}
+
+// resolveParameter 参数引用公共参数时返回公共参数的定义
+func resolveParameter(in *spec.Spec, p *spec.Schema) *spec.Schema {
+	if !p.Ref() {
+		return p
+	}
+	if strings.HasPrefix(*p.Reference, "#/definitions/parameters/") {
+		return in.Definitions.Parameters.LookupID(refID(*p.Reference))
+	}
+	return nil
+}
+
+// preferJSON 有多个content type时优先使用json
+func preferJSON(content spec.HTTPBody) string {
+	keys := make([]string, 0, len(content))
+	for k := range content {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.Contains(k, "json") {
+			return k
+		}
+	}
+	return keys[0]
+}
+
+// refID #/definitions/schemas/{id}中的id
+func refID(ref string) int64 {
+	id, _ := strconv.ParseInt(ref[strings.LastIndex(ref, "/")+1:], 10, 64)
+	return id
+}
+
+// properties 按照x-apicat-orders的顺序返回属性名 没有顺序时按名称排序
+func properties(s *jsonschema.Schema) []string {
+	if len(s.XOrder) == len(s.Properties) {
+		ok := true
+		for _, k := range s.XOrder {
+			if _, exists := s.Properties[k]; !exists {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return s.XOrder
+		}
+	}
+	keys := make([]string, 0, len(s.Properties))
+	for k := range s.Properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// schemaType 第一个非null的类型 和是否可以为null
+func schemaType(s *jsonschema.Schema) (string, bool) {
+	nullable := s.Nullable != nil && *s.Nullable
+	var typ string
+	if s.Type != nil {
+		for _, t := range s.Type.Value() {
+			if t == "null" {
+				nullable = true
+			} else if typ == "" {
+				typ = t
+			}
+		}
+	}
+	if typ == "" && len(s.Properties) > 0 {
+		typ = "object"
+	}
+	return typ, nullable
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
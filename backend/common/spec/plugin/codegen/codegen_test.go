@@ -0,0 +1,152 @@
+package codegen
+
+import (
+	"archive/zip"
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/spec/plugin/openapi"
+)
+
+func unzip(t *testing.T, data []byte) map[string]string {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestClientSDK(t *testing.T) {
+	for _, name := range []string{"openapi3.0.yaml", "openapi3-composition.yaml", "swagger.json"} {
+		raw, err := os.ReadFile("../../testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := openapi.Decode(raw)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		b, err := ClientSDK(s)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		files := unzip(t, b)
+		for _, f := range []string{"go/go.mod", "go/client.go", "go/core/core.go", "go/models/models.go", "typescript/models.ts", "typescript/client.ts"} {
+			if _, ok := files[f]; !ok {
+				t.Fatalf("%s: missing %s", name, f)
+			}
+		}
+		for f, content := range files {
+			if !strings.HasSuffix(f, ".go") {
+				continue
+			}
+			if _, err := parser.ParseFile(token.NewFileSet(), f, content, 0); err != nil {
+				t.Fatalf("%s: %s: %v", name, f, err)
+			}
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	cases := []struct{ in, goName, pkg, camel string }{
+		{"get user by id", "GetUserByID", "getuserbyid", "getUserById"},
+		{"HTTPServer", "HTTPServer", "httpserver", "httpServer"},
+		{"用户 list", "List", "list", "list"},
+		{"2fa", "X2fa", "x2fa", "x2fa"},
+		{"default", "Default", "defaultapi", "default_"},
+		{"context", "Context", "contextapi", "context"},
+	}
+	for _, c := range cases {
+		if v := goName(c.in); v != c.goName {
+			t.Errorf("goName(%q) = %q, want %q", c.in, v, c.goName)
+		}
+		if v := goPackage(c.in); v != c.pkg {
+			t.Errorf("goPackage(%q) = %q, want %q", c.in, v, c.pkg)
+		}
+		if v := camelName(c.in); v != c.camel {
+			t.Errorf("camelName(%q) = %q, want %q", c.in, v, c.camel)
+		}
+	}
+}
+
+func TestServerStub(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/openapi3.0.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := openapi.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ServerStub(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := unzip(t, b)
+	for _, f := range []string{"go.mod", "main.go", "models/models.go", "server/handler.go", "server/router.go", "server/runtime.go"} {
+		content, ok := files[f]
+		if !ok {
+			t.Fatalf("missing %s", f)
+		}
+		if strings.HasSuffix(f, ".go") {
+			if _, err := parser.ParseFile(token.NewFileSet(), f, content, 0); err != nil {
+				t.Fatalf("%s: %v", f, err)
+			}
+		}
+	}
+	for _, v := range []string{
+		"FindPetByID(c *gin.Context, req *FindPetByIDRequest) (models.Pet, error)",
+		"PetID *int64 `uri:\"petId\" binding:\"required\"`",
+	} {
+		if !strings.Contains(files["server/handler.go"], v) {
+			t.Errorf("handler.go does not contain %s", v)
+		}
+	}
+	if !strings.Contains(files["server/router.go"], `r.Handle("GET", "/pet/:petId", func(c *gin.Context) {`) {
+		t.Error("router.go does not register /pet/:petId")
+	}
+	if !strings.Contains(files["models/models.go"], "`json:\"tags,omitempty\" form:\"tags\" binding:\"omitempty,dive\"`") {
+		t.Error("models.go does not validate the items of tags")
+	}
+}
+
+func TestBindingTag(t *testing.T) {
+	i := func(v int64) *int64 { return &v }
+	exclusive := &jsonschema.ValueOrBoolean[int64]{}
+	exclusive.SetBoolean(true)
+	cases := []struct {
+		schema   *jsonschema.Schema
+		goType   string
+		required bool
+		want     string
+	}{
+		{&jsonschema.Schema{Type: jsonschema.CreateSliceOrOne("string")}, "string", true, "required"},
+		{&jsonschema.Schema{Type: jsonschema.CreateSliceOrOne("string"), MinLength: i(1), MaxLength: i(8), Format: "email"}, "*string", false, "omitempty,min=1,max=8,email"},
+		{&jsonschema.Schema{Type: jsonschema.CreateSliceOrOne("string"), Enum: []any{"a", "b"}}, "string", true, "required,oneof=a b"},
+		{&jsonschema.Schema{Type: jsonschema.CreateSliceOrOne("string"), Enum: []any{"a b"}}, "string", false, ""},
+		{&jsonschema.Schema{Type: jsonschema.CreateSliceOrOne("integer"), Minimum: i(0), Maximum: i(10), ExclusiveMaximum: exclusive}, "*int64", true, "required,gte=0,lt=10"},
+		{&jsonschema.Schema{Type: jsonschema.CreateSliceOrOne("array"), MinItems: i(1)}, "[]models.Tag", false, "omitempty,min=1,dive"},
+		{&jsonschema.Schema{Type: jsonschema.CreateSliceOrOne("array")}, "[]string", false, ""},
+	}
+	for _, c := range cases {
+		if v := bindingTag(c.schema, c.goType, c.required); v != c.want {
+			t.Errorf("bindingTag(%s) = %q, want %q", c.goType, v, c.want)
+		}
+	}
+}
@@ -0,0 +1,416 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// goFile 生成一个go文件 类型声明和导入的包在生成过程中收集
+type goFile struct {
+	doc     *document
+	pkg     string
+	module  string
+	imports map[string]bool
+	names   namer
+	decls   []string
+	// server 服务端代码 结构体添加form和binding标签
+	server bool
+}
+
+func newGoFile(doc *document, module, pkg string) *goFile {
+	return &goFile{
+		doc:     doc,
+		pkg:     pkg,
+		module:  module,
+		imports: make(map[string]bool),
+		names:   namer{},
+	}
+}
+
+func (f *goFile) use(path string) {
+	f.imports[path] = true
+}
+
+// declare 添加类型声明 返回声明的位置 用于先占位再填充
+func (f *goFile) declare(code string) int {
+	f.decls = append(f.decls, code)
+	return len(f.decls) - 1
+}
+
+// modelType 引用公共模型 在models包之外需要加包名
+func (f *goFile) modelType(name string) string {
+	if f.pkg == "models" {
+		return name
+	}
+	f.use(f.module + "/models")
+	return "models." + name
+}
+
+// typeOf 返回schema对应的go类型 对象类型会声明为名称为hint的结构体
+func (f *goFile) typeOf(s *jsonschema.Schema, hint string) string {
+	if s == nil {
+		return "any"
+	}
+	if s.Ref() {
+		if m, ok := f.doc.refs[refID(*s.Reference)]; ok {
+			return f.modelType(m.name)
+		}
+		return "any"
+	}
+	switch {
+	case len(s.AllOf) == 1:
+		return f.typeOf(s.AllOf[0], hint)
+	case len(s.AllOf) > 1:
+		return f.structType(s, hint)
+	case len(s.AnyOf) > 0 || len(s.OneOf) > 0:
+		// 多种类型时由调用方自行解析
+		f.use("encoding/json")
+		return "json.RawMessage"
+	}
+
+	typ, _ := schemaType(s)
+	switch typ {
+	case "object":
+		if len(s.Properties) > 0 {
+			return f.structType(s, hint)
+		}
+		if s.AdditionalProperties != nil && !s.AdditionalProperties.IsBool() {
+			return "map[string]" + f.typeOf(s.AdditionalProperties.Value(), hint+"Value")
+		}
+		return "map[string]any"
+	case "array":
+		var items *jsonschema.Schema
+		if s.Items != nil && !s.Items.IsBool() {
+			items = s.Items.Value()
+		}
+		return "[]" + f.typeOf(items, hint+"Item")
+	case "string":
+		return "string"
+	case "integer":
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "file":
+		return "[]byte"
+	}
+	return "any"
+}
+
+// fieldType 可选或者可以为null的字段使用指针 切片和map本身可以为nil
+// 服务端必填的数字和布尔值也使用指针 零值无法通过required校验
+func (f *goFile) fieldType(s *jsonschema.Schema, hint string, required bool) string {
+	t := f.typeOf(s, hint)
+	_, nullable := schemaType(s)
+	if f.server && required && (t == "int64" || t == "float64" || t == "bool") {
+		return "*" + t
+	}
+	if (!required || nullable) && !strings.HasPrefix(t, "[]") && !strings.HasPrefix(t, "map[") &&
+		t != "any" && t != "json.RawMessage" {
+		return "*" + t
+	}
+	return t
+}
+
+func (f *goFile) structType(s *jsonschema.Schema, hint string) string {
+	name := f.names.unique(hint)
+	idx := f.declare("")
+
+	var b strings.Builder
+	writeComment(&b, "", name, s.Description)
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+	fields := namer{}
+	var writeFields func(s *jsonschema.Schema)
+	writeFields = func(s *jsonschema.Schema) {
+		for _, v := range s.AllOf {
+			if v.Ref() {
+				// 组合的公共模型作为嵌入字段
+				t := f.typeOf(v, name)
+				fields[t[strings.LastIndex(t, ".")+1:]] = true
+				fmt.Fprintf(&b, "\t%s\n", t)
+			} else {
+				writeFields(v)
+			}
+		}
+		for _, k := range properties(s) {
+			p := s.Properties[k]
+			field := goName(k)
+			if field == "" {
+				field = "Field"
+			}
+			field = fields.unique(field)
+			required := false
+			for _, r := range s.Required {
+				if r == k {
+					required = true
+				}
+			}
+			t := f.fieldType(p, name+field, required)
+			tag := k
+			if !required {
+				tag += ",omitempty"
+			}
+			tags := fmt.Sprintf("json:%s", quote(tag))
+			if f.server {
+				tags += fmt.Sprintf(" form:%s", quote(k))
+				if v := bindingTag(p, t, required); v != "" {
+					tags += fmt.Sprintf(" binding:%s", quote(v))
+				}
+			}
+			writeComment(&b, "\t", "", p.Description)
+			fmt.Fprintf(&b, "\t%s %s `%s`\n", field, t, tags)
+		}
+	}
+	writeFields(s)
+	b.WriteString("}\n")
+	f.decls[idx] = b.String()
+	return name
+}
+
+func writeComment(b *strings.Builder, indent, name, desc string) {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return
+	}
+	lines := strings.Split(desc, "\n")
+	if name != "" {
+		lines[0] = name + " " + lines[0]
+	}
+	for _, l := range lines {
+		fmt.Fprintf(b, "%s// %s\n", indent, strings.TrimSpace(l))
+	}
+}
+
+// source 拼接包声明 导入和代码后使用gofmt格式化
+func (f *goFile) source(body string) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by ApiCat. DO NOT EDIT.\n\npackage %s\n\n", f.pkg)
+	if len(f.imports) > 0 {
+		paths := make([]string, 0, len(f.imports))
+		for k := range f.imports {
+			paths = append(paths, k)
+		}
+		// 标准库在前 其它的包在后
+		std := func(path string) bool {
+			return !strings.HasPrefix(path, f.module+"/") && !strings.Contains(strings.Split(path, "/")[0], ".")
+		}
+		sort.Slice(paths, func(i, j int) bool {
+			if std(paths[i]) != std(paths[j]) {
+				return std(paths[i])
+			}
+			return paths[i] < paths[j]
+		})
+		b.WriteString("import (\n")
+		for i, v := range paths {
+			if i > 0 && std(paths[i-1]) && !std(v) {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "\t%q\n", v)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.WriteString(body)
+	for _, v := range f.decls {
+		b.WriteString("\n")
+		b.WriteString(v)
+	}
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format %s: %w", f.pkg, err)
+	}
+	return src, nil
+}
+
+// goModels 公共模型 对象声明为结构体 其它类型声明为类型定义
+func goModels(doc *document, module string, server bool) ([]byte, error) {
+	f := newGoFile(doc, module, "models")
+	f.server = server
+	for _, m := range doc.models {
+		f.names[m.name] = true
+	}
+	for _, m := range doc.models {
+		typ, _ := schemaType(m.schema)
+		if (typ == "object" && len(m.schema.Properties) > 0) || len(m.schema.AllOf) > 1 {
+			// 名称已经占用 先释放再声明结构体
+			delete(f.names, m.name)
+			f.structType(m.schema, m.name)
+			continue
+		}
+		var b strings.Builder
+		writeComment(&b, "", m.name, m.schema.Description)
+		fmt.Fprintf(&b, "type %s %s\n", m.name, f.typeOf(m.schema, m.name))
+		f.declare(b.String())
+	}
+	return f.source("")
+}
+
+// goParamField 请求结构体中参数的字段
+type goParamField struct {
+	param *param
+	field string
+	typ   string
+}
+
+// goOperation 生成请求结构体和调用方法 recv为方法的接收者
+func goOperation(f *goFile, op *operation, recv, client string) string {
+	var b strings.Builder
+	fields := namer{}
+	params := make([]goParamField, 0, len(op.params))
+	for _, p := range op.params {
+		field := goName(p.name)
+		if field == "" {
+			field = "Param"
+		}
+		field = fields.unique(field)
+		params = append(params, goParamField{param: p, field: field, typ: f.fieldType(p.schema, op.name+field, p.required)})
+	}
+	var bodyField, bodyTyp string
+	if op.body != nil {
+		bodyField = fields.unique("Body")
+		if strings.Contains(op.bodyType, "json") || op.bodyType == "application/x-www-form-urlencoded" {
+			bodyTyp = f.typeOf(op.body, op.name+"Body")
+		} else {
+			f.use("io")
+			bodyTyp = "io.Reader"
+		}
+	}
+	resTyp := ""
+	if op.response != nil {
+		resTyp = f.typeOf(op.response, op.name+"Response")
+	}
+
+	hasReq := len(params) > 0 || bodyField != ""
+	reqName := ""
+	if hasReq {
+		reqName = f.names.unique(op.name + "Request")
+		fmt.Fprintf(&b, "// %s is the request of %s.\n", reqName, op.name)
+		fmt.Fprintf(&b, "type %s struct {\n", reqName)
+		for _, p := range params {
+			fmt.Fprintf(&b, "\t// %s %s\n", p.param.in, p.param.name)
+			fmt.Fprintf(&b, "\t%s %s\n", p.field, p.typ)
+		}
+		if bodyField != "" {
+			fmt.Fprintf(&b, "\t%s %s\n", bodyField, bodyTyp)
+		}
+		b.WriteString("}\n\n")
+	}
+
+	f.use("context")
+	f.use(f.module + "/core")
+	fmt.Fprintf(&b, "// %s %s\n//\n// %s %s\n", op.name, strings.TrimSpace(op.title), op.method, op.path)
+	args := "ctx context.Context"
+	if hasReq {
+		args += ", req *" + reqName
+	}
+	if resTyp != "" {
+		fmt.Fprintf(&b, "func (%s) %s(%s) (%s, error) {\n", recv, op.name, args, resTyp)
+		fmt.Fprintf(&b, "\tvar out %s\n", resTyp)
+	} else {
+		fmt.Fprintf(&b, "func (%s) %s(%s) error {\n", recv, op.name, args)
+	}
+
+	path := quote(op.path)
+	var pathParams, query, header, cookie []string
+	for _, p := range params {
+		kv := fmt.Sprintf("%s: req.%s", quote(p.param.name), p.field)
+		switch p.param.in {
+		case "path":
+			pathParams = append(pathParams, kv)
+		case "query":
+			query = append(query, kv)
+		case "header":
+			header = append(header, kv)
+		case "cookie":
+			cookie = append(cookie, kv)
+		}
+	}
+	if len(pathParams) > 0 {
+		path = fmt.Sprintf("core.Path(%s, map[string]any{%s})", quote(op.path), strings.Join(pathParams, ", "))
+	}
+	b.WriteString("\tr := &core.Request{\n")
+	fmt.Fprintf(&b, "\t\tMethod: %s,\n\t\tPath: %s,\n", quote(op.method), path)
+	for _, v := range []struct {
+		name string
+		list []string
+	}{{"Query", query}, {"Header", header}, {"Cookie", cookie}} {
+		if len(v.list) > 0 {
+			fmt.Fprintf(&b, "\t\t%s: map[string]any{\n", v.name)
+			for _, kv := range v.list {
+				fmt.Fprintf(&b, "\t\t\t%s,\n", kv)
+			}
+			b.WriteString("\t\t},\n")
+		}
+	}
+	if bodyField != "" {
+		fmt.Fprintf(&b, "\t\tBody: req.%s,\n\t\tContentType: %s,\n", bodyField, quote(op.bodyType))
+	}
+	b.WriteString("\t}\n")
+	if resTyp != "" {
+		fmt.Fprintf(&b, "\terr := %s.Do(ctx, r, &out)\n\treturn out, err\n}\n\n", client)
+	} else {
+		fmt.Fprintf(&b, "\treturn %s.Do(ctx, r, nil)\n}\n\n", client)
+	}
+	return b.String()
+}
+
+// goGroup 目录对应的包 接口为Service的方法
+func goGroup(doc *document, module string, g *group) ([]byte, error) {
+	f := newGoFile(doc, module, g.pkg)
+	f.names["Service"] = true
+	f.names["New"] = true
+	f.use(module + "/core")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Service groups the APIs in %s.\ntype Service struct {\n\tclient *core.Client\n}\n\n", g.title)
+	b.WriteString("func New(client *core.Client) *Service {\n\treturn &Service{client: client}\n}\n\n")
+	for _, op := range g.ops {
+		f.names[op.name] = true
+	}
+	for _, op := range g.ops {
+		b.WriteString(goOperation(f, op, "s *Service", "s.client"))
+	}
+	return f.source(b.String())
+}
+
+// goClient 根包 Client包含各个目录的Service 根目录的接口为Client的方法
+func goClient(doc *document, module, pkg string) ([]byte, error) {
+	f := newGoFile(doc, module, pkg)
+	f.names["Client"] = true
+	f.names["New"] = true
+	f.use(module + "/core")
+
+	var b strings.Builder
+	b.WriteString("// Client calls the APIs, grouped by folder in the fields below.\ntype Client struct {\n\t*core.Client\n\n")
+	for _, g := range doc.groups {
+		if g.name == "" {
+			continue
+		}
+		f.use(module + "/" + g.pkg)
+		fmt.Fprintf(&b, "\t// %s %s\n\t%s *%s.Service\n", g.name, g.title, g.name, g.pkg)
+	}
+	b.WriteString("}\n\n")
+	b.WriteString("// New creates a client that sends requests to baseURL.\nfunc New(baseURL string) *Client {\n\tc := core.New(baseURL)\n\treturn &Client{\n\t\tClient: c,\n")
+	for _, g := range doc.groups {
+		if g.name != "" {
+			fmt.Fprintf(&b, "\t\t%s: %s.New(c),\n", g.name, g.pkg)
+		}
+	}
+	b.WriteString("\t}\n}\n\n")
+	for _, g := range doc.groups {
+		if g.name != "" {
+			continue
+		}
+		for _, op := range g.ops {
+			f.names[op.name] = true
+		}
+		for _, op := range g.ops {
+			b.WriteString(goOperation(f, op, "c *Client", "c.Client"))
+		}
+	}
+	return f.source(b.String())
+}
@@ -0,0 +1,139 @@
+package codegen
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// go中需要全部大写的缩写
+var initialisms = map[string]bool{
+	"api": true, "db": true, "dns": true, "html": true, "http": true, "https": true, "id": true, "ip": true,
+	"json": true, "sql": true, "ssh": true, "tcp": true, "tls": true, "ttl": true, "ui": true, "uid": true,
+	"uri": true, "url": true, "uuid": true, "xml": true,
+}
+
+// ts中不能作为标识符的保留字
+var tsReserved = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true, "debugger": true,
+	"default": true, "delete": true, "do": true, "else": true, "enum": true, "export": true, "extends": true,
+	"false": true, "finally": true, "for": true, "function": true, "if": true, "import": true, "in": true,
+	"instanceof": true, "new": true, "null": true, "return": true, "super": true, "switch": true, "this": true,
+	"throw": true, "true": true, "try": true, "typeof": true, "var": true, "void": true, "while": true, "with": true,
+}
+
+// words 拆分为单词 非ascii字母和数字的字符作为分隔 驼峰处也会拆分
+func words(s string) []string {
+	var (
+		list []string
+		cur  []rune
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			list = append(list, string(cur))
+			cur = cur[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			flush()
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := cur[len(cur)-1]
+			// userId -> user Id, HTTPServer -> HTTP Server
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(prev)) {
+				flush()
+			}
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return list
+}
+
+// goName 导出的go标识符 没有可用的字符时返回空字符串
+func goName(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		lw := strings.ToLower(w)
+		if initialisms[lw] {
+			b.WriteString(strings.ToUpper(lw))
+		} else {
+			b.WriteString(strings.ToUpper(lw[:1]) + lw[1:])
+		}
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+	return name
+}
+
+// 生成的代码中已经导入的包名 目录的包名不能和它们相同
+var goImported = map[string]bool{
+	"context": true, "core": true, "encoding": true, "io": true, "json": true, "models": true,
+}
+
+// goPackage go的包名 全部小写
+func goPackage(s string) string {
+	name := strings.ToLower(strings.Join(words(s), ""))
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "x" + name
+	}
+	if token.IsKeyword(name) || goImported[name] {
+		name += "api"
+	}
+	return name
+}
+
+// camelName ts的属性和方法名
+func camelName(s string) string {
+	var b strings.Builder
+	for i, w := range words(s) {
+		lw := strings.ToLower(w)
+		if i == 0 {
+			b.WriteString(lw)
+		} else {
+			b.WriteString(strings.ToUpper(lw[:1]) + lw[1:])
+		}
+	}
+	name := b.String()
+	if name != "" && unicode.IsDigit(rune(name[0])) {
+		name = "x" + name
+	}
+	if tsReserved[name] {
+		name += "_"
+	}
+	return name
+}
+
+// tsKey 合法的标识符直接作为属性名 否则使用引号
+func tsKey(s string) string {
+	valid := s != ""
+	for i, r := range s {
+		if !(r == '_' || r == '$' || r <= unicode.MaxASCII && unicode.IsLetter(r) || i > 0 && unicode.IsDigit(r)) {
+			valid = false
+			break
+		}
+	}
+	if valid {
+		return s
+	}
+	return quote(s)
+}
+
+// namer 保证同一个作用域中的名称不重复
+type namer map[string]bool
+
+func (n namer) unique(name string) string {
+	res := name
+	for i := 2; n[res]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+	n[res] = true
+	return res
+}
@@ -0,0 +1,369 @@
+package codegen
+
+// goCore 生成的go客户端共用的请求代码
+const goCore = `// Code generated by ApiCat. DO NOT EDIT.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Client sends requests to BaseURL. Header is added to every request.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	Header     http.Header
+}
+
+func New(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: http.DefaultClient,
+		Header:     make(http.Header),
+	}
+}
+
+// Request describes one API call. Nil parameter values are skipped.
+type Request struct {
+	Method      string
+	Path        string
+	Query       map[string]any
+	Header      map[string]any
+	Cookie      map[string]any
+	Body        any
+	ContentType string
+}
+
+// Error is returned when the server responds with a non-2xx status.
+type Error struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api error: status %d: %s", e.StatusCode, e.Body)
+}
+
+// Path replaces the {name} placeholders in template with escaped values.
+func Path(template string, params map[string]any) string {
+	for k, v := range params {
+		if values := stringValues(v); len(values) > 0 {
+			template = strings.ReplaceAll(template, "{"+k+"}", url.PathEscape(values[0]))
+		}
+	}
+	return template
+}
+
+// Do sends the request and decodes a JSON response into out when out is not nil.
+func (c *Client) Do(ctx context.Context, r *Request, out any) error {
+	u := c.BaseURL + r.Path
+	query := url.Values{}
+	for k, v := range r.Query {
+		for _, s := range stringValues(v) {
+			query.Add(k, s)
+		}
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	body, err := encodeBody(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, r.Method, u, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+	for k, v := range r.Header {
+		for _, s := range stringValues(v) {
+			req.Header.Add(k, s)
+		}
+	}
+	for k, v := range r.Cookie {
+		for _, s := range stringValues(v) {
+			req.AddCookie(&http.Cookie{Name: k, Value: s})
+		}
+	}
+	if body != nil && r.ContentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", r.ContentType)
+	}
+
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return &Error{StatusCode: res.StatusCode, Body: data}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func encodeBody(r *Request) (io.Reader, error) {
+	if isNil(r.Body) {
+		return nil, nil
+	}
+	if reader, ok := r.Body.(io.Reader); ok {
+		return reader, nil
+	}
+	data, err := json.Marshal(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if r.ContentType != "application/x-www-form-urlencoded" {
+		return bytes.NewReader(data), nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	for k, v := range fields {
+		for _, s := range stringValues(v) {
+			form.Add(k, s)
+		}
+	}
+	return strings.NewReader(form.Encode()), nil
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// stringValues converts a parameter value into strings, a slice becomes multiple values.
+func stringValues(v any) []string {
+	if isNil(v) {
+		return nil
+	}
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		list := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			list = append(list, stringValues(rv.Index(i).Interface())...)
+		}
+		return list
+	}
+	return []string{fmt.Sprint(rv.Interface())}
+}
+`
+
+// tsRuntime 生成的ts客户端共用的请求代码
+const tsRuntime = `export interface ClientOptions {
+  baseUrl: string
+  headers?: Record<string, string>
+  fetch?: typeof fetch
+}
+
+export interface RequestOptions {
+  method: string
+  path: string
+  pathParams?: Record<string, unknown>
+  query?: Record<string, unknown>
+  headers?: Record<string, unknown>
+  body?: unknown
+  contentType?: string
+}
+
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(` + "`api error: status ${status}`" + `)
+  }
+}
+
+export class HttpClient {
+  constructor(readonly options: ClientOptions) {}
+
+  async request<T>(req: RequestOptions): Promise<T> {
+    let path = req.path
+    for (const [k, v] of Object.entries(req.pathParams ?? {})) {
+      path = path.replace(` + "`{${k}}`" + `, encodeURIComponent(String(v)))
+    }
+    const url = new URL(this.options.baseUrl.replace(/\/$/, '') + path)
+    for (const [k, v] of Object.entries(req.query ?? {})) {
+      if (v === undefined || v === null) continue
+      for (const item of Array.isArray(v) ? v : [v]) url.searchParams.append(k, String(item))
+    }
+    const headers: Record<string, string> = { ...this.options.headers }
+    for (const [k, v] of Object.entries(req.headers ?? {})) {
+      if (v !== undefined && v !== null) headers[k] = String(v)
+    }
+
+    let body: BodyInit | undefined
+    if (req.body !== undefined && req.body !== null) {
+      if (req.contentType?.includes('json')) {
+        body = JSON.stringify(req.body)
+        headers['Content-Type'] = req.contentType
+      } else if (req.contentType === 'application/x-www-form-urlencoded') {
+        body = new URLSearchParams(req.body as Record<string, string>)
+      } else {
+        body = req.body as BodyInit
+        // multipart的boundary由fetch设置
+        if (req.contentType && req.contentType !== 'multipart/form-data') headers['Content-Type'] = req.contentType
+      }
+    }
+
+    const res = await (this.options.fetch ?? fetch)(url, { method: req.method, headers, body })
+    const text = await res.text()
+    const data = text && (res.headers.get('Content-Type') ?? '').includes('json') ? JSON.parse(text) : text
+    if (!res.ok) throw new ApiError(res.status, data)
+    return data as T
+  }
+}
+`
+
+// serverMain 服务端的入口 %s为module
+const serverMain = `package main
+
+import (
+	"%s/server"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	r := gin.Default()
+	// Replace server.Unimplemented{} with your implementation of server.Handler.
+	server.Register(r, server.Unimplemented{})
+	if err := r.Run(":8080"); err != nil {
+		panic(err)
+	}
+}
+`
+
+// serverRuntime 服务端绑定请求和响应的代码
+const serverRuntime = `// Code generated by ApiCat. DO NOT EDIT.
+
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Error is returned by a handler to respond with the given status code.
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrNotImplemented is returned by Unimplemented.
+var ErrNotImplemented = &Error{Status: http.StatusNotImplemented, Message: "not implemented"}
+
+func bindPath(c *gin.Context, ptr any) error {
+	values := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		values[p.Key] = []string{p.Value}
+	}
+	return binding.MapFormWithTag(ptr, values, "uri")
+}
+
+func bindQuery(c *gin.Context, ptr any) error {
+	return binding.MapFormWithTag(ptr, c.Request.URL.Query(), "form")
+}
+
+func bindHeader(c *gin.Context, ptr any) error {
+	return binding.MapFormWithTag(ptr, c.Request.Header, "header")
+}
+
+func bindCookie(c *gin.Context, ptr any) error {
+	values := make(map[string][]string)
+	for _, v := range c.Request.Cookies() {
+		values[v.Name] = append(values[v.Name], v.Value)
+	}
+	return binding.MapFormWithTag(ptr, values, "cookie")
+}
+
+// bindBody decodes a JSON or form body, an empty body is allowed.
+func bindBody(c *gin.Context, ptr any) error {
+	if c.ContentType() == binding.MIMEPOSTForm {
+		if err := c.Request.ParseForm(); err != nil {
+			return err
+		}
+		return binding.MapFormWithTag(ptr, c.Request.PostForm, "form")
+	}
+	if c.Request.Body == nil {
+		return nil
+	}
+	if err := json.NewDecoder(c.Request.Body).Decode(ptr); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// bind validates the request once all parts are bound. It responds 400 and returns false on errors.
+func bind(c *gin.Context, req any, errs ...error) bool {
+	for _, err := range errs {
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return false
+		}
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return false
+	}
+	return true
+}
+
+// respond writes the result of a handler. An *Error responds with its status code.
+func respond(c *gin.Context, status int, res any, err error) {
+	if err != nil {
+		var e *Error
+		if errors.As(err, &e) {
+			c.AbortWithStatusJSON(e.Status, gin.H{"message": e.Message})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	if res == nil {
+		c.Status(status)
+		return
+	}
+	c.JSON(status, res)
+}
+`
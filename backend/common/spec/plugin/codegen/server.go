@@ -0,0 +1,347 @@
+package codegen
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/textproto"
+	"regexp"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// gin的版本 生成的go.mod中使用
+const ginVersion = "v1.9.0"
+
+// 字符串格式对应的校验规则
+var formatRules = map[string]string{
+	"email":     "email",
+	"uri":       "url",
+	"url":       "url",
+	"uuid":      "uuid",
+	"date-time": "datetime=2006-01-02T15:04:05Z07:00",
+	"date":      "datetime=2006-01-02",
+	"ipv4":      "ipv4",
+	"ipv6":      "ipv6",
+	"hostname":  "hostname",
+}
+
+// bindingTag 根据jsonschema的约束生成validator的校验规则 引用的模型由模型自身的标签校验
+func bindingTag(s *jsonschema.Schema, goType string, required bool) string {
+	var rules []string
+	typ, _ := schemaType(s)
+	if s.Ref() {
+		typ = ""
+	}
+	switch typ {
+	case "string":
+		if s.MinLength != nil {
+			rules = append(rules, fmt.Sprintf("min=%d", *s.MinLength))
+		}
+		if s.MaxLength != nil {
+			rules = append(rules, fmt.Sprintf("max=%d", *s.MaxLength))
+		}
+		if v := formatRules[s.Format]; v != "" {
+			rules = append(rules, v)
+		}
+		if v := enumRule(s.Enum); v != "" {
+			rules = append(rules, v)
+		}
+	case "integer", "number":
+		rules = append(rules, boundRule(s.Minimum, s.ExclusiveMinimum, "gte", "gt")...)
+		rules = append(rules, boundRule(s.Maximum, s.ExclusiveMaximum, "lte", "lt")...)
+		if v := enumRule(s.Enum); v != "" {
+			rules = append(rules, v)
+		}
+	case "array":
+		if s.MinItems != nil {
+			rules = append(rules, fmt.Sprintf("min=%d", *s.MinItems))
+		}
+		if s.MaxItems != nil {
+			rules = append(rules, fmt.Sprintf("max=%d", *s.MaxItems))
+		}
+		// 元素是结构体时需要逐个校验
+		elem := strings.TrimLeft(strings.TrimPrefix(goType, "[]"), "*")
+		if strings.HasPrefix(goType, "[]") && !isBuiltin(elem) {
+			rules = append(rules, "dive")
+		}
+	}
+	if len(rules) == 0 {
+		if required {
+			return "required"
+		}
+		return ""
+	}
+	if required {
+		return "required," + strings.Join(rules, ",")
+	}
+	return "omitempty," + strings.Join(rules, ",")
+}
+
+// boundRule 最大值和最小值 exclusive在3.0中是布尔值 在3.1中是边界值
+func boundRule(bound *int64, exclusive *jsonschema.ValueOrBoolean[int64], inclusiveRule, exclusiveRule string) []string {
+	var rules []string
+	if bound != nil {
+		rule := inclusiveRule
+		if exclusive != nil && exclusive.Bool() {
+			rule = exclusiveRule
+		}
+		rules = append(rules, fmt.Sprintf("%s=%d", rule, *bound))
+	}
+	if exclusive != nil && !exclusive.IsBool() {
+		rules = append(rules, fmt.Sprintf("%s=%d", exclusiveRule, exclusive.Value()))
+	}
+	return rules
+}
+
+// enumRule 枚举值中包含空格或者不是字符串和数字时无法使用oneof
+func enumRule(values []any) string {
+	list := make([]string, 0, len(values))
+	for _, v := range values {
+		switch v.(type) {
+		case string, float64, int, int64:
+		default:
+			return ""
+		}
+		s := fmt.Sprint(v)
+		if s == "" || strings.ContainsAny(s, " ,|'") {
+			return ""
+		}
+		list = append(list, s)
+	}
+	if len(list) == 0 {
+		return ""
+	}
+	return "oneof=" + strings.Join(list, " ")
+}
+
+func isBuiltin(t string) bool {
+	switch t {
+	case "string", "int64", "float64", "bool", "any", "byte":
+		return true
+	}
+	return strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[")
+}
+
+// serverOperation 服务端的接口 多个目录中的接口合并到同一个Handler中
+type serverOperation struct {
+	*operation
+	name string
+	req  string
+}
+
+// serverOperations 接口重名时先加上目录名 仍然重名时加上序号
+func serverOperations(doc *document) []*serverOperation {
+	names := namer{}
+	var list []*serverOperation
+	for _, g := range doc.groups {
+		for _, op := range g.ops {
+			name := op.name
+			if names[name] && g.name != "" {
+				name = g.name + name
+			}
+			list = append(list, &serverOperation{operation: op, name: names.unique(name)})
+		}
+	}
+	return list
+}
+
+// serverRequest 请求结构体 参数按照位置分为不同的结构体 绑定时互不影响
+func serverRequest(f *goFile, op *serverOperation) {
+	positions := []struct{ in, field, tag string }{
+		{"path", "Path", "uri"}, {"query", "Query", "form"}, {"header", "Header", "header"}, {"cookie", "Cookie", "cookie"},
+	}
+	hasBody := op.body != nil && (strings.Contains(op.bodyType, "json") || op.bodyType == "application/x-www-form-urlencoded")
+	if len(op.params) == 0 && !hasBody {
+		return
+	}
+
+	op.req = f.names.unique(op.name + "Request")
+	idx := f.declare("")
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s is the request of %s.\ntype %s struct {\n", op.req, op.name, op.req)
+	for _, pos := range positions {
+		var fields strings.Builder
+		names := namer{}
+		for _, p := range op.params {
+			if p.in != pos.in {
+				continue
+			}
+			field := goName(p.name)
+			if field == "" {
+				field = "Param"
+			}
+			field = names.unique(field)
+			t := f.fieldType(p.schema, op.name+pos.field+field, p.required)
+			key := p.name
+			if pos.in == "header" {
+				key = textproto.CanonicalMIMEHeaderKey(key)
+			}
+			tags := fmt.Sprintf("%s:%s", pos.tag, quote(key))
+			if v := bindingTag(p.schema, t, p.required); v != "" {
+				tags += fmt.Sprintf(" binding:%s", quote(v))
+			}
+			writeComment(&fields, "\t", "", p.schema.Description)
+			fmt.Fprintf(&fields, "\t%s %s `%s`\n", field, t, tags)
+		}
+		if fields.Len() == 0 {
+			continue
+		}
+		name := f.names.unique(op.name + pos.field)
+		f.declare(fmt.Sprintf("type %s struct {\n%s}\n", name, fields.String()))
+		fmt.Fprintf(&b, "\t%s %s\n", pos.field, name)
+	}
+	if hasBody {
+		fmt.Fprintf(&b, "\tBody %s\n", f.typeOf(op.body, op.name+"Body"))
+	}
+	b.WriteString("}\n")
+	f.decls[idx] = b.String()
+}
+
+// serverHandler Handler接口和未实现的默认实现
+func serverHandler(doc *document, module string, ops []*serverOperation) ([]byte, error) {
+	f := newGoFile(doc, module, "server")
+	f.server = true
+	for _, v := range []string{"Handler", "Unimplemented", "Error", "ErrNotImplemented", "Register"} {
+		f.names[v] = true
+	}
+	f.use("github.com/gin-gonic/gin")
+
+	results := make([]string, len(ops))
+	for i, op := range ops {
+		serverRequest(f, op)
+		if op.response != nil {
+			results[i] = f.typeOf(op.response, op.name+"Response")
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("// Handler implements the APIs. Embed Unimplemented to implement them one by one.\ntype Handler interface {\n")
+	for i, op := range ops {
+		fmt.Fprintf(&b, "\t// %s %s\n\t//\n\t// %s %s\n", op.name, strings.TrimSpace(op.title), op.method, op.path)
+		fmt.Fprintf(&b, "\t%s\n", serverSignature(op, results[i]))
+	}
+	b.WriteString("}\n\n")
+
+	b.WriteString("// Unimplemented responds 501 to all APIs.\ntype Unimplemented struct{}\n\n")
+	for i, op := range ops {
+		fmt.Fprintf(&b, "func (Unimplemented) %s {\n", serverSignature(op, results[i]))
+		if results[i] != "" {
+			fmt.Fprintf(&b, "\tvar out %s\n\treturn out, ErrNotImplemented\n}\n\n", results[i])
+		} else {
+			b.WriteString("\treturn ErrNotImplemented\n}\n\n")
+		}
+	}
+	return f.source(b.String())
+}
+
+func serverSignature(op *serverOperation, result string) string {
+	args := "c *gin.Context"
+	if op.req != "" {
+		args += ", req *" + op.req
+	}
+	if result != "" {
+		return fmt.Sprintf("%s(%s) (%s, error)", op.name, args, result)
+	}
+	return fmt.Sprintf("%s(%s) error", op.name, args)
+}
+
+var pathParamRegexp = regexp.MustCompile(`{([^/{}]+)}`)
+
+// ginPath /users/{id} -> /users/:id
+func ginPath(path string) string {
+	return pathParamRegexp.ReplaceAllString(path, ":$1")
+}
+
+// serverRouter 注册路由 绑定和校验请求后调用Handler
+func serverRouter(doc *document, module string, ops []*serverOperation) ([]byte, error) {
+	f := newGoFile(doc, module, "server")
+	f.use("github.com/gin-gonic/gin")
+
+	var b strings.Builder
+	b.WriteString("// Register adds the routes of all APIs to r.\nfunc Register(r gin.IRoutes, h Handler) {\n")
+	for _, op := range ops {
+		fmt.Fprintf(&b, "\tr.Handle(%s, %s, func(c *gin.Context) {\n", quote(op.method), quote(ginPath(op.path)))
+		call := fmt.Sprintf("h.%s(c)", op.name)
+		if op.req != "" {
+			var binds []string
+			fields := map[string]string{"path": "Path", "query": "Query", "header": "Header", "cookie": "Cookie"}
+			for _, in := range []string{"path", "query", "header", "cookie"} {
+				for _, p := range op.params {
+					if p.in == in {
+						binds = append(binds, fmt.Sprintf("bind%s(c, &req.%s)", fields[in], fields[in]))
+						break
+					}
+				}
+			}
+			if op.body != nil && (strings.Contains(op.bodyType, "json") || op.bodyType == "application/x-www-form-urlencoded") {
+				binds = append(binds, "bindBody(c, &req.Body)")
+			}
+			fmt.Fprintf(&b, "\t\tvar req %s\n", op.req)
+			fmt.Fprintf(&b, "\t\tif !bind(c, &req, %s) {\n\t\t\treturn\n\t\t}\n", strings.Join(binds, ", "))
+			call = fmt.Sprintf("h.%s(c, &req)", op.name)
+		}
+		if op.response != nil {
+			fmt.Fprintf(&b, "\t\tres, err := %s\n\t\trespond(c, %d, res, err)\n", call, op.status)
+		} else {
+			fmt.Fprintf(&b, "\t\trespond(c, %d, nil, %s)\n", op.status, call)
+		}
+		b.WriteString("\t})\n")
+	}
+	b.WriteString("}\n")
+	return f.source(b.String())
+}
+
+// ServerStub 生成gin的服务端代码 打包为zip
+func ServerStub(in *spec.Spec) ([]byte, error) {
+	doc := newDocument(in)
+	pkg := goPackage(doc.name)
+	if pkg == "" || pkg == "main" {
+		pkg = "server"
+	}
+	ops := serverOperations(doc)
+
+	// serverHandler会设置请求结构体的名称 需要在serverRouter之前生成
+	handler, err := serverHandler(doc, pkg, ops)
+	if err != nil {
+		return nil, err
+	}
+	router, err := serverRouter(doc, pkg, ops)
+	if err != nil {
+		return nil, err
+	}
+	models, err := goModels(doc, pkg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"go.mod", []byte(fmt.Sprintf("module %s\n\ngo 1.20\n\nrequire github.com/gin-gonic/gin %s\n", pkg, ginVersion))},
+		{"main.go", []byte(fmt.Sprintf(serverMain, pkg))},
+		{"models/models.go", models},
+		{"server/handler.go", handler},
+		{"server/router.go", router},
+		{"server/runtime.go", []byte(serverRuntime)},
+	}
+
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,291 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+// tsType 返回schema对应的ts类型 prefix为引用公共模型时的命名空间
+func tsType(doc *document, s *jsonschema.Schema, prefix, indent string) string {
+	if s == nil {
+		return "unknown"
+	}
+	if s.Ref() {
+		if m, ok := doc.refs[refID(*s.Reference)]; ok {
+			return prefix + m.name
+		}
+		return "unknown"
+	}
+
+	var t string
+	typ, nullable := schemaType(s)
+	switch {
+	case len(s.AllOf) > 0:
+		t = tsJoin(doc, s.AllOf, " & ", prefix, indent)
+	case len(s.AnyOf) > 0:
+		t = tsJoin(doc, s.AnyOf, " | ", prefix, indent)
+	case len(s.OneOf) > 0:
+		t = tsJoin(doc, s.OneOf, " | ", prefix, indent)
+	case len(s.Enum) > 0:
+		list := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			if b, err := json.Marshal(v); err == nil {
+				list = append(list, string(b))
+			}
+		}
+		t = strings.Join(list, " | ")
+	case typ == "object":
+		if len(s.Properties) > 0 {
+			t = tsObject(doc, s, prefix, indent)
+		} else if s.AdditionalProperties != nil && !s.AdditionalProperties.IsBool() {
+			t = fmt.Sprintf("Record<string, %s>", tsType(doc, s.AdditionalProperties.Value(), prefix, indent))
+		} else {
+			t = "Record<string, unknown>"
+		}
+	case typ == "array":
+		var items *jsonschema.Schema
+		if s.Items != nil && !s.Items.IsBool() {
+			items = s.Items.Value()
+		}
+		t = fmt.Sprintf("Array<%s>", tsType(doc, items, prefix, indent))
+	case typ == "string":
+		t = "string"
+	case typ == "integer", typ == "number":
+		t = "number"
+	case typ == "boolean":
+		t = "boolean"
+	case typ == "file":
+		t = "Blob"
+	default:
+		t = "unknown"
+	}
+	if nullable && t != "unknown" {
+		t += " | null"
+	}
+	return t
+}
+
+func tsJoin(doc *document, list []*jsonschema.Schema, sep, prefix, indent string) string {
+	types := make([]string, 0, len(list))
+	for _, v := range list {
+		t := tsType(doc, v, prefix, indent)
+		if strings.Contains(t, " | ") || strings.Contains(t, " & ") {
+			t = "(" + t + ")"
+		}
+		types = append(types, t)
+	}
+	return strings.Join(types, sep)
+}
+
+// tsObject 对象的属性 不在required中的属性是可选的
+func tsObject(doc *document, s *jsonschema.Schema, prefix, indent string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for _, k := range properties(s) {
+		p := s.Properties[k]
+		optional := "?"
+		for _, r := range s.Required {
+			if r == k {
+				optional = ""
+			}
+		}
+		tsComment(&b, indent+"  ", p.Description)
+		fmt.Fprintf(&b, "%s  %s%s: %s\n", indent, tsKey(k), optional, tsType(doc, p, prefix, indent+"  "))
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+func tsComment(b *strings.Builder, indent, desc string) {
+	desc = strings.TrimSpace(desc)
+	if desc == "" {
+		return
+	}
+	lines := strings.Split(desc, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(b, "%s/** %s */\n", indent, desc)
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, l := range lines {
+		fmt.Fprintf(b, "%s * %s\n", indent, strings.TrimSpace(l))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+// tsModels 公共模型 有属性的对象声明为interface 其它声明为type
+func tsModels(doc *document) []byte {
+	var b strings.Builder
+	b.WriteString("// Code generated by ApiCat. DO NOT EDIT.\n")
+	for _, m := range doc.models {
+		b.WriteString("\n")
+		tsComment(&b, "", m.schema.Description)
+		typ, _ := schemaType(m.schema)
+		if typ == "object" && len(m.schema.Properties) > 0 && len(m.schema.AllOf) == 0 &&
+			len(m.schema.AnyOf) == 0 && len(m.schema.OneOf) == 0 && len(m.schema.Enum) == 0 {
+			fmt.Fprintf(&b, "export interface %s %s\n", m.name, tsObject(doc, m.schema, "", ""))
+			continue
+		}
+		fmt.Fprintf(&b, "export type %s = %s\n", m.name, tsType(doc, m.schema, "", ""))
+	}
+	if len(doc.models) == 0 {
+		b.WriteString("\nexport {}\n")
+	}
+	return []byte(b.String())
+}
+
+// tsParamField 参数对象中的属性 path query header参数和请求体合并在同一个对象中
+type tsParamField struct {
+	param *param
+	key   string
+}
+
+// tsOperation 生成参数类型和调用方法 params写入namespace中 返回方法的代码
+func tsOperation(doc *document, op *operation, namespace string, params *strings.Builder, method string) string {
+	keys := namer{}
+	fields := make([]tsParamField, 0, len(op.params))
+	for _, p := range op.params {
+		// 浏览器中无法设置cookie 由fetch自动携带
+		if p.in == "cookie" {
+			continue
+		}
+		key := camelName(p.name)
+		if key == "" {
+			key = "param"
+		}
+		fields = append(fields, tsParamField{param: p, key: keys.unique(key)})
+	}
+	bodyKey := ""
+	if op.body != nil {
+		bodyKey = keys.unique("body")
+	}
+
+	paramsType := ""
+	if len(fields) > 0 || bodyKey != "" {
+		name := op.name + "Params"
+		paramsType = name
+		if namespace != "" {
+			paramsType = namespace + "." + name
+		}
+		indent := ""
+		if namespace != "" {
+			indent = "  "
+		}
+		fmt.Fprintf(params, "%sexport interface %s {\n", indent, name)
+		for _, f := range fields {
+			optional := "?"
+			if f.param.required {
+				optional = ""
+			}
+			tsComment(params, indent+"  ", fmt.Sprintf("%s %s", f.param.in, f.param.name))
+			fmt.Fprintf(params, "%s  %s%s: %s\n", indent, f.key, optional, tsType(doc, f.param.schema, "models.", indent+"  "))
+		}
+		if bodyKey != "" {
+			var t string
+			switch {
+			case strings.Contains(op.bodyType, "json"), op.bodyType == "application/x-www-form-urlencoded":
+				t = tsType(doc, op.body, "models.", indent+"  ")
+			case op.bodyType == "multipart/form-data":
+				t = "FormData"
+			default:
+				t = "BodyInit"
+			}
+			fmt.Fprintf(params, "%s  %s: %s\n", indent, bodyKey, t)
+		}
+		fmt.Fprintf(params, "%s}\n\n", indent)
+	}
+
+	resType := "void"
+	if op.response != nil {
+		resType = tsType(doc, op.response, "models.", "  ")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  /**\n   * %s\n   *\n   * %s %s\n   */\n", strings.TrimSpace(op.title), op.method, op.path)
+	if paramsType != "" {
+		fmt.Fprintf(&b, "  %s(params: %s): Promise<%s> {\n", method, paramsType, resType)
+	} else {
+		fmt.Fprintf(&b, "  %s(): Promise<%s> {\n", method, resType)
+	}
+	fmt.Fprintf(&b, "    return this.http.request<%s>({\n", resType)
+	fmt.Fprintf(&b, "      method: %s,\n      path: %s,\n", quote(op.method), quote(op.path))
+	for _, in := range []struct{ in, key string }{{"path", "pathParams"}, {"query", "query"}, {"header", "headers"}} {
+		var list []string
+		for _, f := range fields {
+			if f.param.in == in.in {
+				list = append(list, fmt.Sprintf("%s: params.%s", tsKey(f.param.name), f.key))
+			}
+		}
+		if len(list) > 0 {
+			fmt.Fprintf(&b, "      %s: { %s },\n", in.key, strings.Join(list, ", "))
+		}
+	}
+	if bodyKey != "" {
+		fmt.Fprintf(&b, "      body: params.%s,\n      contentType: %s,\n", bodyKey, quote(op.bodyType))
+	}
+	b.WriteString("    })\n  }\n")
+	return b.String()
+}
+
+// tsClient 目录对应一个类和同名的namespace ApiClient的属性为各个目录的类 根目录的接口为ApiClient的方法
+func tsClient(doc *document) []byte {
+	var b strings.Builder
+	b.WriteString("// Code generated by ApiCat. DO NOT EDIT.\n\nimport * as models from './models'\n\n")
+	b.WriteString(tsRuntime)
+
+	names := namer{"models": true, "ClientOptions": true, "RequestOptions": true, "ApiError": true, "HttpClient": true, "ApiClient": true}
+	props := namer{"http": true, "options": true, "request": true}
+	type member struct{ prop, class string }
+	var members []member
+
+	for _, g := range doc.groups {
+		if g.name == "" {
+			continue
+		}
+		namespace := names.unique(g.name)
+		class := names.unique(g.name + "Api")
+		members = append(members, member{prop: props.unique(camelName(g.name)), class: class})
+
+		var params, methods strings.Builder
+		methodNames := namer{}
+		for _, op := range g.ops {
+			methods.WriteString("\n")
+			methods.WriteString(tsOperation(doc, op, namespace, &params, methodNames.unique(camelName(op.name))))
+		}
+		if params.Len() > 0 {
+			fmt.Fprintf(&b, "\nexport namespace %s {\n%s}\n", namespace, strings.TrimSuffix(params.String(), "\n"))
+		}
+		fmt.Fprintf(&b, "\n/** %s */\nexport class %s {\n  constructor(private readonly http: HttpClient) {}\n%s}\n", g.title, class, methods.String())
+	}
+
+	var params, methods strings.Builder
+	for _, g := range doc.groups {
+		if g.name != "" {
+			continue
+		}
+		for _, op := range g.ops {
+			methods.WriteString("\n")
+			methods.WriteString(tsOperation(doc, op, "", &params, props.unique(camelName(op.name))))
+		}
+	}
+	if params.Len() > 0 {
+		b.WriteString("\n")
+		b.WriteString(strings.TrimSuffix(params.String(), "\n"))
+	}
+
+	b.WriteString("\nexport class ApiClient {\n  readonly http: HttpClient\n")
+	for _, m := range members {
+		fmt.Fprintf(&b, "  readonly %s: %s\n", m.prop, m.class)
+	}
+	b.WriteString("\n  constructor(options: ClientOptions) {\n    this.http = new HttpClient(options)\n")
+	for _, m := range members {
+		fmt.Fprintf(&b, "    this.%s = new %s(this.http)\n", m.prop, m.class)
+	}
+	b.WriteString("  }\n")
+	b.WriteString(methods.String())
+	b.WriteString("}\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,400 @@
+package curl
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"golang.org/x/exp/slices"
+)
+
+// 需要参数但和接口无关的选项 解析时跳过参数
+var ignoredValueFlags = []string{
+	"-o", "--output", "-m", "--max-time", "--connect-timeout", "-x", "--proxy", "-w", "--write-out", "--retry", "-c", "--cookie-jar", "--cacert", "--cert", "--key", "-r", "--range", "--resolve",
+}
+
+// command 解析后的curl命令
+type command struct {
+	method  string
+	url     string
+	get     bool
+	headers [][2]string
+	cookies [][2]string
+	data    []string
+	form    [][2]string
+	user    string
+}
+
+// Import 将curl命令解析为http接口 路径中的数字和uuid作为路径参数
+// json和表单body根据示例推断schema
+func Import(cmd string) (*spec.CollectItem, error) {
+	args, err := split(cmd)
+	if err != nil {
+		return nil, err
+	}
+	c, err := parse(args)
+	if err != nil {
+		return nil, err
+	}
+	return c.collectItem()
+}
+
+// split 按照shell的规则拆分参数 支持单双引号 反斜杠转义和续行
+func split(cmd string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			escaped = false
+			// 续行
+			if r == '\n' {
+				continue
+			}
+			if quote == '"' && r != '"' && r != '\\' && r != '$' && r != '`' {
+				cur.WriteRune('\\')
+			}
+			cur.WriteRune(r)
+			inArg = true
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+func parse(args []string) (*command, error) {
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("not a curl command")
+	}
+	c := &command{}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		// --flag=value的形式
+		flag, value, hasValue := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			if k, v, ok := strings.Cut(arg, "="); ok {
+				flag, value, hasValue = k, v, true
+			}
+		} else if len(arg) > 2 && arg[0] == '-' && strings.ContainsRune("XHdFubA", rune(arg[1])) {
+			// -XPOST的形式
+			flag, value, hasValue = arg[:2], arg[2:], true
+		}
+		next := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires a value", flag)
+			}
+			i++
+			return args[i], nil
+		}
+
+		var err error
+		switch flag {
+		case "-X", "--request":
+			c.method, err = next()
+		case "-H", "--header":
+			var h string
+			if h, err = next(); err == nil {
+				if k, v, ok := strings.Cut(h, ":"); ok {
+					c.headers = append(c.headers, [2]string{strings.TrimSpace(k), strings.TrimSpace(v)})
+				}
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			var d string
+			if d, err = next(); err == nil {
+				c.data = append(c.data, d)
+			}
+		case "--data-urlencode":
+			var d string
+			if d, err = next(); err == nil {
+				if k, v, ok := strings.Cut(d, "="); ok {
+					d = k + "=" + url.QueryEscape(v)
+				}
+				c.data = append(c.data, d)
+			}
+		case "--json":
+			var d string
+			if d, err = next(); err == nil {
+				c.data = append(c.data, d)
+				c.headers = append(c.headers, [2]string{"Content-Type", "application/json"})
+			}
+		case "-F", "--form", "--form-string":
+			var f string
+			if f, err = next(); err == nil {
+				if k, v, ok := strings.Cut(f, "="); ok {
+					c.form = append(c.form, [2]string{k, v})
+				}
+			}
+		case "-u", "--user":
+			c.user, err = next()
+		case "-b", "--cookie":
+			var v string
+			if v, err = next(); err == nil {
+				c.cookies = append(c.cookies, parseCookies(v)...)
+			}
+		case "-A", "--user-agent":
+			var v string
+			if v, err = next(); err == nil {
+				c.headers = append(c.headers, [2]string{"User-Agent", v})
+			}
+		case "-e", "--referer":
+			var v string
+			if v, err = next(); err == nil {
+				c.headers = append(c.headers, [2]string{"Referer", v})
+			}
+		case "-G", "--get":
+			c.get = true
+		case "-I", "--head":
+			c.method = "HEAD"
+		case "--url":
+			c.url, err = next()
+		default:
+			switch {
+			case slices.Contains(ignoredValueFlags, flag):
+				if !hasValue {
+					i++
+				}
+			case strings.HasPrefix(arg, "-"):
+			case c.url == "":
+				c.url = arg
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.url == "" {
+		return nil, errors.New("no url found in curl command")
+	}
+	return c, nil
+}
+
+func parseCookies(v string) [][2]string {
+	var list [][2]string
+	for _, part := range strings.Split(v, ";") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+			list = append(list, [2]string{k, v})
+		}
+	}
+	return list
+}
+
+func (c *command) collectItem() (*spec.CollectItem, error) {
+	raw := c.url
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	method := strings.ToUpper(c.method)
+	if method == "" {
+		method = "GET"
+		if (len(c.data) > 0 && !c.get) || len(c.form) > 0 {
+			method = "POST"
+		}
+	}
+	query := u.Query()
+	body := strings.Join(c.data, "&")
+	if c.get && body != "" {
+		if values, err := url.ParseQuery(body); err == nil {
+			for k, v := range values {
+				query[k] = append(query[k], v...)
+			}
+		}
+		body = ""
+	}
+
+	template, params := spec.PathTemplate(u.Path)
+	req := spec.HTTPRequestNode{
+		GlobalExcepts: make(map[string][]int64),
+	}
+	req.Parameters.Fill()
+	for _, v := range params {
+		req.Parameters.Path = append(req.Parameters.Path, v.Parameter())
+	}
+	for _, k := range queryKeys(u.RawQuery, query) {
+		req.Parameters.Query = append(req.Parameters.Query, stringParameter(k, query.Get(k)))
+	}
+
+	var contentType string
+	for _, h := range c.headers {
+		if strings.EqualFold(h[0], "Content-Type") {
+			contentType = h[1]
+			continue
+		}
+		req.Parameters.Header = append(req.Parameters.Header, stringParameter(h[0], h[1]))
+	}
+	if c.user != "" {
+		req.Parameters.Header = append(req.Parameters.Header,
+			stringParameter("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.user))))
+	}
+	for _, v := range c.cookies {
+		req.Parameters.Cookie = append(req.Parameters.Cookie, stringParameter(v[0], v[1]))
+	}
+	req.Content = c.requestBody(contentType, body)
+
+	// curl命令中没有响应 和新建接口一样添加一个默认的响应
+	res := spec.HTTPResponse{Code: 200}
+	res.Name = "Response Name"
+	res.Content = spec.HTTPBody{"application/json": &spec.Schema{Schema: jsonschema.Create("object")}}
+	responses := spec.HTTPResponsesNode{List: spec.HTTPResponses{res}}
+	return &spec.CollectItem{
+		Type:  spec.ContentItemTypeHttp,
+		Title: method + " " + template,
+		Content: []*spec.NodeProxy{
+			spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.HTTPURLNode{
+				Path:   template,
+				Method: strings.ToLower(method),
+			})),
+			spec.MuseCreateNodeProxy(spec.WarpHTTPNode(req)),
+			spec.MuseCreateNodeProxy(spec.WarpHTTPNode(responses)),
+		},
+	}, nil
+}
+
+// queryKeys 按照参数在url中出现的顺序返回参数名
+func queryKeys(rawQuery string, query url.Values) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(k string) {
+		if k != "" && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for _, part := range strings.Split(rawQuery, "&") {
+		k, _, _ := strings.Cut(part, "=")
+		if k, err := url.QueryUnescape(k); err == nil {
+			add(k)
+		}
+	}
+	// -G添加的参数
+	rest := make([]string, 0)
+	for k := range query {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		add(k)
+	}
+	return keys
+}
+
+func stringParameter(name, value string) *spec.Schema {
+	sh := jsonschema.Create("string")
+	sh.Example = value
+	return &spec.Schema{Name: name, Schema: sh}
+}
+
+// requestBody 没有指定Content-Type时 -F为multipart -d的内容是json时为json 否则为urlencoded
+func (c *command) requestBody(contentType, body string) spec.HTTPBody {
+	if len(c.form) > 0 {
+		sh := jsonschema.Create("object")
+		sh.Properties = make(map[string]*jsonschema.Schema)
+		for _, f := range c.form {
+			var p *jsonschema.Schema
+			if strings.HasPrefix(f[1], "@") {
+				p = jsonschema.Create("file")
+			} else {
+				p = jsonschema.Create("string")
+				p.Example = f[1]
+			}
+			if _, ok := sh.Properties[f[0]]; !ok {
+				sh.XOrder = append(sh.XOrder, f[0])
+			}
+			sh.Properties[f[0]] = p
+		}
+		return spec.HTTPBody{"multipart/form-data": &spec.Schema{Schema: sh}}
+	}
+	if body == "" {
+		return nil
+	}
+
+	mediaType := ""
+	if contentType != "" {
+		if t, _, err := mime.ParseMediaType(contentType); err == nil {
+			mediaType = t
+		} else {
+			mediaType = strings.ToLower(contentType)
+		}
+	}
+	sample, jsonErr := jsonschema.ParseSample([]byte(body))
+	switch {
+	case (mediaType == "" || strings.Contains(mediaType, "json")) && jsonErr == nil:
+		if mediaType == "" {
+			mediaType = "application/json"
+		}
+		return spec.HTTPBody{mediaType: &spec.Schema{Schema: jsonschema.Infer(sample)}}
+	case mediaType == "" || mediaType == "application/x-www-form-urlencoded":
+		sh := jsonschema.Create("object")
+		sh.Properties = make(map[string]*jsonschema.Schema)
+		for _, part := range strings.Split(body, "&") {
+			k, v, _ := strings.Cut(part, "=")
+			k, _ = url.QueryUnescape(k)
+			v, _ = url.QueryUnescape(v)
+			if k == "" {
+				continue
+			}
+			if _, ok := sh.Properties[k]; !ok {
+				sh.XOrder = append(sh.XOrder, k)
+			}
+			p := jsonschema.Create("string")
+			p.Example = v
+			sh.Properties[k] = p
+		}
+		return spec.HTTPBody{"application/x-www-form-urlencoded": &spec.Schema{Schema: sh}}
+	default:
+		sh := jsonschema.Create("string")
+		sh.Example = body
+		return spec.HTTPBody{mediaType: &spec.Schema{Schema: sh}}
+	}
+}
@@ -0,0 +1,111 @@
+package curl
+
+import (
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"golang.org/x/exp/slices"
+)
+
+func TestSplit(t *testing.T) {
+	args, err := split("curl -H 'X-A: 1' \\\n  -d \"{\\\"a\\\":\\\"b\\\\n\\\"}\" 'http://x.com/a?b=1'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"curl", "-H", "X-A: 1", "-d", `{"a":"b\n"}`, "http://x.com/a?b=1"}
+	if !slices.Equal(args, want) {
+		t.Errorf("got %q want %q", args, want)
+	}
+	if _, err := split("curl 'abc"); err == nil {
+		t.Error("unterminated quote should fail")
+	}
+}
+
+func TestImport(t *testing.T) {
+	cmd := `curl -X PUT 'https://api.example.com/users/42/orders?page=1&size=20' \
+  -H 'Content-Type: application/json' \
+  -H 'Authorization: Bearer abc' \
+  -b 'sid=xyz' \
+  --data-raw '{"name":"apicat","tags":["a"],"count":3}' --compressed`
+	item, err := Import(cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Title != "PUT /users/{userId}/orders" {
+		t.Errorf("unexpected title %s", item.Title)
+	}
+	x := &spec.Spec{Collections: []*spec.CollectItem{item}}
+	part, ok := x.CollectionsMap(false, 0)["/users/{userId}/orders"]["put"]
+	if !ok {
+		t.Fatal("collection not found")
+	}
+	if len(part.Parameters.Path) != 1 || part.Parameters.Path[0].Name != "userId" || part.Parameters.Path[0].Schema.Example != int64(42) {
+		t.Errorf("unexpected path parameters %+v", part.Parameters.Path)
+	}
+	var queries []string
+	for _, v := range part.Parameters.Query {
+		queries = append(queries, v.Name)
+	}
+	if !slices.Equal(queries, []string{"page", "size"}) {
+		t.Errorf("unexpected queries %v", queries)
+	}
+	if len(part.Parameters.Header) != 1 || part.Parameters.Header[0].Name != "Authorization" {
+		t.Errorf("unexpected headers %+v", part.Parameters.Header)
+	}
+	if len(part.Parameters.Cookie) != 1 || part.Parameters.Cookie[0].Schema.Example != "xyz" {
+		t.Errorf("unexpected cookies %+v", part.Parameters.Cookie)
+	}
+	body := part.Content["application/json"]
+	if body == nil || !slices.Equal(body.Schema.XOrder, []string{"name", "tags", "count"}) {
+		t.Fatalf("unexpected body %+v", part.Content)
+	}
+	if body.Schema.Properties["count"].Type.Value()[0] != "integer" {
+		t.Errorf("unexpected count type %v", body.Schema.Properties["count"].Type.Value())
+	}
+}
+
+func TestImportForm(t *testing.T) {
+	item, err := Import(`curl https://x.com/upload -F name=a -F file=@photo.png -u admin:123`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := &spec.Spec{Collections: []*spec.CollectItem{item}}
+	part, ok := x.CollectionsMap(false, 0)["/upload"]["post"]
+	if !ok {
+		t.Fatal("form without -X should be post")
+	}
+	form := part.Content["multipart/form-data"]
+	if form == nil || form.Schema.Properties["file"].Type.Value()[0] != "file" {
+		t.Fatalf("unexpected body %+v", part.Content)
+	}
+	if len(part.Parameters.Header) != 1 || part.Parameters.Header[0].Schema.Example != "Basic YWRtaW46MTIz" {
+		t.Errorf("unexpected headers %+v", part.Parameters.Header)
+	}
+
+	item, err = Import(`curl -G https://x.com/search -d q=go -d page=2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x = &spec.Spec{Collections: []*spec.CollectItem{item}}
+	part, ok = x.CollectionsMap(false, 0)["/search"]["get"]
+	if !ok || len(part.Parameters.Query) != 2 || part.Content != nil {
+		t.Errorf("-G should send data as query %+v", part)
+	}
+}
+
+func TestImportLargeID(t *testing.T) {
+	item, err := Import(`curl https://x.com/orders/123456789012345678901234567890`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x := &spec.Spec{Collections: []*spec.CollectItem{item}}
+	part, ok := x.CollectionsMap(false, 0)["/orders/{orderId}"]["get"]
+	if !ok || len(part.Parameters.Path) != 1 {
+		t.Fatal("collection not found")
+	}
+	// 超出int64范围 作为字符串保留原值
+	sh := part.Parameters.Path[0].Schema
+	if sh.Type.Value()[0] != "string" || sh.Example != "123456789012345678901234567890" {
+		t.Errorf("unexpected path parameter %+v", sh)
+	}
+}
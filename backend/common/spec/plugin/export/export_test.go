@@ -3,9 +3,14 @@ package export
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/plugin/asyncapi"
+	"github.com/apicat/apicat/backend/common/spec/plugin/graphql"
+	"github.com/apicat/apicat/backend/common/spec/plugin/openapi"
+	"github.com/apicat/apicat/backend/common/spec/plugin/protobuf"
 )
 
 func TestMd(t *testing.T) {
@@ -47,3 +52,103 @@ func TestHTML(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestMdEvents(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/openapi3.1-webhooks.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := openapi.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Markdown(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"### Webhook\n `newPet`", "### Callback\n `onEvent`", "### URL\n `{$request.body#/callbackUrl}`", "|GetSubscription|`getSubscription`|"} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("%q not found in markdown", v)
+		}
+	}
+}
+
+func TestMdSecurity(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/openapi3-security.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := openapi.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Markdown(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"## Security Schemes", "|bearerAuth|`http`|bearer", "### Security\n- `bearerAuth`", "### Code Samples\n#### curl\n\n```bash\ncurl -X GET '/pets'", "-H 'X-API-Key: YOUR_API_KEY'"} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("%q not found in markdown", v)
+		}
+	}
+}
+
+func TestMdGraphQL(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/example.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := graphql.Import(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Markdown(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"## Table of GraphQL Operations", "**MUTATION** [4.addPet](#graphql-4)", "### Operation\n query `pets`", "```graphql\nquery pet($id: ID!) {", "|`data`|`object`|*|", "\"data\": {"} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("%q not found in markdown", v)
+		}
+	}
+}
+
+func TestMdChannels(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/asyncapi2.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := asyncapi.Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Markdown(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"## Table of Channels", "**PUBLISH/SUBSCRIBE** [2.rooms/{roomId}](#channel-2)", "### Channel\n `rooms/{roomId}`", "### Protocol\n wss", "|roomId|`string`|*|Id of the room", "### Subscribe\n", "#### userJoined\n", "|····from|`object`|*|"} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("%q not found in markdown", v)
+		}
+	}
+}
+
+func TestMdGRPC(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/example.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := protobuf.Import(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Markdown(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"## Table of gRPC Methods", "**SERVER STREAMING** [4.WatchBooks](#grpc-4)", "### Service\n `library.v1.LibraryService`", "### Method\n `CreateBook`", "### Streaming\n bidirectional streaming", "### HTTP\n POST `/v1/{parent=shelves/*}/books`", "Body `book`", "stream `ChatMessage`", "|····name|`string`||Resource name"} {
+		if !strings.Contains(string(b), v) {
+			t.Errorf("%q not found in markdown", v)
+		}
+	}
+}
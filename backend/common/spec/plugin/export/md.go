@@ -9,6 +9,8 @@ import (
 
 	"github.com/apicat/apicat/backend/common/spec"
 	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/spec/markdown"
+	"github.com/apicat/apicat/backend/common/spec/plugin/snippet"
 	"github.com/apicat/datagen"
 	"golang.org/x/exp/slices"
 )
@@ -26,6 +28,8 @@ func Markdown(in *spec.Spec) ([]byte, error) {
 		}
 	}
 
+	renderSecuritySchemes(&buf, in.Definitions.Securities)
+
 	list := make([][2]string, 0)
 	for path, items := range paths {
 		for method := range items {
@@ -52,11 +56,66 @@ func Markdown(in *spec.Spec) ([]byte, error) {
 		fmt.Fprintf(&buf, "  - **%s** [%d.%s](#api-%d)\n", strings.ToUpper(v[1]), k+1, item.Title, k+1)
 	}
 
+	events := in.Events(true, 2)
+	if len(events) > 0 {
+		buf.WriteString("\n## Table of Webhooks and Callbacks\n")
+		for k, v := range events {
+			fmt.Fprintf(&buf, "  - **%s** [%d.%s](#event-%d)\n", strings.ToUpper(eventMethod(v)), k+1, v.Title, k+1)
+		}
+	}
+
+	operations := in.GraphQLOperations(true, 2)
+	if len(operations) > 0 {
+		buf.WriteString("\n## Table of GraphQL Operations\n")
+		for k, v := range operations {
+			fmt.Fprintf(&buf, "  - **%s** [%d.%s](#graphql-%d)\n", strings.ToUpper(v.Operation.Operation), k+1, v.Title, k+1)
+		}
+	}
+
+	channels := in.Channels(true, 2)
+	if len(channels) > 0 {
+		buf.WriteString("\n## Table of Channels\n")
+		for k, v := range channels {
+			fmt.Fprintf(&buf, "  - **%s** [%d.%s](#channel-%d)\n", strings.ToUpper(channelActions(v)), k+1, v.Title, k+1)
+		}
+	}
+
+	methods := in.GRPCMethods(true, 2)
+	if len(methods) > 0 {
+		buf.WriteString("\n## Table of gRPC Methods\n")
+		for k, v := range methods {
+			fmt.Fprintf(&buf, "  - **%s** [%d.%s](#grpc-%d)\n", strings.ToUpper(v.Method.StreamingMode()), k+1, v.Title, k+1)
+		}
+	}
+
 	buf.WriteString("\n\n")
 
+	opt := snippet.Option{
+		Globals:    in.Globals.Parameters,
+		Securities: in.Definitions.Securities,
+	}
+	if len(in.Servers) > 0 {
+		opt.ServerURL = in.Servers[0].URL
+	}
 	for k, v := range list {
 		item := paths[v[0]][v[1]]
-		rednerHttpPart(&buf, k+1, v[0], v[1], item, in.Globals.Parameters)
+		rednerHttpPart(&buf, k+1, v[0], v[1], item, opt)
+	}
+
+	for k, v := range events {
+		renderEventPart(&buf, k+1, v)
+	}
+
+	for k, v := range operations {
+		renderGraphQLPart(&buf, k+1, v)
+	}
+
+	for k, v := range channels {
+		renderChannelPart(&buf, k+1, v)
+	}
+
+	for k, v := range methods {
+		renderGRPCPart(&buf, k+1, v)
 	}
 
 	return buf.Bytes(), nil
@@ -64,11 +123,146 @@ func Markdown(in *spec.Spec) ([]byte, error) {
 
 var jsonschemaHeaderCols = []string{"name", "type", "required", "comment"}
 var paramsHeaderCols = []string{"name", "in", "type", "required", "comment"}
+var linksHeaderCols = []string{"name", "operation", "parameters", "comment"}
+var securityHeaderCols = []string{"name", "type", "detail", "comment"}
 
-func rednerHttpPart(buf *bytes.Buffer, i int, path, method string, part spec.HTTPPart, globls spec.HTTPParameters) {
+func rednerHttpPart(buf *bytes.Buffer, i int, path, method string, part spec.HTTPPart, opt snippet.Option) {
 	fmt.Fprintf(buf, "## <span id=\"api-%d\">%d. %s</span>\n", i, i, part.Title)
 	fmt.Fprintf(buf, "### Path\n [%s](%s)\n", path, path)
 	fmt.Fprintf(buf, "### Method\n %s\n", strings.ToUpper(method))
+	renderHttpContent(buf, part, opt.Globals, snippet.GenerateAll(method, path, &part, opt))
+}
+
+func eventMethod(e spec.EventPart) string {
+	if e.Webhook != nil {
+		return e.Webhook.Method
+	}
+	return e.Callback.Method
+}
+
+// renderEventPart webhook和callback不使用全局参数
+func renderEventPart(buf *bytes.Buffer, i int, e spec.EventPart) {
+	fmt.Fprintf(buf, "## <span id=\"event-%d\">%d. %s</span>\n", i, i, e.Title)
+	if e.Webhook != nil {
+		fmt.Fprintf(buf, "### Webhook\n `%s`\n", e.Webhook.Event)
+	} else {
+		fmt.Fprintf(buf, "### Callback\n `%s`\n", e.Callback.Event)
+		fmt.Fprintf(buf, "### Operation\n %s [%s](%s)\n", strings.ToUpper(e.Callback.Operation.Method), e.Callback.Operation.Path, e.Callback.Operation.Path)
+		fmt.Fprintf(buf, "### URL\n `%s`\n", e.Callback.Expression)
+	}
+	fmt.Fprintf(buf, "### Method\n %s\n", strings.ToUpper(eventMethod(e)))
+	renderHttpContent(buf, e.HTTPPart, spec.HTTPParameters{}, nil)
+}
+
+// renderGraphQLPart 查询文档 变量和响应中data的结构
+func renderGraphQLPart(buf *bytes.Buffer, i int, part spec.GraphQLPart) {
+	fmt.Fprintf(buf, "## <span id=\"graphql-%d\">%d. %s</span>\n", i, i, part.Title)
+	fmt.Fprintf(buf, "### Operation\n %s `%s`\n", part.Operation.Operation, part.Operation.Field)
+	fmt.Fprintf(buf, "### Endpoint\n POST [%s](%s)\n", part.Operation.Path, part.Operation.Path)
+	if part.Query != "" {
+		fmt.Fprintf(buf, "### Query\n\n```graphql\n%s\n```\n\n", strings.TrimSpace(part.Query))
+	}
+	if part.Variables != nil && len(part.Variables.Properties) > 0 {
+		fmt.Fprintf(buf, "### Variables\n")
+		renderTableHeader(buf, jsonschemaHeaderCols)
+		renderSchema(buf, "`root`", 0, true, part.Variables)
+	}
+	fmt.Fprintf(buf, "### Response\n")
+	if part.Response != nil {
+		renderTableHeader(buf, jsonschemaHeaderCols)
+		renderSchema(buf, "`data`", 0, true, part.Response)
+		b, _ := json.Marshal(part.Response.Flatten())
+		if rx, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"}); err == nil {
+			buf.WriteString("\n\nExample\n\n")
+			buf.WriteString("\n```json\n")
+			mockexample, _ := json.MarshalIndent(map[string]any{"data": rx}, "", "  ")
+			buf.Write(mockexample)
+			buf.WriteString("\n```\n\n")
+		}
+	}
+	buf.WriteString("\n\n------------\n")
+}
+
+// channelActions 通道上的操作 例如publish/subscribe
+func channelActions(c spec.ChannelPart) string {
+	list := make([]string, 0, 2)
+	for _, v := range c.Operations() {
+		list = append(list, v.Action)
+	}
+	return strings.Join(list, "/")
+}
+
+// renderChannelPart 通道的参数和每个操作的消息
+func renderChannelPart(buf *bytes.Buffer, i int, part spec.ChannelPart) {
+	fmt.Fprintf(buf, "## <span id=\"channel-%d\">%d. %s</span>\n", i, i, part.Title)
+	fmt.Fprintf(buf, "### Channel\n `%s`\n", part.Channel.Channel)
+	if part.Channel.Protocol != "" {
+		fmt.Fprintf(buf, "### Protocol\n %s\n", part.Channel.Protocol)
+	}
+	if len(part.Doc.Items) > 0 {
+		if raw, err := markdown.ToMarkdown(&part.Doc); err == nil {
+			fmt.Fprintf(buf, "\n%s\n", raw)
+		}
+	}
+	if len(part.Channel.Parameters) > 0 {
+		fmt.Fprintf(buf, "### Parameters\n")
+		renderTableHeader(buf, jsonschemaHeaderCols)
+		for _, v := range part.Channel.Parameters {
+			if v.Schema == nil {
+				continue
+			}
+			s := *v.Schema
+			if s.Description == "" {
+				s.Description = v.Description
+			}
+			renderSchema(buf, v.Name, 0, v.Required, &s)
+		}
+	}
+	for _, op := range part.Operations() {
+		fmt.Fprintf(buf, "### %s\n", strings.ToUpper(op.Action[:1])+op.Action[1:])
+		if op.Summary != "" {
+			fmt.Fprintf(buf, "> %s\n\n", op.Summary)
+		}
+		for _, m := range op.Messages {
+			name := m.Title
+			if name == "" {
+				name = m.Name
+			}
+			if name != "" {
+				fmt.Fprintf(buf, "#### %s\n", name)
+			}
+			if m.Description != "" {
+				fmt.Fprintf(buf, "%s\n\n", m.Description)
+			}
+			if m.ContentType != "" {
+				fmt.Fprintf(buf, "ContentType `%s`\n\n", m.ContentType)
+			}
+			if m.Headers != nil {
+				fmt.Fprintf(buf, "Headers\n\n")
+				renderTableHeader(buf, jsonschemaHeaderCols)
+				renderSchema(buf, "`root`", 0, true, m.Headers)
+				buf.WriteString("\n")
+			}
+			if m.Payload == nil {
+				continue
+			}
+			fmt.Fprintf(buf, "Payload\n\n")
+			renderTableHeader(buf, jsonschemaHeaderCols)
+			renderSchema(buf, "`root`", 0, true, m.Payload)
+			b, _ := json.Marshal(m.Payload.Flatten())
+			if rx, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"}); err == nil {
+				buf.WriteString("\n\nExample\n\n")
+				buf.WriteString("\n```json\n")
+				mockexample, _ := json.MarshalIndent(rx, "", "  ")
+				buf.Write(mockexample)
+				buf.WriteString("\n```\n\n")
+			}
+		}
+	}
+	buf.WriteString("\n\n------------\n")
+}
+
+func renderHttpContent(buf *bytes.Buffer, part spec.HTTPPart, globls spec.HTTPParameters, snippets []snippet.Snippet) {
 
 	skips := make(map[string]bool)
 	for k, v := range part.GlobalExcepts {
@@ -116,6 +310,8 @@ func rednerHttpPart(buf *bytes.Buffer, i int, path, method string, part spec.HTT
 		}
 	}
 
+	renderSecurity(buf, part.Security)
+
 	if len(part.Content) > 0 {
 		fmt.Fprintf(buf, "### Request Body\n")
 		for k, v := range part.Content {
@@ -126,7 +322,7 @@ func rednerHttpPart(buf *bytes.Buffer, i int, path, method string, part spec.HTT
 			}
 			renderSchema(buf, "`root`", 0, true, v.Schema)
 			if strings.Contains(k, "json") {
-				b, _ := json.Marshal(v.Schema)
+				b, _ := json.Marshal(v.Schema.Flatten())
 				if rx, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"}); err == nil {
 					buf.WriteString("\n\nExample\n\n")
 					buf.WriteString("\n```json\n")
@@ -149,7 +345,7 @@ func rednerHttpPart(buf *bytes.Buffer, i int, path, method string, part spec.HTT
 			}
 			renderSchema(buf, "`root`", 0, true, v.Schema)
 			if strings.Contains(k, "json") {
-				b, _ := json.Marshal(v.Schema)
+				b, _ := json.Marshal(v.Schema.Flatten())
 				if rx, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"}); err == nil {
 					buf.WriteString("\n\nExample\n\n")
 					buf.WriteString("\n```json\n")
@@ -160,13 +356,147 @@ func rednerHttpPart(buf *bytes.Buffer, i int, path, method string, part spec.HTT
 			}
 			break
 		}
+		renderLinks(buf, res.Links)
 	}
 
+	renderSnippets(buf, snippets)
+
 	buf.WriteString("\n\n------------\n")
 }
 
+// 代码块使用的语言标记
+var snippetFences = map[string]string{"curl": "bash"}
+
+func renderSnippets(buf *bytes.Buffer, snippets []snippet.Snippet) {
+	if len(snippets) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "### Code Samples\n")
+	for _, v := range snippets {
+		fence, ok := snippetFences[v.Language]
+		if !ok {
+			fence = v.Language
+		}
+		fmt.Fprintf(buf, "#### %s\n\n```%s\n%s\n```\n\n", v.Language, fence, v.Code)
+	}
+}
+
+func renderSecuritySchemes(buf *bytes.Buffer, schemes spec.SecuritySchemes) {
+	if len(schemes) == 0 {
+		return
+	}
+	buf.WriteString("\n## Security Schemes\n")
+	renderTableHeader(buf, securityHeaderCols)
+	for _, v := range schemes {
+		var detail string
+		switch v.Type {
+		case spec.SecurityTypeAPIKey:
+			detail = v.Key + " in " + v.In
+		case spec.SecurityTypeHTTP:
+			detail = v.Scheme
+			if v.BearerFormat != "" {
+				detail += " " + v.BearerFormat
+			}
+		case spec.SecurityTypeOAuth2:
+			if v.Flows != nil {
+				flows := make([]string, 0)
+				for _, f := range []struct {
+					name string
+					flow *spec.OAuthFlow
+				}{
+					{"implicit", v.Flows.Implicit},
+					{"password", v.Flows.Password},
+					{"clientCredentials", v.Flows.ClientCredentials},
+					{"authorizationCode", v.Flows.AuthorizationCode},
+				} {
+					if f.flow != nil {
+						flows = append(flows, f.name)
+					}
+				}
+				detail = strings.Join(flows, ", ")
+			}
+		case spec.SecurityTypeOpenIDConnect:
+			detail = v.OpenIDConnectURL
+		}
+
+		buf.WriteString("|")
+		renderString(buf, v.Name)
+		buf.WriteString("|`")
+		buf.WriteString(v.Type)
+		buf.WriteString("`|")
+		renderString(buf, detail)
+		buf.WriteString("|")
+		renderString(buf, v.Description)
+		buf.WriteByte('\n')
+	}
+}
+
+// renderSecurity 满足其中一项即可 同一项中的认证方式需要同时满足
+func renderSecurity(buf *bytes.Buffer, requirements []spec.SecurityRequirement) {
+	if len(requirements) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, "### Security\n")
+	for _, req := range requirements {
+		names := make([]string, 0, len(req))
+		for k, scopes := range req {
+			if len(scopes) > 0 {
+				k += " (" + strings.Join(scopes, ", ") + ")"
+			}
+			names = append(names, "`"+k+"`")
+		}
+		sort.Strings(names)
+		fmt.Fprintf(buf, "- %s\n", strings.Join(names, " + "))
+	}
+}
+
+func renderLinks(buf *bytes.Buffer, links spec.HTTPLinks) {
+	if len(links) == 0 {
+		return
+	}
+	names := make([]string, 0, len(links))
+	for k := range links {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	buf.WriteString("\nLinks\n\n")
+	renderTableHeader(buf, linksHeaderCols)
+	for _, name := range names {
+		v := links[name]
+		operation := v.OperationID
+		if operation == "" {
+			operation = v.OperationRef
+		}
+		params := make([]string, 0, len(v.Parameters))
+		for k, x := range v.Parameters {
+			params = append(params, k+"="+x)
+		}
+		sort.Strings(params)
+
+		buf.WriteString("|")
+		renderString(buf, name)
+		buf.WriteString("|`")
+		buf.WriteString(operation)
+		buf.WriteString("`|")
+		renderString(buf, strings.Join(params, ", "))
+		buf.WriteString("|")
+		renderString(buf, v.Description)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+}
+
 func renderSchema(buf *bytes.Buffer, name string, lvl int, required bool, s *jsonschema.Schema) {
-	if s.Type == nil {
+	if s == nil {
+		return
+	}
+	s = s.MergeAllOf()
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		renderSchemaComposition(buf, name, lvl, required, s)
+		return
+	}
+	if s.Type == nil || len(s.Type.Value()) == 0 {
 		return
 	}
 	typ := s.Type.Value()
@@ -185,6 +515,22 @@ func renderSchema(buf *bytes.Buffer, name string, lvl int, required bool, s *jso
 	}
 }
 
+// renderSchemaComposition oneOf/anyOf 每个可选类型作为子项显示
+func renderSchemaComposition(buf *bytes.Buffer, name string, lvl int, required bool, s *jsonschema.Schema) {
+	typ, list := "oneOf", s.OneOf
+	if len(list) == 0 {
+		typ, list = "anyOf", s.AnyOf
+	}
+	desc := s.Description
+	if s.Discriminator != nil {
+		desc = strings.TrimSpace(fmt.Sprintf("%s discriminator: %s", desc, s.Discriminator.PropertyName))
+	}
+	renderSchemaItem(buf, name, typ, desc, lvl, required)
+	for i, v := range list {
+		renderSchema(buf, fmt.Sprintf("`option %d`", i+1), lvl+1, false, v)
+	}
+}
+
 func renderSchemaItem(buf *bytes.Buffer, name, typ, desc string, lvl int, required bool) {
 	buf.WriteByte('|')
 	buf.WriteString(strings.Repeat("·", lvl*4))
@@ -242,3 +588,51 @@ func needsEscaping(text rune) bool {
 		return false
 	}
 }
+
+// renderGRPCPart 服务 方法 流模式 以及请求和响应消息的结构
+func renderGRPCPart(buf *bytes.Buffer, i int, part spec.GRPCPart) {
+	fmt.Fprintf(buf, "## <span id=\"grpc-%d\">%d. %s</span>\n", i, i, part.Title)
+	fmt.Fprintf(buf, "### Service\n `%s`\n", part.Method.FullService())
+	fmt.Fprintf(buf, "### Method\n `%s`\n", part.Method.Method)
+	fmt.Fprintf(buf, "### Streaming\n %s\n", part.Method.StreamingMode())
+	method, path := part.Method.Transcoding()
+	fmt.Fprintf(buf, "### HTTP\n %s `%s`\n", method, path)
+	if part.Method.HTTPBody != "" {
+		fmt.Fprintf(buf, "\nBody `%s`\n", part.Method.HTTPBody)
+	}
+	if len(part.Doc.Items) > 0 {
+		if raw, err := markdown.ToMarkdown(&part.Doc); err == nil {
+			fmt.Fprintf(buf, "\n%s\n", raw)
+		}
+	}
+	for _, v := range []struct {
+		title  string
+		typ    string
+		stream bool
+		schema *jsonschema.Schema
+	}{
+		{"Request", part.Method.RequestType, part.Method.ClientStreaming, part.Request},
+		{"Response", part.Method.ResponseType, part.Method.ServerStreaming, part.Response},
+	} {
+		fmt.Fprintf(buf, "### %s\n", v.title)
+		if v.stream {
+			fmt.Fprintf(buf, "stream `%s`\n\n", v.typ)
+		} else {
+			fmt.Fprintf(buf, "`%s`\n\n", v.typ)
+		}
+		if v.schema == nil {
+			continue
+		}
+		renderTableHeader(buf, jsonschemaHeaderCols)
+		renderSchema(buf, "`root`", 0, true, v.schema)
+		b, _ := json.Marshal(v.schema.Flatten())
+		if rx, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"}); err == nil {
+			buf.WriteString("\n\nExample\n\n")
+			buf.WriteString("\n```json\n")
+			mockexample, _ := json.MarshalIndent(rx, "", "  ")
+			buf.Write(mockexample)
+			buf.WriteString("\n```\n\n")
+		}
+	}
+	buf.WriteString("\n\n------------\n")
+}
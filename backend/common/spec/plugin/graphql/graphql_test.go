@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"golang.org/x/exp/slices"
+)
+
+func importFile(t *testing.T, name string) *spec.Spec {
+	raw, err := os.ReadFile("../../testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Import(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return x
+}
+
+func TestImportSDL(t *testing.T) {
+	x := importFile(t, "example.graphql")
+
+	names := make([]string, 0)
+	for _, v := range x.Definitions.Schemas {
+		names = append(names, v.Name)
+	}
+	if !slices.Equal(names, []string{"NewPet", "Node", "Pet", "SearchResult", "Status", "User"}) {
+		t.Fatalf("unexpected definitions %v", names)
+	}
+	pet := x.Definitions.Schemas.Lookup("Pet").Schema
+	if !slices.Equal(pet.Required, []string{"id", "name"}) || !slices.Equal(pet.XOrder, []string{"id", "name", "tag", "status", "owner", "born"}) {
+		t.Errorf("unexpected pet %+v", pet)
+	}
+	if len(x.Definitions.Schemas.Lookup("SearchResult").Schema.OneOf) != 2 {
+		t.Error("union should be oneOf")
+	}
+
+	if len(x.Collections) != 2 || x.Collections[0].Title != "Query" || x.Collections[1].Title != "Mutation" {
+		t.Fatalf("unexpected collections %+v", x.Collections)
+	}
+	ops := x.GraphQLOperations(true, 1)
+	if len(ops) != 4 {
+		t.Fatalf("unexpected operations %+v", ops)
+	}
+	pets := ops[1]
+	if pets.Operation.Operation != spec.GraphQLQuery || pets.Operation.Field != "pets" || pets.Operation.Path != "/graphql" {
+		t.Errorf("unexpected operation %+v", pets.Operation)
+	}
+	if !strings.HasPrefix(pets.Query, "query pets($status: Status, $limit: Int) {\n  pets(status: $status, limit: $limit) {") {
+		t.Errorf("unexpected query %s", pets.Query)
+	}
+	if pets.Variables.Properties["limit"].Default != int64(20) || len(pets.Variables.Required) != 0 {
+		t.Errorf("unexpected variables %+v", pets.Variables)
+	}
+	if pets.Response.Properties["pets"].Items.Value().Properties["owner"] == nil {
+		t.Error("response refs not expanded")
+	}
+	if !slices.Equal(ops[3].Variables.Required, []string{"input"}) {
+		t.Errorf("unexpected variables %+v", ops[3].Variables)
+	}
+}
+
+func TestImportIntrospection(t *testing.T) {
+	x := importFile(t, "graphql-introspection.json")
+	if len(x.Definitions.Schemas) != 2 {
+		t.Fatalf("unexpected definitions %+v", x.Definitions.Schemas)
+	}
+	book := x.Definitions.Schemas.Lookup("Book")
+	if book.Description != `A "book"` || book.Schema.Properties["genres"].Items == nil {
+		t.Errorf("unexpected book %+v", book.Schema)
+	}
+	ops := x.GraphQLOperations(false, 0)
+	if len(ops) != 1 || ops[0].Title != "book" {
+		t.Fatalf("unexpected operations %+v", ops)
+	}
+	vars := ops[0].Variables
+	if vars.Properties["isbn"].Description != "ISBN-13" || !slices.Equal(vars.Required, []string{"isbn"}) {
+		t.Errorf("unexpected variables %+v", vars)
+	}
+}
+
+func TestResponseSchema(t *testing.T) {
+	x := importFile(t, "example.graphql")
+	ops := x.GraphQLOperations(false, 0)
+	resolve := x.Definitions.SchemaResolver()
+
+	query := `
+query Search($text: String!) {
+  found: search(text: $text) {
+    __typename
+    ... on User { ...UserFields }
+  }
+  pet(id: "1") { id owner { name } }
+}
+fragment UserFields on User { id nick: name }
+`
+	s, err := ResponseSchema(query, "", ops, resolve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(s.XOrder, []string{"found", "pet"}) {
+		t.Fatalf("unexpected fields %v", s.XOrder)
+	}
+	user := s.Properties["found"].Items.Value()
+	if !slices.Equal(user.XOrder, []string{"__typename", "id", "nick"}) || user.Properties["__typename"].Enum[0] != "User" {
+		t.Errorf("unexpected union member %+v", user)
+	}
+	pet := s.Properties["pet"]
+	if !slices.Equal(pet.XOrder, []string{"id", "owner"}) || !slices.Equal(pet.Properties["owner"].XOrder, []string{"name"}) {
+		t.Errorf("unexpected pet %+v", pet)
+	}
+
+	for _, q := range []string{
+		`{ pet(id: "1") { unknown } }`,
+		`{ pet(id: "1") }`,
+		`{ unknown }`,
+		`query A { pets { id } } query B { pets { id } }`,
+	} {
+		if _, err := ResponseSchema(q, "", ops, resolve); err == nil {
+			t.Errorf("expected error for %s", q)
+		}
+	}
+	if _, err := ResponseSchema(`mutation { addPet(input: {name: "x"}) { name } }`, "", ops, resolve); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,296 @@
+package graphql
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/spec/markdown"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// 默认的查询文档中嵌套对象的最大层数
+const selectionDepth = 3
+
+// 模型的虚拟id 位数相同 导入时替换为数据库中的id不会相互影响
+const virtualIDBase = 1000000
+
+// Import 导入SDL或者introspection查询的结果
+// 每个类型生成一个公共模型 query mutation和subscription的每个字段生成一个graphql集合
+func Import(data []byte) (*spec.Spec, error) {
+	sdl := string(data)
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		s, err := introspectionToSDL(trimmed)
+		if err != nil {
+			return nil, err
+		}
+		sdl = s
+	}
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	if err != nil {
+		return nil, err
+	}
+
+	im := &importer{schema: schema, refs: make(map[string]string)}
+	out := &spec.Spec{
+		ApiCat: "2.0",
+		Info: &spec.Info{
+			Title:   "GraphQL",
+			Version: "1.0.0",
+		},
+		Servers:     []*spec.Server{},
+		Definitions: spec.Definitions{Schemas: im.definitions()},
+		Collections: []*spec.CollectItem{},
+	}
+	for _, root := range []struct {
+		operation string
+		title     string
+		def       *ast.Definition
+	}{
+		{spec.GraphQLQuery, "Query", schema.Query},
+		{spec.GraphQLMutation, "Mutation", schema.Mutation},
+		{spec.GraphQLSubscription, "Subscription", schema.Subscription},
+	} {
+		if root.def == nil || len(root.def.Fields) == 0 {
+			continue
+		}
+		dir := &spec.CollectItem{Type: spec.ContentItemTypeDir, Title: root.title}
+		for _, f := range root.def.Fields {
+			if strings.HasPrefix(f.Name, "__") {
+				continue
+			}
+			dir.Items = append(dir.Items, im.operation(root.operation, f))
+		}
+		out.Collections = append(out.Collections, dir)
+	}
+	return out, nil
+}
+
+type importer struct {
+	schema *ast.Schema
+	// 类型名 => 模型的引用
+	refs map[string]string
+}
+
+// definitions 除了根类型 内置类型和标量以外的类型都作为公共模型
+func (im *importer) definitions() spec.Schemas {
+	roots := map[string]bool{}
+	for _, v := range []*ast.Definition{im.schema.Query, im.schema.Mutation, im.schema.Subscription} {
+		if v != nil {
+			roots[v.Name] = true
+		}
+	}
+	names := make([]string, 0)
+	for name, def := range im.schema.Types {
+		if def.BuiltIn || roots[name] || strings.HasPrefix(name, "__") || def.Kind == ast.Scalar {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		im.refs[name] = fmt.Sprintf("#/definitions/schemas/%d", virtualIDBase+i+1)
+	}
+
+	list := make(spec.Schemas, 0, len(names))
+	for i, name := range names {
+		def := im.schema.Types[name]
+		list = append(list, &spec.Schema{
+			ID:          int64(virtualIDBase + i + 1),
+			Name:        name,
+			Description: def.Description,
+			Schema:      im.definition(def),
+		})
+	}
+	return list
+}
+
+func (im *importer) definition(def *ast.Definition) *jsonschema.Schema {
+	var s *jsonschema.Schema
+	switch def.Kind {
+	case ast.Enum:
+		s = jsonschema.Create("string")
+		for _, v := range def.EnumValues {
+			s.Enum = append(s.Enum, v.Name)
+		}
+	case ast.Union:
+		s = &jsonschema.Schema{}
+		for _, v := range def.Types {
+			s.OneOf = append(s.OneOf, im.typeSchema(&ast.Type{NamedType: v}))
+		}
+	default:
+		s = jsonschema.Create("object")
+		s.Properties = make(map[string]*jsonschema.Schema)
+		for _, f := range def.Fields {
+			if strings.HasPrefix(f.Name, "__") {
+				continue
+			}
+			p := im.typeSchema(f.Type)
+			if p.Ref() {
+				// 引用不能有其它属性 描述放在allOf外层
+				if f.Description != "" {
+					p = &jsonschema.Schema{AllOf: []*jsonschema.Schema{p}, Description: f.Description}
+				}
+			} else {
+				p.Description = f.Description
+			}
+			s.Properties[f.Name] = p
+			s.XOrder = append(s.XOrder, f.Name)
+			if f.Type.NonNull {
+				s.Required = append(s.Required, f.Name)
+			}
+		}
+	}
+	s.Title = def.Name
+	s.Description = def.Description
+	return s
+}
+
+// typeSchema graphql的类型转为jsonschema 非空只影响所在对象的required
+func (im *importer) typeSchema(t *ast.Type) *jsonschema.Schema {
+	if t.Elem != nil {
+		s := jsonschema.Create("array")
+		s.Items = &jsonschema.ValueOrBoolean[*jsonschema.Schema]{}
+		s.Items.SetValue(im.typeSchema(t.Elem))
+		return s
+	}
+	switch t.NamedType {
+	case "Int":
+		return jsonschema.Create("integer")
+	case "Float":
+		return jsonschema.Create("number")
+	case "Boolean":
+		return jsonschema.Create("boolean")
+	case "String", "ID":
+		return jsonschema.Create("string")
+	}
+	if ref, ok := im.refs[t.NamedType]; ok {
+		return &jsonschema.Schema{Reference: &ref}
+	}
+	// 自定义的标量
+	s := jsonschema.Create("string")
+	s.Description = "scalar " + t.NamedType
+	return s
+}
+
+// operation 根类型上的一个字段 生成默认的查询文档 参数作为变量
+func (im *importer) operation(operation string, f *ast.FieldDefinition) *spec.CollectItem {
+	content := make([]*spec.NodeProxy, 0)
+	for _, v := range markdown.ToDocment([]byte(f.Description)).Items {
+		content = append(content, spec.MuseCreateNodeProxy(v))
+	}
+
+	vars := jsonschema.Create("object")
+	vars.Properties = make(map[string]*jsonschema.Schema)
+	for _, arg := range f.Arguments {
+		p := im.typeSchema(arg.Type)
+		if !p.Ref() {
+			p.Description = arg.Description
+			if arg.DefaultValue != nil {
+				if v, err := arg.DefaultValue.Value(nil); err == nil {
+					p.Default = v
+				}
+			}
+		}
+		vars.Properties[arg.Name] = p
+		vars.XOrder = append(vars.XOrder, arg.Name)
+		if arg.Type.NonNull && arg.DefaultValue == nil {
+			vars.Required = append(vars.Required, arg.Name)
+		}
+	}
+
+	res := jsonschema.Create("object")
+	res.Properties = map[string]*jsonschema.Schema{f.Name: im.typeSchema(f.Type)}
+	res.XOrder = []string{f.Name}
+	if f.Type.NonNull {
+		res.Required = []string{f.Name}
+	}
+
+	content = append(content,
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.GraphQLOperationNode{
+			Operation: operation,
+			Field:     f.Name,
+			Path:      "/graphql",
+		})),
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.GraphQLQueryNode{Query: im.queryDocument(operation, f)})),
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.GraphQLVariablesNode{Schema: vars})),
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.GraphQLResponseNode{Schema: res})),
+	)
+	return &spec.CollectItem{
+		Type:    spec.ContentItemTypeGraphQL,
+		Title:   f.Name,
+		Content: content,
+	}
+}
+
+// queryDocument 查询字段的参数都使用变量 选择集包含嵌套对象中不需要参数的字段
+func (im *importer) queryDocument(operation string, f *ast.FieldDefinition) string {
+	var b strings.Builder
+	b.WriteString(operation)
+	b.WriteString(" ")
+	b.WriteString(f.Name)
+	if len(f.Arguments) > 0 {
+		vars := make([]string, 0, len(f.Arguments))
+		args := make([]string, 0, len(f.Arguments))
+		for _, arg := range f.Arguments {
+			vars = append(vars, fmt.Sprintf("$%s: %s", arg.Name, arg.Type.String()))
+			args = append(args, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+		}
+		fmt.Fprintf(&b, "(%s) {\n  %s(%s)", strings.Join(vars, ", "), f.Name, strings.Join(args, ", "))
+	} else {
+		fmt.Fprintf(&b, " {\n  %s", f.Name)
+	}
+	im.selection(&b, f.Type, 1, map[string]bool{}, true)
+	b.WriteString("\n}\n")
+	return b.String()
+}
+
+// selection 写入类型的选择集 标量没有选择集 visiting用于跳过递归的类型
+// union中的片段已经在外层选择了__typename
+func (im *importer) selection(b *strings.Builder, t *ast.Type, depth int, visiting map[string]bool, typename bool) {
+	def := im.schema.Types[t.Name()]
+	if def == nil || def.IsLeafType() {
+		return
+	}
+	indent := strings.Repeat("  ", depth)
+	b.WriteString(" {")
+	if typename {
+		fmt.Fprintf(b, "\n%s  __typename", indent)
+	}
+	if def.Kind == ast.Union {
+		for _, name := range def.Types {
+			fmt.Fprintf(b, "\n%s  ... on %s", indent, name)
+			im.selection(b, &ast.Type{NamedType: name}, depth+1, visiting, false)
+		}
+		fmt.Fprintf(b, "\n%s}", indent)
+		return
+	}
+	visiting[def.Name] = true
+	for _, field := range def.Fields {
+		if strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+		// 必填参数无法给出默认值
+		required := false
+		for _, arg := range field.Arguments {
+			if arg.Type.NonNull && arg.DefaultValue == nil {
+				required = true
+			}
+		}
+		if required {
+			continue
+		}
+		child := im.schema.Types[field.Type.Name()]
+		if child != nil && !child.IsLeafType() && (depth >= selectionDepth || visiting[child.Name]) {
+			continue
+		}
+		fmt.Fprintf(b, "\n%s  %s", indent, field.Name)
+		im.selection(b, field.Type, depth+1, visiting, true)
+	}
+	delete(visiting, def.Name)
+	fmt.Fprintf(b, "\n%s}", indent)
+}
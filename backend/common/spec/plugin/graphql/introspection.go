@@ -0,0 +1,180 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+func (t *introspectionTypeRef) String() string {
+	if t == nil {
+		return ""
+	}
+	switch t.Kind {
+	case "NON_NULL":
+		return t.OfType.String() + "!"
+	case "LIST":
+		return "[" + t.OfType.String() + "]"
+	}
+	return t.Name
+}
+
+type introspectionInputValue struct {
+	Name         string                `json:"name"`
+	Description  string                `json:"description"`
+	Type         *introspectionTypeRef `json:"type"`
+	DefaultValue *string               `json:"defaultValue"`
+}
+
+type introspectionField struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Args        []introspectionInputValue `json:"args"`
+	Type        *introspectionTypeRef     `json:"type"`
+}
+
+type introspectionType struct {
+	Kind          string                    `json:"kind"`
+	Name          string                    `json:"name"`
+	Description   string                    `json:"description"`
+	Fields        []introspectionField      `json:"fields"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+	EnumValues    []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"enumValues"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionTypeRef `json:"queryType"`
+	MutationType     *introspectionTypeRef `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef `json:"subscriptionType"`
+	Types            []introspectionType   `json:"types"`
+}
+
+// introspectionToSDL introspection查询的结果转为SDL 支持包含data的完整响应
+func introspectionToSDL(data []byte) (string, error) {
+	var res struct {
+		Data struct {
+			Schema *introspectionSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *introspectionSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return "", err
+	}
+	schema := res.Schema
+	if schema == nil {
+		schema = res.Data.Schema
+	}
+	if schema == nil || schema.QueryType == nil {
+		return "", errors.New("invalid introspection result")
+	}
+
+	var b strings.Builder
+	b.WriteString("schema {\n")
+	fmt.Fprintf(&b, "  query: %s\n", schema.QueryType.Name)
+	if schema.MutationType != nil {
+		fmt.Fprintf(&b, "  mutation: %s\n", schema.MutationType.Name)
+	}
+	if schema.SubscriptionType != nil {
+		fmt.Fprintf(&b, "  subscription: %s\n", schema.SubscriptionType.Name)
+	}
+	b.WriteString("}\n")
+
+	for _, t := range schema.Types {
+		// 内置的标量和内省类型由解析器提供
+		if strings.HasPrefix(t.Name, "__") {
+			continue
+		}
+		switch t.Name {
+		case "Int", "Float", "String", "Boolean", "ID":
+			continue
+		}
+		b.WriteString("\n")
+		writeDescription(&b, "", t.Description)
+		switch t.Kind {
+		case "SCALAR":
+			fmt.Fprintf(&b, "scalar %s\n", t.Name)
+		case "OBJECT", "INTERFACE":
+			keyword := "type"
+			if t.Kind == "INTERFACE" {
+				keyword = "interface"
+			}
+			fmt.Fprintf(&b, "%s %s", keyword, t.Name)
+			if len(t.Interfaces) > 0 {
+				names := make([]string, 0, len(t.Interfaces))
+				for _, v := range t.Interfaces {
+					names = append(names, v.Name)
+				}
+				fmt.Fprintf(&b, " implements %s", strings.Join(names, " & "))
+			}
+			b.WriteString(" {\n")
+			for _, f := range t.Fields {
+				writeDescription(&b, "  ", f.Description)
+				fmt.Fprintf(&b, "  %s", f.Name)
+				if len(f.Args) > 0 {
+					args := make([]string, 0, len(f.Args))
+					for _, arg := range f.Args {
+						args = append(args, inputValue(arg))
+					}
+					fmt.Fprintf(&b, "(%s)", strings.Join(args, ", "))
+				}
+				fmt.Fprintf(&b, ": %s\n", f.Type.String())
+			}
+			b.WriteString("}\n")
+		case "INPUT_OBJECT":
+			fmt.Fprintf(&b, "input %s {\n", t.Name)
+			for _, f := range t.InputFields {
+				writeDescription(&b, "  ", f.Description)
+				fmt.Fprintf(&b, "  %s\n", inputValue(f))
+			}
+			b.WriteString("}\n")
+		case "ENUM":
+			fmt.Fprintf(&b, "enum %s {\n", t.Name)
+			for _, v := range t.EnumValues {
+				writeDescription(&b, "  ", v.Description)
+				fmt.Fprintf(&b, "  %s\n", v.Name)
+			}
+			b.WriteString("}\n")
+		case "UNION":
+			names := make([]string, 0, len(t.PossibleTypes))
+			for _, v := range t.PossibleTypes {
+				names = append(names, v.Name)
+			}
+			fmt.Fprintf(&b, "union %s = %s\n", t.Name, strings.Join(names, " | "))
+		}
+	}
+	return b.String(), nil
+}
+
+// inputValue 参数和输入类型的字段 默认值已经是graphql的字面量
+func inputValue(v introspectionInputValue) string {
+	s := fmt.Sprintf("%s: %s", v.Name, v.Type.String())
+	if v.Description != "" {
+		// graphql字符串的转义和json相同
+		desc, _ := json.Marshal(v.Description)
+		s = string(desc) + " " + s
+	}
+	if v.DefaultValue != nil {
+		s += " = " + *v.DefaultValue
+	}
+	return s
+}
+
+func writeDescription(b *strings.Builder, indent, desc string) {
+	if desc == "" {
+		return
+	}
+	// 描述单独成行 避免结尾的引号和块字符串的结束符连在一起
+	fmt.Fprintf(b, "%s\"\"\"\n%s\n%s\"\"\"\n", indent, strings.ReplaceAll(desc, `"""`, `\"""`), indent)
+}
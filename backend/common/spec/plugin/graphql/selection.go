@@ -0,0 +1,230 @@
+package graphql
+
+import (
+	"fmt"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// 解析引用时的最大次数 避免模型之间循环引用
+const maxResolveCount = 10
+
+// ResponseSchema 按照查询文档的选择集裁剪响应的结构 返回的结构对应响应中的data
+// 属性名为字段的别名 引用的模型会被展开
+func ResponseSchema(query, operationName string, ops []spec.GraphQLPart, resolve func(ref string) *jsonschema.Schema) (*jsonschema.Schema, error) {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return nil, err
+	}
+	op := doc.Operations.ForName(operationName)
+	if op == nil {
+		if operationName == "" {
+			return nil, fmt.Errorf("must provide operation name if query contains multiple operations")
+		}
+		return nil, fmt.Errorf("unknown operation named %q", operationName)
+	}
+
+	sh := &shaper{fragments: doc.Fragments, resolve: resolve}
+	out := jsonschema.Create("object")
+	out.Properties = make(map[string]*jsonschema.Schema)
+	for _, f := range sh.fields(op.SelectionSet, "") {
+		key := f.Alias
+		if key == "" {
+			key = f.Name
+		}
+		if f.Name == "__typename" {
+			out.Properties[key] = typename(rootName(op.Operation))
+			out.XOrder = append(out.XOrder, key)
+			continue
+		}
+		var part *spec.GraphQLPart
+		for i := range ops {
+			if ops[i].Operation.Operation == string(op.Operation) && ops[i].Operation.Field == f.Name {
+				part = &ops[i]
+				break
+			}
+		}
+		if part == nil || part.Response == nil || part.Response.Properties[f.Name] == nil {
+			return nil, fmt.Errorf("cannot query field %q on type %q", f.Name, rootName(op.Operation))
+		}
+		s, err := sh.shape(part.Response.Properties[f.Name], f)
+		if err != nil {
+			return nil, err
+		}
+		out.Properties[key] = s
+		out.XOrder = append(out.XOrder, key)
+	}
+	return out, nil
+}
+
+func rootName(op ast.Operation) string {
+	switch op {
+	case ast.Mutation:
+		return "Mutation"
+	case ast.Subscription:
+		return "Subscription"
+	}
+	return "Query"
+}
+
+func typename(name string) *jsonschema.Schema {
+	s := jsonschema.Create("string")
+	if name != "" {
+		s.Enum = []any{name}
+	}
+	return s
+}
+
+type shaper struct {
+	fragments ast.FragmentDefinitionList
+	resolve   func(ref string) *jsonschema.Schema
+}
+
+// deref 展开引用 只有一项的allOf是带有描述的引用
+func (sh *shaper) deref(s *jsonschema.Schema) *jsonschema.Schema {
+	for i := 0; s != nil && i < maxResolveCount; i++ {
+		switch {
+		case s.Ref():
+			if sh.resolve == nil {
+				return nil
+			}
+			s = sh.resolve(*s.Reference)
+		case len(s.AllOf) == 1 && len(s.Properties) == 0:
+			s = s.AllOf[0]
+		default:
+			return s
+		}
+	}
+	return s
+}
+
+// fields 展开片段后的字段 typeName为当前对象的类型 片段的类型不一致时忽略
+func (sh *shaper) fields(set ast.SelectionSet, typeName string) []*ast.Field {
+	list := make([]*ast.Field, 0, len(set))
+	for _, v := range set {
+		switch x := v.(type) {
+		case *ast.Field:
+			list = append(list, x)
+		case *ast.InlineFragment:
+			if x.TypeCondition == "" || typeName == "" || x.TypeCondition == typeName {
+				list = append(list, sh.fields(x.SelectionSet, typeName)...)
+			}
+		case *ast.FragmentSpread:
+			fragment := sh.fragments.ForName(x.Name)
+			if fragment != nil && (typeName == "" || fragment.TypeCondition == typeName) {
+				list = append(list, sh.fields(fragment.SelectionSet, typeName)...)
+			}
+		}
+	}
+	return list
+}
+
+// conditions 选择集中片段的类型 用于从union中选择类型
+func (sh *shaper) conditions(set ast.SelectionSet) []string {
+	var list []string
+	for _, v := range set {
+		switch x := v.(type) {
+		case *ast.InlineFragment:
+			if x.TypeCondition != "" {
+				list = append(list, x.TypeCondition)
+			}
+		case *ast.FragmentSpread:
+			if fragment := sh.fragments.ForName(x.Name); fragment != nil {
+				list = append(list, fragment.TypeCondition)
+			}
+		}
+	}
+	return list
+}
+
+// shape 按照字段的选择集裁剪字段的结构
+func (sh *shaper) shape(s *jsonschema.Schema, f *ast.Field) (*jsonschema.Schema, error) {
+	s = sh.deref(s)
+	if s == nil {
+		return jsonschema.Create("null"), nil
+	}
+
+	if s.Items != nil && !s.Items.IsBool() {
+		items, err := sh.shape(s.Items.Value(), f)
+		if err != nil {
+			return nil, err
+		}
+		out := *s
+		out.Items = &jsonschema.ValueOrBoolean[*jsonschema.Schema]{}
+		out.Items.SetValue(items)
+		return &out, nil
+	}
+
+	// union 优先选择片段中出现的类型
+	if len(s.OneOf) > 0 {
+		variants := make([]*jsonschema.Schema, 0, len(s.OneOf))
+		for _, v := range s.OneOf {
+			if v = sh.deref(v); v != nil {
+				variants = append(variants, v)
+			}
+		}
+		if len(variants) == 0 {
+			return jsonschema.Create("null"), nil
+		}
+		s = variants[0]
+	found:
+		for _, cond := range sh.conditions(f.SelectionSet) {
+			for _, v := range variants {
+				if v.Title == cond {
+					s = v
+					break found
+				}
+			}
+		}
+	}
+
+	if len(s.Properties) == 0 {
+		if len(f.SelectionSet) > 0 {
+			return nil, fmt.Errorf("field %q must not have a selection since it has no subfields", f.Name)
+		}
+		return s, nil
+	}
+	if len(f.SelectionSet) == 0 {
+		return nil, fmt.Errorf("field %q of type %q must have a selection of subfields", f.Name, s.Title)
+	}
+
+	out := jsonschema.Create("object")
+	out.Title = s.Title
+	out.Description = s.Description
+	out.Properties = make(map[string]*jsonschema.Schema)
+	for _, child := range sh.fields(f.SelectionSet, s.Title) {
+		key := child.Alias
+		if key == "" {
+			key = child.Name
+		}
+		var p *jsonschema.Schema
+		required := child.Name == "__typename"
+		if required {
+			p = typename(s.Title)
+		} else {
+			v, ok := s.Properties[child.Name]
+			if !ok {
+				return nil, fmt.Errorf("cannot query field %q on type %q", child.Name, s.Title)
+			}
+			var err error
+			if p, err = sh.shape(v, child); err != nil {
+				return nil, err
+			}
+			for _, r := range s.Required {
+				required = required || r == child.Name
+			}
+		}
+		// 同名的字段只保留一个
+		if _, ok := out.Properties[key]; !ok {
+			out.XOrder = append(out.XOrder, key)
+			if required {
+				out.Required = append(out.Required, key)
+			}
+		}
+		out.Properties[key] = p
+	}
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package har
+
+// http://www.softwareishard.com/blog/har-12-spec/
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+}
+
+type Request struct {
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	HTTPVersion string    `json:"httpVersion"`
+	Headers     []NameVal `json:"headers"`
+	QueryString []NameVal `json:"queryString"`
+	PostData    *PostData `json:"postData,omitempty"`
+}
+
+type Response struct {
+	Status      int       `json:"status"`
+	StatusText  string    `json:"statusText"`
+	HTTPVersion string    `json:"httpVersion"`
+	Headers     []NameVal `json:"headers"`
+	Content     Content   `json:"content"`
+}
+
+type NameVal struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string    `json:"mimeType"`
+	Text     string    `json:"text"`
+	Params   []NameVal `json:"params"`
+}
+
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	// 二进制内容时为base64
+	Encoding string `json:"encoding,omitempty"`
+}
@@ -0,0 +1,81 @@
+package har
+
+import (
+	"os"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"golang.org/x/exp/slices"
+)
+
+func TestImport(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/example.har")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Import(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(x.Servers) != 2 || x.Servers[0].URL != "https://api.example.com" || x.Servers[1].URL != "https://auth.example.com" {
+		t.Fatalf("unexpected servers %+v", x.Servers)
+	}
+	if len(x.Collections) != 2 || x.Collections[0].Title != "api.example.com" {
+		t.Fatalf("unexpected collections %+v", x.Collections)
+	}
+	dirs := make([]string, 0)
+	for _, v := range x.Collections[0].Items {
+		dirs = append(dirs, v.Title)
+	}
+	if !slices.Equal(dirs, []string{"users", "orders"}) {
+		t.Errorf("unexpected dirs %v", dirs)
+	}
+
+	paths := x.CollectionsMap(false, 0)
+	if len(paths) != 4 {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+
+	user := paths["/users/{userId}"]["get"]
+	if len(user.Parameters.Path) != 1 || user.Parameters.Path[0].Schema.Type.Value()[0] != "integer" {
+		t.Errorf("unexpected path parameters %+v", user.Parameters.Path)
+	}
+	if len(user.Parameters.Query) != 1 || user.Parameters.Query[0].Required {
+		t.Errorf("include should be optional %+v", user.Parameters.Query)
+	}
+	if len(user.Parameters.Header) != 1 || user.Parameters.Header[0].Name != "Authorization" {
+		t.Errorf("unexpected headers %+v", user.Parameters.Header)
+	}
+	codes := user.Responses.Map()
+	if len(codes) != 2 {
+		t.Fatalf("unexpected responses %+v", user.Responses)
+	}
+	ok := codes[200].Content["application/json"].Schema
+	if !slices.Equal(ok.Required, []string{"id", "name"}) || ok.Properties["email"] == nil {
+		t.Errorf("unexpected response schema %+v", ok)
+	}
+	if codes[404].Content["application/json"].Schema.Properties["error"] == nil {
+		t.Error("base64 response not decoded")
+	}
+
+	order := paths["/users/{userId}/orders"]["post"]
+	if order.Content["application/json"] == nil || order.Content["application/json"].Schema.Properties["quantity"].Type.Value()[0] != "integer" {
+		t.Errorf("unexpected request body %+v", order.Content)
+	}
+	if p := paths["/orders/{orderId}"]["get"].Parameters.Path; len(p) != 1 || p[0].Schema.Format != "uuid" {
+		t.Errorf("unexpected uuid parameter %+v", p)
+	}
+	if form := paths["/token"]["post"].Content["application/x-www-form-urlencoded"]; form == nil || len(form.Schema.Properties) != 2 {
+		t.Errorf("unexpected form body %+v", form)
+	}
+
+	var found bool
+	x.WalkCollections(func(v *spec.CollectItem, p []string) bool {
+		found = found || v.Title == "GET /static/app.js"
+		return true
+	})
+	if found {
+		t.Error("static resource imported")
+	}
+}
@@ -0,0 +1,365 @@
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"golang.org/x/exp/slices"
+)
+
+// 静态资源不作为接口导入
+var staticExtensions = []string{".js", ".css", ".map", ".html", ".htm", ".png", ".jpg", ".jpeg", ".gif", ".svg", ".ico", ".webp", ".woff", ".woff2", ".ttf", ".eot", ".mp4", ".mp3"}
+var staticMimeTypes = []string{"text/html", "text/css", "text/javascript", "application/javascript", "image/", "font/", "video/", "audio/"}
+
+// endpoint 同一个host下请求方法和路径模板相同的请求
+type endpoint struct {
+	host    string
+	dir     string
+	method  string
+	path    string
+	params  []spec.PathVariable
+	entries []Entry
+}
+
+// Import 将HAR中记录的请求转为接口 按host和路径的第一段分组
+// 路径中的数字和uuid作为路径参数 相同的接口合并后根据所有的示例推断参数和body
+func Import(data []byte) (*spec.Spec, error) {
+	var h HAR
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	if len(h.Log.Entries) == 0 {
+		return nil, errors.New("har has no entries")
+	}
+
+	var (
+		hosts     []string
+		endpoints []*endpoint
+	)
+	index := make(map[string]*endpoint)
+	for _, entry := range h.Log.Entries {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil || u.Host == "" || isStatic(u.Path, entry.Response.Content.MimeType) {
+			continue
+		}
+		host := u.Scheme + "://" + u.Host
+		template, params := spec.PathTemplate(u.Path)
+		method := strings.ToLower(entry.Request.Method)
+		key := host + " " + method + " " + template
+		e, ok := index[key]
+		if !ok {
+			e = &endpoint{host: host, dir: pathDir(template), method: method, path: template, params: params}
+			index[key] = e
+			endpoints = append(endpoints, e)
+			if !slices.Contains(hosts, host) {
+				hosts = append(hosts, host)
+			}
+		}
+		e.entries = append(e.entries, entry)
+	}
+
+	var parmts spec.HTTPParameters
+	parmts.Fill()
+	p := &spec.Spec{
+		ApiCat: "2.0.1",
+		Info: &spec.Info{
+			Title:   "HAR",
+			Version: "1.0.0",
+		},
+		Servers: make([]*spec.Server, 0, len(hosts)),
+		Globals: spec.Global{Parameters: parmts},
+		Definitions: spec.Definitions{
+			Schemas:    make(spec.Schemas, 0),
+			Parameters: make(spec.Schemas, 0),
+			Responses:  make(spec.HTTPResponseDefines, 0),
+		},
+		Collections: make([]*spec.CollectItem, 0, len(hosts)),
+	}
+	if h.Log.Creator.Name != "" {
+		p.Info.Description = fmt.Sprintf("Imported from %s %s", h.Log.Creator.Name, h.Log.Creator.Version)
+	}
+
+	var id int64
+	nextID := func() int64 {
+		id++
+		return id
+	}
+	for _, host := range hosts {
+		p.Servers = append(p.Servers, &spec.Server{URL: host, Description: strings.SplitN(host, "://", 2)[1]})
+		hostItem := &spec.CollectItem{
+			ID:    nextID(),
+			Type:  spec.ContentItemTypeDir,
+			Title: strings.SplitN(host, "://", 2)[1],
+			Items: make([]*spec.CollectItem, 0),
+		}
+		dirs := make(map[string]*spec.CollectItem)
+		for _, e := range endpoints {
+			if e.host != host {
+				continue
+			}
+			parent := hostItem
+			if e.dir != "" {
+				dir, ok := dirs[e.dir]
+				if !ok {
+					dir = &spec.CollectItem{
+						ID:       nextID(),
+						ParentID: hostItem.ID,
+						Type:     spec.ContentItemTypeDir,
+						Title:    e.dir,
+						Items:    make([]*spec.CollectItem, 0),
+					}
+					dirs[e.dir] = dir
+					hostItem.Items = append(hostItem.Items, dir)
+				}
+				parent = dir
+			}
+			parent.Items = append(parent.Items, &spec.CollectItem{
+				ID:       nextID(),
+				ParentID: parent.ID,
+				Type:     spec.ContentItemTypeHttp,
+				Title:    strings.ToUpper(e.method) + " " + e.path,
+				Content:  e.content(),
+			})
+		}
+		p.Collections = append(p.Collections, hostItem)
+	}
+	return p, nil
+}
+
+func isStatic(p, mimeType string) bool {
+	if slices.Contains(staticExtensions, strings.ToLower(path.Ext(p))) {
+		return true
+	}
+	mimeType = strings.ToLower(mimeType)
+	for _, v := range staticMimeTypes {
+		if strings.HasPrefix(mimeType, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathDir 路径模板中第一个非参数的段 用于分组
+func pathDir(template string) string {
+	for _, seg := range strings.Split(strings.Trim(template, "/"), "/") {
+		if seg != "" && !strings.HasPrefix(seg, "{") {
+			return seg
+		}
+	}
+	return ""
+}
+
+func (e *endpoint) content() []*spec.NodeProxy {
+	req := spec.HTTPRequestNode{
+		GlobalExcepts: make(map[string][]int64),
+	}
+	req.Parameters.Fill()
+	for _, v := range e.params {
+		req.Parameters.Path = append(req.Parameters.Path, v.Parameter())
+	}
+
+	queries := make([][]NameVal, 0, len(e.entries))
+	headers := make([][]NameVal, 0, len(e.entries))
+	for _, v := range e.entries {
+		queries = append(queries, v.Request.QueryString)
+		headers = append(headers, filterHeaders(v.Request.Headers))
+	}
+	req.Parameters.Query = mergeParameters(queries)
+	req.Parameters.Header = mergeParameters(headers)
+	req.Content = e.requestBody()
+
+	return []*spec.NodeProxy{
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.HTTPURLNode{
+			Path:   e.path,
+			Method: e.method,
+		})),
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(req)),
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(*e.responses())),
+	}
+}
+
+// filterHeaders 只保留自定义的头和Authorization 浏览器自动添加的头不作为参数
+func filterHeaders(list []NameVal) []NameVal {
+	headers := make([]NameVal, 0)
+	for _, v := range list {
+		name := strings.ToLower(v.Name)
+		if strings.HasPrefix(name, "x-") || name == "authorization" {
+			headers = append(headers, v)
+		}
+	}
+	return headers
+}
+
+// mergeParameters 合并多个请求的参数 所有请求都有的参数为必须
+func mergeParameters(list [][]NameVal) spec.Schemas {
+	var names []string
+	counts := make(map[string]int)
+	examples := make(map[string]string)
+	for _, params := range list {
+		seen := make(map[string]bool)
+		for _, v := range params {
+			key := strings.ToLower(v.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, ok := counts[key]; !ok {
+				names = append(names, v.Name)
+				examples[key] = v.Value
+			}
+			counts[key]++
+		}
+	}
+	schemas := make(spec.Schemas, 0, len(names))
+	for _, name := range names {
+		sh := jsonschema.Create("string")
+		sh.Example = examples[strings.ToLower(name)]
+		schemas = append(schemas, &spec.Schema{
+			Name:     name,
+			Required: counts[strings.ToLower(name)] == len(list),
+			Schema:   sh,
+		})
+	}
+	return schemas
+}
+
+func (e *endpoint) requestBody() spec.HTTPBody {
+	samples := newBodySamples()
+	for _, v := range e.entries {
+		if v.Request.PostData == nil {
+			continue
+		}
+		pd := v.Request.PostData
+		contentType := mediaType(pd.MimeType)
+		switch {
+		case contentType == "application/x-www-form-urlencoded" || contentType == "multipart/form-data":
+			obj := make(map[string]any)
+			params := pd.Params
+			if len(params) == 0 && contentType == "application/x-www-form-urlencoded" {
+				if values, err := url.ParseQuery(pd.Text); err == nil {
+					for k := range values {
+						params = append(params, NameVal{Name: k, Value: values.Get(k)})
+					}
+				}
+			}
+			for _, p := range params {
+				obj[p.Name] = p.Value
+			}
+			samples.add(contentType, obj)
+		default:
+			samples.addText(contentType, pd.Text)
+		}
+	}
+	return samples.body()
+}
+
+func (e *endpoint) responses() *spec.HTTPResponsesNode {
+	var codes []int
+	byCode := make(map[int]*bodySamples)
+	for _, v := range e.entries {
+		res := v.Response
+		// 被取消或没有响应的请求
+		if res.Status == 0 {
+			continue
+		}
+		samples, ok := byCode[res.Status]
+		if !ok {
+			samples = newBodySamples()
+			byCode[res.Status] = samples
+			codes = append(codes, res.Status)
+		}
+		text := res.Content.Text
+		if res.Content.Encoding == "base64" {
+			if b, err := base64.StdEncoding.DecodeString(text); err == nil {
+				text = string(b)
+			}
+		}
+		samples.addText(mediaType(res.Content.MimeType), text)
+	}
+
+	node := &spec.HTTPResponsesNode{List: make(spec.HTTPResponses, 0, len(codes))}
+	for _, code := range codes {
+		r := spec.HTTPResponse{Code: code}
+		r.Description = http.StatusText(code)
+		r.Content = byCode[code].body()
+		node.List = append(node.List, r)
+	}
+	return node
+}
+
+// bodySamples 按照content type收集body示例
+type bodySamples struct {
+	types   []string
+	samples map[string][]any
+	texts   map[string]string
+}
+
+func newBodySamples() *bodySamples {
+	return &bodySamples{
+		samples: make(map[string][]any),
+		texts:   make(map[string]string),
+	}
+}
+
+func (b *bodySamples) addType(contentType string) {
+	if !slices.Contains(b.types, contentType) {
+		b.types = append(b.types, contentType)
+	}
+}
+
+func (b *bodySamples) add(contentType string, v any) {
+	b.addType(contentType)
+	b.samples[contentType] = append(b.samples[contentType], v)
+}
+
+// addText json内容解析后作为示例 其它内容只保留第一个作为字符串示例
+func (b *bodySamples) addText(contentType, text string) {
+	if contentType == "" || strings.TrimSpace(text) == "" {
+		return
+	}
+	if strings.Contains(contentType, "json") {
+		if v, err := jsonschema.ParseSample([]byte(text)); err == nil {
+			b.add(contentType, v)
+		}
+		return
+	}
+	b.addType(contentType)
+	if _, ok := b.texts[contentType]; !ok {
+		b.texts[contentType] = text
+	}
+}
+
+func (b *bodySamples) body() spec.HTTPBody {
+	if len(b.types) == 0 {
+		return nil
+	}
+	body := make(spec.HTTPBody)
+	for _, t := range b.types {
+		var sh *jsonschema.Schema
+		if samples, ok := b.samples[t]; ok {
+			sh = jsonschema.Infer(samples...)
+		} else {
+			sh = jsonschema.Create("string")
+			sh.Example = b.texts[t]
+		}
+		body[t] = &spec.Schema{Schema: sh}
+	}
+	return body
+}
+
+func mediaType(v string) string {
+	t, _, err := mime.ParseMediaType(v)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(v))
+	}
+	return t
+}
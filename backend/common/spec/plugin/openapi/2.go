@@ -17,6 +17,8 @@ import (
 type fromSwagger struct {
 	schemaMapping     map[string]int64
 	parametersMapping map[string]int64
+	// 文档级别的认证要求 接口没有设置时使用
+	security []spec.SecurityRequirement
 }
 
 func (s *fromSwagger) parseInfo(info *base.Info) *spec.Info {
@@ -95,6 +97,60 @@ func (s *fromSwagger) parseParametersDefine(in *v2.Swagger) spec.Schemas {
 	return ps
 }
 
+// swagger的oauth2 flow名称和openapi3的对应关系
+var swaggerOAuthFlows = map[string]string{
+	"implicit":    "implicit",
+	"password":    "password",
+	"application": "clientCredentials",
+	"accessCode":  "authorizationCode",
+}
+
+func (s *fromSwagger) parseSecurityDefinitions(defs *v2.SecurityDefinitions) spec.SecuritySchemes {
+	schemes := make(spec.SecuritySchemes, 0)
+	if defs == nil {
+		return schemes
+	}
+	for name, v := range defs.Definitions {
+		scheme := &spec.SecurityScheme{
+			Name:        name,
+			Type:        v.Type,
+			Description: v.Description,
+		}
+		switch v.Type {
+		case "basic":
+			scheme.Type = spec.SecurityTypeHTTP
+			scheme.Scheme = "basic"
+		case spec.SecurityTypeAPIKey:
+			scheme.In = v.In
+			scheme.Key = v.Name
+		case spec.SecurityTypeOAuth2:
+			flow := &spec.OAuthFlow{
+				AuthorizationURL: v.AuthorizationUrl,
+				TokenURL:         v.TokenUrl,
+				Scopes:           map[string]string{},
+			}
+			if v.Scopes != nil {
+				flow.Scopes = v.Scopes.Values
+			}
+			scheme.Flows = &spec.OAuthFlows{}
+			switch swaggerOAuthFlows[v.Flow] {
+			case "implicit":
+				scheme.Flows.Implicit = flow
+			case "password":
+				scheme.Flows.Password = flow
+			case "clientCredentials":
+				scheme.Flows.ClientCredentials = flow
+			default:
+				scheme.Flows.AuthorizationCode = flow
+			}
+		default:
+			continue
+		}
+		schemes = append(schemes, scheme)
+	}
+	return schemes
+}
+
 // 主要处理$ref引用问题
 func (s *fromSwagger) parseContent(b *base.SchemaProxy) *jsonschema.Schema {
 	js, err := jsonSchemaConverter(b)
@@ -107,10 +163,17 @@ func (s *fromSwagger) parseContent(b *base.SchemaProxy) *jsonschema.Schema {
 func (s *fromSwagger) parseRequest(in *v2.Swagger, info *v2.Operation) spec.HTTPRequestNode {
 	// parameters := &spec.HttpParameters{}
 	request := spec.HTTPRequestNode{
-		Content: make(spec.HTTPBody),
+		Content:  make(spec.HTTPBody),
+		Security: s.security,
+	}
+	if len(info.Security) > 0 {
+		request.Security = parseSecurityRequirements(info.Security)
 	}
 	request.Parameters.Fill()
-	var body *jsonschema.Schema
+	var (
+		body         *jsonschema.Schema
+		bodyRequired bool
+	)
 	// 有效载荷application/x-www-form-urlencoded和multipart/form-data请求是通过使用form参数来描述，而不是body参数。
 	formData := &jsonschema.Schema{
 		Type:       jsonschema.CreateSliceOrOne("object"),
@@ -146,6 +209,7 @@ func (s *fromSwagger) parseRequest(in *v2.Swagger, info *v2.Operation) spec.HTTP
 			}
 		case "body":
 			body = s.parseContent(v.Schema)
+			bodyRequired = required
 		}
 	}
 
@@ -164,7 +228,7 @@ func (s *fromSwagger) parseRequest(in *v2.Swagger, info *v2.Operation) spec.HTTP
 			request.Content[v] = &spec.Schema{Schema: formData}
 		} else {
 			if body != nil {
-				request.Content[v] = &spec.Schema{Schema: body}
+				request.Content[v] = &spec.Schema{Schema: body, Required: bodyRequired}
 			}
 		}
 	}
@@ -356,10 +420,14 @@ type swaggerSpec struct {
 	Parameters  map[string]openAPIParamter            `json:"parameters,omitempty"`
 	Responses   map[string]any                        `json:"responses,omitempty"`
 	Paths       map[string]map[string]swaggerPathItem `json:"paths"`
+
+	SecurityDefinitions map[string]any `json:"securityDefinitions,omitempty"`
 }
 
 type toSwagger struct {
 	schemas map[int64]string
+	// 输出的认证方式
+	securities map[string]any
 }
 
 func (s *toSwagger) toBase(in *spec.Spec) *swaggerSpec {
@@ -421,6 +489,75 @@ type swaggerPathItem struct {
 	Produces    []string          `json:"produces,omitempty"`
 	Parameters  []openAPIParamter `json:"parameters,omitempty"`
 	Responses   map[string]any    `json:"responses,omitempty"`
+
+	Security []spec.SecurityRequirement `json:"security,omitempty"`
+}
+
+// toSecurityDefinitions swagger不支持bearer和openIdConnect
+// bearer转为header中的Authorization openIdConnect忽略
+func (s *toSwagger) toSecurityDefinitions(schemes spec.SecuritySchemes) map[string]any {
+	if len(schemes) == 0 {
+		return nil
+	}
+	out := make(map[string]any)
+	for _, v := range schemes {
+		def := map[string]any{}
+		if v.Description != "" {
+			def["description"] = v.Description
+		}
+		switch v.Type {
+		case spec.SecurityTypeAPIKey:
+			def["type"] = spec.SecurityTypeAPIKey
+			def["in"] = v.In
+			def["name"] = v.Key
+		case spec.SecurityTypeHTTP:
+			if strings.EqualFold(v.Scheme, "basic") {
+				def["type"] = "basic"
+			} else {
+				def["type"] = spec.SecurityTypeAPIKey
+				def["in"] = "header"
+				def["name"] = "Authorization"
+			}
+		case spec.SecurityTypeOAuth2:
+			if v.Flows == nil {
+				continue
+			}
+			// swagger只支持一种flow
+			def["type"] = spec.SecurityTypeOAuth2
+			for _, x := range []struct {
+				name string
+				flow *spec.OAuthFlow
+			}{
+				{"accessCode", v.Flows.AuthorizationCode},
+				{"application", v.Flows.ClientCredentials},
+				{"password", v.Flows.Password},
+				{"implicit", v.Flows.Implicit},
+			} {
+				flow := x.flow
+				if flow == nil {
+					continue
+				}
+				def["flow"] = x.name
+				if flow.AuthorizationURL != "" {
+					def["authorizationUrl"] = flow.AuthorizationURL
+				}
+				if flow.TokenURL != "" {
+					def["tokenUrl"] = flow.TokenURL
+				}
+				scopes := flow.Scopes
+				if scopes == nil {
+					scopes = map[string]string{}
+				}
+				def["scopes"] = scopes
+				break
+			}
+		default:
+			continue
+		}
+		out[v.Name] = def
+	}
+	s.securities = out
+	return out
 }
 
 func (s *toSwagger) toReqParameters(ps spec.HTTPRequestNode, spe *spec.Spec) []openAPIParamter {
@@ -641,6 +778,7 @@ func (s *toSwagger) toPaths(in *spec.Spec) (map[string]map[string]swaggerPathIte
 				Produces:    product,
 				Responses:   reslist,
 				Tags:        op.Tags,
+				Security:    toSecurityRequirements(s.securities, op.Req.Security),
 			}
 			for k := range op.Req.Content {
 				item.Consumes = append(item.Consumes, k)
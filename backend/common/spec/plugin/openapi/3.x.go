@@ -15,6 +15,8 @@ import (
 type fromOpenapi struct {
 	schemaMapping     map[string]int64
 	parametersMapping map[string]int64
+	// 文档级别的认证要求 接口没有设置时使用
+	security []spec.SecurityRequirement
 }
 
 func (o *fromOpenapi) parseInfo(info *base.Info) *spec.Info {
@@ -79,6 +81,7 @@ func (o *fromOpenapi) parseDefinetions(comp *v3.Components) spec.Definitions {
 			Schemas:    make(spec.Schemas, 0),
 			Parameters: make(spec.Schemas, 0),
 			Responses:  make(spec.HTTPResponseDefines, 0),
+			Securities: make(spec.SecuritySchemes, 0),
 		}
 	}
 	o.schemaMapping = map[string]int64{}
@@ -119,12 +122,52 @@ func (o *fromOpenapi) parseDefinetions(comp *v3.Components) spec.Definitions {
 		if v.Content != nil {
 			def.Content = o.parseContent(v.Content)
 		}
+		def.Links = o.parseLinks(v.Links)
 		rets = append(rets, def)
 	}
 	return spec.Definitions{
 		Schemas:    schemas,
 		Responses:  rets,
 		Parameters: o.parseParametersDefine(comp),
+		Securities: o.parseSecuritySchemes(comp.SecuritySchemes),
+	}
+}
+
+func (o *fromOpenapi) parseSecuritySchemes(in map[string]*v3.SecurityScheme) spec.SecuritySchemes {
+	schemes := make(spec.SecuritySchemes, 0)
+	for name, v := range in {
+		scheme := &spec.SecurityScheme{
+			Name:             name,
+			Type:             v.Type,
+			Description:      v.Description,
+			In:               v.In,
+			Key:              v.Name,
+			Scheme:           v.Scheme,
+			BearerFormat:     v.BearerFormat,
+			OpenIDConnectURL: v.OpenIdConnectUrl,
+		}
+		if v.Flows != nil {
+			scheme.Flows = &spec.OAuthFlows{
+				Implicit:          parseOAuthFlow(v.Flows.Implicit),
+				Password:          parseOAuthFlow(v.Flows.Password),
+				ClientCredentials: parseOAuthFlow(v.Flows.ClientCredentials),
+				AuthorizationCode: parseOAuthFlow(v.Flows.AuthorizationCode),
+			}
+		}
+		schemes = append(schemes, scheme)
+	}
+	return schemes
+}
+
+func parseOAuthFlow(in *v3.OAuthFlow) *spec.OAuthFlow {
+	if in == nil {
+		return nil
+	}
+	return &spec.OAuthFlow{
+		AuthorizationURL: in.AuthorizationUrl,
+		TokenURL:         in.TokenUrl,
+		RefreshURL:       in.RefreshUrl,
+		Scopes:           in.Scopes,
 	}
 }
 
@@ -214,13 +257,68 @@ func (o *fromOpenapi) parseeResoponse(responses map[string]*v3.Response) spec.HT
 			}
 		}
 		resp.Content = o.parseContent(res.Content)
+		resp.Links = o.parseLinks(res.Links)
 		outresponses.List = append(outresponses.List, resp)
 	}
 	return outresponses
 }
 
+func (o *fromOpenapi) parseLinks(links map[string]*v3.Link) spec.HTTPLinks {
+	if len(links) == 0 {
+		return nil
+	}
+	out := make(spec.HTTPLinks)
+	for name, v := range links {
+		out[name] = &spec.HTTPLink{
+			OperationID:  v.OperationId,
+			OperationRef: v.OperationRef,
+			Parameters:   v.Parameters,
+			RequestBody:  v.RequestBody,
+			Description:  v.Description,
+		}
+	}
+	return out
+}
+
+// parseOperation 将接口的描述 请求和响应转为文档节点
+func (o *fromOpenapi) parseOperation(info *v3.Operation) []*spec.NodeProxy {
+	content := make([]*spec.NodeProxy, 0)
+
+	// parse markdown to doc
+	doctree := markdown.ToDocment([]byte(info.Description))
+	for _, v := range doctree.Items {
+		content = append(content, spec.MuseCreateNodeProxy(v))
+	}
+
+	// request
+	var req spec.HTTPRequestNode
+	req.Parameters = o.parseParameters(info.Parameters)
+	req.Security = o.security
+	if len(info.Security) > 0 {
+		req.Security = parseSecurityRequirements(info.Security)
+	}
+	if info.RequestBody != nil {
+		req.Content = o.parseContent(info.RequestBody.Content)
+		if info.RequestBody.Required != nil && *info.RequestBody.Required {
+			for _, v := range req.Content {
+				v.Required = true
+			}
+		}
+	}
+	content = append(content, spec.MuseCreateNodeProxy(spec.WarpHTTPNode(req)))
+	// response
+	if info.Responses != nil {
+		res := o.parseeResoponse(info.Responses.Codes)
+		content = append(content, spec.MuseCreateNodeProxy(spec.WarpHTTPNode(res)))
+	}
+	return content
+}
+
 func (o *fromOpenapi) parseCollections(paths *v3.Paths) []*spec.CollectItem {
 	collects := make([]*spec.CollectItem, 0)
+	if paths == nil {
+		return collects
+	}
 	for path, p := range paths.PathItems {
 		op := p.GetOperations()
 		for method, info := range op {
@@ -232,33 +330,82 @@ func (o *fromOpenapi) parseCollections(paths *v3.Paths) []*spec.CollectItem {
 					}),
 				),
 			}
+			content = append(content, o.parseOperation(info)...)
 
-			// parse markdown to doc
-			doctree := markdown.ToDocment([]byte(info.Description))
-			for _, v := range doctree.Items {
-				content = append(content, spec.MuseCreateNodeProxy(v))
+			title := info.Summary
+			if title == "" {
+				title = path
 			}
 
-			// request
-			var req spec.HTTPRequestNode
-			req.Parameters = o.parseParameters(info.Parameters)
-			if info.RequestBody != nil {
-				req.Content = o.parseContent(info.RequestBody.Content)
+			collects = append(collects, &spec.CollectItem{
+				Type:    spec.ContentItemTypeHttp,
+				Title:   title,
+				Tags:    info.Tags,
+				Content: content,
+			})
+			collects = append(collects, o.parseCallbacks(spec.HTTPURLNode{Path: path, Method: method}, info)...)
+		}
+	}
+	return collects
+}
+
+// parseCallbacks 接口的每个回调请求作为一个集合
+func (o *fromOpenapi) parseCallbacks(operation spec.HTTPURLNode, info *v3.Operation) []*spec.CollectItem {
+	collects := make([]*spec.CollectItem, 0)
+	for event, callback := range info.Callbacks {
+		for expression, p := range callback.Expression {
+			for method, cb := range p.GetOperations() {
+				content := []*spec.NodeProxy{
+					spec.MuseCreateNodeProxy(
+						spec.WarpHTTPNode(spec.HTTPCallbackNode{
+							Event:      event,
+							Expression: expression,
+							Method:     method,
+							Operation:  operation,
+						}),
+					),
+				}
+				content = append(content, o.parseOperation(cb)...)
+
+				title := cb.Summary
+				if title == "" {
+					title = event
+				}
+
+				collects = append(collects, &spec.CollectItem{
+					Type:    spec.ContentItemTypeCallback,
+					Title:   title,
+					Tags:    info.Tags,
+					Content: content,
+				})
 			}
-			content = append(content, spec.MuseCreateNodeProxy(spec.WarpHTTPNode(req)))
-			// response
-			if info.Responses != nil {
-				res := o.parseeResoponse(info.Responses.Codes)
-				content = append(content, spec.MuseCreateNodeProxy(spec.WarpHTTPNode(res)))
+		}
+	}
+	return collects
+}
+
+// parseWebhooks openapi 3.1 的webhooks
+func (o *fromOpenapi) parseWebhooks(webhooks map[string]*v3.PathItem) []*spec.CollectItem {
+	collects := make([]*spec.CollectItem, 0)
+	for event, p := range webhooks {
+		for method, info := range p.GetOperations() {
+			content := []*spec.NodeProxy{
+				spec.MuseCreateNodeProxy(
+					spec.WarpHTTPNode(spec.HTTPWebhookNode{
+						Event:  event,
+						Method: method,
+					}),
+				),
 			}
+			content = append(content, o.parseOperation(info)...)
 
 			title := info.Summary
 			if title == "" {
-				title = path
+				title = event
 			}
 
 			collects = append(collects, &spec.CollectItem{
-				Type:    spec.ContentItemTypeHttp,
+				Type:    spec.ContentItemTypeWebhook,
 				Title:   title,
 				Tags:    info.Tags,
 				Content: content,
@@ -276,11 +423,16 @@ type openapiSpec struct {
 	Servers    []*spec.Server                        `json:"servers,omitempty"`
 	Components map[string]any                        `json:"components,omitempty"`
 	Paths      map[string]map[string]openapiPathItem `json:"paths"`
-	Tags       []tagObject                           `json:"tags,omitempty"`
+	Webhooks   map[string]map[string]openapiPathItem `json:"webhooks,omitempty"`
+	// XWebhooks 3.0 不支持webhooks 使用扩展字段输出
+	XWebhooks map[string]map[string]openapiPathItem `json:"x-webhooks,omitempty"`
+	Tags      []tagObject                           `json:"tags,omitempty"`
 }
 
 type toOpenapi struct {
 	schemaMapping map[int64]string
+	// 输出的认证方式
+	securities map[string]any
 }
 
 func (o *toOpenapi) toBase(in *spec.Spec, ver string) *openapiSpec {
@@ -297,16 +449,20 @@ func (o *toOpenapi) toBase(in *spec.Spec, ver string) *openapiSpec {
 }
 
 type openapiRequestbody struct {
-	Content spec.HTTPBody `json:"content,omitempty"`
+	Required bool          `json:"required,omitempty"`
+	Content  spec.HTTPBody `json:"content,omitempty"`
 }
 type openapiPathItem struct {
-	Summary     string              `json:"summary"`
-	Description string              `json:"description,omitempty"`
-	OperationId string              `json:"operationId"`
-	Tags        []string            `json:"tags,omitempty"`
-	Parameters  []openAPIParamter   `json:"parameters,omitempty"`
-	RequestBody *openapiRequestbody `json:"requestBody,omitempty"`
-	Responses   map[string]any      `json:"responses,omitempty"`
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	OperationId string                     `json:"operationId"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []openAPIParamter          `json:"parameters,omitempty"`
+	RequestBody *openapiRequestbody        `json:"requestBody,omitempty"`
+	Responses   map[string]any             `json:"responses,omitempty"`
+	Security    []spec.SecurityRequirement `json:"security,omitempty"`
+	// event -> expression -> method
+	Callbacks map[string]map[string]map[string]openapiPathItem `json:"callbacks,omitempty"`
 }
 
 // 3.0/3.1使用的jsonschema标准不太一样 3.1偏标准
@@ -373,50 +529,20 @@ func (o *toOpenapi) toReqParameters(spe *spec.Spec, ps spec.HTTPRequestNode, ver
 func (o *toOpenapi) toPaths(ver string, in *spec.Spec) (
 	map[string]map[string]openapiPathItem, []tagObject) {
 	var (
-		out  = make(map[string]map[string]openapiPathItem)
-		tags = make(map[string]struct{})
+		out       = make(map[string]map[string]openapiPathItem)
+		tags      = make(map[string]struct{})
+		callbacks = o.toCallbacks(ver, in)
 	)
 	for path, ops := range walkHttpCollection(in) {
 		if path == "" {
 			continue
 		}
 		for method, op := range ops {
-			item := openapiPathItem{
-				Summary:     op.Title,
-				Description: op.Description,
-				OperationId: op.OperatorID,
-				Tags:        op.Tags,
-				Parameters:  o.toReqParameters(in, op.Req, ver),
-				Responses:   make(map[string]any),
-			}
+			item := o.toPathItem(ver, in, op)
+			item.Callbacks = callbacks[strings.ToLower(method)+" "+path]
 			for _, v := range op.Tags {
 				tags[v] = struct{}{}
 			}
-			for k, v := range op.Req.Content {
-				sp := &spec.Schema{
-					Schema:      o.convertJSONSchema(ver, v.Schema),
-					Description: v.Description,
-					Examples:    v.Examples,
-				}
-				if sp.Schema.Example != nil {
-					sp.Example = sp.Schema.Example
-				}
-				if item.RequestBody == nil {
-					item.RequestBody = &openapiRequestbody{
-						Content: make(spec.HTTPBody),
-					}
-				}
-				item.RequestBody.Content[k] = sp
-			}
-			for _, v := range op.Res.List {
-				res := o.toResponse(in, v.HTTPResponseDefine, ver)
-				item.Responses[strconv.Itoa(v.Code)] = res
-			}
-			if len(op.Res.List) == 0 {
-				item.Responses["200"] = map[string]any{
-					"description": "success",
-				}
-			}
 			if _, ok := out[path]; !ok {
 				out[path] = make(map[string]openapiPathItem)
 			}
@@ -431,6 +557,85 @@ func (o *toOpenapi) toPaths(ver string, in *spec.Spec) (
 	}()
 }
 
+func (o *toOpenapi) toPathItem(ver string, in *spec.Spec, op specPathItem) openapiPathItem {
+	item := openapiPathItem{
+		Summary:     op.Title,
+		Description: op.Description,
+		OperationId: op.OperatorID,
+		Tags:        op.Tags,
+		Parameters:  o.toReqParameters(in, op.Req, ver),
+		Responses:   make(map[string]any),
+		Security:    toSecurityRequirements(o.securities, op.Req.Security),
+	}
+	for k, v := range op.Req.Content {
+		sp := &spec.Schema{
+			Schema:      o.convertJSONSchema(ver, v.Schema),
+			Description: v.Description,
+			Examples:    v.Examples,
+		}
+		if sp.Schema.Example != nil {
+			sp.Example = sp.Schema.Example
+		}
+		if item.RequestBody == nil {
+			item.RequestBody = &openapiRequestbody{
+				Content: make(spec.HTTPBody),
+			}
+		}
+		item.RequestBody.Content[k] = sp
+		if v.Required {
+			item.RequestBody.Required = true
+		}
+	}
+	for _, v := range op.Res.List {
+		res := o.toResponse(in, v.HTTPResponseDefine, ver)
+		item.Responses[strconv.Itoa(v.Code)] = res
+	}
+	if len(op.Res.List) == 0 {
+		item.Responses["200"] = map[string]any{
+			"description": "success",
+		}
+	}
+	return item
+}
+
+// toWebhooks 返回 event -> method 结构的webhooks
+func (o *toOpenapi) toWebhooks(ver string, in *spec.Spec) map[string]map[string]openapiPathItem {
+	out := make(map[string]map[string]openapiPathItem)
+	for _, v := range walkEventCollection(in) {
+		if v.Webhook == nil {
+			continue
+		}
+		if _, ok := out[v.Webhook.Event]; !ok {
+			out[v.Webhook.Event] = make(map[string]openapiPathItem)
+		}
+		out[v.Webhook.Event][v.Webhook.Method] = o.toPathItem(ver, in, v.specPathItem)
+	}
+	return out
+}
+
+// toCallbacks 按照所属接口的 "method path" 分组返回回调
+func (o *toOpenapi) toCallbacks(ver string, in *spec.Spec) map[string]map[string]map[string]map[string]openapiPathItem {
+	out := make(map[string]map[string]map[string]map[string]openapiPathItem)
+	for _, v := range walkEventCollection(in) {
+		cb := v.Callback
+		if cb == nil {
+			continue
+		}
+		key := strings.ToLower(cb.Operation.Method) + " " + cb.Operation.Path
+		if _, ok := out[key]; !ok {
+			out[key] = make(map[string]map[string]map[string]openapiPathItem)
+		}
+		if _, ok := out[key][cb.Event]; !ok {
+			out[key][cb.Event] = make(map[string]map[string]openapiPathItem)
+		}
+		if _, ok := out[key][cb.Event][cb.Expression]; !ok {
+			out[key][cb.Event][cb.Expression] = make(map[string]openapiPathItem)
+		}
+		out[key][cb.Event][cb.Expression][cb.Method] = o.toPathItem(ver, in, v.specPathItem)
+	}
+	return out
+}
+
 func (o *toOpenapi) toResponse(in *spec.Spec, def spec.HTTPResponseDefine, ver string) map[string]any {
 	res := map[string]any{}
 	v := def
@@ -464,6 +669,9 @@ func (o *toOpenapi) toResponse(in *spec.Spec, def spec.HTTPResponseDefine, ver s
 		}
 		res["headers"] = headers
 	}
+	if len(v.Links) > 0 {
+		res["links"] = v.Links
+	}
 	res["description"] = v.Description
 	return res
 }
@@ -493,9 +701,74 @@ func (o *toOpenapi) toComponents(ver string, in *spec.Spec) map[string]any {
 		}
 	}
 
-	return map[string]any{
+	components := map[string]any{
 		"schemas":    schemas,
 		"responses":  respons,
 		"parameters": parameters,
 	}
+	if securities := o.toSecuritySchemes(in.Definitions.Securities); len(securities) > 0 {
+		components["securitySchemes"] = securities
+	}
+	return components
+}
+
+// openapiOAuthFlow scopes是必须的
+type openapiOAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes"`
+}
+
+func (o *toOpenapi) toSecuritySchemes(schemes spec.SecuritySchemes) map[string]any {
+	o.securities = make(map[string]any)
+	for _, v := range schemes {
+		def := map[string]any{
+			"type": v.Type,
+		}
+		if v.Description != "" {
+			def["description"] = v.Description
+		}
+		switch v.Type {
+		case spec.SecurityTypeAPIKey:
+			def["in"] = v.In
+			def["name"] = v.Key
+		case spec.SecurityTypeHTTP:
+			def["scheme"] = v.Scheme
+			if v.BearerFormat != "" {
+				def["bearerFormat"] = v.BearerFormat
+			}
+		case spec.SecurityTypeOAuth2:
+			flows := map[string]any{}
+			if v.Flows != nil {
+				for name, flow := range map[string]*spec.OAuthFlow{
+					"implicit":          v.Flows.Implicit,
+					"password":          v.Flows.Password,
+					"clientCredentials": v.Flows.ClientCredentials,
+					"authorizationCode": v.Flows.AuthorizationCode,
+				} {
+					if flow == nil {
+						continue
+					}
+					x := openapiOAuthFlow{
+						AuthorizationURL: flow.AuthorizationURL,
+						TokenURL:         flow.TokenURL,
+						RefreshURL:       flow.RefreshURL,
+						Scopes:           flow.Scopes,
+					}
+					if x.Scopes == nil {
+						x.Scopes = map[string]string{}
+					}
+					flows[name] = x
+				}
+			}
+			def["flows"] = flows
+		case spec.SecurityTypeOpenIDConnect:
+			def["openIdConnectUrl"] = v.OpenIDConnectURL
+		default:
+			continue
+		}
+		o.securities[v.Name] = def
+	}
+	return o.securities
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/apicat/apicat/backend/common/spec/jsonschema"
 	"github.com/apicat/apicat/backend/common/spec/markdown"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/pb33f/libopenapi/utils"
 )
 
@@ -44,7 +45,7 @@ func parseSwagger(document libopenapi.Document) (*spec.Spec, error) {
 	if len(errors) > 0 {
 		return nil, fmt.Errorf("swagger version:%s parse faild", document.GetVersion())
 	}
-	sw := &fromSwagger{}
+	sw := &fromSwagger{security: parseSecurityRequirements(model.Model.Security)}
 	schemas := sw.parseDefinetions(model.Model.Definitions)
 	responseDefinitions := sw.parseResponsesDefine(&model.Model)
 	parameters := sw.parseParametersDefine(&model.Model)
@@ -56,7 +57,7 @@ func parseSwagger(document libopenapi.Document) (*spec.Spec, error) {
 		ApiCat:      "2.0.1",
 		Info:        sw.parseInfo(model.Model.Info),
 		Servers:     sw.parseServers(&model.Model),
-		Definitions: spec.Definitions{Schemas: schemas, Responses: responseDefinitions, Parameters: parameters},
+		Definitions: spec.Definitions{Schemas: schemas, Responses: responseDefinitions, Parameters: parameters, Securities: sw.parseSecurityDefinitions(model.Model.SecurityDefinitions)},
 		Globals:     spec.Global{Parameters: globalparameters},
 		Collections: sw.parseCollections(&model.Model, model.Model.Paths),
 	}, nil
@@ -67,7 +68,7 @@ func parseOpenAPI3(document libopenapi.Document) (*spec.Spec, error) {
 	if len(errors) > 0 {
 		return nil, fmt.Errorf("openapi version:%s parse faild", document.GetVersion())
 	}
-	o := &fromOpenapi{}
+	o := &fromOpenapi{security: parseSecurityRequirements(model.Model.Security)}
 	globalparameters := spec.HTTPParameters{}
 	globalparameters.Fill()
 	return &spec.Spec{
@@ -76,7 +77,7 @@ func parseOpenAPI3(document libopenapi.Document) (*spec.Spec, error) {
 		Servers:     o.parseServers(model.Model.Servers),
 		Globals:     spec.Global{Parameters: globalparameters},
 		Definitions: o.parseDefinetions(model.Model.Components),
-		Collections: o.parseCollections(model.Model.Paths),
+		Collections: append(o.parseCollections(model.Model.Paths), o.parseWebhooks(model.Model.Webhooks)...),
 	}, nil
 }
 
@@ -87,6 +88,7 @@ func Encode(in *spec.Spec, version string) ([]byte, error) {
 	case "2.0":
 		sw := &toSwagger{}
 		sp := sw.toBase(in)
+		sp.SecurityDefinitions = sw.toSecurityDefinitions(in.Definitions.Securities)
 		paths, tags := sw.toPaths(in)
 		sp.Paths = paths
 		sp.Tags = tags
@@ -98,6 +100,12 @@ func Encode(in *spec.Spec, version string) ([]byte, error) {
 			paths, tag := op.toPaths(version, in)
 			sp.Paths = paths
 			sp.Tags = tag
+			// webhooks 从3.1开始支持 3.0 输出为x-webhooks
+			if strings.HasPrefix(version, "3.0") {
+				sp.XWebhooks = op.toWebhooks(version, in)
+			} else {
+				sp.Webhooks = op.toWebhooks(version, in)
+			}
 			return json.MarshalIndent(sp, "", "  ")
 		}
 	}
@@ -184,32 +192,13 @@ func walkHttpCollection(doc *spec.Spec) map[string]map[string]specPathItem {
 			if v.Type != spec.ContentItemTypeHttp {
 				return true
 			}
-			var (
-				info    spec.HTTPURLNode
-				docRoot spec.Document
-			)
-			item := specPathItem{
-				Title:      v.Title,
-				OperatorID: fmt.Sprintf("%d", v.ID),
-				Tags:       v.Tags,
-			}
+			var info spec.HTTPURLNode
 			for _, n := range v.Content {
-				switch nx := n.Node.(type) {
-				case *spec.HTTPNode[spec.HTTPURLNode]:
+				if nx, ok := n.Node.(*spec.HTTPNode[spec.HTTPURLNode]); ok {
 					info = nx.Attrs
-				case *spec.HTTPNode[spec.HTTPRequestNode]:
-					item.Req = nx.Attrs
-				case *spec.HTTPNode[spec.HTTPResponsesNode]:
-					item.Res = nx.Attrs
-				case *spec.DocNode:
-					docRoot.Items = append(docRoot.Items, nx)
-				}
-			}
-			if len(docRoot.Items) > 0 {
-				if raw, err := markdown.ToMarkdown(&docRoot); err == nil {
-					item.Description = string(raw)
 				}
 			}
+			item := toSpecPathItem(v)
 			if _, ok := paths[info.Path]; !ok {
 				paths[info.Path] = map[string]specPathItem{
 					info.Method: item,
@@ -223,6 +212,64 @@ func walkHttpCollection(doc *spec.Spec) map[string]map[string]specPathItem {
 	return paths
 }
 
+type specEventItem struct {
+	specPathItem
+	Webhook  *spec.HTTPWebhookNode
+	Callback *spec.HTTPCallbackNode
+}
+
+// walkEventCollection 返回所有的webhook和callback
+func walkEventCollection(doc *spec.Spec) []specEventItem {
+	events := make([]specEventItem, 0)
+	doc.WalkCollections(
+		func(v *spec.CollectItem, _ []string) bool {
+			if v.Type != spec.ContentItemTypeWebhook && v.Type != spec.ContentItemTypeCallback {
+				return true
+			}
+			item := specEventItem{specPathItem: toSpecPathItem(v)}
+			for _, n := range v.Content {
+				switch nx := n.Node.(type) {
+				case *spec.HTTPNode[spec.HTTPWebhookNode]:
+					item.Webhook = &nx.Attrs
+				case *spec.HTTPNode[spec.HTTPCallbackNode]:
+					item.Callback = &nx.Attrs
+				}
+			}
+			if item.Webhook != nil || item.Callback != nil {
+				events = append(events, item)
+			}
+			return true
+		},
+	)
+	return events
+}
+
+// toSpecPathItem 提取集合中的描述 请求和响应
+func toSpecPathItem(v *spec.CollectItem) specPathItem {
+	var docRoot spec.Document
+	item := specPathItem{
+		Title:      v.Title,
+		OperatorID: fmt.Sprintf("%d", v.ID),
+		Tags:       v.Tags,
+	}
+	for _, n := range v.Content {
+		switch nx := n.Node.(type) {
+		case *spec.HTTPNode[spec.HTTPRequestNode]:
+			item.Req = nx.Attrs
+		case *spec.HTTPNode[spec.HTTPResponsesNode]:
+			item.Res = nx.Attrs
+		case *spec.DocNode:
+			docRoot.Items = append(docRoot.Items, nx)
+		}
+	}
+	if len(docRoot.Items) > 0 {
+		if raw, err := markdown.ToMarkdown(&docRoot); err == nil {
+			item.Description = string(raw)
+		}
+	}
+	return item
+}
+
 // 将jsonschema 转为对应的 openaapi版本 主要是引用
 func toConvertJSONSchemaRef(v *jsonschema.Schema, ver string, mapping map[int64]string) *jsonschema.Schema {
 	sh := *v
@@ -252,5 +299,74 @@ func toConvertJSONSchemaRef(v *jsonschema.Schema, ver string, mapping map[int64]
 			sh.AdditionalProperties.SetValue(toConvertJSONSchemaRef(sh.AdditionalProperties.Value(), ver, mapping))
 		}
 	}
+	// swagger 2.0 只支持allOf
+	if ver[0] == '2' {
+		sh.AnyOf, sh.OneOf, sh.Discriminator = nil, nil, nil
+	}
+	for _, list := range []*[]*jsonschema.Schema{&sh.AllOf, &sh.AnyOf, &sh.OneOf} {
+		if len(*list) == 0 {
+			continue
+		}
+		items := make([]*jsonschema.Schema, len(*list))
+		for i, v := range *list {
+			items[i] = toConvertJSONSchemaRef(v, ver, mapping)
+		}
+		*list = items
+	}
+	if sh.Discriminator != nil && len(sh.Discriminator.Mapping) > 0 {
+		d := &jsonschema.Discriminator{
+			PropertyName: sh.Discriminator.PropertyName,
+			Mapping:      make(map[string]string),
+		}
+		for k, ref := range sh.Discriminator.Mapping {
+			if id := toInt64(getRefName(ref)); id > 0 {
+				ref = fmt.Sprintf("#/components/schemas/%s", mapping[id])
+			}
+			d.Mapping[k] = ref
+		}
+		sh.Discriminator = d
+	}
 	return &sh
 }
+
+func parseSecurityRequirements(in []*base.SecurityRequirement) []spec.SecurityRequirement {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]spec.SecurityRequirement, 0, len(in))
+	for _, v := range in {
+		req := make(spec.SecurityRequirement)
+		for name, scopes := range v.Requirements {
+			if scopes == nil {
+				scopes = []string{}
+			}
+			req[name] = scopes
+		}
+		out = append(out, req)
+	}
+	return out
+}
+
+// toSecurityRequirements 输出时去掉没有输出的认证方式
+func toSecurityRequirements(defined map[string]any, requirements []spec.SecurityRequirement) []spec.SecurityRequirement {
+	out := make([]spec.SecurityRequirement, 0, len(requirements))
+	for _, v := range requirements {
+		req := make(spec.SecurityRequirement)
+		for name, scopes := range v {
+			if _, ok := defined[name]; !ok {
+				continue
+			}
+			if scopes == nil {
+				scopes = []string{}
+			}
+			req[name] = scopes
+		}
+		if len(req) > 0 {
+			out = append(out, req)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
@@ -3,6 +3,7 @@ package openapi
 import (
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/apicat/apicat/backend/common/spec"
@@ -50,3 +51,185 @@ func TestDecode(t *testing.T) {
 // 	}
 
 // }
+
+func TestComposition(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/openapi3-composition.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pet := x.Definitions.Schemas.Lookup("Pet")
+	if pet == nil || len(pet.Schema.OneOf) != 2 || pet.Schema.Discriminator == nil {
+		t.Fatalf("oneOf/discriminator not decoded: %+v", pet)
+	}
+	if ref := pet.Schema.Discriminator.Mapping["cat"]; ref != fmt.Sprintf("#/definitions/schemas/%d", stringToUnid("Cat")) {
+		t.Fatalf("discriminator mapping not converted: %s", ref)
+	}
+	if cat := x.Definitions.Schemas.Lookup("Cat"); cat == nil || len(cat.Schema.AllOf) != 2 {
+		t.Fatalf("allOf not decoded: %+v", cat)
+	}
+
+	for _, v := range []string{"3.0.0", "3.1.0"} {
+		out, err := Encode(x, v)
+		if err != nil {
+			t.Fatal(v, err)
+		}
+		for _, s := range []string{`"oneOf"`, `"allOf"`, `"anyOf"`, `"propertyName": "kind"`, `"cat": "#/components/schemas/Cat"`} {
+			if !strings.Contains(string(out), s) {
+				t.Errorf("%s: %s not found in output", v, s)
+			}
+		}
+	}
+}
+
+func TestWebhooksAndCallbacks(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/openapi3.1-webhooks.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, s *spec.Spec) {
+		var webhook *spec.HTTPWebhookNode
+		var callback *spec.HTTPCallbackNode
+		var link *spec.HTTPLink
+		for _, v := range s.Events(false, 0) {
+			if v.Webhook != nil {
+				webhook = v.Webhook
+			}
+			if v.Callback != nil {
+				callback = v.Callback
+			}
+		}
+		for _, res := range s.CollectionsMap(false, 0)["/subscriptions"]["post"].Responses {
+			link = res.Links["GetSubscription"]
+		}
+		if webhook == nil || webhook.Event != "newPet" || webhook.Method != "post" {
+			t.Errorf("%s: webhook not decoded: %+v", name, webhook)
+		}
+		if callback == nil || callback.Event != "onEvent" || callback.Expression != "{$request.body#/callbackUrl}" ||
+			callback.Operation.Path != "/subscriptions" || callback.Operation.Method != "post" {
+			t.Errorf("%s: callback not decoded: %+v", name, callback)
+		}
+		if link == nil || link.OperationID != "getSubscription" || link.Parameters["id"] != "$response.body#/id" {
+			t.Errorf("%s: link not decoded: %+v", name, link)
+		}
+	}
+	check("decode", x)
+
+	out, err := Encode(x, "3.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, err := Decode(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("round trip", y)
+
+	// 3.0 没有webhooks 输出为x-webhooks
+	out, err = Encode(x, "3.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), `"webhooks"`) || !strings.Contains(string(out), `"x-webhooks"`) || !strings.Contains(string(out), `"callbacks"`) {
+		t.Errorf("unexpected 3.0 output: %s", out)
+	}
+	if _, err := Decode(out); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSecurity(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/openapi3-security.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(name string, s *spec.Spec, schemes int) {
+		if n := len(s.Definitions.Securities); n != schemes {
+			t.Errorf("%s: expected %d security schemes, got %d", name, schemes, n)
+		}
+		if v := s.Definitions.Securities.Lookup("apiKey"); v == nil || v.Type != spec.SecurityTypeAPIKey || v.In != "header" || v.Key != "X-API-Key" {
+			t.Errorf("%s: apiKey not decoded: %+v", name, v)
+		}
+		paths := s.CollectionsMap(false, 0)
+		if sec := paths["/pets"]["get"].Security; len(sec) != 1 || sec[0]["bearerAuth"] == nil {
+			t.Errorf("%s: default security not applied: %+v", name, sec)
+		}
+		if sec := paths["/pets"]["post"].Security; len(sec) != 2 || sec[0]["apiKey"] == nil {
+			t.Errorf("%s: operation security not decoded: %+v", name, sec)
+		}
+	}
+	check("decode", x, 3)
+	if v := x.Definitions.Securities.Lookup("oauth"); v == nil || v.Flows == nil || v.Flows.AuthorizationCode == nil || v.Flows.AuthorizationCode.Scopes["read"] == "" {
+		t.Errorf("oauth2 flows not decoded: %+v", v)
+	}
+
+	for _, v := range []string{"3.0.0", "3.1.0", "2.0"} {
+		out, err := Encode(x, v)
+		if err != nil {
+			t.Fatal(v, err)
+		}
+		y, err := Decode(out)
+		if err != nil {
+			t.Fatal(v, err)
+		}
+		check(v, y, 3)
+	}
+}
+
+func TestRequestBodyRequired(t *testing.T) {
+	raw := []byte(`openapi: 3.0.0
+info:
+  title: body
+  version: 1.0.0
+paths:
+  /pets:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+      responses:
+        "200":
+          description: ok
+    put:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+      responses:
+        "200":
+          description: ok
+`)
+	x, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := x.CollectionsMap(false, 0)["/pets"]
+	if !ops["post"].Content.Required() || ops["put"].Content.Required() {
+		t.Fatalf("required: post %v, put %v", ops["post"].Content.Required(), ops["put"].Content.Required())
+	}
+
+	out, err := Encode(x, "3.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(out), `"required": true`); n != 1 {
+		t.Errorf("required request bodies in output: %d\n%s", n, out)
+	}
+}
@@ -35,10 +35,7 @@ func jsonSchemaConverter(b *base.SchemaProxy) (*jsonschema.Schema, error) {
 		return &jsonschema.Schema{Reference: &refid}, nil
 	}
 	in := b.Schema()
-	var t jsonschema.SliceOrOneValue[string]
-	t.SetValue(in.Type...)
 	out := jsonschema.Schema{
-		Type:          &t,
 		Title:         in.Title,
 		Description:   in.Description,
 		MultipleOf:    in.MultipleOf,
@@ -60,6 +57,11 @@ func jsonSchemaConverter(b *base.SchemaProxy) (*jsonschema.Schema, error) {
 		Example:       in.Example,
 	}
 
+	// 组合类型可能没有type
+	if len(in.Type) > 0 {
+		out.Type = jsonschema.CreateSliceOrOne(in.Type...)
+	}
+
 	if in.ExclusiveMaximum != nil {
 		em := &jsonschema.ValueOrBoolean[int64]{}
 		if in.ExclusiveMaximum.IsA() {
@@ -127,6 +129,36 @@ func jsonSchemaConverter(b *base.SchemaProxy) (*jsonschema.Schema, error) {
 		out.Items = items
 	}
 
+	for _, v := range []struct {
+		in  []*base.SchemaProxy
+		out *[]*jsonschema.Schema
+	}{
+		{in.AllOf, &out.AllOf},
+		{in.AnyOf, &out.AnyOf},
+		{in.OneOf, &out.OneOf},
+	} {
+		for _, x := range v.in {
+			js, err := jsonSchemaConverter(x)
+			if err != nil {
+				return nil, err
+			}
+			*v.out = append(*v.out, js)
+		}
+	}
+
+	if in.Discriminator != nil {
+		d := &jsonschema.Discriminator{
+			PropertyName: in.Discriminator.PropertyName,
+		}
+		if len(in.Discriminator.Mapping) > 0 {
+			d.Mapping = make(map[string]string)
+			for k, ref := range in.Discriminator.Mapping {
+				d.Mapping[k] = fmt.Sprintf("#/definitions/schemas/%d", stringToUnid(getRefName(ref)))
+			}
+		}
+		out.Discriminator = d
+	}
+
 	if in.Deprecated != nil && *in.Deprecated {
 		out.Deprecated = true
 	}
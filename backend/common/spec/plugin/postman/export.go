@@ -0,0 +1,393 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/spec/markdown"
+	"github.com/apicat/datagen"
+)
+
+const collectionSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// 服务器地址对应的集合变量
+const baseUrlVariable = "baseUrl"
+
+type toPostman struct {
+	in *spec.Spec
+}
+
+// Encode 将项目导出为postman collection v2.1
+// 服务器地址使用变量{{baseUrl}} 全局参数作为集合变量 在请求中引用
+func Encode(in *spec.Spec) ([]byte, error) {
+	// 展开引用会修改内容 在副本上操作
+	raw, err := in.ToJSON(spec.JSONOption{})
+	if err != nil {
+		return nil, err
+	}
+	if in, err = spec.ParseJSON(raw); err != nil {
+		return nil, err
+	}
+
+	t := &toPostman{in: in}
+	pm := Spec{
+		Info: Info{
+			Schema: collectionSchema,
+		},
+		Items:     t.toItems(in.Collections),
+		Variables: t.toVariables(),
+	}
+	if in.Info != nil {
+		pm.Info.Name = in.Info.Title
+		pm.Info.Description = in.Info.Description
+	}
+	return json.MarshalIndent(pm, "", "  ")
+}
+
+func (t *toPostman) toVariables() []Variable {
+	variables := make([]Variable, 0)
+	if len(t.in.Servers) > 0 {
+		variables = append(variables, Variable{
+			Key:         baseUrlVariable,
+			Value:       t.in.Servers[0].URL,
+			Description: t.in.Servers[0].Description,
+		})
+	}
+	for _, in := range []string{"header", "query", "path"} {
+		for _, v := range t.in.Globals.Parameters.Map()[in] {
+			variables = append(variables, Variable{
+				Key:         v.Name,
+				Value:       exampleValue(v),
+				Description: v.Description,
+			})
+		}
+	}
+	return variables
+}
+
+// toItems 目录转为文件夹 只导出http接口
+func (t *toPostman) toItems(collections []*spec.CollectItem) []Item {
+	items := make([]Item, 0)
+	for _, v := range collections {
+		switch v.Type {
+		case spec.ContentItemTypeDir:
+			items = append(items, Item{
+				Name:  v.Title,
+				Items: t.toItems(v.Items),
+			})
+		case spec.ContentItemTypeHttp:
+			if item := t.toItem(v); item != nil {
+				items = append(items, *item)
+			}
+		}
+	}
+	return items
+}
+
+func (t *toPostman) toItem(v *spec.CollectItem) *Item {
+	var (
+		docRoot spec.Document
+		urlNode *spec.HTTPURLNode
+		req     spec.HTTPRequestNode
+		res     spec.HTTPResponsesNode
+	)
+	for _, n := range v.Content {
+		switch nx := n.Node.(type) {
+		case *spec.HTTPNode[spec.HTTPURLNode]:
+			urlNode = &nx.Attrs
+		case *spec.HTTPNode[spec.HTTPRequestNode]:
+			req = nx.Attrs
+		case *spec.HTTPNode[spec.HTTPResponsesNode]:
+			res = nx.Attrs
+		case *spec.DocNode:
+			docRoot.Items = append(docRoot.Items, nx)
+		}
+	}
+	if urlNode == nil {
+		return nil
+	}
+
+	req.Parameters.Fill()
+	for _, list := range req.Parameters.Map() {
+		for _, p := range list {
+			t.in.ExpendRef(p, 3)
+		}
+	}
+	for _, c := range req.Content {
+		t.in.ExpendRef(c, 3)
+	}
+
+	request := &Request{
+		Method:  strings.ToUpper(urlNode.Method),
+		Headers: make([]Variable, 0),
+		Url:     t.toURL(urlNode.Path, req),
+		Auth:    t.toAuth(req.Security),
+	}
+	if len(docRoot.Items) > 0 {
+		if raw, err := markdown.ToMarkdown(&docRoot); err == nil {
+			request.Description = string(raw)
+		}
+	}
+
+	for _, p := range t.globals("header", req) {
+		request.Headers = append(request.Headers, Variable{Key: p.Name, Value: "{{" + p.Name + "}}"})
+	}
+	for _, p := range req.Parameters.Header {
+		request.Headers = append(request.Headers, Variable{Key: p.Name, Value: exampleValue(p), Description: p.Description})
+	}
+
+	var contentType string
+	for _, k := range sortedKeys(req.Content) {
+		if body := toBody(k, req.Content[k]); body != nil {
+			contentType = k
+			request.Body = body
+			break
+		}
+	}
+	if contentType != "" {
+		request.Headers = append(request.Headers, Variable{Key: "Content-Type", Value: contentType})
+	}
+
+	item := &Item{
+		Name:     v.Title,
+		Request:  request,
+		Response: make([]Response, 0, len(res.List)),
+	}
+	for _, r := range res.List {
+		t.in.ExpendRef(&r.HTTPResponseDefine, 3)
+		item.Response = append(item.Response, t.toResponse(r, request))
+	}
+	return item
+}
+
+// globals 请求使用的全局参数 排除接口中忽略的
+func (t *toPostman) globals(in string, req spec.HTTPRequestNode) spec.Schemas {
+	list := make(spec.Schemas, 0)
+	for _, p := range t.in.Globals.Parameters.Map()[in] {
+		excepted := false
+		for _, id := range req.GlobalExcepts[in] {
+			if id == p.ID {
+				excepted = true
+				break
+			}
+		}
+		if !excepted {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// toURL 路径参数{id}转为postman的:id
+func (t *toPostman) toURL(path string, req spec.HTTPRequestNode) URL {
+	u := URL{
+		Host: []string{"{{" + baseUrlVariable + "}}"},
+		Path: make([]string, 0),
+	}
+	pathParams := make(map[string]Variable)
+	for _, p := range t.globals("path", req) {
+		pathParams[p.Name] = Variable{Key: p.Name, Value: "{{" + p.Name + "}}"}
+	}
+	for _, p := range req.Parameters.Path {
+		pathParams[p.Name] = Variable{Key: p.Name, Value: exampleValue(p), Description: p.Description}
+	}
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg == "" {
+			continue
+		}
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name := seg[1 : len(seg)-1]
+			seg = ":" + name
+			v, ok := pathParams[name]
+			if !ok {
+				v = Variable{Key: name}
+			}
+			u.Variables = append(u.Variables, v)
+		}
+		u.Path = append(u.Path, seg)
+	}
+
+	for _, p := range t.globals("query", req) {
+		u.Queries = append(u.Queries, Variable{Key: p.Name, Value: "{{" + p.Name + "}}"})
+	}
+	for _, p := range req.Parameters.Query {
+		u.Queries = append(u.Queries, Variable{Key: p.Name, Value: exampleValue(p), Description: p.Description})
+	}
+
+	u.Raw = u.Host[0] + "/" + strings.Join(u.Path, "/")
+	if len(u.Queries) > 0 {
+		qs := make([]string, 0, len(u.Queries))
+		for _, q := range u.Queries {
+			qs = append(qs, q.Key+"="+q.Value)
+		}
+		u.Raw += "?" + strings.Join(qs, "&")
+	}
+	return u
+}
+
+// toAuth 使用第一个认证要求中的第一个认证方式 凭证的值引用同名的变量
+func (t *toPostman) toAuth(requirements []spec.SecurityRequirement) *Auth {
+	for _, req := range requirements {
+		names := make([]string, 0, len(req))
+		for k := range req {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			scheme := t.in.Definitions.Securities.Lookup(name)
+			if scheme == nil {
+				continue
+			}
+			value := "{{" + name + "}}"
+			switch {
+			case scheme.Type == spec.SecurityTypeHTTP && strings.EqualFold(scheme.Scheme, "basic"):
+				return &Auth{Type: "basic", Basic: []AuthAttribute{
+					{Key: "username", Value: "{{" + name + "Username}}", Type: "string"},
+					{Key: "password", Value: "{{" + name + "Password}}", Type: "string"},
+				}}
+			case scheme.Type == spec.SecurityTypeAPIKey:
+				in := scheme.In
+				if in != "query" {
+					in = "header"
+				}
+				return &Auth{Type: "apikey", Apikey: []AuthAttribute{
+					{Key: "key", Value: scheme.Key, Type: "string"},
+					{Key: "value", Value: value, Type: "string"},
+					{Key: "in", Value: in, Type: "string"},
+				}}
+			case scheme.Type == spec.SecurityTypeOAuth2:
+				return &Auth{Type: "oauth2", Oauth2: []AuthAttribute{
+					{Key: "accessToken", Value: value, Type: "string"},
+					{Key: "scope", Value: strings.Join(req[name], " "), Type: "string"},
+				}}
+			default:
+				return &Auth{Type: "bearer", Bearer: []AuthAttribute{
+					{Key: "token", Value: value, Type: "string"},
+				}}
+			}
+		}
+	}
+	return nil
+}
+
+func (t *toPostman) toResponse(r spec.HTTPResponse, req *Request) Response {
+	res := Response{
+		Name: r.Description,
+		OriginalRequest: OriginalRequest{
+			Method: req.Method,
+			Header: req.Headers,
+			URL:    req.Url,
+		},
+		Status: http.StatusText(r.Code),
+		Code:   r.Code,
+		Header: make([]Variable, 0),
+		Cookie: make([]Cookie, 0),
+	}
+	if res.Name == "" {
+		res.Name = r.Name
+	}
+	if res.Name == "" {
+		res.Name = fmt.Sprintf("%d", r.Code)
+	}
+	for _, h := range r.Header {
+		res.Header = append(res.Header, Variable{Key: h.Name, Value: exampleValue(h), Description: h.Description})
+	}
+	for _, k := range sortedKeys(r.Content) {
+		c := r.Content[k]
+		if c == nil || c.Schema == nil {
+			continue
+		}
+		res.Header = append(res.Header, Variable{Key: "Content-Type", Value: k})
+		if strings.Contains(k, "json") {
+			res.PostmanePreviewLanguage = "json"
+			res.Body = string(generateJSON(c.Schema))
+		} else {
+			res.PostmanePreviewLanguage = "text"
+			if c.Schema.Example != nil {
+				res.Body = fmt.Sprint(c.Schema.Example)
+			}
+		}
+		break
+	}
+	return res
+}
+
+// toBody json类型使用datagen生成示例 表单类型使用属性的示例
+func toBody(contentType string, c *spec.Schema) *Body {
+	if c == nil || c.Schema == nil {
+		return nil
+	}
+	body := &Body{}
+	switch {
+	case strings.Contains(contentType, "json"):
+		body.Mode = "raw"
+		body.Raw = string(generateJSON(c.Schema))
+		body.Options.Raw.Language = "json"
+	case contentType == "application/x-www-form-urlencoded":
+		body.Mode = "urlencoded"
+		body.Urlencoded = formVariables(c.Schema)
+	case contentType == "multipart/form-data":
+		body.Mode = "formdata"
+		body.Formdata = formVariables(c.Schema)
+	default:
+		body.Mode = "raw"
+		if c.Schema.Example != nil {
+			body.Raw = fmt.Sprint(c.Schema.Example)
+		}
+	}
+	return body
+}
+
+func formVariables(s *jsonschema.Schema) []Variable {
+	list := make([]Variable, 0, len(s.Properties))
+	for _, name := range sortedKeys(s.Properties) {
+		p := s.Properties[name]
+		v := Variable{Key: name, Description: p.Description}
+		if p.Example != nil {
+			v.Value = fmt.Sprint(p.Example)
+		}
+		t := "text"
+		if p.Type != nil && len(p.Type.Value()) > 0 && p.Type.Value()[0] == "file" {
+			t = "file"
+		}
+		v.Type = &t
+		list = append(list, v)
+	}
+	return list
+}
+
+func generateJSON(s *jsonschema.Schema) []byte {
+	b, _ := json.Marshal(s.Flatten())
+	v, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"})
+	if err != nil {
+		return nil
+	}
+	raw, _ := json.MarshalIndent(v, "", "  ")
+	return raw
+}
+
+func exampleValue(s *spec.Schema) string {
+	switch {
+	case s.Example != nil:
+		return fmt.Sprint(s.Example)
+	case s.Schema != nil && s.Schema.Example != nil:
+		return fmt.Sprint(s.Schema.Example)
+	case s.Schema != nil && s.Schema.Default != nil:
+		return fmt.Sprint(s.Schema.Default)
+	}
+	return ""
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -3,6 +3,7 @@ package postman
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/apicat/apicat/backend/common/spec"
@@ -10,11 +11,19 @@ import (
 )
 
 func Import(data []byte) (*spec.Spec, error) {
+	var env Environment
+	if err := json.Unmarshal(data, &env); err == nil && env.Scope == "environment" {
+		return importEnvironment(&env), nil
+	}
+
 	var pm Spec
 	if err := json.Unmarshal(data, &pm); err != nil {
 		return nil, err
 	}
 
+	schemes := make(spec.SecuritySchemes, 0)
+	collections := walkCpllection(pm.Items, 1000, pm.Auth, &schemes)
+
 	p := &spec.Spec{
 		ApiCat: "2.0.1",
 		Info: &spec.Info{
@@ -24,17 +33,32 @@ func Import(data []byte) (*spec.Spec, error) {
 		Servers: func() []*spec.Server {
 			for _, v := range pm.Items {
 				if v.Request != nil {
+					// 使用变量的地址如 {{baseUrl}} 没有协议
+					url := strings.Join(v.Request.Url.Host, ".")
+					if v.Request.Url.Protocol != "" {
+						url = fmt.Sprintf("%s://%s", v.Request.Url.Protocol, url)
+					}
 					return []*spec.Server{{
-						URL: fmt.Sprintf("%s://%s",
-							v.Request.Url.Protocol,
-							strings.Join(v.Request.Url.Host, "/"),
-						),
+						URL:         url,
 						Description: "default",
 					}}
 				}
 			}
 			return []*spec.Server{}
 		}(),
+		Environments: func() []*spec.Environment {
+			if len(pm.Variables) == 0 {
+				return nil
+			}
+			env := &spec.Environment{Name: pm.Info.Name}
+			for _, v := range pm.Variables {
+				if v.Disabled {
+					continue
+				}
+				env.Variables = append(env.Variables, v.toVariable())
+			}
+			return []*spec.Environment{env}
+		}(),
 		Globals: func() spec.Global {
 			var parmts spec.HTTPParameters
 			parmts.Fill()
@@ -46,24 +70,58 @@ func Import(data []byte) (*spec.Spec, error) {
 			Schemas:    make(spec.Schemas, 0),
 			Parameters: make(spec.Schemas, 0),
 			Responses:  make(spec.HTTPResponseDefines, 0),
+			Securities: schemes,
 		},
-		Collections: walkCpllection(pm.Items, 1000),
+		Collections: collections,
 	}
 	return p, nil
 }
 
-func walkCpllection(items []Item, parentid int64) []*spec.CollectItem {
+// importEnvironment 导入postman的环境文件 只包含变量
+func importEnvironment(env *Environment) *spec.Spec {
+	e := &spec.Environment{Name: env.Name, Variables: make([]*spec.Variable, 0)}
+	for _, v := range env.Values {
+		if !v.Enabled {
+			continue
+		}
+		e.Variables = append(e.Variables, &spec.Variable{
+			Name:   v.Key,
+			Value:  v.Value,
+			Secret: v.Type == "secret",
+		})
+	}
+
+	var parmts spec.HTTPParameters
+	parmts.Fill()
+	return &spec.Spec{
+		ApiCat:       "2.0.1",
+		Info:         &spec.Info{Title: env.Name},
+		Servers:      []*spec.Server{},
+		Environments: []*spec.Environment{e},
+		Globals:      spec.Global{Parameters: parmts},
+		Definitions: spec.Definitions{
+			Schemas:    make(spec.Schemas, 0),
+			Parameters: make(spec.Schemas, 0),
+			Responses:  make(spec.HTTPResponseDefines, 0),
+		},
+		Collections: make([]*spec.CollectItem, 0),
+	}
+}
+
+// walkCpllection auth为上级目录或集合的认证方式 用到的认证方式会加入schemes
+func walkCpllection(items []Item, parentid int64, auth *Auth, schemes *spec.SecuritySchemes) []*spec.CollectItem {
 	cs := make([]*spec.CollectItem, 0)
 	for i, v := range items {
 		// http request
 		id := parentid*1024 + int64(i) + 1
+		itemAuth := inheritAuth(v.Auth, auth)
 		if v.Request != nil {
 			specItem := &spec.CollectItem{
 				ID:       id,
 				ParentID: parentid,
 				Type:     spec.ContentItemTypeHttp,
 				Title:    v.Name,
-				Content:  convertContent(v),
+				Content:  convertContent(v, toSecurity(inheritAuth(v.Request.Auth, itemAuth), schemes)),
 			}
 			cs = append(cs, specItem)
 		}
@@ -74,7 +132,7 @@ func walkCpllection(items []Item, parentid int64) []*spec.CollectItem {
 				ParentID: parentid,
 				Type:     spec.ContentItemTypeDir,
 				Title:    v.Name,
-				Items:    walkCpllection(v.Items, id),
+				Items:    walkCpllection(v.Items, id, itemAuth, schemes),
 			}
 			cs = append(cs, specItem)
 		}
@@ -82,9 +140,10 @@ func walkCpllection(items []Item, parentid int64) []*spec.CollectItem {
 	return cs
 }
 
-func convertContent(item Item) []*spec.NodeProxy {
+func convertContent(item Item, security []spec.SecurityRequirement) []*spec.NodeProxy {
 	req := spec.HTTPRequestNode{
 		GlobalExcepts: make(map[string][]int64),
+		Security:      security,
 	}
 	req.Parameters.Fill()
 	for k, v := range item.Request.Url.Path {
@@ -125,6 +184,87 @@ func convertContent(item Item) []*spec.NodeProxy {
 	return nodes
 }
 
+func inheritAuth(auth, parent *Auth) *Auth {
+	if auth == nil || auth.Type == "" || auth.Type == "inherit" {
+		return parent
+	}
+	return auth
+}
+
+var postmanOAuthGrantTypes = map[string]func(*spec.OAuthFlows, *spec.OAuthFlow){
+	"authorization_code":   func(f *spec.OAuthFlows, x *spec.OAuthFlow) { f.AuthorizationCode = x },
+	"implicit":             func(f *spec.OAuthFlows, x *spec.OAuthFlow) { f.Implicit = x },
+	"password_credentials": func(f *spec.OAuthFlows, x *spec.OAuthFlow) { f.Password = x },
+	"client_credentials":   func(f *spec.OAuthFlows, x *spec.OAuthFlow) { f.ClientCredentials = x },
+}
+
+// toSecurity 将postman的认证方式转换为接口的认证要求 noauth和不支持的类型没有认证要求
+func toSecurity(auth *Auth, schemes *spec.SecuritySchemes) []spec.SecurityRequirement {
+	if auth == nil {
+		return nil
+	}
+	var scheme *spec.SecurityScheme
+	switch auth.Type {
+	case "bearer":
+		scheme = &spec.SecurityScheme{Name: "bearerAuth", Type: spec.SecurityTypeHTTP, Scheme: "bearer"}
+	case "basic":
+		scheme = &spec.SecurityScheme{Name: "basicAuth", Type: spec.SecurityTypeHTTP, Scheme: "basic"}
+	case "apikey":
+		scheme = &spec.SecurityScheme{
+			Name: "apiKeyAuth",
+			Type: spec.SecurityTypeAPIKey,
+			In:   "header",
+			Key:  authAttribute(auth.Apikey, "key"),
+		}
+		if authAttribute(auth.Apikey, "in") == "query" {
+			scheme.In = "query"
+		}
+		if scheme.Key == "" {
+			scheme.Key = "X-API-Key"
+		}
+	case "oauth2":
+		flow := &spec.OAuthFlow{
+			AuthorizationURL: authAttribute(auth.Oauth2, "authUrl"),
+			TokenURL:         authAttribute(auth.Oauth2, "accessTokenUrl"),
+			Scopes:           make(map[string]string),
+		}
+		for _, v := range strings.Fields(authAttribute(auth.Oauth2, "scope")) {
+			flow.Scopes[v] = ""
+		}
+		scheme = &spec.SecurityScheme{Name: "oauth2", Type: spec.SecurityTypeOAuth2, Flows: &spec.OAuthFlows{}}
+		set, ok := postmanOAuthGrantTypes[authAttribute(auth.Oauth2, "grant_type")]
+		if !ok {
+			set = postmanOAuthGrantTypes["authorization_code"]
+		}
+		set(scheme.Flows, flow)
+	default:
+		return nil
+	}
+	name := addSecurityScheme(schemes, scheme)
+	scopes := make([]string, 0)
+	if scheme.Flows != nil {
+		scopes = strings.Fields(authAttribute(auth.Oauth2, "scope"))
+	}
+	return []spec.SecurityRequirement{{name: scopes}}
+}
+
+// addSecurityScheme 相同配置的认证方式只添加一次 名称冲突时加上序号
+func addSecurityScheme(schemes *spec.SecuritySchemes, scheme *spec.SecurityScheme) string {
+	for _, v := range *schemes {
+		x := *v
+		x.Name = scheme.Name
+		if reflect.DeepEqual(&x, scheme) {
+			return v.Name
+		}
+	}
+	name := scheme.Name
+	for i := 2; schemes.Lookup(scheme.Name) != nil; i++ {
+		scheme.Name = fmt.Sprintf("%s%d", name, i)
+	}
+	*schemes = append(*schemes, scheme)
+	return scheme.Name
+}
+
 var contenttypemapp = map[string]string{
 	"json":      "application/json",
 	"urlencode": "application/x-www-form-urlencoded",
@@ -10,8 +10,24 @@ import (
 
 // https://schema.postman.com/collection/json/v2.1.0/draft-07/docs/index.html
 type Spec struct {
-	Info  Info   `json:"info"`
-	Items []Item `json:"item"`
+	Info      Info       `json:"info"`
+	Items     []Item     `json:"item"`
+	Variables []Variable `json:"variable"`
+	Auth      *Auth      `json:"auth,omitempty"`
+}
+
+// Environment postman导出的环境文件
+type Environment struct {
+	Name   string             `json:"name"`
+	Values []EnvironmentValue `json:"values"`
+	Scope  string             `json:"_postman_variable_scope"`
+}
+
+type EnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
 }
 type Info struct {
 	Name        string `json:"name"`
@@ -21,26 +37,28 @@ type Info struct {
 
 type Item struct {
 	Name        string     `json:"name"`
-	Description string     `json:"description"`
+	Description string     `json:"description,omitempty"`
 	Request     *Request   `json:"request,omitempty"`
-	Response    []Response `json:"response"`
-	Items       []Item     `json:"item"`
+	Response    []Response `json:"response,omitempty"`
+	Items       []Item     `json:"item,omitempty"`
+	Auth        *Auth      `json:"auth,omitempty"`
 }
 
 type Request struct {
 	Method      string     `json:"method"`
 	Headers     []Variable `json:"header"`
 	Url         URL        `json:"url"`
-	Description string     `json:"description"`
-	Body        *Body
+	Description string     `json:"description,omitempty"`
+	Body        *Body      `json:"body,omitempty"`
+	Auth        *Auth      `json:"auth,omitempty"`
 }
 
 type Variable struct {
 	Key         string  `json:"key"`
 	Value       string  `json:"value"`
-	Description string  `json:"description"`
-	Type        *string `json:"type"`
-	Disabled    bool
+	Description string  `json:"description,omitempty"`
+	Type        *string `json:"type,omitempty"`
+	Disabled    bool    `json:"disabled,omitempty"`
 }
 
 func (v *Variable) toJSONSchema() *jsonschema.Schema {
@@ -55,6 +73,15 @@ func (v *Variable) toJSONSchema() *jsonschema.Schema {
 	return sh
 }
 
+func (v *Variable) toVariable() *spec.Variable {
+	return &spec.Variable{
+		Name:        v.Key,
+		Value:       v.Value,
+		Secret:      v.Type != nil && *v.Type == "secret",
+		Description: v.Description,
+	}
+}
+
 func (v *Variable) toSchema() *spec.Schema {
 	return &spec.Schema{
 		Name:        v.Key,
@@ -63,49 +90,78 @@ func (v *Variable) toSchema() *spec.Schema {
 	}
 }
 
+// Auth 认证方式 type为noauth时不需要认证 没有设置时继承上级目录或集合的认证方式
+type Auth struct {
+	Type   string          `json:"type"`
+	Bearer []AuthAttribute `json:"bearer,omitempty"`
+	Basic  []AuthAttribute `json:"basic,omitempty"`
+	Apikey []AuthAttribute `json:"apikey,omitempty"`
+	Oauth2 []AuthAttribute `json:"oauth2,omitempty"`
+}
+
+// AuthAttribute 认证方式的配置项 值可能不是字符串
+type AuthAttribute struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+func authAttribute(attrs []AuthAttribute, key string) string {
+	for _, v := range attrs {
+		if v.Key == key {
+			if s, ok := v.Value.(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
 type Cookie struct{}
 
 type URL struct {
 	Raw       string     `json:"raw"`
-	Protocol  string     `json:"protocol"`
+	Protocol  string     `json:"protocol,omitempty"`
 	Host      []string   `json:"host"`
 	Path      []string   `json:"path"`
-	Queries   []Variable `json:"query"`
-	Variables []Variable `json:"variable"`
+	Queries   []Variable `json:"query,omitempty"`
+	Variables []Variable `json:"variable,omitempty"`
 }
 
 type Response struct {
-	Name                    string
-	OriginalRequest         OriginalRequest
-	Status                  string
-	Code                    int
-	PostmanePreviewLanguage string `json:"_postman_previewlanguage"`
-	Header                  []Variable
-	Cookie                  []Cookie
-	Body                    string
+	Name                    string          `json:"name"`
+	OriginalRequest         OriginalRequest `json:"originalRequest"`
+	Status                  string          `json:"status"`
+	Code                    int             `json:"code"`
+	PostmanePreviewLanguage string          `json:"_postman_previewlanguage"`
+	Header                  []Variable      `json:"header"`
+	Cookie                  []Cookie        `json:"cookie"`
+	Body                    string          `json:"body"`
 }
 
 type OriginalRequest struct {
-	Method string
-	Header []Variable
-	URL    URL
+	Method string     `json:"method"`
+	Header []Variable `json:"header"`
+	URL    URL        `json:"url"`
 }
 
 type Body struct {
-	Mode       string
-	Raw        string
-	Urlencoded []Variable
-	Formdata   []Variable
-	File       struct {
-		Src     *string
-		Content string
-	}
-	Options struct {
+	Mode       string     `json:"mode"`
+	Raw        string     `json:"raw,omitempty"`
+	Urlencoded []Variable `json:"urlencoded,omitempty"`
+	Formdata   []Variable `json:"formdata,omitempty"`
+	File       *BodyFile  `json:"file,omitempty"`
+	Options    struct {
 		Raw struct {
-			Language string
-		}
-	}
-	Disabled bool
+			Language string `json:"language,omitempty"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+type BodyFile struct {
+	Src     *string `json:"src"`
+	Content string  `json:"content,omitempty"`
 }
 
 func jsonToSchema(b string) *jsonschema.Schema {
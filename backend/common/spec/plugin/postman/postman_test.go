@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
 )
 
 func TestEncode(t *testing.T) {
@@ -19,3 +22,149 @@ func TestEncode(t *testing.T) {
 	b, _ := json.MarshalIndent(x, "", "  ")
 	fmt.Println(string(b))
 }
+
+func TestImportVariables(t *testing.T) {
+	x, err := Import([]byte(`{
+		"info": {"name": "demo"},
+		"item": [{"name": "users", "request": {"method": "GET", "url": {"raw": "{{baseUrl}}/users", "host": ["{{baseUrl}}"], "path": ["users"]}}}],
+		"variable": [{"key": "baseUrl", "value": "https://api.example.com"}, {"key": "token", "value": "abc", "type": "secret"}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(x.Servers) != 1 || x.Servers[0].URL != "{{baseUrl}}" {
+		t.Errorf("unexpected servers %+v", x.Servers)
+	}
+	if len(x.Environments) != 1 || len(x.Environments[0].Variables) != 2 || !x.Environments[0].Variables[1].Secret {
+		t.Errorf("unexpected environments %+v", x.Environments)
+	}
+
+	x, err = Import([]byte(`{
+		"name": "staging",
+		"values": [{"key": "baseUrl", "value": "https://staging.example.com", "enabled": true}, {"key": "old", "value": "x", "enabled": false}],
+		"_postman_variable_scope": "environment"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(x.Environments) != 1 || x.Environments[0].Name != "staging" || x.Environments[0].Map(true)["baseUrl"] != "https://staging.example.com" || len(x.Environments[0].Variables) != 1 {
+		t.Errorf("unexpected environment %+v", x.Environments)
+	}
+}
+
+func TestImportAuth(t *testing.T) {
+	x, err := Import([]byte(`{
+		"info": {"name": "demo"},
+		"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+		"item": [
+			{"name": "users", "request": {"method": "GET", "url": {"path": ["users"]}}},
+			{"name": "public", "request": {"method": "GET", "url": {"path": ["public"]}, "auth": {"type": "noauth"}}},
+			{"name": "admin", "auth": {"type": "apikey", "apikey": [{"key": "key", "value": "X-Admin-Key"}, {"key": "in", "value": "header"}]}, "item": [
+				{"name": "stats", "request": {"method": "GET", "url": {"path": ["admin", "stats"]}}},
+				{"name": "jobs", "request": {"method": "GET", "url": {"path": ["admin", "jobs"]}, "auth": {"type": "inherit"}}}
+			]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(x.Definitions.Securities) != 2 {
+		t.Fatalf("unexpected securities %+v", x.Definitions.Securities)
+	}
+	if s := x.Definitions.Securities.Lookup("apiKeyAuth"); s == nil || s.In != "header" || s.Key != "X-Admin-Key" {
+		t.Errorf("unexpected api key scheme %+v", s)
+	}
+
+	security := map[string][]spec.SecurityRequirement{}
+	for path, v := range x.CollectionsMap(false, 0) {
+		for method, part := range v {
+			security[strings.ToLower(method)+" "+path] = part.Security
+		}
+	}
+	for k, want := range map[string]string{
+		"get /users":       "bearerAuth",
+		"get /public":      "",
+		"get /admin/stats": "apiKeyAuth",
+		"get /admin/jobs":  "apiKeyAuth",
+	} {
+		reqs := security[k]
+		if want == "" {
+			if len(reqs) != 0 {
+				t.Errorf("%s: expected no security got %v", k, reqs)
+			}
+			continue
+		}
+		if len(reqs) != 1 || reqs[0][want] == nil {
+			t.Errorf("%s: expected %s got %v", k, want, reqs)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	in, err := spec.ParseJSON([]byte(`{
+		"apicat": "2.0",
+		"info": {"title": "demo", "version": "1.0.0"},
+		"servers": [{"url": "https://api.example.com", "description": "prod"}],
+		"globals": {"parameters": {"header": [{"id": 1, "name": "X-Tenant", "schema": {"type": "string", "example": "t1"}}]}},
+		"definitions": {"schemas": [{"id": 1, "name": "User", "schema": {"type": "object", "properties": {"name": {"type": "string"}}}}], "parameters": [], "responses": []},
+		"collections": [{"id": 1, "title": "users", "type": "category", "items": [
+			{"id": 2, "title": "get user", "type": "http", "content": [
+				{"type": "apicat-http-url", "attrs": {"path": "/users/{id}", "method": "get"}},
+				{"type": "apicat-http-request", "attrs": {"parameters": {"path": [{"name": "id", "required": true, "schema": {"type": "integer", "example": 1}}]}}},
+				{"type": "apicat-http-response", "attrs": {"list": [{"code": 200, "description": "ok", "content": {"application/json": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}}]}}
+			]},
+			{"id": 3, "title": "create user", "type": "http", "content": [
+				{"type": "apicat-http-url", "attrs": {"path": "/users", "method": "post"}},
+				{"type": "apicat-http-request", "attrs": {"globalExcepts": {"header": [1]}, "content": {"application/json": {"schema": {"$ref": "#/definitions/schemas/1"}}}}},
+				{"type": "apicat-http-response", "attrs": {"list": [{"code": 201, "description": "created"}]}}
+			]}
+		]}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := Encode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pm Spec
+	if err := json.Unmarshal(raw, &pm); err != nil {
+		t.Fatal(err)
+	}
+	if pm.Info.Schema != collectionSchema || len(pm.Variables) != 2 || pm.Variables[0].Key != "baseUrl" || pm.Variables[1].Key != "X-Tenant" {
+		t.Fatalf("unexpected collection %+v", pm)
+	}
+	if len(pm.Items) != 1 || len(pm.Items[0].Items) != 2 {
+		t.Fatalf("folders not kept %+v", pm.Items)
+	}
+
+	get := pm.Items[0].Items[0].Request
+	if get.Url.Raw != "{{baseUrl}}/users/:id" || len(get.Url.Variables) != 1 || get.Url.Variables[0].Value != "1" {
+		t.Errorf("unexpected url %+v", get.Url)
+	}
+	if len(get.Headers) != 1 || get.Headers[0].Value != "{{X-Tenant}}" {
+		t.Errorf("unexpected headers %+v", get.Headers)
+	}
+	if res := pm.Items[0].Items[0].Response; len(res) != 1 || res[0].Code != 200 || !strings.Contains(res[0].Body, "name") {
+		t.Errorf("unexpected responses %+v", res)
+	}
+
+	post := pm.Items[0].Items[1].Request
+	if post.Body == nil || post.Body.Mode != "raw" || !strings.Contains(post.Body.Raw, "name") {
+		t.Errorf("unexpected body %+v", post.Body)
+	}
+	for _, h := range post.Headers {
+		if h.Key == "X-Tenant" {
+			t.Error("excepted global header exported")
+		}
+	}
+
+	if _, err := Import(raw); err != nil {
+		t.Fatal(err)
+	}
+
+	// 导出不修改原来的内容
+	if b, _ := in.ToJSON(spec.JSONOption{}); !strings.Contains(string(b), "#/definitions/schemas/1") {
+		t.Error("refs expanded in the source spec")
+	}
+}
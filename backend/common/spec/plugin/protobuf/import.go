@@ -0,0 +1,272 @@
+package protobuf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/apicat/backend/common/spec/markdown"
+)
+
+// 模型的虚拟id 位数相同 导入时替换为数据库中的id不会相互影响
+const virtualIDBase = 1000000
+
+// Import 解析proto文件 不依赖protoc
+// 每个消息生成一个公共模型 每个service生成一个分类 每个rpc生成一个grpc集合
+// import的其它文件中的类型无法解析 除了常用的google.protobuf类型都作为任意对象
+func Import(data []byte) (*spec.Spec, error) {
+	file, err := parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(file.messages) == 0 && len(file.services) == 0 {
+		return nil, errors.New("no message or service found")
+	}
+
+	im := &importer{
+		file:  file,
+		refs:  make(map[string]string),
+		enums: make(map[string]*protoEnum),
+	}
+	for _, v := range file.enums {
+		im.enums[v.fullName] = v
+	}
+
+	title := file.pkg
+	if title == "" && len(file.services) > 0 {
+		title = file.services[0].name
+	}
+	if title == "" {
+		title = "Protobuf"
+	}
+	out := &spec.Spec{
+		ApiCat: "2.0",
+		Info: &spec.Info{
+			Title:   title,
+			Version: "1.0.0",
+		},
+		Servers:     []*spec.Server{},
+		Definitions: spec.Definitions{Schemas: im.definitions()},
+		Collections: []*spec.CollectItem{},
+	}
+	for _, svc := range file.services {
+		dir := &spec.CollectItem{Type: spec.ContentItemTypeDir, Title: svc.name}
+		for _, m := range svc.methods {
+			dir.Items = append(dir.Items, im.method(svc, m))
+		}
+		out.Collections = append(out.Collections, dir)
+	}
+	return out, nil
+}
+
+type importer struct {
+	file *protoFile
+	// 消息的完整名称 => 模型的引用
+	refs  map[string]string
+	enums map[string]*protoEnum
+}
+
+// name 去掉包名的消息名称 嵌套的消息为Outer.Inner
+func (im *importer) name(fullName string) string {
+	if im.file.pkg == "" {
+		return fullName
+	}
+	return strings.TrimPrefix(fullName, im.file.pkg+".")
+}
+
+func (im *importer) definitions() spec.Schemas {
+	for i, v := range im.file.messages {
+		im.refs[v.fullName] = fmt.Sprintf("#/definitions/schemas/%d", virtualIDBase+i+1)
+	}
+	list := make(spec.Schemas, 0, len(im.file.messages))
+	for i, v := range im.file.messages {
+		list = append(list, &spec.Schema{
+			ID:          int64(virtualIDBase + i + 1),
+			Name:        im.name(v.fullName),
+			Description: v.comment,
+			Schema:      im.message(v),
+		})
+	}
+	return list
+}
+
+// message proto3中的字段都不是必须的 proto2的required字段作为必须
+func (im *importer) message(msg *protoMessage) *jsonschema.Schema {
+	s := jsonschema.Create("object")
+	s.Title = im.name(msg.fullName)
+	s.Description = msg.comment
+	s.Properties = make(map[string]*jsonschema.Schema)
+	for _, f := range msg.fields {
+		p := im.fieldSchema(msg.fullName, f)
+		desc := f.comment
+		if f.oneof != "" {
+			desc = strings.TrimSpace(desc + "\n(oneof " + f.oneof + ")")
+		}
+		if p.Ref() {
+			// 引用不能有其它属性 描述放在allOf外层
+			if desc != "" || f.deprecated {
+				p = &jsonschema.Schema{AllOf: []*jsonschema.Schema{p}, Description: desc, Deprecated: f.deprecated}
+			}
+		} else {
+			p.Description = desc
+			p.Deprecated = f.deprecated
+		}
+		s.Properties[f.name] = p
+		s.XOrder = append(s.XOrder, f.name)
+		if f.label == "required" {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+func (im *importer) fieldSchema(scope string, f *protoField) *jsonschema.Schema {
+	value := im.typeSchema(scope, f.typ)
+	switch {
+	case f.keyType != "":
+		s := jsonschema.Create("object")
+		s.AdditionalProperties = &jsonschema.ValueOrBoolean[*jsonschema.Schema]{}
+		s.AdditionalProperties.SetValue(value)
+		return s
+	case f.label == "repeated":
+		s := jsonschema.Create("array")
+		s.Items = &jsonschema.ValueOrBoolean[*jsonschema.Schema]{}
+		s.Items.SetValue(value)
+		return s
+	}
+	return value
+}
+
+// typeSchema 按照proto3的json映射转换类型 64位整数仍然作为integer
+func (im *importer) typeSchema(scope, typ string) *jsonschema.Schema {
+	if s := scalarSchema(typ); s != nil {
+		return s
+	}
+	full := im.resolve(scope, typ)
+	if ref, ok := im.refs[full]; ok {
+		return &jsonschema.Schema{Reference: &ref}
+	}
+	if e, ok := im.enums[full]; ok {
+		s := jsonschema.Create("string")
+		for _, v := range e.values {
+			s.Enum = append(s.Enum, v)
+		}
+		return s
+	}
+	if s := wellKnownSchema(strings.TrimPrefix(typ, ".")); s != nil {
+		return s
+	}
+	s := jsonschema.Create("object")
+	s.Description = "message " + strings.TrimPrefix(typ, ".")
+	return s
+}
+
+// resolve 按照protobuf的作用域规则 从内层向外层查找类型的完整名称
+func (im *importer) resolve(scope, typ string) string {
+	if strings.HasPrefix(typ, ".") {
+		return typ[1:]
+	}
+	for {
+		name := scoped(scope, typ)
+		if _, ok := im.refs[name]; ok {
+			return name
+		}
+		if _, ok := im.enums[name]; ok {
+			return name
+		}
+		if scope == "" {
+			return typ
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+func scalarSchema(typ string) *jsonschema.Schema {
+	var s *jsonschema.Schema
+	switch typ {
+	case "double", "float":
+		s = jsonschema.Create("number")
+		s.Format = typ
+	case "int32", "uint32", "sint32", "fixed32", "sfixed32":
+		s = jsonschema.Create("integer")
+		s.Format = "int32"
+	case "int64", "uint64", "sint64", "fixed64", "sfixed64":
+		s = jsonschema.Create("integer")
+		s.Format = "int64"
+	case "bool":
+		s = jsonschema.Create("boolean")
+	case "string":
+		s = jsonschema.Create("string")
+	case "bytes":
+		s = jsonschema.Create("string")
+		s.Format = "byte"
+	}
+	return s
+}
+
+// wellKnownSchema google.protobuf中常用类型的json映射
+func wellKnownSchema(typ string) *jsonschema.Schema {
+	var s *jsonschema.Schema
+	switch typ {
+	case "google.protobuf.Timestamp":
+		s = jsonschema.Create("string")
+		s.Format = "date-time"
+	case "google.protobuf.Duration", "google.protobuf.FieldMask":
+		s = jsonschema.Create("string")
+	case "google.protobuf.Empty", "google.protobuf.Struct", "google.protobuf.Any":
+		s = jsonschema.Create("object")
+	case "google.protobuf.ListValue":
+		s = jsonschema.Create("array")
+	case "google.protobuf.Value":
+		s = &jsonschema.Schema{}
+	case "google.protobuf.DoubleValue":
+		return scalarSchema("double")
+	case "google.protobuf.FloatValue":
+		return scalarSchema("float")
+	case "google.protobuf.Int64Value", "google.protobuf.UInt64Value":
+		return scalarSchema("int64")
+	case "google.protobuf.Int32Value", "google.protobuf.UInt32Value":
+		return scalarSchema("int32")
+	case "google.protobuf.BoolValue":
+		return scalarSchema("bool")
+	case "google.protobuf.StringValue":
+		return scalarSchema("string")
+	case "google.protobuf.BytesValue":
+		return scalarSchema("bytes")
+	}
+	return s
+}
+
+func (im *importer) method(svc *protoService, m *protoMethod) *spec.CollectItem {
+	content := make([]*spec.NodeProxy, 0)
+	for _, v := range markdown.ToDocment([]byte(m.comment)).Items {
+		content = append(content, spec.MuseCreateNodeProxy(v))
+	}
+	content = append(content,
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.GRPCMethodNode{
+			Package:         im.file.pkg,
+			Service:         svc.name,
+			Method:          m.name,
+			RequestType:     strings.TrimPrefix(m.input, "."),
+			ResponseType:    strings.TrimPrefix(m.output, "."),
+			ClientStreaming: m.clientStream,
+			ServerStreaming: m.serverStream,
+			HTTPMethod:      m.httpMethod,
+			HTTPPath:        m.httpPath,
+			HTTPBody:        m.httpBody,
+		})),
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.GRPCRequestNode{Schema: im.typeSchema(m.scope, m.input)})),
+		spec.MuseCreateNodeProxy(spec.WarpHTTPNode(spec.GRPCResponseNode{Schema: im.typeSchema(m.scope, m.output)})),
+	)
+	return &spec.CollectItem{
+		Type:    spec.ContentItemTypeGRPC,
+		Title:   m.name,
+		Content: content,
+	}
+}
@@ -0,0 +1,755 @@
+package protobuf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	// comment 紧挨在前面的注释
+	comment string
+}
+
+// lexer 将proto文件拆分为token 注释不作为token 而是记录在相邻的token上
+type lexer struct {
+	src  []rune
+	pos  int
+	line int
+	// trailing token所在行尾的注释 key为token的下标
+	trailing map[int]string
+}
+
+func tokenize(src string) ([]token, map[int]string, error) {
+	l := &lexer{src: []rune(src), line: 1, trailing: make(map[int]string)}
+	var (
+		tokens      []token
+		comment     []string
+		commentLine int
+	)
+	for {
+		blank := l.skipSpace()
+		if blank {
+			comment = nil
+		}
+		if l.pos >= len(l.src) {
+			break
+		}
+		r := l.src[l.pos]
+		if r == '/' && l.pos+1 < len(l.src) && (l.src[l.pos+1] == '/' || l.src[l.pos+1] == '*') {
+			startLine := l.line
+			text, err := l.readComment()
+			if err != nil {
+				return nil, nil, err
+			}
+			// 与上一个token在同一行的注释属于上一个token
+			if n := len(tokens); n > 0 && tokens[n-1].line == startLine {
+				l.trailing[n-1] = text
+				continue
+			}
+			if comment != nil && commentLine+1 < startLine {
+				comment = nil
+			}
+			comment = append(comment, text)
+			commentLine = l.line
+			continue
+		}
+
+		t := token{line: l.line}
+		if comment != nil && commentLine+1 >= l.line {
+			t.comment = strings.Join(comment, "\n")
+		}
+		comment = nil
+		switch {
+		case r == '_' || unicode.IsLetter(r) || (r == '.' && l.pos+1 < len(l.src) && unicode.IsLetter(l.src[l.pos+1])):
+			// 以.开头的是完整的类型名称
+			l.pos++
+			t.kind, t.text = tokenIdent, string(r)+l.readWhile(func(r rune) bool {
+				return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+			})
+		case unicode.IsDigit(r) || (r == '-' && l.pos+1 < len(l.src) && unicode.IsDigit(l.src[l.pos+1])):
+			l.pos++
+			t.kind, t.text = tokenNumber, string(r)+l.readWhile(func(r rune) bool {
+				return r == '.' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+			})
+		case r == '"' || r == '\'':
+			s, err := l.readString(r)
+			if err != nil {
+				return nil, nil, err
+			}
+			t.kind, t.text = tokenString, s
+		default:
+			l.pos++
+			t.kind, t.text = tokenSymbol, string(r)
+		}
+		tokens = append(tokens, t)
+	}
+	tokens = append(tokens, token{kind: tokenEOF, line: l.line})
+	return tokens, l.trailing, nil
+}
+
+// skipSpace 跳过空白字符 返回是否跳过了空行
+func (l *lexer) skipSpace() bool {
+	newlines := 0
+	for l.pos < len(l.src) && unicode.IsSpace(l.src[l.pos]) {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			newlines++
+		}
+		l.pos++
+	}
+	return newlines > 1
+}
+
+func (l *lexer) readWhile(f func(rune) bool) string {
+	start := l.pos
+	for l.pos < len(l.src) && f(l.src[l.pos]) {
+		l.pos++
+	}
+	return string(l.src[start:l.pos])
+}
+
+// readComment 读取注释的内容 去掉注释符号和块注释每行开头的*
+func (l *lexer) readComment() (string, error) {
+	if l.src[l.pos+1] == '/' {
+		l.pos += 2
+		text := l.readWhile(func(r rune) bool { return r != '\n' })
+		return strings.TrimSpace(text), nil
+	}
+	l.pos += 2
+	start := l.pos
+	for l.pos+1 < len(l.src) && !(l.src[l.pos] == '*' && l.src[l.pos+1] == '/') {
+		if l.src[l.pos] == '\n' {
+			l.line++
+		}
+		l.pos++
+	}
+	if l.pos+1 >= len(l.src) {
+		return "", fmt.Errorf("line %d: unterminated comment", l.line)
+	}
+	lines := strings.Split(string(l.src[start:l.pos]), "\n")
+	l.pos += 2
+	out := make([]string, 0, len(lines))
+	for _, v := range lines {
+		v = strings.TrimSpace(v)
+		v = strings.TrimSpace(strings.TrimPrefix(v, "*"))
+		if v != "" {
+			out = append(out, v)
+		}
+	}
+	return strings.Join(out, "\n"), nil
+}
+
+func (l *lexer) readString(quote rune) (string, error) {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.src) && l.src[l.pos] != quote {
+		if l.src[l.pos] == '\\' {
+			l.pos++
+		}
+		if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+			return "", fmt.Errorf("line %d: unterminated string", l.line)
+		}
+		l.pos++
+	}
+	if l.pos >= len(l.src) {
+		return "", fmt.Errorf("line %d: unterminated string", l.line)
+	}
+	l.pos++
+	raw := string(l.src[start:l.pos])
+	if quote == '\'' {
+		raw = `"` + strings.ReplaceAll(raw[1:len(raw)-1], `"`, `\"`) + `"`
+	}
+	s, err := strconv.Unquote(raw)
+	if err != nil {
+		// 不支持的转义保留原文
+		return raw[1 : len(raw)-1], nil
+	}
+	return s, nil
+}
+
+type protoFile struct {
+	syntax   string
+	pkg      string
+	messages []*protoMessage
+	enums    []*protoEnum
+	services []*protoService
+}
+
+type protoMessage struct {
+	// fullName 包含包名和外层消息的名称 例如pkg.Outer.Inner
+	fullName string
+	comment  string
+	fields   []*protoField
+}
+
+type protoField struct {
+	name    string
+	typ     string
+	keyType string
+	label   string
+	oneof   string
+	comment string
+	// deprecated 字段选项中的deprecated=true
+	deprecated bool
+}
+
+type protoEnum struct {
+	fullName string
+	comment  string
+	values   []string
+}
+
+type protoService struct {
+	name    string
+	comment string
+	methods []*protoMethod
+}
+
+type protoMethod struct {
+	name         string
+	comment      string
+	input        string
+	output       string
+	clientStream bool
+	serverStream bool
+	httpMethod   string
+	httpPath     string
+	httpBody     string
+	// scope 解析请求和响应类型时的作用域
+	scope string
+}
+
+type parser struct {
+	tokens   []token
+	trailing map[int]string
+	pos      int
+	file     *protoFile
+}
+
+// parse 解析proto2 proto3或editions语法的文件 只保留生成文档需要的信息
+func parse(src string) (*protoFile, error) {
+	tokens, trailing, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens, trailing: trailing, file: &protoFile{syntax: "proto2"}}
+	if err := p.parseFile(); err != nil {
+		return nil, err
+	}
+	return p.file, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// comment token的注释 没有前置注释时使用语句结尾的注释
+func (p *parser) comment(start token, end int) string {
+	if start.comment != "" {
+		return start.comment
+	}
+	return p.trailing[end]
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) expect(text string) (token, error) {
+	t := p.next()
+	if t.text != text || t.kind == tokenString {
+		return t, p.errorf(t, "expected %q, got %q", text, t.text)
+	}
+	return t, nil
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.next()
+	if t.kind != tokenIdent {
+		return "", p.errorf(t, "expected identifier, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *parser) accept(text string) bool {
+	if t := p.peek(); t.kind == tokenSymbol && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseFile() error {
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return nil
+		case p.accept(";"):
+		case t.text == "syntax" || t.text == "edition":
+			p.next()
+			if _, err := p.expect("="); err != nil {
+				return err
+			}
+			v := p.next()
+			if v.kind != tokenString {
+				return p.errorf(v, "expected string, got %q", v.text)
+			}
+			p.file.syntax = v.text
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case t.text == "package":
+			p.next()
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			p.file.pkg = name
+			if _, err := p.expect(";"); err != nil {
+				return err
+			}
+		case t.text == "import" || t.text == "option":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case t.text == "message":
+			if err := p.parseMessage(p.file.pkg); err != nil {
+				return err
+			}
+		case t.text == "enum":
+			if err := p.parseEnum(p.file.pkg); err != nil {
+				return err
+			}
+		case t.text == "service":
+			if err := p.parseService(); err != nil {
+				return err
+			}
+		case t.text == "extend":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			return p.errorf(t, "unexpected %q", t.text)
+		}
+	}
+}
+
+// skipStatement 跳过到;为止的语句 或者一个完整的{}块
+func (p *parser) skipStatement() error {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected end of file")
+		case t.kind != tokenSymbol:
+		case t.text == "{":
+			depth++
+		case t.text == "}":
+			depth--
+			if depth == 0 {
+				// option x = {...}; 块后面还有;
+				p.accept(";")
+				return nil
+			}
+		case t.text == ";" && depth == 0:
+			return nil
+		}
+	}
+}
+
+func scoped(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func (p *parser) parseMessage(scope string) error {
+	start := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	msg := &protoMessage{fullName: scoped(scope, name), comment: p.comment(start, p.pos-1)}
+	p.file.messages = append(p.file.messages, msg)
+	return p.parseMessageBody(msg, "")
+}
+
+// parseMessageBody 解析消息的内容直到} oneof的内容也使用这个方法解析
+func (p *parser) parseMessageBody(msg *protoMessage, oneof string) error {
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected end of file")
+		case p.accept("}"):
+			return nil
+		case p.accept(";"):
+		case t.text == "message" && oneof == "":
+			if err := p.parseMessage(msg.fullName); err != nil {
+				return err
+			}
+		case t.text == "enum" && oneof == "":
+			if err := p.parseEnum(msg.fullName); err != nil {
+				return err
+			}
+		case t.text == "oneof" && oneof == "":
+			p.next()
+			name, err := p.ident()
+			if err != nil {
+				return err
+			}
+			if _, err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.parseMessageBody(msg, name); err != nil {
+				return err
+			}
+		case t.text == "option" || t.text == "reserved" || t.text == "extensions" || t.text == "extend":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			f, err := p.parseField(msg.fullName)
+			if err != nil {
+				return err
+			}
+			f.oneof = oneof
+			msg.fields = append(msg.fields, f)
+		}
+	}
+}
+
+// parseField [label] type name = number [options]; 或者 map<key, value> name = number;
+// proto2的group作为同名的嵌套消息
+func (p *parser) parseField(scope string) (*protoField, error) {
+	start := p.peek()
+	f := &protoField{}
+	if t := p.peek(); t.text == "repeated" || t.text == "optional" || t.text == "required" {
+		f.label = p.next().text
+	}
+	typ, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	if typ == "map" && p.accept("<") {
+		if f.keyType, err = p.ident(); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(","); err != nil {
+			return nil, err
+		}
+		if typ, err = p.ident(); err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(">"); err != nil {
+			return nil, err
+		}
+	}
+	f.typ = typ
+	if f.name, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("="); err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokenNumber {
+		return nil, p.errorf(t, "expected field number, got %q", t.text)
+	}
+	if p.accept("[") {
+		if err := p.parseFieldOptions(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if typ == "group" {
+		msg := &protoMessage{fullName: scoped(scope, f.name), comment: start.comment}
+		p.file.messages = append(p.file.messages, msg)
+		if _, err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		if err := p.parseMessageBody(msg, ""); err != nil {
+			return nil, err
+		}
+		f.typ, f.name = f.name, strings.ToLower(f.name)
+		f.comment = start.comment
+		return f, nil
+	}
+	if _, err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	f.comment = p.comment(start, p.pos-1)
+	return f, nil
+}
+
+// parseFieldOptions 只关心deprecated 其它选项跳过
+func (p *parser) parseFieldOptions(f *protoField) error {
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected end of file")
+		case t.kind == tokenSymbol && t.text == "]":
+			return nil
+		case t.kind == tokenIdent && t.text == "deprecated":
+			if p.accept("=") {
+				f.deprecated = p.next().text == "true"
+			}
+		case t.kind == tokenSymbol && t.text == "{":
+			p.pos--
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// skipBlock 跳过一个{}块
+func (p *parser) skipBlock() error {
+	depth := 0
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected end of file")
+		case t.kind != tokenSymbol:
+		case t.text == "{":
+			depth++
+		case t.text == "}":
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) parseEnum(scope string) error {
+	start := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	enum := &protoEnum{fullName: scoped(scope, name), comment: p.comment(start, p.pos-1)}
+	p.file.enums = append(p.file.enums, enum)
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected end of file")
+		case p.accept("}"):
+			return nil
+		case p.accept(";"):
+		case t.text == "option" || t.text == "reserved":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			value, err := p.ident()
+			if err != nil {
+				return err
+			}
+			enum.values = append(enum.values, value)
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *parser) parseService() error {
+	start := p.next()
+	name, err := p.ident()
+	if err != nil {
+		return err
+	}
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	svc := &protoService{name: name, comment: p.comment(start, p.pos-1)}
+	p.file.services = append(p.file.services, svc)
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected end of file")
+		case p.accept("}"):
+			return nil
+		case p.accept(";"):
+		case t.text == "rpc":
+			m, err := p.parseMethod()
+			if err != nil {
+				return err
+			}
+			svc.methods = append(svc.methods, m)
+		case t.text == "option":
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		default:
+			return p.errorf(t, "unexpected %q", t.text)
+		}
+	}
+}
+
+// parseMethod rpc Name ([stream] Request) returns ([stream] Response) {options}
+func (p *parser) parseMethod() (*protoMethod, error) {
+	start := p.next()
+	m := &protoMethod{scope: p.file.pkg}
+	var err error
+	if m.name, err = p.ident(); err != nil {
+		return nil, err
+	}
+	if m.input, m.clientStream, err = p.methodType(); err != nil {
+		return nil, err
+	}
+	if _, err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if m.output, m.serverStream, err = p.methodType(); err != nil {
+		return nil, err
+	}
+	if p.accept(";") {
+		m.comment = p.comment(start, p.pos-1)
+		return m, nil
+	}
+	if _, err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	m.comment = p.comment(start, p.pos-1)
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, p.errorf(t, "unexpected end of file")
+		case p.accept("}"):
+			p.accept(";")
+			return m, nil
+		case p.accept(";"):
+		case t.text == "option":
+			if err := p.parseMethodOption(m); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf(t, "unexpected %q", t.text)
+		}
+	}
+}
+
+func (p *parser) methodType() (string, bool, error) {
+	if _, err := p.expect("("); err != nil {
+		return "", false, err
+	}
+	stream := false
+	typ, err := p.ident()
+	if err != nil {
+		return "", false, err
+	}
+	// stream也可以是消息的名称
+	if typ == "stream" && p.peek().kind == tokenIdent {
+		stream = true
+		if typ, err = p.ident(); err != nil {
+			return "", false, err
+		}
+	}
+	if _, err := p.expect(")"); err != nil {
+		return "", false, err
+	}
+	return typ, stream, nil
+}
+
+// parseMethodOption 读取google.api.http注解中的第一个规则 其它选项跳过
+func (p *parser) parseMethodOption(m *protoMethod) error {
+	begin := p.pos
+	p.next()
+	if !(p.accept("(") && p.peek().text == "google.api.http") {
+		p.pos = begin
+		return p.skipStatement()
+	}
+	p.next()
+	if _, err := p.expect(")"); err != nil {
+		return err
+	}
+	// option (google.api.http).get = "/v1/path";
+	if t := p.peek(); t.kind == tokenIdent && strings.HasPrefix(t.text, ".") {
+		p.next()
+		if _, err := p.expect("="); err != nil {
+			return err
+		}
+		m.setHTTPRule(t.text[1:], p.next().text)
+		return p.skipStatement()
+	}
+	if _, err := p.expect("="); err != nil {
+		return err
+	}
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	for {
+		t := p.next()
+		switch {
+		case t.kind == tokenEOF:
+			return p.errorf(t, "unexpected end of file")
+		case t.kind == tokenSymbol && t.text == "}":
+			p.accept(";")
+			return nil
+		case t.kind != tokenIdent:
+		case p.peek().text == "{":
+			// additional_bindings custom等嵌套的规则
+			if err := p.skipBlock(); err != nil {
+				return err
+			}
+		case p.accept(":"):
+			if p.peek().text == "{" {
+				if err := p.skipBlock(); err != nil {
+					return err
+				}
+				continue
+			}
+			m.setHTTPRule(t.text, p.next().text)
+		}
+	}
+}
+
+// setHTTPRule 只保留第一个http规则
+func (m *protoMethod) setHTTPRule(key, value string) {
+	switch key {
+	case "get", "put", "post", "delete", "patch":
+		if m.httpMethod == "" {
+			m.httpMethod, m.httpPath = key, value
+		}
+	case "body":
+		m.httpBody = value
+	}
+}
@@ -0,0 +1,105 @@
+package protobuf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"golang.org/x/exp/slices"
+)
+
+func TestImport(t *testing.T) {
+	raw, err := os.ReadFile("../../testdata/example.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, err := Import(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0)
+	for _, v := range x.Definitions.Schemas {
+		names = append(names, v.Name)
+	}
+	if !slices.Equal(names, []string{"Book", "Book.Author", "Book.Reference", "GetBookRequest", "CreateBookRequest", "WatchBooksRequest", "UploadSummary", "ChatMessage"}) {
+		t.Fatalf("unexpected definitions %v", names)
+	}
+	book := x.Definitions.Schemas.Lookup("Book")
+	if book.Description != "A single book." || !slices.Equal(book.Schema.XOrder, []string{"name", "title", "authors", "genre", "labels", "create_time", "pages", "page_count", "isbn", "link"}) {
+		t.Fatalf("unexpected book %+v", book.Schema)
+	}
+	props := book.Schema.Properties
+	if props["name"].Description != "Resource name, shelves/{shelf}/books/{book}." {
+		t.Errorf("unexpected leading comment %q", props["name"].Description)
+	}
+	if !props["pages"].Deprecated || props["pages"].Description != "Use page_count instead." || props["pages"].Format != "int64" {
+		t.Errorf("unexpected pages %+v", props["pages"])
+	}
+	if !slices.Equal(props["genre"].Enum, []any{"GENRE_UNSPECIFIED", "FICTION", "HISTORY"}) {
+		t.Errorf("unexpected genre %+v", props["genre"])
+	}
+	if props["labels"].AdditionalProperties.Value().Type.Value()[0] != "string" {
+		t.Errorf("unexpected labels %+v", props["labels"])
+	}
+	if props["create_time"].Format != "date-time" {
+		t.Errorf("unexpected create_time %+v", props["create_time"])
+	}
+	if !props["authors"].Items.Value().Ref() || props["link"].AllOf[0].Ref() != true || props["link"].Description != "(oneof source)" {
+		t.Errorf("unexpected refs %+v %+v", props["authors"], props["link"])
+	}
+	author := x.Definitions.Schemas.Lookup("Book.Author").Schema
+	if len(author.Properties["favorite"].Enum) != 3 {
+		t.Errorf("absolute type not resolved %+v", author.Properties["favorite"])
+	}
+
+	if len(x.Collections) != 1 || x.Collections[0].Title != "LibraryService" {
+		t.Fatalf("unexpected collections %+v", x.Collections)
+	}
+	methods := x.GRPCMethods(true, 1)
+	if len(methods) != 6 {
+		t.Fatalf("unexpected methods %+v", methods)
+	}
+	for i, want := range []struct {
+		method, mode, httpMethod, httpPath string
+	}{
+		{"GetBook", spec.GRPCUnary, "GET", "/v1/{name=shelves/*/books/*}"},
+		{"CreateBook", spec.GRPCUnary, "POST", "/v1/{parent=shelves/*}/books"},
+		{"DeleteBook", spec.GRPCUnary, "DELETE", "/v1/{name=shelves/*/books/*}"},
+		{"WatchBooks", spec.GRPCServerStreaming, "POST", "/library.v1.LibraryService/WatchBooks"},
+		{"UploadBooks", spec.GRPCClientStreaming, "POST", "/library.v1.LibraryService/UploadBooks"},
+		{"Chat", spec.GRPCBidiStreaming, "POST", "/library.v1.LibraryService/Chat"},
+	} {
+		m := methods[i].Method
+		method, path := m.Transcoding()
+		if m.Method != want.method || m.StreamingMode() != want.mode || method != want.httpMethod || path != want.httpPath {
+			t.Errorf("unexpected method %d %+v", i, m)
+		}
+	}
+	if methods[1].Method.HTTPBody != "book" || methods[1].Request.Properties["book"] == nil {
+		t.Errorf("unexpected create book %+v", methods[1])
+	}
+	if methods[2].Response.Type.Value()[0] != "object" || methods[2].Method.ResponseType != "google.protobuf.Empty" {
+		t.Errorf("unexpected empty response %+v", methods[2].Response)
+	}
+	if methods[0].Doc.Items == nil || methods[5].Doc.Items != nil {
+		t.Error("unexpected method comments")
+	}
+	if methods[4].Doc.Items == nil {
+		t.Error("block comment should be used")
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, src := range []string{
+		`message A { string a = ; }`,
+		`service S { rpc A(B) returns C; }`,
+		`message A { string a = 1;`,
+		`/* not closed`,
+		``,
+	} {
+		if _, err := Import([]byte(src)); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package snippet
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+func (r *request) curl() string {
+	shellQuote := func(s string) string {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	lines := []string{fmt.Sprintf("curl -X %s %s", r.method, shellQuote(r.url))}
+	for _, h := range r.headers {
+		lines = append(lines, "-H "+shellQuote(h[0]+": "+h[1]))
+	}
+	switch r.bodyType {
+	case bodyJSON, bodyRaw:
+		lines = append(lines, "-H "+shellQuote("Content-Type: "+r.contentType))
+		lines = append(lines, "--data-raw "+shellQuote(r.body))
+	case bodyForm:
+		for _, f := range r.form {
+			lines = append(lines, "--data-urlencode "+shellQuote(f.name+"="+f.value))
+		}
+	case bodyMultipart:
+		for _, f := range r.form {
+			if f.file {
+				lines = append(lines, "-F "+shellQuote(f.name+"=@"+f.value))
+			} else {
+				lines = append(lines, "-F "+shellQuote(f.name+"="+f.value))
+			}
+		}
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+func (r *request) golang() string {
+	imports := []string{"fmt", "io", "net/http"}
+	var b strings.Builder
+	body := "nil"
+	switch r.bodyType {
+	case bodyJSON, bodyRaw:
+		imports = append(imports, "strings")
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", goString(r.body))
+		body = "body"
+	case bodyForm:
+		imports = append(imports, "net/url", "strings")
+		b.WriteString("\tform := url.Values{}\n")
+		for _, f := range r.form {
+			fmt.Fprintf(&b, "\tform.Add(%s, %s)\n", quote(f.name), quote(f.value))
+		}
+		b.WriteString("\tbody := strings.NewReader(form.Encode())\n")
+		body = "body"
+	case bodyMultipart:
+		imports = append(imports, "bytes", "mime/multipart")
+		b.WriteString("\tbody := &bytes.Buffer{}\n\twriter := multipart.NewWriter(body)\n")
+		for _, f := range r.form {
+			if f.file {
+				if !slices.Contains(imports, "os") {
+					imports = append(imports, "os", "path/filepath")
+				}
+				fmt.Fprintf(&b, "\tif f, err := os.Open(%s); err == nil {\n", quote(f.value))
+				fmt.Fprintf(&b, "\t\tpart, _ := writer.CreateFormFile(%s, filepath.Base(f.Name()))\n", quote(f.name))
+				b.WriteString("\t\tio.Copy(part, f)\n\t\tf.Close()\n\t}\n")
+			} else {
+				fmt.Fprintf(&b, "\twriter.WriteField(%s, %s)\n", quote(f.name), quote(f.value))
+			}
+		}
+		b.WriteString("\twriter.Close()\n")
+		body = "body"
+	}
+
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n", quote(r.method), quote(r.url), body)
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, h := range r.headers {
+		fmt.Fprintf(&b, "\treq.Header.Set(%s, %s)\n", quote(h[0]), quote(h[1]))
+	}
+	switch r.bodyType {
+	case bodyJSON, bodyRaw:
+		fmt.Fprintf(&b, "\treq.Header.Set(\"Content-Type\", %s)\n", quote(r.contentType))
+	case bodyForm:
+		b.WriteString("\treq.Header.Set(\"Content-Type\", \"application/x-www-form-urlencoded\")\n")
+	case bodyMultipart:
+		b.WriteString("\treq.Header.Set(\"Content-Type\", writer.FormDataContentType())\n")
+	}
+	b.WriteString("\n\tres, err := http.DefaultClient.Do(req)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	b.WriteString("\tdefer res.Body.Close()\n\n\tdata, _ := io.ReadAll(res.Body)\n\tfmt.Println(res.StatusCode, string(data))\n}")
+
+	sort.Strings(imports)
+	var head strings.Builder
+	head.WriteString("package main\n\nimport (\n")
+	for _, v := range imports {
+		fmt.Fprintf(&head, "\t%q\n", v)
+	}
+	head.WriteString(")\n\nfunc main() {\n")
+	return head.String() + b.String()
+}
+
+// goString 没有反引号时使用原始字符串 保留json的格式
+func goString(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
+
+func (r *request) python() string {
+	var b strings.Builder
+	b.WriteString("import requests\n\n")
+	fmt.Fprintf(&b, "url = %s\n", quote(r.url))
+	headers := r.headers
+	if r.bodyType == bodyJSON || r.bodyType == bodyRaw {
+		headers = append(headers[:len(headers):len(headers)], [2]string{"Content-Type", r.contentType})
+	}
+	args := []string{"headers=headers"}
+	b.WriteString("headers = {\n")
+	for _, h := range headers {
+		fmt.Fprintf(&b, "    %s: %s,\n", quote(h[0]), quote(h[1]))
+	}
+	b.WriteString("}\n")
+
+	switch r.bodyType {
+	case bodyJSON, bodyRaw:
+		fmt.Fprintf(&b, "payload = %s\n", pythonString(r.body))
+		args = append(args, "data=payload")
+	case bodyForm, bodyMultipart:
+		b.WriteString("payload = {\n")
+		var files []field
+		for _, f := range r.form {
+			if f.file {
+				files = append(files, f)
+				continue
+			}
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(f.name), quote(f.value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "data=payload")
+		if r.bodyType == bodyMultipart {
+			b.WriteString("files = {\n")
+			for _, f := range files {
+				fmt.Fprintf(&b, "    %s: open(%s, \"rb\"),\n", quote(f.name), quote(f.value))
+			}
+			b.WriteString("}\n")
+			args = append(args, "files=files")
+		}
+	}
+	fmt.Fprintf(&b, "\nresponse = requests.request(%s, url, %s)\n", quote(r.method), strings.Join(args, ", "))
+	b.WriteString("print(response.status_code, response.text)")
+	return b.String()
+}
+
+// pythonString 多行并且没有需要转义的字符时使用三引号保留格式
+func pythonString(s string) string {
+	if strings.Contains(s, "\n") && !strings.ContainsAny(s, `\`) && !strings.Contains(s, `"""`) && !strings.HasSuffix(s, `"`) {
+		return `"""` + s + `"""`
+	}
+	return quote(s)
+}
+
+func (r *request) javascript() string {
+	var b strings.Builder
+	headers := r.headers
+	if r.bodyType == bodyJSON || r.bodyType == bodyRaw {
+		headers = append(headers[:len(headers):len(headers)], [2]string{"Content-Type", r.contentType})
+	}
+	var body string
+	switch r.bodyType {
+	case bodyJSON:
+		if r.body != "" {
+			body = "JSON.stringify(" + indent(r.body, "  ") + ")"
+		} else {
+			body = quote(r.body)
+		}
+	case bodyRaw:
+		body = quote(r.body)
+	case bodyForm:
+		b.WriteString("const body = new URLSearchParams();\n")
+		for _, f := range r.form {
+			fmt.Fprintf(&b, "body.append(%s, %s);\n", quote(f.name), quote(f.value))
+		}
+		b.WriteString("\n")
+		body = "body"
+	case bodyMultipart:
+		b.WriteString("const body = new FormData();\n")
+		for _, f := range r.form {
+			if f.file {
+				fmt.Fprintf(&b, "body.append(%s, document.querySelector('input[type=\"file\"]').files[0]);\n", quote(f.name))
+			} else {
+				fmt.Fprintf(&b, "body.append(%s, %s);\n", quote(f.name), quote(f.value))
+			}
+		}
+		b.WriteString("\n")
+		body = "body"
+	}
+
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", quote(r.url))
+	fmt.Fprintf(&b, "  method: %s,\n", quote(r.method))
+	if len(headers) > 0 {
+		b.WriteString("  headers: {\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "    %s: %s,\n", quote(h[0]), quote(h[1]))
+		}
+		b.WriteString("  },\n")
+	}
+	if body != "" {
+		fmt.Fprintf(&b, "  body: %s,\n", body)
+	}
+	b.WriteString("});\n\nconsole.log(response.status, await response.text());")
+	return b.String()
+}
+
+func (r *request) java() string {
+	var b strings.Builder
+	b.WriteString("OkHttpClient client = new OkHttpClient();\n\n")
+	body := "null"
+	switch r.bodyType {
+	case bodyJSON, bodyRaw:
+		fmt.Fprintf(&b, "MediaType mediaType = MediaType.parse(%s);\n", javaQuote(r.contentType))
+		fmt.Fprintf(&b, "RequestBody body = RequestBody.create(%s, mediaType);\n", javaQuote(r.body))
+		body = "body"
+	case bodyForm:
+		b.WriteString("RequestBody body = new FormBody.Builder()\n")
+		for _, f := range r.form {
+			fmt.Fprintf(&b, "  .add(%s, %s)\n", javaQuote(f.name), javaQuote(f.value))
+		}
+		b.WriteString("  .build();\n")
+		body = "body"
+	case bodyMultipart:
+		b.WriteString("RequestBody body = new MultipartBody.Builder()\n  .setType(MultipartBody.FORM)\n")
+		for _, f := range r.form {
+			if f.file {
+				fmt.Fprintf(&b, "  .addFormDataPart(%s, %s,\n    RequestBody.create(new File(%s), MediaType.parse(\"application/octet-stream\")))\n",
+					javaQuote(f.name), javaQuote(f.value[strings.LastIndex(f.value, "/")+1:]), javaQuote(f.value))
+			} else {
+				fmt.Fprintf(&b, "  .addFormDataPart(%s, %s)\n", javaQuote(f.name), javaQuote(f.value))
+			}
+		}
+		b.WriteString("  .build();\n")
+		body = "body"
+	default:
+		// okhttp的POST PUT PATCH必须有请求体
+		switch r.method {
+		case "POST", "PUT", "PATCH":
+			body = "RequestBody.create(new byte[0], null)"
+		}
+	}
+
+	b.WriteString("Request request = new Request.Builder()\n")
+	fmt.Fprintf(&b, "  .url(%s)\n", javaQuote(r.url))
+	fmt.Fprintf(&b, "  .method(%s, %s)\n", javaQuote(r.method), body)
+	for _, h := range r.headers {
+		fmt.Fprintf(&b, "  .addHeader(%s, %s)\n", javaQuote(h[0]), javaQuote(h[1]))
+	}
+	b.WriteString("  .build();\n\n")
+	b.WriteString("try (Response response = client.newCall(request).execute()) {\n")
+	b.WriteString("  System.out.println(response.code() + \" \" + response.body().string());\n}")
+	return b.String()
+}
@@ -0,0 +1,310 @@
+package snippet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+	"github.com/apicat/datagen"
+	"golang.org/x/exp/slices"
+)
+
+// Languages 支持生成的语言
+var Languages = []string{"curl", "go", "python", "javascript", "java"}
+
+// 上传文件时使用的示例路径
+const filePlaceholder = "/path/to/file"
+
+// body的类型
+const (
+	bodyNone = iota
+	bodyJSON
+	bodyRaw
+	bodyForm
+	bodyMultipart
+)
+
+type Snippet struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// Option 生成代码时使用的服务器地址 全局参数和认证方式
+type Option struct {
+	ServerURL  string
+	Globals    spec.HTTPParameters
+	Securities spec.SecuritySchemes
+}
+
+// field 表单字段 file为true时value为文件路径
+type field struct {
+	name  string
+	value string
+	file  bool
+}
+
+// request 各个语言共用的请求内容
+type request struct {
+	method      string
+	url         string
+	headers     [][2]string
+	contentType string
+	bodyType    int
+	body        string
+	form        []field
+}
+
+// Generate 将接口生成指定语言可以直接运行的请求代码
+func Generate(lang, method, path string, part *spec.HTTPPart, opt Option) (string, error) {
+	r := newRequest(method, path, part, opt)
+	switch lang {
+	case "curl":
+		return r.curl(), nil
+	case "go":
+		return r.golang(), nil
+	case "python":
+		return r.python(), nil
+	case "javascript":
+		return r.javascript(), nil
+	case "java":
+		return r.java(), nil
+	}
+	return "", fmt.Errorf("unsupported language %s", lang)
+}
+
+// GenerateAll 按照Languages的顺序生成所有语言的代码
+func GenerateAll(method, path string, part *spec.HTTPPart, opt Option) []Snippet {
+	r := newRequest(method, path, part, opt)
+	return []Snippet{
+		{Language: "curl", Code: r.curl()},
+		{Language: "go", Code: r.golang()},
+		{Language: "python", Code: r.python()},
+		{Language: "javascript", Code: r.javascript()},
+		{Language: "java", Code: r.java()},
+	}
+}
+
+func newRequest(method, path string, part *spec.HTTPPart, opt Option) *request {
+	r := &request{method: strings.ToUpper(method)}
+	params := part.Parameters
+	globals := func(in string, list spec.Schemas) spec.Schemas {
+		res := make(spec.Schemas, 0, len(list))
+		for _, v := range list {
+			if !slices.Contains(part.GlobalExcepts[in], v.ID) {
+				res = append(res, v)
+			}
+		}
+		return res
+	}
+
+	for _, p := range append(globals("path", opt.Globals.Path), params.Path...) {
+		if v := exampleValue(p); v != "" {
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", url.PathEscape(v))
+		}
+	}
+	var query []string
+	for _, p := range append(globals("query", opt.Globals.Query), params.Query...) {
+		query = append(query, url.QueryEscape(p.Name)+"="+url.QueryEscape(exampleValue(p)))
+	}
+	for _, p := range append(globals("header", opt.Globals.Header), params.Header...) {
+		r.headers = append(r.headers, [2]string{p.Name, exampleValue(p)})
+	}
+	var cookies []string
+	for _, p := range append(globals("cookie", opt.Globals.Cookie), params.Cookie...) {
+		cookies = append(cookies, p.Name+"="+exampleValue(p))
+	}
+
+	// 只使用第一个认证要求
+	if len(part.Security) > 0 {
+		for _, name := range sortedKeys(part.Security[0]) {
+			scheme := opt.Securities.Lookup(name)
+			if scheme == nil {
+				continue
+			}
+			switch scheme.Type {
+			case spec.SecurityTypeAPIKey:
+				switch scheme.In {
+				case "query":
+					query = append(query, url.QueryEscape(scheme.Key)+"=YOUR_API_KEY")
+				case "cookie":
+					cookies = append(cookies, scheme.Key+"=YOUR_API_KEY")
+				default:
+					r.headers = append(r.headers, [2]string{scheme.Key, "YOUR_API_KEY"})
+				}
+			case spec.SecurityTypeHTTP:
+				if strings.EqualFold(scheme.Scheme, "basic") {
+					r.headers = append(r.headers, [2]string{"Authorization", "Basic YOUR_CREDENTIALS"})
+				} else {
+					r.headers = append(r.headers, [2]string{"Authorization", "Bearer YOUR_TOKEN"})
+				}
+			default:
+				r.headers = append(r.headers, [2]string{"Authorization", "Bearer YOUR_ACCESS_TOKEN"})
+			}
+		}
+	}
+	if len(cookies) > 0 {
+		r.headers = append(r.headers, [2]string{"Cookie", strings.Join(cookies, "; ")})
+	}
+
+	r.url = strings.TrimSuffix(opt.ServerURL, "/") + path
+	if len(query) > 0 {
+		r.url += "?" + strings.Join(query, "&")
+	}
+	r.setBody(part.Content)
+	return r
+}
+
+// setBody 有多个content type时优先使用json
+func (r *request) setBody(content spec.HTTPBody) {
+	types := sortedKeys(content)
+	if len(types) == 0 {
+		return
+	}
+	contentType := types[0]
+	for _, t := range types {
+		if strings.Contains(t, "json") {
+			contentType = t
+			break
+		}
+	}
+	c := content[contentType]
+	if c == nil || c.Schema == nil {
+		return
+	}
+	r.contentType = contentType
+	switch {
+	case strings.Contains(contentType, "json"):
+		r.bodyType = bodyJSON
+		r.body = generateJSON(c.Schema)
+	case contentType == "application/x-www-form-urlencoded":
+		r.bodyType = bodyForm
+		r.form = formFields(c.Schema)
+	case contentType == "multipart/form-data":
+		r.bodyType = bodyMultipart
+		r.form = formFields(c.Schema)
+	default:
+		r.bodyType = bodyRaw
+		if c.Schema.Example != nil {
+			r.body = fmt.Sprint(c.Schema.Example)
+		}
+	}
+}
+
+func formFields(s *jsonschema.Schema) []field {
+	names := s.XOrder
+	if len(names) != len(s.Properties) {
+		names = sortedKeys(s.Properties)
+	}
+	list := make([]field, 0, len(names))
+	for _, name := range names {
+		p := s.Properties[name]
+		if p == nil {
+			continue
+		}
+		f := field{name: name}
+		if (p.Type != nil && len(p.Type.Value()) > 0 && p.Type.Value()[0] == "file") || p.Format == "binary" {
+			f.file = true
+			f.value = filePlaceholder
+		} else if p.Example != nil {
+			f.value = fmt.Sprint(p.Example)
+		}
+		list = append(list, f)
+	}
+	return list
+}
+
+func generateJSON(s *jsonschema.Schema) string {
+	b, _ := json.Marshal(s.Flatten())
+	v, err := datagen.JSONSchemaGen(b, &datagen.GenOption{DatagenKey: "x-apicat-mock"})
+	if err != nil {
+		return ""
+	}
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func exampleValue(s *spec.Schema) string {
+	switch {
+	case s.Example != nil:
+		return fmt.Sprint(s.Example)
+	case s.Schema != nil && s.Schema.Example != nil:
+		return fmt.Sprint(s.Schema.Example)
+	case s.Schema != nil && s.Schema.Default != nil:
+		return fmt.Sprint(s.Schema.Default)
+	}
+	return ""
+}
+
+// quote 转为双引号包裹的字符串 json的转义在go python和javascript中都可以使用
+func quote(s string) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// javaQuote 转为java的字符串
+// 不可打印的字符使用\uXXXX 换行和回车必须使用\n \r 因为java会在编译前先替换\uXXXX
+func javaQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if r == ' ' || (r != utf8.RuneError && unicode.IsPrint(r)) {
+				b.WriteRune(r)
+				continue
+			}
+			if r > 0xffff {
+				r1, r2 := utf16.EncodeRune(r)
+				fmt.Fprintf(&b, `\u%04x\u%04x`, r1, r2)
+				continue
+			}
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// indent 除第一行以外的行添加缩进
+func indent(s, prefix string) string {
+	return strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
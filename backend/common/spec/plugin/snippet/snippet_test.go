@@ -0,0 +1,125 @@
+package snippet
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+func stringSchema(name string, example any) *spec.Schema {
+	sh := jsonschema.Create("string")
+	sh.Example = example
+	return &spec.Schema{Name: name, Schema: sh}
+}
+
+func testPart(content spec.HTTPBody) *spec.HTTPPart {
+	part := &spec.HTTPPart{}
+	part.GlobalExcepts = map[string][]int64{"header": {2}}
+	part.Parameters.Fill()
+	part.Parameters.Path = spec.Schemas{stringSchema("id", 42)}
+	part.Parameters.Query = spec.Schemas{stringSchema("q", "a b")}
+	part.Parameters.Header = spec.Schemas{stringSchema("X-Trace", "t1")}
+	part.Content = content
+	part.Security = []spec.SecurityRequirement{{"token": nil}}
+	return part
+}
+
+func testOption() Option {
+	lang := stringSchema("Accept-Language", "zh")
+	lang.ID = 1
+	excepted := stringSchema("X-Excepted", "no")
+	excepted.ID = 2
+	return Option{
+		ServerURL: "https://api.example.com/v1/",
+		Globals: spec.HTTPParameters{
+			Header: spec.Schemas{lang, excepted},
+		},
+		Securities: spec.SecuritySchemes{{Name: "token", Type: spec.SecurityTypeHTTP, Scheme: "bearer"}},
+	}
+}
+
+func TestGenerateJSON(t *testing.T) {
+	obj := jsonschema.Create("object")
+	obj.Properties = map[string]*jsonschema.Schema{"name": jsonschema.Create("string")}
+	part := testPart(spec.HTTPBody{
+		"application/json": &spec.Schema{Schema: obj},
+		"text/plain":       &spec.Schema{Schema: jsonschema.Create("string")},
+	})
+
+	snippets := GenerateAll("put", "/users/{id}", part, testOption())
+	if len(snippets) != len(Languages) {
+		t.Fatalf("unexpected snippets %d", len(snippets))
+	}
+	for _, s := range snippets {
+		for _, want := range []string{"https://api.example.com/v1/users/42?q=a+b", "Accept-Language", "X-Trace", "Bearer YOUR_TOKEN", "application/json", "name"} {
+			if !strings.Contains(s.Code, want) {
+				t.Errorf("%s snippet missing %q:\n%s", s.Language, want, s.Code)
+			}
+		}
+		if strings.Contains(s.Code, "X-Excepted") {
+			t.Errorf("%s snippet contains excepted global parameter", s.Language)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", snippets[1].Code, 0); err != nil {
+		t.Errorf("invalid go snippet %v:\n%s", err, snippets[1].Code)
+	}
+	if _, err := Generate("ruby", "get", "/", part, testOption()); err == nil {
+		t.Error("unsupported language should fail")
+	}
+}
+
+func TestGenerateMultipart(t *testing.T) {
+	obj := jsonschema.Create("object")
+	obj.Properties = map[string]*jsonschema.Schema{
+		"title": {Type: jsonschema.CreateSliceOrOne("string"), Example: "it's"},
+		"file":  jsonschema.Create("file"),
+	}
+	obj.XOrder = []string{"title", "file"}
+	part := testPart(spec.HTTPBody{"multipart/form-data": &spec.Schema{Schema: obj}})
+
+	code, err := Generate("curl", "post", "/upload", part, testOption())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(code, `-F 'title=it'\''s'`) || !strings.Contains(code, "-F 'file=@/path/to/file'") {
+		t.Errorf("unexpected curl snippet:\n%s", code)
+	}
+	code, _ = Generate("go", "post", "/upload", part, testOption())
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", code, 0); err != nil {
+		t.Errorf("invalid go snippet %v:\n%s", err, code)
+	}
+	code, _ = Generate("python", "post", "/upload", part, testOption())
+	if !strings.Contains(code, `"file": open("/path/to/file", "rb")`) {
+		t.Errorf("unexpected python snippet:\n%s", code)
+	}
+}
+
+func TestGenerateJavaEmptyBody(t *testing.T) {
+	code, _ := Generate("java", "post", "/users", testPart(nil), testOption())
+	if !strings.Contains(code, `.method("POST", RequestBody.create(new byte[0], null))`) {
+		t.Errorf("unexpected java snippet:\n%s", code)
+	}
+	code, _ = Generate("java", "get", "/users", testPart(nil), testOption())
+	if !strings.Contains(code, `.method("GET", null)`) {
+		t.Errorf("unexpected java snippet:\n%s", code)
+	}
+}
+
+func TestJavaQuote(t *testing.T) {
+	for s, want := range map[string]string{
+		"a\"b\\c":        `"a\"b\\c"`,
+		"line\nnext\r\t": `"line\nnext\r\t"`,
+		"\a\v\x00\x7f":   `"\u0007\u000b\u0000\u007f"`,
+		"中文 \u2028":      `"中文 \u2028"`,
+		"\U000e0001":     `"\udb40\udc01"`,
+		"\xff":           `"\ufffd"`,
+	} {
+		if got := javaQuote(s); got != want {
+			t.Errorf("%q: expected %s, got %s", s, want, got)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package refs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 每种节点在dot中的形状
+var dotShapes = map[string]string{
+	KindCollection: "box",
+	KindSchema:     "ellipse",
+	KindResponse:   "note",
+	KindParameter:  "hexagon",
+}
+
+// DOT 使用graphviz的dot格式输出引用关系
+// 相同的两个节点之间只输出一条边 边的标签为所有引用位置的部分和名称
+// 已经不存在的定义使用虚线
+func (g *Graph) DOT(name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(name))
+	b.WriteString("  rankdir=LR;\n")
+	for _, n := range g.Nodes {
+		label := n.Title
+		if label == "" {
+			label = n.Key
+		}
+		attrs := fmt.Sprintf("label=%s, shape=%s", dotQuote(label), dotShapes[n.Kind])
+		if n.Missing {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.Key), attrs)
+	}
+
+	type pair struct{ from, to string }
+	labels := make(map[pair][]string)
+	order := make([]pair, 0)
+	for _, e := range g.Edges {
+		p := pair{e.From, e.To}
+		if _, ok := labels[p]; !ok {
+			order = append(order, p)
+		}
+		label := strings.TrimSpace(e.Section + " " + e.Name)
+		if !contains(labels[p], label) {
+			labels[p] = append(labels[p], label)
+		}
+	}
+	for _, p := range order {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(p.from), dotQuote(p.to), dotQuote(strings.Join(labels[p], "\n")))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotQuote 转为dot的字符串 换行使用\n
+func dotQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+	return `"` + s + `"`
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
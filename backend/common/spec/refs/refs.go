@@ -0,0 +1,281 @@
+package refs
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+// 引用关系图中节点的类型
+const (
+	KindCollection = "collection"
+	KindSchema     = "schema"
+	KindResponse   = "response"
+	KindParameter  = "parameter"
+)
+
+// 引用所在的部分 其它集合节点中的引用使用节点的类型 例如graphql-variables
+const (
+	SectionParameter = "parameter"
+	SectionBody      = "body"
+	SectionResponse  = "response"
+	SectionHeader    = "header"
+	SectionSchema    = "schema"
+)
+
+// Node 集合或公共定义
+type Node struct {
+	// Key 节点的唯一标识 格式为kind:id
+	Key   string `json:"key"`
+	Kind  string `json:"kind"`
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+	// Type 集合的类型 http graphql等
+	Type string `json:"type,omitempty"`
+	// Missing 被引用的定义已经不存在
+	Missing bool `json:"missing,omitempty"`
+}
+
+// Location 引用在集合或公共定义中的位置
+type Location struct {
+	Section string `json:"section"`
+	// Name 参数名称 请求体或响应体的mime 响应的状态码 响应头名称
+	Name string `json:"name,omitempty"`
+	// Path 引用在集合节点的attrs或公共定义中的jsonpath
+	Path string `json:"path"`
+}
+
+// Edge From中引用了To 每一处引用都是一条边
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Location
+}
+
+// Graph 项目中集合和公共定义的引用关系
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+	nodes map[string]*Node
+}
+
+func key(kind string, id int64) string {
+	return kind + ":" + strconv.FormatInt(id, 10)
+}
+
+var refRegexp = regexp.MustCompile(`^#/definitions/(schemas|responses|parameters)/(\d+)$`)
+
+var refKinds = map[string]string{
+	"schemas":    KindSchema,
+	"responses":  KindResponse,
+	"parameters": KindParameter,
+}
+
+// parseRef 返回引用的定义的类型和id 不是公共定义的引用时返回false
+func parseRef(ref string) (string, int64, bool) {
+	m := refRegexp.FindStringSubmatch(ref)
+	if m == nil {
+		return "", 0, false
+	}
+	id, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return refKinds[m[1]], id, true
+}
+
+func (g *Graph) addNode(n *Node) *Node {
+	n.Key = key(n.Kind, n.ID)
+	if v, ok := g.nodes[n.Key]; ok {
+		return v
+	}
+	g.nodes[n.Key] = n
+	g.Nodes = append(g.Nodes, n)
+	return n
+}
+
+// Lookup 查找节点 不存在时返回nil
+func (g *Graph) Lookup(kind string, id int64) *Node {
+	return g.nodes[key(kind, id)]
+}
+
+// Build 解析项目中所有集合和公共定义中的引用
+// 集合按照在目录树中的顺序 公共定义按照模型 响应 参数的顺序
+func Build(s *spec.Spec) *Graph {
+	g := &Graph{
+		Nodes: make([]*Node, 0),
+		Edges: make([]*Edge, 0),
+		nodes: make(map[string]*Node),
+	}
+	for _, v := range s.Definitions.Schemas {
+		g.addNode(&Node{Kind: KindSchema, ID: v.ID, Title: v.Name})
+	}
+	for _, v := range s.Definitions.Responses {
+		g.addNode(&Node{Kind: KindResponse, ID: v.ID, Title: v.Name})
+	}
+	for _, v := range s.Definitions.Parameters {
+		g.addNode(&Node{Kind: KindParameter, ID: v.ID, Title: v.Name})
+	}
+	g.collections(s.Collections)
+
+	for _, v := range s.Definitions.Schemas {
+		from := key(KindSchema, v.ID)
+		walk("$", toAny(v.Schema), g.add(from, SectionSchema, ""))
+	}
+	for _, v := range s.Definitions.Responses {
+		g.response(key(KindResponse, v.ID), "$", toMap(v))
+	}
+	for _, v := range s.Definitions.Parameters {
+		from := key(KindParameter, v.ID)
+		walk("$.schema", toAny(v.Schema), g.add(from, SectionParameter, v.Name))
+	}
+	return g
+}
+
+func (g *Graph) collections(items []*spec.CollectItem) {
+	for _, item := range items {
+		if item.Type == spec.ContentItemTypeDir {
+			g.collections(item.Items)
+			continue
+		}
+		n := g.addNode(&Node{Kind: KindCollection, ID: item.ID, Title: item.Title, Type: string(item.Type)})
+		for _, v := range item.Content {
+			node := toMap(v)
+			typ, _ := node["type"].(string)
+			attrs, _ := node["attrs"].(map[string]any)
+			switch typ {
+			case "apicat-http-request":
+				g.request(n.Key, attrs)
+			case "apicat-http-response":
+				list, _ := attrs["list"].([]any)
+				for i, res := range list {
+					if m, ok := res.(map[string]any); ok {
+						g.response(n.Key, index("$.list", i), m)
+					}
+				}
+			default:
+				if strings.HasPrefix(typ, "apicat-") {
+					walk("$", attrs, g.add(n.Key, strings.TrimPrefix(typ, "apicat-"), ""))
+				}
+			}
+		}
+	}
+}
+
+// request 请求参数和请求体中的引用
+func (g *Graph) request(from string, attrs map[string]any) {
+	params, _ := attrs["parameters"].(map[string]any)
+	for _, in := range []string{"path", "query", "header", "cookie"} {
+		list, _ := params[in].([]any)
+		for i, v := range list {
+			p, _ := v.(map[string]any)
+			name, _ := p["name"].(string)
+			walk(index(child("$.parameters", in), i), p, g.add(from, SectionParameter, name))
+		}
+	}
+	content, _ := attrs["content"].(map[string]any)
+	for _, mime := range sortedKeys(content) {
+		walk(child("$.content", mime), content[mime], g.add(from, SectionBody, mime))
+	}
+}
+
+// response 集合中的响应或公共响应中的引用
+// 集合中的响应使用状态码作为名称 响应头和响应体使用各自的名称
+func (g *Graph) response(from, path string, res map[string]any) {
+	name := ""
+	if code, ok := res["code"].(float64); ok {
+		name = strconv.Itoa(int(code))
+	}
+	if ref, ok := res["$ref"].(string); ok {
+		g.add(from, SectionResponse, name)(path, ref)
+	}
+	headers, _ := res["header"].([]any)
+	for i, v := range headers {
+		h, _ := v.(map[string]any)
+		hname, _ := h["name"].(string)
+		walk(index(child(path, "header"), i), h, g.add(from, SectionHeader, hname))
+	}
+	content, _ := res["content"].(map[string]any)
+	for _, mime := range sortedKeys(content) {
+		section, sname := SectionBody, mime
+		if name != "" {
+			section, sname = SectionResponse, name
+		}
+		walk(child(child(path, "content"), mime), content[mime], g.add(from, section, sname))
+	}
+}
+
+// add 返回记录引用的函数 被引用的定义不存在时添加missing节点
+func (g *Graph) add(from, section, name string) func(path, ref string) {
+	return func(path, ref string) {
+		kind, id, ok := parseRef(ref)
+		if !ok {
+			return
+		}
+		to := g.nodes[key(kind, id)]
+		if to == nil {
+			to = g.addNode(&Node{Kind: kind, ID: id, Missing: true})
+		}
+		g.Edges = append(g.Edges, &Edge{
+			From:     from,
+			To:       to.Key,
+			Location: Location{Section: section, Name: name, Path: path},
+		})
+	}
+}
+
+// walk 查找json中所有的$ref 对象的属性按照名称排序
+func walk(path string, v any, fn func(path, ref string)) {
+	switch x := v.(type) {
+	case map[string]any:
+		if ref, ok := x["$ref"].(string); ok {
+			fn(path, ref)
+		}
+		for _, k := range sortedKeys(x) {
+			walk(child(path, k), x[k], fn)
+		}
+	case []any:
+		for i, item := range x {
+			walk(index(path, i), item, fn)
+		}
+	}
+}
+
+func toAny(v any) any {
+	b, _ := json.Marshal(v)
+	var x any
+	json.Unmarshal(b, &x)
+	return x
+}
+
+func toMap(v any) map[string]any {
+	m, _ := toAny(v).(map[string]any)
+	return m
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var identRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// child 对象属性的路径 属性名不是标识符时使用['name']
+func child(path, key string) string {
+	if identRegexp.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "['" + key + "']"
+}
+
+func index(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
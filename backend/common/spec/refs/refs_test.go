@@ -0,0 +1,120 @@
+package refs
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+func loadGraph(t *testing.T) *Graph {
+	raw, err := os.ReadFile("../testdata/refs.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := spec.ParseJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Build(s)
+}
+
+func TestBuild(t *testing.T) {
+	g := loadGraph(t)
+	if len(g.Nodes) != 9 {
+		t.Errorf("nodes: %d", len(g.Nodes))
+	}
+	missing := g.Lookup(KindSchema, 9)
+	if missing == nil || !missing.Missing {
+		t.Errorf("schema 9 should be missing: %+v", missing)
+	}
+
+	got := make([]string, 0)
+	for _, e := range g.Edges {
+		got = append(got, e.From+" "+e.To+" "+e.Section+" "+e.Name+" "+e.Path)
+	}
+	want := []string{
+		"collection:10 schema:1 parameter near $.parameters.query[0].schema",
+		"collection:10 parameter:30 parameter  $.parameters.header[0]",
+		"collection:10 response:20 response 200 $.list[0]",
+		"collection:10 schema:9 response 404 $.list[1].content['application/json'].schema",
+		"collection:11 schema:3 body application/json $.content['application/json'].schema",
+		"collection:11 schema:3 response 201 $.list[0].content['application/json'].schema.properties.data",
+		"collection:12 schema:1 graphql-variables  $.schema.properties.where",
+		"schema:2 schema:1 schema  $.properties.address",
+		"schema:2 schema:2 schema  $.properties.friends.items",
+		"schema:3 schema:2 schema  $.properties.owner",
+		"response:20 schema:2 body application/json $.content['application/json'].schema",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("edges:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestUsages(t *testing.T) {
+	g := loadGraph(t)
+	if g.Usages(KindSchema, 100) != nil {
+		t.Error("usages of unknown schema should be nil")
+	}
+
+	type usage struct {
+		key    string
+		direct bool
+		via    string
+		paths  string
+	}
+	got := make([]usage, 0)
+	for _, u := range g.Usages(KindSchema, 1) {
+		via := make([]string, 0)
+		for _, v := range u.Via {
+			via = append(via, v.Title)
+		}
+		paths := make([]string, 0)
+		for _, l := range u.Locations {
+			paths = append(paths, l.Section+":"+l.Path)
+		}
+		got = append(got, usage{u.Key, u.Direct, strings.Join(via, ","), strings.Join(paths, ",")})
+	}
+	want := []usage{
+		{"schema:2", true, "", "schema:$.properties.address"},
+		{"collection:10", true, "", "parameter:$.parameters.query[0].schema"},
+		{"collection:12", true, "", "graphql-variables:$.schema.properties.where"},
+		{"schema:3", false, "User", "schema:$.properties.owner"},
+		{"response:20", false, "User", "body:$.content['application/json'].schema"},
+		{"collection:11", false, "Order,User", "body:$.content['application/json'].schema,response:$.list[0].content['application/json'].schema.properties.data"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("usages:\n%+v", got)
+	}
+
+	// 自身的循环引用不作为使用
+	for _, u := range g.Usages(KindSchema, 2) {
+		if u.Key == "schema:2" {
+			t.Error("schema 2 should not use itself")
+		}
+	}
+	if u := g.Usages(KindResponse, 20); len(u) != 1 || u[0].Key != "collection:10" || u[0].Locations[0].Name != "200" {
+		t.Errorf("usages of response 20: %+v", u)
+	}
+}
+
+func TestDOT(t *testing.T) {
+	dot := loadGraph(t).DOT("refs")
+	for _, want := range []string{
+		"digraph \"refs\" {\n  rankdir=LR;\n",
+		`  "schema:1" [label="Address", shape=ellipse];`,
+		`  "collection:10" [label="Get user", shape=box];`,
+		`  "schema:9" [label="schema:9", shape=ellipse, style=dashed];`,
+		`  "collection:11" -> "schema:3" [label="body application/json\nresponse 201"];`,
+		`  "schema:2" -> "schema:2" [label="schema"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot does not contain %q:\n%s", want, dot)
+		}
+	}
+	if n := strings.Count(dot, "->"); n != 10 {
+		t.Errorf("edges in dot: %d", n)
+	}
+}
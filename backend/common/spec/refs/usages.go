@@ -0,0 +1,66 @@
+package refs
+
+import "sort"
+
+// Usage 引用了指定定义的集合或公共定义
+type Usage struct {
+	*Node
+	// Direct 为false时通过Via中的定义间接引用
+	Direct bool `json:"direct"`
+	// Via 间接引用经过的定义 从这个节点直接引用的定义开始 不包含被查找的定义
+	Via []*Node `json:"via"`
+	// Locations 这个节点中引用Via中第一个定义或被查找定义的位置
+	Locations []Location `json:"locations"`
+}
+
+// Usages 查找直接和间接引用了定义的集合和公共定义 定义不存在时返回nil
+// 按照引用的层级排序 同一层级按照节点的顺序 每个节点只出现一次 使用最短的引用路径
+func (g *Graph) Usages(kind string, id int64) []*Usage {
+	target := g.Lookup(kind, id)
+	if target == nil {
+		return nil
+	}
+
+	// 被引用的节点 => 引用它的边
+	incoming := make(map[string][]*Edge)
+	for _, e := range g.Edges {
+		incoming[e.To] = append(incoming[e.To], e)
+	}
+	order := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		order[n.Key] = i
+	}
+
+	// parent 节点在最短路径中引用的下一个节点
+	parent := map[string]string{target.Key: ""}
+	list := make([]*Usage, 0)
+	level := []string{target.Key}
+	for len(level) > 0 {
+		next := make([]string, 0)
+		for _, to := range level {
+			for _, e := range incoming[to] {
+				if _, ok := parent[e.From]; ok {
+					continue
+				}
+				parent[e.From] = to
+				next = append(next, e.From)
+			}
+		}
+		sort.Slice(next, func(i, j int) bool { return order[next[i]] < order[next[j]] })
+		for _, from := range next {
+			u := &Usage{Node: g.nodes[from], Via: make([]*Node, 0), Locations: make([]Location, 0)}
+			for k := parent[from]; k != target.Key; k = parent[k] {
+				u.Via = append(u.Via, g.nodes[k])
+			}
+			u.Direct = len(u.Via) == 0
+			for _, e := range incoming[parent[from]] {
+				if e.From == from {
+					u.Locations = append(u.Locations, e.Location)
+				}
+			}
+			list = append(list, u)
+		}
+		level = next
+	}
+	return list
+}
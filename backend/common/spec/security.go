@@ -0,0 +1,111 @@
+package spec
+
+import (
+	"net/http"
+	"strings"
+)
+
+// 认证方式的类型 和openapi 3的securitySchemes一致
+const (
+	SecurityTypeAPIKey        = "apiKey"
+	SecurityTypeHTTP          = "http"
+	SecurityTypeOAuth2        = "oauth2"
+	SecurityTypeOpenIDConnect = "openIdConnect"
+)
+
+// SecurityScheme 项目中定义的认证方式
+type SecurityScheme struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	// apiKey 参数的位置 header query cookie 和参数名
+	In  string `json:"in,omitempty"`
+	Key string `json:"key,omitempty"`
+	// http 的认证方式 basic bearer
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	// oauth2
+	Flows *OAuthFlows `json:"flows,omitempty"`
+	// openIdConnect
+	OpenIDConnectURL string `json:"openIdConnectUrl,omitempty"`
+}
+
+type OAuthFlows struct {
+	Implicit          *OAuthFlow `json:"implicit,omitempty"`
+	Password          *OAuthFlow `json:"password,omitempty"`
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+}
+
+type OAuthFlow struct {
+	AuthorizationURL string            `json:"authorizationUrl,omitempty"`
+	TokenURL         string            `json:"tokenUrl,omitempty"`
+	RefreshURL       string            `json:"refreshUrl,omitempty"`
+	Scopes           map[string]string `json:"scopes,omitempty"`
+}
+
+type SecuritySchemes []*SecurityScheme
+
+func (s SecuritySchemes) Lookup(name string) *SecurityScheme {
+	for _, v := range s {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// SecurityRequirement 需要同时满足的认证方式 key为认证方式名称 value为oauth2的scopes
+// 接口的多个SecurityRequirement满足其中一个即可
+type SecurityRequirement map[string][]string
+
+// CredentialPresent 请求中是否带有该认证方式需要的凭证 只检查是否存在 不校验凭证的值
+func (s *SecurityScheme) CredentialPresent(r *http.Request) bool {
+	switch s.Type {
+	case SecurityTypeAPIKey:
+		switch s.In {
+		case "query":
+			return r.URL.Query().Get(s.Key) != ""
+		case "cookie":
+			c, err := r.Cookie(s.Key)
+			return err == nil && c.Value != ""
+		}
+		return r.Header.Get(s.Key) != ""
+	case SecurityTypeHTTP:
+		scheme := s.Scheme
+		if scheme == "" {
+			scheme = "bearer"
+		}
+		return authorizationScheme(r, scheme)
+	case SecurityTypeOAuth2, SecurityTypeOpenIDConnect:
+		return authorizationScheme(r, "bearer")
+	}
+	return true
+}
+
+func authorizationScheme(r *http.Request, scheme string) bool {
+	v := strings.TrimSpace(r.Header.Get("Authorization"))
+	prefix, credential, ok := strings.Cut(v, " ")
+	return ok && strings.EqualFold(prefix, scheme) && strings.TrimSpace(credential) != ""
+}
+
+// Satisfied 请求是否满足接口的认证要求 没有认证要求时总是满足
+// 要求中引用了未定义的认证方式时该要求无法满足
+func (s SecuritySchemes) Satisfied(r *http.Request, requirements []SecurityRequirement) bool {
+	if len(requirements) == 0 {
+		return true
+	}
+	for _, req := range requirements {
+		ok := true
+		for name := range req {
+			if scheme := s.Lookup(name); scheme == nil || !scheme.CredentialPresent(r) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package spec
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecuritySatisfied(t *testing.T) {
+	schemes := SecuritySchemes{
+		{Name: "bearerAuth", Type: SecurityTypeHTTP, Scheme: "bearer"},
+		{Name: "basicAuth", Type: SecurityTypeHTTP, Scheme: "basic"},
+		{Name: "apiKey", Type: SecurityTypeAPIKey, In: "query", Key: "api_key"},
+		{Name: "tenant", Type: SecurityTypeAPIKey, In: "header", Key: "X-Tenant"},
+	}
+	requirements := []SecurityRequirement{
+		{"bearerAuth": {}},
+		{"apiKey": {}, "tenant": {}},
+	}
+
+	for _, v := range []struct {
+		url     string
+		headers map[string]string
+		want    bool
+	}{
+		{"/pets", nil, false},
+		{"/pets", map[string]string{"Authorization": "Bearer abc"}, true},
+		{"/pets", map[string]string{"Authorization": "Basic abc"}, false},
+		{"/pets", map[string]string{"Authorization": "Bearer "}, false},
+		{"/pets?api_key=1", nil, false},
+		{"/pets?api_key=1", map[string]string{"X-Tenant": "t1"}, true},
+	} {
+		r := httptest.NewRequest("GET", v.url, nil)
+		for k, x := range v.headers {
+			r.Header.Set(k, x)
+		}
+		if got := schemes.Satisfied(r, requirements); got != v.want {
+			t.Errorf("%s %v: expected %v got %v", v.url, v.headers, v.want, got)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/pets", nil)
+	if !schemes.Satisfied(r, nil) {
+		t.Error("no requirements should always be satisfied")
+	}
+	r.Header.Set("Authorization", "Bearer t")
+	if schemes.Satisfied(r, []SecurityRequirement{{"unknown": {}}}) || schemes.Satisfied(r, []SecurityRequirement{{"bearerAuth": {}, "unknown": {}}}) {
+		t.Error("undefined scheme should not be satisfied")
+	}
+	if !schemes.Satisfied(r, []SecurityRequirement{{"unknown": {}}, {"bearerAuth": {}}}) {
+		t.Error("other requirement should be satisfied")
+	}
+}
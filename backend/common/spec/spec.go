@@ -13,9 +13,14 @@ import (
 type ContentType string
 
 const (
-	ContentItemTypeDir  ContentType = "category"
-	ContentItemTypeHttp             = "http"
-	ContentItemTypeDoc              = "doc"
+	ContentItemTypeDir      ContentType = "category"
+	ContentItemTypeHttp                 = "http"
+	ContentItemTypeDoc                  = "doc"
+	ContentItemTypeWebhook              = "webhook"
+	ContentItemTypeCallback             = "callback"
+	ContentItemTypeGraphQL              = "graphql"
+	ContentItemTypeChannel              = "channel"
+	ContentItemTypeGRPC                 = "grpc"
 )
 
 func init() {
@@ -23,17 +28,30 @@ func init() {
 	RegisterNode(WarpHTTPNode(HTTPURLNode{}))
 	RegisterNode(WarpHTTPNode(HTTPRequestNode{}))
 	RegisterNode(WarpHTTPNode(HTTPResponsesNode{}))
+	RegisterNode(WarpHTTPNode(HTTPScriptNode{}))
+	RegisterNode(WarpHTTPNode(HTTPWebhookNode{}))
+	RegisterNode(WarpHTTPNode(HTTPCallbackNode{}))
+	RegisterNode(WarpHTTPNode(GraphQLOperationNode{}))
+	RegisterNode(WarpHTTPNode(GraphQLQueryNode{}))
+	RegisterNode(WarpHTTPNode(GraphQLVariablesNode{}))
+	RegisterNode(WarpHTTPNode(GraphQLResponseNode{}))
+	RegisterNode(WarpHTTPNode(ChannelNode{}))
+	RegisterNode(WarpHTTPNode(ChannelOperationNode{}))
+	RegisterNode(WarpHTTPNode(GRPCMethodNode{}))
+	RegisterNode(WarpHTTPNode(GRPCRequestNode{}))
+	RegisterNode(WarpHTTPNode(GRPCResponseNode{}))
 }
 
 // Spec 是apicat的协议的整体结构
 type Spec struct {
 	// spec schema版本 当前固定2.0
-	ApiCat      string         `json:"apicat"`
-	Info        *Info          `json:"info"`
-	Servers     []*Server      `json:"servers"`
-	Globals     Global         `json:"globals"`
-	Definitions Definitions    `json:"definitions"`
-	Collections []*CollectItem `json:"collections"`
+	ApiCat       string         `json:"apicat"`
+	Info         *Info          `json:"info"`
+	Servers      []*Server      `json:"servers"`
+	Environments []*Environment `json:"environments,omitempty"`
+	Globals      Global         `json:"globals"`
+	Definitions  Definitions    `json:"definitions"`
+	Collections  []*CollectItem `json:"collections"`
 }
 
 // WalkCollections 遍历集合
@@ -91,6 +109,11 @@ func (s *Spec) expendRef(v Referencer, max int, parentRef ...string) {
 			s.expendRef(&v, max, parentRef...)
 			x.Items.SetValue(&v)
 		}
+		for _, list := range [][]*jsonschema.Schema{x.AllOf, x.AnyOf, x.OneOf} {
+			for _, v := range list {
+				s.expendRef(v, max, parentRef...)
+			}
+		}
 	case *Schema:
 		if x.Ref() {
 			ps := strings.Split(*x.Reference, "/")
@@ -140,53 +163,98 @@ func (s *Spec) CollectionsMap(expend bool, refexpendMaxCount int) map[string]map
 			method string
 			path   string
 		)
-		part := HTTPPart{
-			Title: v.Title,
-			ID:    v.ID,
-			Dir:   strings.Join(p, "/"),
-		}
 		for _, item := range v.Content {
-			switch nx := item.Node.(type) {
-			case *HTTPNode[HTTPURLNode]:
+			if nx, ok := item.Node.(*HTTPNode[HTTPURLNode]); ok {
 				method, path = nx.Attrs.Method, nx.Attrs.Path
-			case *HTTPNode[HTTPRequestNode]:
-				nx.Attrs.Parameters.Fill()
-				if expend {
-					mp := nx.Attrs.Parameters.Map()
-					for _, v := range mp {
-						for k := range v {
-							s.expendRef(v[k], refexpendMaxCount)
-						}
-					}
-					if nx.Attrs.Content != nil {
-						for k := range nx.Attrs.Content {
-							s.expendRef(nx.Attrs.Content[k], refexpendMaxCount)
-						}
-					}
-				}
-				part.HTTPRequestNode = nx.Attrs
-			case *HTTPNode[HTTPResponsesNode]:
-				res := nx.Attrs.List
-				if expend {
-					for i, v := range res {
-						s.expendRef(&v.HTTPResponseDefine, refexpendMaxCount)
-						res[i] = v
-					}
-				}
-				part.Responses = res
 			}
 		}
 		subs, ok := paths[path]
 		if !ok {
 			subs = make(map[string]HTTPPart)
 		}
-		subs[method] = part
+		subs[method] = s.httpPart(v, p, expend, refexpendMaxCount)
 		paths[path] = subs
 		return true
 	})
 	return paths
 }
 
+// EventPart webhook或callback的定义
+type EventPart struct {
+	Type     ContentType
+	Webhook  *HTTPWebhookNode
+	Callback *HTTPCallbackNode
+	HTTPPart
+}
+
+// Events 按照集合的顺序返回所有的webhook和callback
+func (s *Spec) Events(expend bool, refexpendMaxCount int) []EventPart {
+	events := make([]EventPart, 0)
+	s.WalkCollections(func(v *CollectItem, p []string) bool {
+		if v.Type != ContentItemTypeWebhook && v.Type != ContentItemTypeCallback {
+			return true
+		}
+		event := EventPart{
+			Type:     v.Type,
+			HTTPPart: s.httpPart(v, p, expend, refexpendMaxCount),
+		}
+		for _, item := range v.Content {
+			switch nx := item.Node.(type) {
+			case *HTTPNode[HTTPWebhookNode]:
+				event.Webhook = &nx.Attrs
+			case *HTTPNode[HTTPCallbackNode]:
+				event.Callback = &nx.Attrs
+			}
+		}
+		if event.Webhook != nil || event.Callback != nil {
+			events = append(events, event)
+		}
+		return true
+	})
+	return events
+}
+
+// httpPart 提取集合中的请求 响应和脚本
+func (s *Spec) httpPart(v *CollectItem, p []string, expend bool, refexpendMaxCount int) HTTPPart {
+	part := HTTPPart{
+		Title: v.Title,
+		ID:    v.ID,
+		Dir:   strings.Join(p, "/"),
+	}
+	for _, item := range v.Content {
+		switch nx := item.Node.(type) {
+		case *HTTPNode[HTTPRequestNode]:
+			nx.Attrs.Parameters.Fill()
+			if expend {
+				mp := nx.Attrs.Parameters.Map()
+				for _, v := range mp {
+					for k := range v {
+						s.expendRef(v[k], refexpendMaxCount)
+					}
+				}
+				if nx.Attrs.Content != nil {
+					for k := range nx.Attrs.Content {
+						s.expendRef(nx.Attrs.Content[k], refexpendMaxCount)
+					}
+				}
+			}
+			part.HTTPRequestNode = nx.Attrs
+		case *HTTPNode[HTTPResponsesNode]:
+			res := nx.Attrs.List
+			if expend {
+				for i, v := range res {
+					s.expendRef(&v.HTTPResponseDefine, refexpendMaxCount)
+					res[i] = v
+				}
+			}
+			part.Responses = res
+		case *HTTPNode[HTTPScriptNode]:
+			part.Script = nx.Attrs
+		}
+	}
+	return part
+}
+
 func (s *Spec) Valid() error {
 	return nil
 }
@@ -280,6 +348,20 @@ type Definitions struct {
 	Schemas    Schemas             `json:"schemas"`
 	Parameters Schemas             `json:"parameters"`
 	Responses  HTTPResponseDefines `json:"responses"`
+	Securities SecuritySchemes     `json:"securities,omitempty"`
+}
+
+// SchemaResolver 返回解析 #/definitions/schemas/{id} 引用的函数 用于jsonschema校验
+func (d *Definitions) SchemaResolver() func(ref string) *jsonschema.Schema {
+	return func(ref string) *jsonschema.Schema {
+		if !strings.HasPrefix(ref, "#/definitions/schemas/") {
+			return nil
+		}
+		if v := d.Schemas.LookupID(mustGetRefID(ref)); v != nil {
+			return v.Schema
+		}
+		return nil
+	}
 }
 
 func mustGetRefID(v string) int64 {
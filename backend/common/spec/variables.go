@@ -0,0 +1,153 @@
+package spec
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/apicat/apicat/backend/common/spec/jsonschema"
+)
+
+var variableRegexp = regexp.MustCompile(`{{\s*([\w.\-]+)\s*}}`)
+
+// Environment 项目的环境 如开发 测试 生产
+type Environment struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Variables   []*Variable `json:"variables"`
+}
+
+// Variable 环境变量 在文档中使用 {{name}} 引用
+// Secret 为true时导出和展示时不包含值
+type Variable struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Secret      bool   `json:"secret,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Map 返回变量名和值 withSecret为false时忽略私密变量
+func (e *Environment) Map(withSecret bool) map[string]string {
+	m := make(map[string]string, len(e.Variables))
+	for _, v := range e.Variables {
+		if v.Secret && !withSecret {
+			continue
+		}
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+// ReplaceVariables 替换字符串中的 {{name}} 不存在的变量保持原样
+func ReplaceVariables(s string, vars map[string]string) string {
+	if len(vars) == 0 || !strings.Contains(s, "{{") {
+		return s
+	}
+	return variableRegexp.ReplaceAllStringFunc(s, func(m string) string {
+		name := variableRegexp.FindStringSubmatch(m)[1]
+		if x, ok := vars[name]; ok {
+			return x
+		}
+		return m
+	})
+}
+
+// ReplaceVariables 替换服务器地址 全局参数和所有示例值中的变量
+func (s *Spec) ReplaceVariables(vars map[string]string) {
+	if len(vars) == 0 {
+		return
+	}
+	r := variableReplacer(vars)
+	for _, v := range s.Servers {
+		v.URL = ReplaceVariables(v.URL, vars)
+	}
+	r.parameters(&s.Globals.Parameters)
+	r.schemas(s.Definitions.Parameters)
+	for _, v := range s.Definitions.Schemas {
+		r.jsonschema(v.Schema)
+	}
+	for i := range s.Definitions.Responses {
+		r.response(&s.Definitions.Responses[i])
+	}
+	s.WalkCollections(func(v *CollectItem, _ []string) bool {
+		for _, node := range v.Content {
+			switch nx := node.Node.(type) {
+			case *HTTPNode[HTTPRequestNode]:
+				r.parameters(&nx.Attrs.Parameters)
+				r.body(nx.Attrs.Content)
+			case *HTTPNode[HTTPResponsesNode]:
+				for i := range nx.Attrs.List {
+					r.response(&nx.Attrs.List[i].HTTPResponseDefine)
+				}
+			}
+		}
+		return true
+	})
+}
+
+type variableReplacer map[string]string
+
+func (r variableReplacer) value(v any) any {
+	switch x := v.(type) {
+	case string:
+		return ReplaceVariables(x, r)
+	case []any:
+		for i := range x {
+			x[i] = r.value(x[i])
+		}
+	case map[string]any:
+		for k := range x {
+			x[k] = r.value(x[k])
+		}
+	}
+	return v
+}
+
+func (r variableReplacer) parameters(p *HTTPParameters) {
+	for _, ps := range p.Map() {
+		r.schemas(ps)
+	}
+}
+
+func (r variableReplacer) schemas(list Schemas) {
+	for _, v := range list {
+		if v == nil {
+			continue
+		}
+		v.Example = r.value(v.Example)
+		for k, e := range v.Examples {
+			e.Value = r.value(e.Value)
+			v.Examples[k] = e
+		}
+		r.jsonschema(v.Schema)
+	}
+}
+
+func (r variableReplacer) body(b HTTPBody) {
+	for _, v := range b {
+		r.schemas(Schemas{v})
+	}
+}
+
+func (r variableReplacer) response(v *HTTPResponseDefine) {
+	r.schemas(v.Header)
+	r.body(v.Content)
+}
+
+func (r variableReplacer) jsonschema(s *jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	s.Example = r.value(s.Example)
+	s.Default = r.value(s.Default)
+	for _, v := range s.Properties {
+		r.jsonschema(v)
+	}
+	if s.Items != nil && !s.Items.IsBool() {
+		r.jsonschema(s.Items.Value())
+	}
+	for _, list := range [][]*jsonschema.Schema{s.AllOf, s.AnyOf, s.OneOf} {
+		for _, v := range list {
+			r.jsonschema(v)
+		}
+	}
+}
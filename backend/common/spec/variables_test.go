@@ -0,0 +1,48 @@
+package spec
+
+import (
+	"testing"
+)
+
+func TestReplaceVariables(t *testing.T) {
+	vars := map[string]string{"baseUrl": "https://api.example.com", "tenantId": "t1"}
+	if v := ReplaceVariables("{{ baseUrl }}/tenants/{{tenantId}}/{{unknown}}", vars); v != "https://api.example.com/tenants/t1/{{unknown}}" {
+		t.Errorf("unexpected %s", v)
+	}
+
+	raw := []byte(`{
+		"apicat": "2.0",
+		"info": {"title": "t", "version": "1.0.0"},
+		"servers": [{"url": "{{baseUrl}}/v1"}],
+		"globals": {"parameters": {"header": [{"id": 1, "name": "X-Tenant", "schema": {"type": "string", "example": "{{tenantId}}"}}]}},
+		"definitions": {"schemas": [], "parameters": [], "responses": []},
+		"collections": [{"id": 1, "title": "get", "type": "http", "content": [
+			{"type": "apicat-http-url", "attrs": {"path": "/users", "method": "get"}},
+			{"type": "apicat-http-request", "attrs": {"parameters": {"query": [{"name": "tenant", "example": "{{tenantId}}"}]},
+				"content": {"application/json": {"schema": {"type": "object", "example": {"tenant": ["{{tenantId}}"]}}}}}},
+			{"type": "apicat-http-response", "attrs": {"list": [{"code": 200, "content": {"application/json": {"schema": {"type": "object", "properties": {"url": {"type": "string", "default": "{{baseUrl}}"}}}}}}]}}
+		]}]
+	}`)
+	s, err := ParseJSON(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.ReplaceVariables(vars)
+
+	if s.Servers[0].URL != "https://api.example.com/v1" {
+		t.Errorf("server %s", s.Servers[0].URL)
+	}
+	if v := s.Globals.Parameters.Header[0].Schema.Example; v != "t1" {
+		t.Errorf("global parameter %v", v)
+	}
+	part := s.CollectionsMap(false, 1)["/users"]["get"]
+	if v := part.Parameters.Query[0].Example; v != "t1" {
+		t.Errorf("query %v", v)
+	}
+	if v := part.Content["application/json"].Schema.Example.(map[string]any)["tenant"].([]any)[0]; v != "t1" {
+		t.Errorf("body example %v", v)
+	}
+	if v := part.Responses[0].Content["application/json"].Schema.Properties["url"].Default; v != "https://api.example.com" {
+		t.Errorf("response default %v", v)
+	}
+}
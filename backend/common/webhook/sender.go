@@ -0,0 +1,162 @@
+package webhook
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// 保存的响应内容的最大长度
+const maxResponseSize = 1024
+
+// DefaultBackoff 失败后每次重试前等待的时间 共发送4次
+var DefaultBackoff = []time.Duration{10 * time.Second, time.Minute, 5 * time.Minute}
+
+// ErrAddressNotAllowed 请求地址解析到了内网 本机或链路本地地址
+var ErrAddressNotAllowed = errors.New("address is not allowed")
+
+// Attempt 一次发送的结果
+type Attempt struct {
+	// Number 从1开始
+	Number     int
+	StatusCode int
+	Response   string
+	Error      string
+	Duration   time.Duration
+	// Success 响应状态码为2xx
+	Success bool
+	// Retry 失败后是否还会重试
+	Retry bool
+}
+
+type Sender struct {
+	Client  *http.Client
+	Backoff []time.Duration
+}
+
+// NewSender allowLocal为false时拒绝连接内网 本机和链路本地地址
+// 在建立连接时检查解析后的地址 重定向和DNS重绑定同样会被拒绝 不使用环境变量中的代理
+func NewSender(allowLocal bool) *Sender {
+	transport := &http.Transport{
+		DialContext:         NewDialer(allowLocal).DialContext,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+	return &Sender{
+		Client:  &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		Backoff: DefaultBackoff,
+	}
+}
+
+// NewDialer allowLocal为false时在建立连接前拒绝内网 本机和链路本地地址
+// 其它需要请求用户填写的地址的地方也可以使用 如接口测试
+func NewDialer(allowLocal bool) *net.Dialer {
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if !allowLocal {
+		dialer.Control = denyLocalAddress
+	}
+	return dialer
+}
+
+func denyLocalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !allowedIP(ip) {
+		return ErrAddressNotAllowed
+	}
+	return nil
+}
+
+// 运营商级NAT使用的共享地址 rfc6598
+var sharedAddressSpace = &net.IPNet{IP: net.IP{100, 64, 0, 0}, Mask: net.CIDRMask(10, 32)}
+
+// allowedIP 是否允许向该地址发送webhook
+func allowedIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
+
+// Send 发送签名后的请求 网络错误 5xx和429时按照Backoff重试 其它状态码不再重试
+// 每次发送后调用report 返回最终是否成功 重试前在当前goroutine中等待
+func (s *Sender) Send(url, secret, event, delivery string, body []byte, report func(*Attempt)) bool {
+	for i := 1; ; i++ {
+		a := s.Deliver(url, secret, event, delivery, body, i)
+		if report != nil {
+			report(a)
+		}
+		if !a.Retry {
+			return a.Success
+		}
+		time.Sleep(s.Delay(i))
+	}
+}
+
+// Deliver 只发送一次 number为第几次发送 从1开始 失败后是否还需要重试由Attempt.Retry表示
+func (s *Sender) Deliver(url, secret, event, delivery string, body []byte, number int) *Attempt {
+	a := s.attempt(url, secret, event, delivery, body)
+	a.Number = number
+	a.Retry = !a.Success && retryable(a) && number <= len(s.Backoff)
+	return a
+}
+
+// Delay 第number次发送失败后到下次重试的等待时间
+func (s *Sender) Delay(number int) time.Duration {
+	if number < 1 || number > len(s.Backoff) {
+		return 0
+	}
+	return s.Backoff[number-1]
+}
+
+func (s *Sender) attempt(url, secret, event, delivery string, body []byte) *Attempt {
+	a := &Attempt{}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ApiCat-Webhook")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, delivery)
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, body))
+	}
+
+	start := time.Now()
+	resp, err := s.Client.Do(req)
+	a.Duration = time.Since(start)
+	if err != nil {
+		// 不返回解析出的内网地址
+		if errors.Is(err, ErrAddressNotAllowed) {
+			err = ErrAddressNotAllowed
+		}
+		a.Error = err.Error()
+		return a
+	}
+	defer resp.Body.Close()
+
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	a.StatusCode = resp.StatusCode
+	a.Response = string(b)
+	a.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	return a
+}
+
+// retryable 网络错误 服务端错误和限流时重试 不允许的地址不重试
+func retryable(a *Attempt) bool {
+	if a.Error == ErrAddressNotAllowed.Error() {
+		return false
+	}
+	return a.StatusCode == 0 || a.StatusCode >= 500 || a.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/apicat/apicat/backend/common/spec/diff"
+)
+
+// 可以订阅的事件
+const (
+	EventCollectionCreated = "collection.created"
+	EventCollectionUpdated = "collection.updated"
+	EventCollectionDeleted = "collection.deleted"
+	EventSchemaChanged     = "schema.changed"
+	EventIterationUpdated  = "iteration.updated"
+	EventMemberAdded       = "member.added"
+	EventShareToggled      = "share.toggled"
+	// EventPing 测试webhook是否可用 不需要订阅
+	EventPing = "ping"
+)
+
+var Events = []string{
+	EventCollectionCreated,
+	EventCollectionUpdated,
+	EventCollectionDeleted,
+	EventSchemaChanged,
+	EventIterationUpdated,
+	EventMemberAdded,
+	EventShareToggled,
+}
+
+// 请求头
+const (
+	HeaderEvent     = "X-Apicat-Event"
+	HeaderDelivery  = "X-Apicat-Delivery"
+	HeaderSignature = "X-Apicat-Signature"
+)
+
+func ValidEvent(event string) bool {
+	for _, v := range Events {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
+
+type Project struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Actor 触发事件的用户
+type Actor struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+// Entity 发生变化的对象 Type为collection schema iteration member project
+type Entity struct {
+	Type  string `json:"type"`
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+// Payload 发送给订阅者的内容
+type Payload struct {
+	// ID 投递的唯一标识 和请求头X-Apicat-Delivery相同
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	// Action 事件的具体动作 例如schema.changed的added changed removed
+	Action    string   `json:"action,omitempty"`
+	Timestamp int64    `json:"timestamp"`
+	Project   *Project `json:"project"`
+	Actor     *Actor   `json:"actor,omitempty"`
+	Entity    *Entity  `json:"entity,omitempty"`
+	// Diff 集合和模型变化前后的差异
+	Diff *diff.Report `json:"diff,omitempty"`
+	// Data 事件的其它数据 例如成员的权限
+	Data map[string]any `json:"data,omitempty"`
+}
+
+// Sign 使用secret计算body的HMAC-SHA256签名 格式为sha256=hex
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名 供接收方使用
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
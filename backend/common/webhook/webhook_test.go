@@ -0,0 +1,179 @@
+package webhook
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	sig := Sign("secret", body)
+	if sig != "sha256=4f4bb3a54e99c4a20e243485229f9b08c66e09104ba6f79c23ce647242a4ce84" {
+		t.Errorf("signature: %s", sig)
+	}
+	if !Verify("secret", body, sig) {
+		t.Error("signature should be valid")
+	}
+	if Verify("other", body, sig) || Verify("secret", []byte(`{}`), sig) {
+		t.Error("signature should be invalid")
+	}
+}
+
+func TestSend(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderEvent) != EventPing || r.Header.Get(HeaderDelivery) != "d1" {
+			t.Errorf("headers: %v", r.Header)
+		}
+		if !Verify("secret", body, r.Header.Get(HeaderSignature)) {
+			t.Error("invalid signature")
+		}
+		received.Add(1)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	attempts := make([]*Attempt, 0)
+	s := &Sender{Client: srv.Client(), Backoff: []time.Duration{time.Millisecond}}
+	if !s.Send(srv.URL, "secret", EventPing, "d1", []byte(`{"event":"ping"}`), func(a *Attempt) {
+		attempts = append(attempts, a)
+	}) {
+		t.Error("send should succeed")
+	}
+	if received.Load() != 1 || len(attempts) != 1 {
+		t.Fatalf("received %d, attempts %d", received.Load(), len(attempts))
+	}
+	if a := attempts[0]; a.Number != 1 || a.StatusCode != 200 || a.Response != "ok" || !a.Success || a.Retry {
+		t.Errorf("attempt: %+v", a)
+	}
+}
+
+func TestSendRetry(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 前两次失败 第三次成功
+		switch received.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	codes := make([]int, 0)
+	retries := make([]bool, 0)
+	s := &Sender{Client: srv.Client(), Backoff: []time.Duration{time.Millisecond, 5 * time.Millisecond, time.Millisecond}}
+	ok := s.Send(srv.URL, "", EventPing, "d2", []byte(`{}`), func(a *Attempt) {
+		codes = append(codes, a.StatusCode)
+		retries = append(retries, a.Retry)
+	})
+	if !ok || received.Load() != 3 {
+		t.Fatalf("ok %v, received %d", ok, received.Load())
+	}
+	if codes[0] != 503 || codes[1] != 429 || codes[2] != 204 {
+		t.Errorf("codes: %v", codes)
+	}
+	if !retries[0] || !retries[1] || retries[2] {
+		t.Errorf("retries: %v", retries)
+	}
+}
+
+func TestSendFailed(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	s := &Sender{Client: srv.Client(), Backoff: []time.Duration{time.Millisecond, time.Millisecond}}
+
+	// 4xx不重试
+	if s.Send(srv.URL+"/gone", "", EventPing, "d3", []byte(`{}`), nil) || received.Load() != 1 {
+		t.Errorf("4xx: received %d", received.Load())
+	}
+
+	// 重试次数用完
+	received.Store(0)
+	last := &Attempt{}
+	if s.Send(srv.URL, "", EventPing, "d4", []byte(`{}`), func(a *Attempt) { last = a }) || received.Load() != 3 {
+		t.Errorf("5xx: received %d", received.Load())
+	}
+	if last.Number != 3 || last.Retry {
+		t.Errorf("last attempt: %+v", last)
+	}
+
+	// 网络错误
+	srv.Close()
+	last = &Attempt{}
+	if s.Send(srv.URL, "", EventPing, "d5", []byte(`{}`), func(a *Attempt) { last = a }) || last.Number != 3 || last.Error == "" {
+		t.Errorf("network error: %+v", last)
+	}
+}
+
+func TestSendLocal(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+
+	// 默认拒绝本机地址 不重试 不返回响应内容
+	s := NewSender(false)
+	s.Backoff = []time.Duration{time.Millisecond}
+	last := &Attempt{}
+	if s.Send(srv.URL, "", EventPing, "d6", []byte(`{}`), func(a *Attempt) { last = a }) || received.Load() != 0 {
+		t.Fatalf("local address should be denied, received %d", received.Load())
+	}
+	if last.Number != 1 || last.Retry || last.Response != "" || last.Error != ErrAddressNotAllowed.Error() {
+		t.Errorf("denied attempt: %+v", last)
+	}
+
+	s = NewSender(true)
+	if !s.Send(srv.URL, "", EventPing, "d7", []byte(`{}`), nil) || received.Load() != 1 {
+		t.Errorf("local address should be allowed, received %d", received.Load())
+	}
+
+	for ip, want := range map[string]bool{
+		"127.0.0.1": false, "10.1.2.3": false, "172.16.0.1": false, "192.168.1.1": false,
+		"169.254.169.254": false, "0.0.0.0": false, "::1": false, "fe80::1": false, "fd00::1": false,
+		"100.64.0.1": false, "100.127.255.254": false, "::ffff:100.64.0.1": false,
+		"8.8.8.8": true, "100.128.0.1": true, "2001:4860:4860::8888": true,
+	} {
+		if allowedIP(net.ParseIP(ip)) != want {
+			t.Errorf("%s: expected %v", ip, want)
+		}
+	}
+}
+
+func TestNewDialer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if _, err := NewDialer(false).Dial("tcp", l.Addr().String()); !errors.Is(err, ErrAddressNotAllowed) {
+		t.Errorf("local address should be denied, got %v", err)
+	}
+	conn, err := NewDialer(true).Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("local address should be allowed, got %v", err)
+	}
+	conn.Close()
+}
@@ -37,11 +37,23 @@ type OpenAIFile struct {
 	Endpoint string `yaml:"endpoint" env:"APICAT_OPENAI_ENDPOINT"`
 }
 
+// WebhookFile allow_local为true时允许向内网和本机地址发送webhook 仅用于测试
+type WebhookFile struct {
+	AllowLocal string `yaml:"allow_local" env:"APICAT_WEBHOOK_ALLOW_LOCAL"`
+}
+
+// TestRunFile allow_local为true时接口测试允许请求内网和本机地址
+type TestRunFile struct {
+	AllowLocal string `yaml:"allow_local" env:"APICAT_TEST_RUN_ALLOW_LOCAL"`
+}
+
 type FileConfig struct {
-	App    AppFile    `yaml:"application"`
-	Log    LogFile    `yaml:"log"`
-	DB     DBFile     `yaml:"database"`
-	OpenAI OpenAIFile `yaml:"openai"`
+	App     AppFile     `yaml:"application"`
+	Log     LogFile     `yaml:"log"`
+	DB      DBFile      `yaml:"database"`
+	OpenAI  OpenAIFile  `yaml:"openai"`
+	Webhook WebhookFile `yaml:"webhook"`
+	TestRun TestRunFile `yaml:"test_run"`
 }
 
 type ConfigItem struct {
@@ -77,11 +89,21 @@ type OpenAI struct {
 	Endpoint ConfigItem `env:"APICAT_OPENAI_ENDPOINT"`
 }
 
+type Webhook struct {
+	AllowLocal ConfigItem `env:"APICAT_WEBHOOK_ALLOW_LOCAL"`
+}
+
+type TestRun struct {
+	AllowLocal ConfigItem `env:"APICAT_TEST_RUN_ALLOW_LOCAL"`
+}
+
 type SysConfig struct {
-	App    App
-	Log    Log
-	DB     DB
-	OpenAI OpenAI
+	App     App
+	Log     Log
+	DB      DB
+	OpenAI  OpenAI
+	Webhook Webhook
+	TestRun TestRun
 }
 
 var (
@@ -144,6 +166,18 @@ func createDefault() *SysConfig {
 				DataSource: "value",
 			},
 		},
+		Webhook: Webhook{
+			AllowLocal: ConfigItem{
+				Value:      "false",
+				DataSource: "value",
+			},
+		},
+		TestRun: TestRun{
+			AllowLocal: ConfigItem{
+				Value:      "false",
+				DataSource: "value",
+			},
+		},
 	}
 }
 
@@ -169,6 +203,8 @@ func getEnvConfig() SysConfig {
 	setEnvValues(&envConfig.Log, "env")
 	setEnvValues(&envConfig.DB, "env")
 	setEnvValues(&envConfig.OpenAI, "env")
+	setEnvValues(&envConfig.Webhook, "env")
+	setEnvValues(&envConfig.TestRun, "env")
 
 	return envConfig
 }
@@ -208,6 +244,8 @@ func replaceEnvVars(fileConfig *FileConfig, sysConfig *SysConfig) {
 	setEnvValues(&fileConfig.Log, &sysConfig.Log)
 	setEnvValues(&fileConfig.DB, &sysConfig.DB)
 	setEnvValues(&fileConfig.OpenAI, &sysConfig.OpenAI)
+	setEnvValues(&fileConfig.Webhook, &sysConfig.Webhook)
+	setEnvValues(&fileConfig.TestRun, &sysConfig.TestRun)
 }
 
 func loadConfig(filepath string) (*SysConfig, error) {
@@ -253,6 +291,8 @@ func sysToFile(sysConfig *SysConfig) *FileConfig {
 	setFileValues(&sysConfig.Log, &fileConfig.Log)
 	setFileValues(&sysConfig.DB, &fileConfig.DB)
 	setFileValues(&sysConfig.OpenAI, &fileConfig.OpenAI)
+	setFileValues(&sysConfig.Webhook, &fileConfig.Webhook)
+	setFileValues(&sysConfig.TestRun, &fileConfig.TestRun)
 
 	return fileConfig
 }
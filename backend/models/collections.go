@@ -17,7 +17,7 @@ type Collections struct {
 	ProjectId     uint   `gorm:"type:bigint;index;not null;comment:项目id"`
 	ParentId      uint   `gorm:"type:bigint;not null;comment:父级id"`
 	Title         string `gorm:"type:varchar(255);not null;comment:名称"`
-	Type          string `gorm:"type:varchar(255);not null;comment:类型:category,doc,http"`
+	Type          string `gorm:"type:varchar(255);not null;comment:类型:category,doc,http,webhook,callback,graphql,channel,grpc"`
 	SharePassword string `gorm:"type:varchar(255);comment:项目分享密码"`
 	Content       string `gorm:"type:mediumtext;comment:内容"`
 	DisplayOrder  int    `gorm:"type:int(11);not null;default:0;comment:显示顺序"`
@@ -227,11 +227,16 @@ func CollectionsImport(projectID, parentID uint, collections []*spec.CollectItem
 				collectionStr = replaceVirtualIDToID(collectionStr, refContentNameToId.DefinitionParameters, "#/definitions/parameters/")
 				collectionStr = ReplaceGlobalParametersVirtualIDToID(collectionStr, refContentNameToId.GolbalParameters)
 
+				collectionType := string(collection.Type)
+				if collectionType == "" {
+					collectionType = spec.ContentItemTypeHttp
+				}
+
 				record := &Collections{
 					ProjectId:    projectID,
 					ParentId:     parentID,
 					Title:        collection.Title,
-					Type:         "http",
+					Type:         collectionType,
 					Content:      collectionStr,
 					DisplayOrder: i,
 				}
@@ -336,6 +341,7 @@ func CollectionExport(project *Projects, collection *Collections) *spec.Spec {
 	apicatData.Definitions.Schemas = DefinitionSchemasExport(project.ID)
 	apicatData.Definitions.Parameters = DefinitionParametersExport(project.ID)
 	apicatData.Definitions.Responses = DefinitionResponsesExport(project.ID)
+	apicatData.Definitions.Securities = SecuritySchemesExport(project.ID)
 
 	paths := apicatData.CollectionsMap(true, 2)
 	for _, path := range paths {
@@ -356,6 +362,7 @@ func CollectionExport(project *Projects, collection *Collections) *spec.Spec {
 		Schemas:    spec.Schemas{},
 		Parameters: spec.Schemas{},
 		Responses:  spec.HTTPResponseDefines{},
+		Securities: apicatData.Definitions.Securities,
 	}
 
 	return apicatData
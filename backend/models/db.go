@@ -171,6 +171,13 @@ func initTable() {
 		&Iterations{},
 		&IterationApis{},
 		&ProjectGroups{},
+		&TestRuns{},
+		&TestRunResults{},
+		&Environments{},
+		&ProjectReleases{},
+		&SecuritySchemes{},
+		&Webhooks{},
+		&WebhookDeliveries{},
 	); err != nil {
 		panic(err.Error())
 	}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/apicat/apicat/backend/common/spec"
+)
+
+type Environments struct {
+	ID           uint   `gorm:"type:bigint;primaryKey;autoIncrement"`
+	ProjectID    uint   `gorm:"type:bigint;index;not null;comment:项目id"`
+	Name         string `gorm:"type:varchar(255);not null;comment:环境名称"`
+	Description  string `gorm:"type:varchar(255);comment:描述"`
+	Variables    string `gorm:"type:mediumtext;comment:变量"`
+	DisplayOrder int    `gorm:"type:int(11);not null;default:0;comment:显示顺序"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func NewEnvironments(ids ...uint) (*Environments, error) {
+	if len(ids) > 0 {
+		env := &Environments{ID: ids[0]}
+		if err := Conn.Take(env).Error; err != nil {
+			return env, err
+		}
+		return env, nil
+	}
+	return &Environments{}, nil
+}
+
+func (e *Environments) List(projectID uint) ([]*Environments, error) {
+	var environments []*Environments
+	return environments, Conn.Where("project_id = ?", projectID).Order("display_order asc").Order("id asc").Find(&environments).Error
+}
+
+func (e *Environments) Create() error {
+	return Conn.Create(e).Error
+}
+
+func (e *Environments) Update() error {
+	return Conn.Save(e).Error
+}
+
+func (e *Environments) Delete() error {
+	return Conn.Delete(e).Error
+}
+
+// GetVariables 解析保存的变量
+func (e *Environments) GetVariables() []*spec.Variable {
+	variables := make([]*spec.Variable, 0)
+	if e.Variables != "" {
+		json.Unmarshal([]byte(e.Variables), &variables)
+	}
+	return variables
+}
+
+func (e *Environments) SetVariables(variables []*spec.Variable) {
+	if variables == nil {
+		variables = make([]*spec.Variable, 0)
+	}
+	b, _ := json.Marshal(variables)
+	e.Variables = string(b)
+}
+
+// ToSpec 转为 spec 的环境 withSecret为false时私密变量的值为空
+func (e *Environments) ToSpec(withSecret bool) *spec.Environment {
+	variables := e.GetVariables()
+	if !withSecret {
+		for _, v := range variables {
+			if v.Secret {
+				v.Value = ""
+			}
+		}
+	}
+	return &spec.Environment{
+		Name:        e.Name,
+		Description: e.Description,
+		Variables:   variables,
+	}
+}
+
+func EnvironmentsImport(projectID uint, environments []*spec.Environment) {
+	for i, v := range environments {
+		env := &Environments{
+			ProjectID:    projectID,
+			Name:         v.Name,
+			Description:  v.Description,
+			DisplayOrder: i,
+		}
+		env.SetVariables(v.Variables)
+		Conn.Create(env)
+	}
+}
+
+// EnvironmentsExport 导出项目的环境 不包含私密变量的值
+func EnvironmentsExport(projectID uint) []*spec.Environment {
+	env, _ := NewEnvironments()
+	environments, err := env.List(projectID)
+	if err != nil {
+		return nil
+	}
+	list := make([]*spec.Environment, 0, len(environments))
+	for _, v := range environments {
+		list = append(list, v.ToSpec(false))
+	}
+	return list
+}
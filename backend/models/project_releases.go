@@ -0,0 +1,526 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"gorm.io/gorm"
+)
+
+// ProjectReleases 项目的版本快照 内容为发布时整个项目导出的 apicat 结构 创建后不可修改
+type ProjectReleases struct {
+	ID        uint   `gorm:"type:bigint;primaryKey;autoIncrement"`
+	ProjectID uint   `gorm:"type:bigint;index;not null;comment:项目id"`
+	Version   string `gorm:"type:varchar(255);not null;comment:版本号"`
+	Notes     string `gorm:"type:text;comment:版本说明"`
+	Content   string `gorm:"type:longtext;comment:项目快照"`
+	CreatedAt time.Time
+	CreatedBy uint `gorm:"type:bigint;not null;default:0;comment:创建人id"`
+}
+
+func NewProjectReleases(ids ...uint) (*ProjectReleases, error) {
+	if len(ids) > 0 {
+		release := &ProjectReleases{ID: ids[0]}
+		if err := Conn.Take(release).Error; err != nil {
+			return release, err
+		}
+		return release, nil
+	}
+	return &ProjectReleases{}, nil
+}
+
+// List 不查询快照内容
+func (r *ProjectReleases) List(projectID uint) ([]*ProjectReleases, error) {
+	var releases []*ProjectReleases
+	return releases, Conn.Omit("content").Where("project_id = ?", projectID).Order("id desc").Find(&releases).Error
+}
+
+func (r *ProjectReleases) GetCountByVersion() (int64, error) {
+	var count int64
+	return count, Conn.Model(&ProjectReleases{}).Where("project_id = ? AND version = ?", r.ProjectID, r.Version).Count(&count).Error
+}
+
+// Create 保存项目当前的快照
+func (r *ProjectReleases) Create(project *Projects) error {
+	content := ProjectExport(project)
+	content.Info.Version = r.Version
+	b, err := content.ToJSON(spec.JSONOption{})
+	if err != nil {
+		return err
+	}
+	r.ProjectID = project.ID
+	r.Content = string(b)
+	return Conn.Create(r).Error
+}
+
+// Spec 解析保存的快照
+func (r *ProjectReleases) Spec() (*spec.Spec, error) {
+	return spec.ParseJSON([]byte(r.Content))
+}
+
+func (r *ProjectReleases) Creator() string {
+	user, err := NewUsers(r.CreatedBy)
+	if err != nil {
+		return ""
+	}
+
+	return user.Username
+}
+
+// Rollback 使用快照替换项目当前的接口 模型 公共参数 公共响应 认证方式和服务器
+// 快照中仍存在的接口和模型沿用原来的id 恢复前的内容保存到历史记录 其余的移入回收站 环境不受影响
+// 整个过程在同一个事务中执行 任意一步失败都会回滚
+func (r *ProjectReleases) Rollback(uid uint) error {
+	content, err := r.Spec()
+	if err != nil {
+		return err
+	}
+
+	return Conn.Transaction(func(tx *gorm.DB) error {
+		var collectionIDs []uint
+		if err := tx.Model(&Collections{}).Where("project_id = ?", r.ProjectID).Pluck("id", &collectionIDs).Error; err != nil {
+			return err
+		}
+		if len(collectionIDs) > 0 {
+			if err := tx.Model(&Collections{}).Where("id IN ?", collectionIDs).Update("deleted_by", uid).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", collectionIDs).Delete(&Collections{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 快照中没有模型目录 目录保持不变 恢复的模型仍然放在原来的目录中
+		if err := tx.Model(&DefinitionSchemas{}).Where("project_id = ? AND type = ?", r.ProjectID, "schema").Update("deleted_by", uid).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("project_id = ? AND type = ?", r.ProjectID, "schema").Delete(&DefinitionSchemas{}).Error; err != nil {
+			return err
+		}
+		for _, v := range []any{&DefinitionResponses{}, &DefinitionParameters{}, &GlobalParameters{}, &Servers{}, &SecuritySchemes{}} {
+			if err := tx.Where("project_id = ?", r.ProjectID).Delete(v).Error; err != nil {
+				return err
+			}
+		}
+
+		rb := &releaseRollback{
+			tx:        tx,
+			projectID: r.ProjectID,
+			uid:       uid,
+			refs: &RefContentVirtualIDToId{
+				DefinitionSchemas:    virtualIDToIDMap{},
+				DefinitionResponses:  virtualIDToIDMap{},
+				DefinitionParameters: virtualIDToIDMap{},
+				GolbalParameters:     virtualIDToIDMap{},
+			},
+			collections: map[uint]bool{},
+		}
+		if err := rb.restore(content); err != nil {
+			return err
+		}
+
+		// 没有恢复的接口留在回收站 不再属于任何迭代
+		removed := make([]uint, 0, len(collectionIDs))
+		for _, id := range collectionIDs {
+			if !rb.collections[id] {
+				removed = append(removed, id)
+			}
+		}
+		if len(removed) > 0 {
+			return tx.Where("collection_id IN ?", removed).Delete(&IterationApis{}).Error
+		}
+		return nil
+	})
+}
+
+// releaseRollback 在事务中把快照写回项目
+// 快照中的id就是项目原来的id 没有被其它记录占用时直接沿用 否则重新分配并替换引用
+type releaseRollback struct {
+	tx        *gorm.DB
+	projectID uint
+	uid       uint
+	// refs 快照id到新id的映射 只记录id发生变化的
+	refs *RefContentVirtualIDToId
+	// collections 恢复的接口id
+	collections map[uint]bool
+}
+
+func (rb *releaseRollback) restore(content *spec.Spec) error {
+	for i, server := range content.Servers {
+		if err := rb.tx.Create(&Servers{
+			ProjectId:    rb.projectID,
+			Description:  server.Description,
+			Url:          server.URL,
+			DisplayOrder: i,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	for i, v := range content.Definitions.Securities {
+		scheme := &SecuritySchemes{
+			ProjectID:    rb.projectID,
+			DisplayOrder: i,
+		}
+		scheme.SetSpec(v)
+		if err := rb.tx.Create(scheme).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := rb.schemas(content.Definitions.Schemas); err != nil {
+		return err
+	}
+	if err := rb.responses(content.Definitions.Responses); err != nil {
+		return err
+	}
+	if err := rb.parameters(content.Definitions.Parameters); err != nil {
+		return err
+	}
+	if err := rb.globalParameters(&content.Globals.Parameters); err != nil {
+		return err
+	}
+	return rb.collectItems(0, content.Collections)
+}
+
+// id 快照中的id没有被占用时沿用 否则返回0由数据库分配
+func (rb *releaseRollback) id(model any, id int64) (uint, error) {
+	if id <= 0 {
+		return 0, nil
+	}
+	var count int64
+	if err := rb.tx.Unscoped().Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		return 0, nil
+	}
+	return uint(id), nil
+}
+
+// replaceRefs 替换id发生变化的引用
+func (rb *releaseRollback) replaceRefs(content string) string {
+	content = replaceVirtualIDToID(content, rb.refs.DefinitionSchemas, "#/definitions/schemas/")
+	content = replaceVirtualIDToID(content, rb.refs.DefinitionResponses, "#/definitions/responses/")
+	content = replaceVirtualIDToID(content, rb.refs.DefinitionParameters, "#/definitions/parameters/")
+	if len(rb.refs.GolbalParameters) > 0 {
+		content = ReplaceGlobalParametersVirtualIDToID(content, rb.refs.GolbalParameters)
+	}
+	return content
+}
+
+func (rb *releaseRollback) schemas(schemas spec.Schemas) error {
+	records := make([]*DefinitionSchemas, 0, len(schemas))
+	for i, v := range schemas {
+		b, err := json.Marshal(v.Schema)
+		if err != nil {
+			return err
+		}
+
+		record := &DefinitionSchemas{}
+		err = rb.tx.Unscoped().Where("id = ? AND project_id = ? AND type = ?", v.ID, rb.projectID, "schema").Take(record).Error
+		switch {
+		case err == nil:
+			if record.Name != v.Name || record.Description != v.Description || record.Schema != string(b) {
+				if err := rb.tx.Create(&DefinitionSchemaHistories{
+					SchemaID:    record.ID,
+					Name:        record.Name,
+					Description: record.Description,
+					Type:        record.Type,
+					Schema:      record.Schema,
+					CreatedBy:   rb.uid,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			parentID, err := rb.parentID(record.ParentId)
+			if err != nil {
+				return err
+			}
+			if err := rb.tx.Unscoped().Model(record).Updates(map[string]any{
+				"parent_id":     parentID,
+				"name":          v.Name,
+				"description":   v.Description,
+				"schema":        string(b),
+				"display_order": i,
+				"updated_by":    rb.uid,
+				"deleted_at":    nil,
+				"deleted_by":    0,
+			}).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			id, err := rb.id(&DefinitionSchemas{}, v.ID)
+			if err != nil {
+				return err
+			}
+			record = &DefinitionSchemas{
+				ID:           id,
+				ProjectId:    rb.projectID,
+				Name:         v.Name,
+				Description:  v.Description,
+				Type:         "schema",
+				Schema:       string(b),
+				DisplayOrder: i,
+				CreatedBy:    rb.uid,
+				UpdatedBy:    rb.uid,
+			}
+			if err := rb.tx.Create(record).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if record.ID != uint(v.ID) {
+			rb.refs.DefinitionSchemas[v.ID] = record.ID
+		}
+		records = append(records, record)
+	}
+
+	if len(rb.refs.DefinitionSchemas) == 0 {
+		return nil
+	}
+	for _, record := range records {
+		schema := replaceVirtualIDToID(record.Schema, rb.refs.DefinitionSchemas, "#/definitions/schemas/")
+		if schema == record.Schema {
+			continue
+		}
+		if err := rb.tx.Unscoped().Model(record).Update("schema", schema).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parentID 模型原来的目录还存在时继续放在该目录中 否则放到根目录
+func (rb *releaseRollback) parentID(id uint) (uint, error) {
+	if id == 0 {
+		return 0, nil
+	}
+	var count int64
+	if err := rb.tx.Model(&DefinitionSchemas{}).Where("id = ? AND project_id = ? AND type = ?", id, rb.projectID, "category").Count(&count).Error; err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return id, nil
+}
+
+func (rb *releaseRollback) responses(responses spec.HTTPResponseDefines) error {
+	for i, v := range responses {
+		header, err := json.Marshal(v.Header)
+		if err != nil {
+			return err
+		}
+		content, err := json.Marshal(v.Content)
+		if err != nil {
+			return err
+		}
+
+		id, err := rb.id(&DefinitionResponses{}, v.ID)
+		if err != nil {
+			return err
+		}
+		record := &DefinitionResponses{
+			ID:           id,
+			ProjectID:    rb.projectID,
+			Name:         v.Name,
+			Description:  v.Description,
+			Type:         "response",
+			Header:       rb.replaceRefs(string(header)),
+			Content:      rb.replaceRefs(string(content)),
+			DisplayOrder: i,
+		}
+		if err := rb.tx.Create(record).Error; err != nil {
+			return err
+		}
+		if record.ID != uint(v.ID) {
+			rb.refs.DefinitionResponses[v.ID] = record.ID
+		}
+	}
+	return nil
+}
+
+func (rb *releaseRollback) parameters(parameters spec.Schemas) error {
+	for _, v := range parameters {
+		schema, err := json.Marshal(v.Schema)
+		if err != nil {
+			return err
+		}
+		required := 0
+		if v.Required {
+			required = 1
+		}
+
+		id, err := rb.id(&DefinitionParameters{}, v.ID)
+		if err != nil {
+			return err
+		}
+		record := &DefinitionParameters{
+			ID:        id,
+			ProjectID: rb.projectID,
+			Name:      v.Name,
+			Required:  required,
+			Schema:    rb.replaceRefs(string(schema)),
+		}
+		if err := rb.tx.Create(record).Error; err != nil {
+			return err
+		}
+		if record.ID != uint(v.ID) {
+			rb.refs.DefinitionParameters[v.ID] = record.ID
+		}
+	}
+	return nil
+}
+
+func (rb *releaseRollback) globalParameters(parameters *spec.HTTPParameters) error {
+	for _, in := range []string{"header", "cookie", "query", "path"} {
+		var params []*spec.Schema
+		switch in {
+		case "header":
+			params = parameters.Header
+		case "cookie":
+			params = parameters.Cookie
+		case "query":
+			params = parameters.Query
+		case "path":
+			params = parameters.Path
+		}
+
+		for _, v := range params {
+			schema, err := json.Marshal(v.Schema)
+			if err != nil {
+				return err
+			}
+			required := 0
+			if v.Required {
+				required = 1
+			}
+
+			id, err := rb.id(&GlobalParameters{}, v.ID)
+			if err != nil {
+				return err
+			}
+			record := &GlobalParameters{
+				ID:        id,
+				ProjectID: rb.projectID,
+				In:        in,
+				Name:      v.Name,
+				Required:  required,
+				Schema:    string(schema),
+			}
+			if err := rb.tx.Create(record).Error; err != nil {
+				return err
+			}
+			if record.ID != uint(v.ID) {
+				rb.refs.GolbalParameters[v.ID] = record.ID
+			}
+		}
+	}
+	return nil
+}
+
+func (rb *releaseRollback) collectItems(parentID uint, items []*spec.CollectItem) error {
+	for i, item := range items {
+		typ, content := string(item.Type), ""
+		if len(item.Items) > 0 || typ == "category" {
+			typ = "category"
+		} else {
+			b, err := json.Marshal(item.Content)
+			if err != nil {
+				return err
+			}
+			content = rb.replaceRefs(string(b))
+			if typ == "" {
+				typ = spec.ContentItemTypeHttp
+			}
+		}
+
+		record := &Collections{}
+		err := rb.tx.Unscoped().Where("id = ? AND project_id = ?", item.ID, rb.projectID).Take(record).Error
+		switch {
+		case err == nil:
+			if typ != "category" && (record.Title != item.Title || record.Content != content) {
+				if err := rb.tx.Create(&CollectionHistories{
+					CollectionId: record.ID,
+					Title:        record.Title,
+					Type:         record.Type,
+					Content:      record.Content,
+					CreatedBy:    rb.uid,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			if err := rb.tx.Unscoped().Model(record).Updates(map[string]any{
+				"parent_id":     parentID,
+				"title":         item.Title,
+				"type":          typ,
+				"content":       content,
+				"display_order": i,
+				"updated_by":    rb.uid,
+				"deleted_at":    nil,
+				"deleted_by":    0,
+			}).Error; err != nil {
+				return err
+			}
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			id, err := rb.id(&Collections{}, item.ID)
+			if err != nil {
+				return err
+			}
+			record = &Collections{
+				ID:           id,
+				ProjectId:    rb.projectID,
+				ParentId:     parentID,
+				Title:        item.Title,
+				Type:         typ,
+				Content:      content,
+				DisplayOrder: i,
+				CreatedBy:    rb.uid,
+				UpdatedBy:    rb.uid,
+			}
+			if err := rb.tx.Create(record).Error; err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+		rb.collections[record.ID] = true
+
+		if typ == "category" {
+			if err := rb.collectItems(record.ID, item.Items); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := rb.tags(record.ID, item.Tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rb *releaseRollback) tags(collectionID uint, tags []string) error {
+	if err := rb.tx.Where("collection_id = ?", collectionID).Delete(&TagToCollections{}).Error; err != nil {
+		return err
+	}
+	for _, name := range tags {
+		tag := &Tags{}
+		err := rb.tx.Where("project_id = ? AND name = ?", rb.projectID, name).Take(tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = &Tags{ProjectId: rb.projectID, Name: name}
+			err = rb.tx.Create(tag).Error
+		}
+		if err != nil {
+			return err
+		}
+		if err := rb.tx.Create(&TagToCollections{TagId: tag.ID, CollectionId: collectionID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := []any{
+		&Collections{}, &CollectionHistories{}, &Servers{}, &Tags{}, &TagToCollections{},
+		&GlobalParameters{}, &DefinitionSchemas{}, &DefinitionResponses{}, &DefinitionParameters{},
+		&DefinitionSchemaHistories{}, &IterationApis{}, &Environments{}, &ProjectReleases{}, &SecuritySchemes{},
+	}
+	for _, v := range tables {
+		// sqlite中bigint主键不会自增 先用integer主键建表
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(v); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE TABLE `%s` (`id` integer PRIMARY KEY AUTOINCREMENT)", stmt.Schema.Table)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatal(err)
+	}
+	old := Conn
+	Conn = db
+	t.Cleanup(func() { Conn = old })
+}
+
+func TestReleaseRollbackSchemas(t *testing.T) {
+	setupTestDB(t)
+	project := &Projects{ID: 1}
+	mustCreate := func(v any) {
+		t.Helper()
+		if err := Conn.Create(v).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustCreate(&DefinitionSchemas{ID: 10, ProjectId: 1, Name: "folder", Type: "category"})
+	mustCreate(&DefinitionSchemas{ID: 11, ProjectId: 1, ParentId: 10, Name: "User", Type: "schema", Schema: `{"type":"object"}`})
+	mustCreate(&DefinitionSchemas{ID: 12, ProjectId: 1, Name: "Pet", Type: "schema", Schema: `{"type":"object"}`})
+	mustCreate(&DefinitionResponses{ID: 20, ProjectID: 1, Name: "PetResponse", Type: "response", Header: `[]`,
+		Content: `{"application/json":{"schema":{"$ref":"#/definitions/schemas/12"}}}`})
+	mustCreate(&DefinitionParameters{ID: 30, ProjectID: 1, Name: "pet", Schema: `{"$ref":"#/definitions/schemas/12"}`})
+
+	release := &ProjectReleases{Version: "1.0.0"}
+	if err := release.Create(project); err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改目录中的模型 Pet被彻底删除后id被其它项目占用
+	if err := Conn.Model(&DefinitionSchemas{}).Where("id = ?", 11).Update("name", "Changed").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := Conn.Unscoped().Delete(&DefinitionSchemas{}, 12).Error; err != nil {
+		t.Fatal(err)
+	}
+	mustCreate(&DefinitionSchemas{ID: 12, ProjectId: 2, Name: "Other", Type: "schema", Schema: `{}`})
+
+	if err := release.Rollback(1); err != nil {
+		t.Fatal(err)
+	}
+
+	folder := &DefinitionSchemas{}
+	if err := Conn.Take(folder, 10).Error; err != nil {
+		t.Fatalf("folder should be kept: %v", err)
+	}
+	user := &DefinitionSchemas{}
+	if err := Conn.Take(user, 11).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "User" || user.ParentId != 10 {
+		t.Errorf("schema should be restored in its folder, got name %q parent %d", user.Name, user.ParentId)
+	}
+
+	pet := &DefinitionSchemas{}
+	if err := Conn.Where("project_id = ? AND name = ?", 1, "Pet").Take(pet).Error; err != nil {
+		t.Fatal(err)
+	}
+	if pet.ID == 12 {
+		t.Fatal("schema id used by another project should be reassigned")
+	}
+	ref := fmt.Sprintf(`"#/definitions/schemas/%d"`, pet.ID)
+	response := &DefinitionResponses{}
+	if err := Conn.Take(response, 20).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(response.Content, ref) {
+		t.Errorf("response should reference %s, got %s", ref, response.Content)
+	}
+	parameter := &DefinitionParameters{}
+	if err := Conn.Take(parameter, 30).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(parameter.Schema, ref) {
+		t.Errorf("parameter should reference %s, got %s", ref, parameter.Schema)
+	}
+}
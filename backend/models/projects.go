@@ -1,9 +1,12 @@
 package models
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 
+	"github.com/apicat/apicat/backend/common/spec"
+	"github.com/apicat/apicat/backend/common/spec/lint"
 	"gorm.io/gorm"
 )
 
@@ -15,6 +18,8 @@ type Projects struct {
 	SharePassword string `gorm:"type:varchar(255);comment:项目分享密码"`
 	Description   string `gorm:"type:varchar(255);comment:项目描述"`
 	Cover         string `gorm:"type:varchar(255);comment:项目封面"`
+	MockStateful  bool   `gorm:"type:tinyint(1);not null;default:0;comment:mock是否开启有状态模式"`
+	LintConfig    string `gorm:"type:mediumtext;comment:规范检查配置"`
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	DeletedAt     gorm.DeletedAt
@@ -70,3 +75,45 @@ func (p *Projects) Delete() error {
 func (p *Projects) Save() error {
 	return Conn.Save(p).Error
 }
+
+// ProjectExport 返回整个项目导出的 apicat 结构
+func ProjectExport(project *Projects) *spec.Spec {
+	apicatData := &spec.Spec{}
+	apicatData.ApiCat = "apicat"
+	apicatData.Info = &spec.Info{
+		ID:          project.PublicId,
+		Title:       project.Title,
+		Description: project.Description,
+		Version:     "1.0.0",
+	}
+
+	apicatData.Servers = ServersExport(project.ID)
+	apicatData.Environments = EnvironmentsExport(project.ID)
+	apicatData.Globals.Parameters = GlobalParametersExport(project.ID)
+	apicatData.Definitions.Schemas = DefinitionSchemasExport(project.ID)
+	apicatData.Definitions.Parameters = DefinitionParametersExport(project.ID)
+	apicatData.Definitions.Responses = DefinitionResponsesExport(project.ID)
+	apicatData.Definitions.Securities = SecuritySchemesExport(project.ID)
+	apicatData.Collections = CollectionsExport(project.ID)
+	return apicatData
+}
+
+// GetLintConfig 解析保存的规范检查配置 没有配置时使用默认配置
+func (p *Projects) GetLintConfig() *lint.Config {
+	cfg := &lint.Config{}
+	if p.LintConfig != "" {
+		json.Unmarshal([]byte(p.LintConfig), cfg)
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = make(map[string]string)
+	}
+	if cfg.Custom == nil {
+		cfg.Custom = make([]*lint.CustomRule, 0)
+	}
+	return cfg
+}
+
+func (p *Projects) SetLintConfig(cfg *lint.Config) {
+	b, _ := json.Marshal(cfg)
+	p.LintConfig = string(b)
+}
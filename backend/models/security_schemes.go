@@ -0,0 +1,186 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/apicat/apicat/backend/common/spec"
+	"gorm.io/gorm"
+)
+
+type SecuritySchemes struct {
+	ID           uint   `gorm:"type:bigint;primaryKey;autoIncrement"`
+	ProjectID    uint   `gorm:"type:bigint;index;not null;comment:项目id"`
+	Name         string `gorm:"type:varchar(255);not null;comment:认证方式名称"`
+	Type         string `gorm:"type:varchar(255);not null;comment:认证方式类型:apiKey,http,oauth2,openIdConnect"`
+	Description  string `gorm:"type:varchar(255);comment:描述"`
+	Content      string `gorm:"type:mediumtext;comment:认证方式配置"`
+	DisplayOrder int    `gorm:"type:int(11);not null;default:0;comment:显示顺序"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func NewSecuritySchemes(ids ...uint) (*SecuritySchemes, error) {
+	if len(ids) > 0 {
+		scheme := &SecuritySchemes{ID: ids[0]}
+		if err := Conn.Take(scheme).Error; err != nil {
+			return scheme, err
+		}
+		return scheme, nil
+	}
+	return &SecuritySchemes{}, nil
+}
+
+func (s *SecuritySchemes) List(projectID uint) ([]*SecuritySchemes, error) {
+	var schemes []*SecuritySchemes
+	return schemes, Conn.Where("project_id = ?", projectID).Order("display_order asc").Order("id asc").Find(&schemes).Error
+}
+
+// GetCountByName 同一项目下名称相同的认证方式数量 不包含自己
+func (s *SecuritySchemes) GetCountByName() (int64, error) {
+	var count int64
+	return count, Conn.Model(&SecuritySchemes{}).Where("project_id = ? AND name = ? AND id != ?", s.ProjectID, s.Name, s.ID).Count(&count).Error
+}
+
+func (s *SecuritySchemes) Create() error {
+	return Conn.Create(s).Error
+}
+
+func (s *SecuritySchemes) Update() error {
+	return Conn.Save(s).Error
+}
+
+func (s *SecuritySchemes) Delete() error {
+	return Conn.Delete(s).Error
+}
+
+// Rename 修改认证方式 同时把接口认证要求中引用的旧名称改为新名称 包括回收站中的接口
+func (s *SecuritySchemes) Rename(oldName string) error {
+	return Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(s).Error; err != nil {
+			return err
+		}
+
+		var collections []*Collections
+		if err := tx.Unscoped().Where("project_id = ? AND content LIKE ?", s.ProjectID, `%"security"%`).Find(&collections).Error; err != nil {
+			return err
+		}
+		for _, c := range collections {
+			content, changed := walkSecurityRequirements(c.Content, func(req map[string]any) bool {
+				scopes, ok := req[oldName]
+				if !ok {
+					return false
+				}
+				delete(req, oldName)
+				req[s.Name] = scopes
+				return true
+			})
+			if !changed {
+				continue
+			}
+			if err := tx.Unscoped().Model(c).Update("content", content).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ReferencedCount 认证要求中引用了该认证方式的接口数量
+func (s *SecuritySchemes) ReferencedCount() (int64, error) {
+	var collections []*Collections
+	if err := Conn.Where("project_id = ? AND content LIKE ?", s.ProjectID, `%"security"%`).Find(&collections).Error; err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, c := range collections {
+		found := false
+		walkSecurityRequirements(c.Content, func(req map[string]any) bool {
+			if _, ok := req[s.Name]; ok {
+				found = true
+			}
+			return false
+		})
+		if found {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// walkSecurityRequirements 遍历接口内容中的认证要求 fn返回true表示修改了该要求
+// 有修改时返回重新序列化的内容
+func walkSecurityRequirements(content string, fn func(req map[string]any) bool) (string, bool) {
+	nodes := []map[string]any{}
+	if err := json.Unmarshal([]byte(content), &nodes); err != nil {
+		return content, false
+	}
+
+	changed := false
+	for _, node := range nodes {
+		if node["type"] != "apicat-http-request" {
+			continue
+		}
+		attrs, _ := node["attrs"].(map[string]any)
+		requirements, _ := attrs["security"].([]any)
+		for _, v := range requirements {
+			if req, ok := v.(map[string]any); ok && fn(req) {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return content, false
+	}
+
+	b, err := json.Marshal(nodes)
+	if err != nil {
+		return content, false
+	}
+	return string(b), true
+}
+
+// ToSpec 解析保存的配置 名称类型和描述以字段为准
+func (s *SecuritySchemes) ToSpec() *spec.SecurityScheme {
+	scheme := &spec.SecurityScheme{}
+	if s.Content != "" {
+		json.Unmarshal([]byte(s.Content), scheme)
+	}
+	scheme.Name = s.Name
+	scheme.Type = s.Type
+	scheme.Description = s.Description
+	return scheme
+}
+
+func (s *SecuritySchemes) SetSpec(scheme *spec.SecurityScheme) {
+	s.Name = scheme.Name
+	s.Type = scheme.Type
+	s.Description = scheme.Description
+	b, _ := json.Marshal(scheme)
+	s.Content = string(b)
+}
+
+func SecuritySchemesImport(projectID uint, schemes spec.SecuritySchemes) {
+	for i, v := range schemes {
+		scheme := &SecuritySchemes{
+			ProjectID:    projectID,
+			DisplayOrder: i,
+		}
+		scheme.SetSpec(v)
+		Conn.Create(scheme)
+	}
+}
+
+func SecuritySchemesExport(projectID uint) spec.SecuritySchemes {
+	scheme, _ := NewSecuritySchemes()
+	schemes, err := scheme.List(projectID)
+	if err != nil {
+		return nil
+	}
+	list := make(spec.SecuritySchemes, 0, len(schemes))
+	for _, v := range schemes {
+		list = append(list, v.ToSpec())
+	}
+	return list
+}
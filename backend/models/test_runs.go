@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TestRuns struct {
+	ID        uint   `gorm:"type:bigint;primaryKey;autoIncrement"`
+	ProjectID uint   `gorm:"type:bigint;index;not null;comment:项目id"`
+	ServerUrl string `gorm:"type:varchar(255);not null;comment:测试的服务器地址"`
+	Status    string `gorm:"type:varchar(255);not null;comment:测试状态:running,passed,failed"`
+	Total     int    `gorm:"type:int(11);not null;default:0;comment:测试的接口数量"`
+	Passed    int    `gorm:"type:int(11);not null;default:0;comment:通过数量"`
+	Failed    int    `gorm:"type:int(11);not null;default:0;comment:失败数量"`
+	Duration  int64  `gorm:"type:bigint;not null;default:0;comment:耗时(毫秒)"`
+	CreatedAt time.Time
+	CreatedBy uint `gorm:"type:bigint;not null;default:0;comment:创建人id"`
+}
+
+type TestRunResults struct {
+	ID           uint   `gorm:"type:bigint;primaryKey;autoIncrement"`
+	TestRunID    uint   `gorm:"type:bigint;index;not null;comment:测试id"`
+	CollectionID uint   `gorm:"type:bigint;not null;comment:集合id"`
+	Title        string `gorm:"type:varchar(255);not null;comment:接口名称"`
+	Path         string `gorm:"type:varchar(255);not null;comment:接口路径"`
+	Method       string `gorm:"type:varchar(255);not null;comment:请求方法"`
+	Url          string `gorm:"type:varchar(1024);comment:请求地址"`
+	StatusCode   int    `gorm:"type:int(11);not null;default:0;comment:响应状态码"`
+	Duration     int64  `gorm:"type:bigint;not null;default:0;comment:耗时(毫秒)"`
+	Passed       bool   `gorm:"type:tinyint(1);not null;default:0;comment:是否通过"`
+	Failures     string `gorm:"type:mediumtext;comment:失败原因"`
+	Tests        string `gorm:"type:mediumtext;comment:脚本测试结果"`
+	Logs         string `gorm:"type:mediumtext;comment:脚本日志"`
+}
+
+func NewTestRuns(ids ...uint) (*TestRuns, error) {
+	if len(ids) > 0 {
+		tr := &TestRuns{ID: ids[0]}
+		if err := Conn.Take(tr).Error; err != nil {
+			return tr, err
+		}
+		return tr, nil
+	}
+	return &TestRuns{}, nil
+}
+
+func (tr *TestRuns) List(projectID uint) ([]*TestRuns, error) {
+	var testRuns []*TestRuns
+	return testRuns, Conn.Where("project_id = ?", projectID).Order("created_at desc").Find(&testRuns).Error
+}
+
+func (tr *TestRuns) Create() error {
+	return Conn.Create(tr).Error
+}
+
+// Finish 测试结束后更新结果汇总并保存每个接口的结果
+func (tr *TestRuns) Finish(results []*TestRunResults) error {
+	return Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(tr).Select("status", "total", "passed", "failed", "duration").Updates(tr).Error; err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			return nil
+		}
+		for _, v := range results {
+			v.TestRunID = tr.ID
+		}
+		return tx.Create(&results).Error
+	})
+}
+
+// FailStale 将创建时间早于before还在执行中的测试标记为失败 服务重启后这些测试不会再继续
+func (tr *TestRuns) FailStale(projectID uint, before time.Time, running, failed string) error {
+	return Conn.Model(&TestRuns{}).
+		Where("project_id = ? AND status = ? AND created_at < ?", projectID, running, before).
+		Update("status", failed).Error
+}
+
+func (tr *TestRuns) Results() ([]*TestRunResults, error) {
+	var results []*TestRunResults
+	return results, Conn.Where("test_run_id = ?", tr.ID).Order("id asc").Find(&results).Error
+}
+
+func (tr *TestRuns) Delete() error {
+	return Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("test_run_id = ?", tr.ID).Delete(&TestRunResults{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(tr).Error
+	})
+}
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Webhooks struct {
+	ID        uint   `gorm:"type:bigint;primaryKey;autoIncrement"`
+	ProjectID uint   `gorm:"type:bigint;index;not null;comment:项目id"`
+	Url       string `gorm:"type:varchar(1024);not null;comment:接收地址"`
+	Secret    string `gorm:"type:varchar(255);comment:签名密钥"`
+	Events    string `gorm:"type:varchar(1024);not null;comment:订阅的事件"`
+	IsEnabled int    `gorm:"type:tinyint(1);not null;default:1;comment:是否启用:0停用,1启用"`
+	CreatedAt time.Time
+	CreatedBy uint `gorm:"type:bigint;not null;default:0;comment:创建人id"`
+	UpdatedAt time.Time
+}
+
+type WebhookDeliveries struct {
+	ID         uint   `gorm:"type:bigint;primaryKey;autoIncrement"`
+	WebhookID  uint   `gorm:"type:bigint;index;not null;comment:webhook id"`
+	DeliveryID string `gorm:"type:varchar(255);not null;comment:投递的唯一标识"`
+	Event      string `gorm:"type:varchar(255);not null;comment:事件"`
+	Payload    string `gorm:"type:mediumtext;comment:发送的内容"`
+	Status     string `gorm:"type:varchar(255);not null;comment:投递状态:pending,success,failed"`
+	StatusCode int    `gorm:"type:int(11);not null;default:0;comment:最后一次响应状态码"`
+	Response   string `gorm:"type:text;comment:最后一次响应内容"`
+	Error      string `gorm:"type:varchar(1024);comment:最后一次错误"`
+	Attempts   int    `gorm:"type:int(11);not null;default:0;comment:发送次数"`
+	Duration   int64  `gorm:"type:bigint;not null;default:0;comment:最后一次耗时(毫秒)"`
+	// NextAttemptAt 待发送的投递到期后由后台任务发送 发送中和发送结束后为空
+	NextAttemptAt *time.Time `gorm:"index;comment:下次发送时间"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func NewWebhooks(ids ...uint) (*Webhooks, error) {
+	if len(ids) > 0 {
+		w := &Webhooks{ID: ids[0]}
+		if err := Conn.Take(w).Error; err != nil {
+			return w, err
+		}
+		return w, nil
+	}
+	return &Webhooks{}, nil
+}
+
+func (w *Webhooks) List(projectID uint) ([]*Webhooks, error) {
+	var webhooks []*Webhooks
+	return webhooks, Conn.Where("project_id = ?", projectID).Order("id asc").Find(&webhooks).Error
+}
+
+// ListByEvent 项目中启用并订阅了事件的webhook
+func (w *Webhooks) ListByEvent(projectID uint, event string) ([]*Webhooks, error) {
+	var webhooks []*Webhooks
+	if err := Conn.Where("project_id = ? AND is_enabled = 1", projectID).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	list := make([]*Webhooks, 0, len(webhooks))
+	for _, v := range webhooks {
+		if v.Subscribed(event) {
+			list = append(list, v)
+		}
+	}
+	return list, nil
+}
+
+func (w *Webhooks) Create() error {
+	return Conn.Create(w).Error
+}
+
+func (w *Webhooks) Update() error {
+	return Conn.Save(w).Error
+}
+
+// Delete 同时删除投递记录
+func (w *Webhooks) Delete() error {
+	return Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", w.ID).Delete(&WebhookDeliveries{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(w).Error
+	})
+}
+
+func (w *Webhooks) GetEvents() []string {
+	events := make([]string, 0)
+	if w.Events != "" {
+		json.Unmarshal([]byte(w.Events), &events)
+	}
+	return events
+}
+
+func (w *Webhooks) SetEvents(events []string) {
+	if events == nil {
+		events = make([]string, 0)
+	}
+	b, _ := json.Marshal(events)
+	w.Events = string(b)
+}
+
+func (w *Webhooks) Subscribed(event string) bool {
+	for _, v := range w.GetEvents() {
+		if v == event {
+			return true
+		}
+	}
+	return false
+}
+
+func NewWebhookDeliveries(ids ...uint) (*WebhookDeliveries, error) {
+	if len(ids) > 0 {
+		wd := &WebhookDeliveries{ID: ids[0]}
+		if err := Conn.Take(wd).Error; err != nil {
+			return wd, err
+		}
+		return wd, nil
+	}
+	return &WebhookDeliveries{}, nil
+}
+
+// List 最近的投递记录 limit为0时返回全部
+func (wd *WebhookDeliveries) List(webhookID uint, limit int) ([]*WebhookDeliveries, error) {
+	var deliveries []*WebhookDeliveries
+	query := Conn.Where("webhook_id = ?", webhookID).Order("created_at desc").Order("id desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	return deliveries, query.Find(&deliveries).Error
+}
+
+func (wd *WebhookDeliveries) Create() error {
+	return Conn.Create(wd).Error
+}
+
+// Update 只更新发送结果 投递记录已经随webhook删除时不会重新创建
+func (wd *WebhookDeliveries) Update() error {
+	return Conn.Model(wd).Select("status", "status_code", "response", "error", "attempts", "duration", "next_attempt_at").Updates(wd).Error
+}
+
+// ListDue 到期需要发送的投递 按下次发送时间排序
+func (wd *WebhookDeliveries) ListDue(status string, now time.Time, limit int) ([]*WebhookDeliveries, error) {
+	var deliveries []*WebhookDeliveries
+	return deliveries, Conn.Where("status = ? AND next_attempt_at <= ?", status, now).Order("next_attempt_at asc").Limit(limit).Find(&deliveries).Error
+}
+
+// Claim 清空下次发送时间表示开始发送 已经被其它任务领取时返回false
+func (wd *WebhookDeliveries) Claim() (bool, error) {
+	tx := Conn.Model(&WebhookDeliveries{}).Where("id = ? AND next_attempt_at IS NOT NULL", wd.ID).Update("next_attempt_at", nil)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	wd.NextAttemptAt = nil
+	return tx.RowsAffected == 1, nil
+}
+
+// Resume 服务退出时正在发送的投递没有下次发送时间 启动后重新发送
+func (wd *WebhookDeliveries) Resume(status string, now time.Time) error {
+	return Conn.Model(&WebhookDeliveries{}).Where("status = ? AND next_attempt_at IS NULL", status).Update("next_attempt_at", now).Error
+}
@@ -151,6 +151,13 @@ export const createCollectionByAI = async ({ project_id, ...params }: any, axios
 // AI通过schema创建集合
 export const createCollectionWithSchemaByAI = async ({ project_id, schema_id }: any) => Ajax.get(`/projects/${project_id}/ai/collections/name?schema_id=${schema_id}`)
 
+// 通过curl命令创建集合
+export const createCollectionByCurl = async ({ project_id, ...data }: any) => Ajax.post(`${baseRestfulApiPath(project_id)}/curl`, data)
+
+// 接口请求代码示例
+export const getCollectionSnippets = async ({ project_id, collection_id, ...params }: any) =>
+  Ajax.get(`${detailRestfulPath(project_id, collection_id)}/snippets${queryStringify(params)}`)
+
 // 文档历史记录列表
 export const getDocumentHistoryRecordList = ({ project_id, collection_id }: Record<string, any>) => Ajax.get(`${detailRestfulPath(project_id, collection_id)}/histories`)
 
@@ -134,6 +134,10 @@ export const enum ExportProjectTypes {
   HTML = 'HTML',
   MARKDOWN = 'md',
   ApiCat = 'apicat',
+  Postman = 'postman',
+  SDK = 'sdk',
+  Server = 'server',
+  AsyncAPI = 'asyncapi',
 }
 
 // 项目导入类型